@@ -9,9 +9,11 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/auth"
 	"github.com/shirenchuang/bilibili-mcp/pkg/config"
 	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+	"github.com/skip2/go-qrcode"
 )
 
 // findConfigFile 智能查找配置文件
@@ -45,9 +47,25 @@ func main() {
 	var (
 		accountName string
 		configPath  string
+		logLevel    string
+		logout      bool
+		deleteName  string
+		useBrowser  bool
+		exportName  string
+		exportOut   string
+		importFile  string
+		passphrase  string
 	)
 	flag.StringVar(&accountName, "account", "", "账号名称（用于区分多账号）")
 	flag.StringVar(&configPath, "config", "config.yaml", "配置文件路径")
+	flag.StringVar(&logLevel, "log-level", "", "日志级别 (debug/info/warn/error)，覆盖配置文件和环境变量")
+	flag.BoolVar(&logout, "logout", false, "退出指定账号（注销服务端会话并删除本地cookies）")
+	flag.StringVar(&deleteName, "delete", "", "从本地删除指定账号及其cookies（不注销服务端会话）")
+	flag.BoolVar(&useBrowser, "browser", false, "打开浏览器登录（默认在终端显示二维码扫码登录）")
+	flag.StringVar(&exportName, "export", "", "导出指定账号的登录信息，用于迁移到其他机器")
+	flag.StringVar(&exportOut, "out", "", "导出文件路径（默认 <账号名>_account.json）")
+	flag.StringVar(&importFile, "import", "", "从导出文件导入账号")
+	flag.StringVar(&passphrase, "passphrase", "", "导出/导入文件的加密口令（可选，导出时设置后导入需提供相同口令）")
 	flag.Parse()
 
 	// 智能查找配置文件
@@ -60,12 +78,59 @@ func main() {
 		os.Exit(1)
 	}
 
+	// 命令行参数和环境变量可覆盖日志级别
+	cfg.ApplyLogLevelOverride(logLevel)
+
 	// 初始化日志系统
 	if err := logger.Init(cfg); err != nil {
 		fmt.Printf("初始化日志系统失败: %v\n", err)
 		os.Exit(1)
 	}
 
+	// 导出账号模式
+	if exportName != "" {
+		if err := exportAccount(exportName, exportOut, passphrase); err != nil {
+			fmt.Printf("❌ 导出账号失败: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 导入账号模式
+	if importFile != "" {
+		if err := importAccount(importFile, passphrase); err != nil {
+			fmt.Printf("❌ 导入账号失败: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 删除账号模式
+	if deleteName != "" {
+		loginService := auth.NewLoginService()
+		if err := loginService.DeleteAccount(deleteName); err != nil {
+			fmt.Printf("❌ 删除账号失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ 账号 '%s' 已从本地删除\n", deleteName)
+		printAccounts(loginService)
+		return
+	}
+
+	// 退出登录模式
+	if logout {
+		if accountName == "" {
+			fmt.Println("❌ 退出登录需要指定账号: ./bilibili-login -logout -account <账号名>")
+			os.Exit(1)
+		}
+		if err := auth.NewLoginService().Logout(accountName); err != nil {
+			fmt.Printf("❌ 退出登录失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ 账号 '%s' 已退出登录，服务端会话已失效\n", accountName)
+		return
+	}
+
 	// 如果没有指定账号名，提示用户输入
 	if accountName == "" {
 		fmt.Print("请输入账号名称（用于区分多账号，直接回车使用'default'）: ")
@@ -88,7 +153,7 @@ func main() {
 	loginService := auth.NewLoginService()
 
 	// 检查账号是否已经存在
-	if isLoggedIn, account, err := loginService.CheckLoginStatus(context.Background(), accountName); err == nil && isLoggedIn && account != nil {
+	if isLoggedIn, account, err := loginService.CheckLoginStatus(context.Background(), accountName, false); err == nil && isLoggedIn && account != nil {
 		fmt.Printf("⚠️  账号 '%s' 已存在\n", accountName)
 		fmt.Printf("   昵称: %s\n", account.Nickname)
 		fmt.Printf("   UID: %s\n", account.UID)
@@ -107,12 +172,21 @@ func main() {
 
 	// 开始登录流程
 	fmt.Println("🔄 开始登录流程...")
-	fmt.Println("🌐 即将打开B站登录页面，支持多种登录方式")
+	if useBrowser {
+		fmt.Println("🌐 即将打开B站登录页面，支持多种登录方式")
+	} else {
+		fmt.Println("📱 请使用B站手机客户端扫描下方二维码登录")
+	}
 	fmt.Println("⏰ 登录超时时间: 5分钟")
 	fmt.Println()
 
 	// 执行登录
-	if err := loginService.Login(context.Background(), accountName); err != nil {
+	if useBrowser {
+		err = loginService.Login(context.Background(), accountName)
+	} else {
+		err = loginService.LoginWithQR(context.Background(), accountName, printQRCode)
+	}
+	if err != nil {
 		logger.Errorf("登录失败: %v", err)
 		fmt.Printf("❌ 登录失败: %v\n", err)
 		os.Exit(1)
@@ -123,22 +197,7 @@ func main() {
 	fmt.Println()
 
 	// 显示当前所有账号
-	accounts, err := loginService.ListAccounts()
-	if err == nil && len(accounts) > 0 {
-		fmt.Println("📋 当前已登录的账号:")
-		for i, acc := range accounts {
-			marker := ""
-			if acc.IsDefault {
-				marker += " (默认)"
-			}
-			if !acc.IsActive {
-				marker += " (未激活)"
-			}
-			fmt.Printf("  %d. %s - %s (UID: %s)%s\n",
-				i+1, acc.Name, acc.Nickname, acc.UID, marker)
-		}
-		fmt.Println()
-	}
+	printAccounts(loginService)
 
 	fmt.Println("🚀 现在可以启动MCP服务了:")
 	fmt.Println("   ./bilibili-mcp")
@@ -146,4 +205,92 @@ func main() {
 	fmt.Println("📖 或者查看更多账号管理命令:")
 	fmt.Println("   ./bilibili-login -account work     # 登录工作账号")
 	fmt.Println("   ./bilibili-login -account personal # 登录个人账号")
+	fmt.Println("   ./bilibili-login -logout -account work # 退出工作账号")
+	fmt.Println("   ./bilibili-login -delete work      # 仅删除本地账号")
+	fmt.Println("   ./bilibili-login -browser          # 打开浏览器使用其他方式登录")
+	fmt.Println("   ./bilibili-login -export work -out work.json # 导出账号用于迁移")
+	fmt.Println("   ./bilibili-login -import work.json # 在新机器上导入账号")
+}
+
+// printAccounts 显示当前所有账号
+func printAccounts(loginService *auth.LoginService) {
+	accounts, err := loginService.ListAccounts()
+	if err != nil || len(accounts) == 0 {
+		return
+	}
+
+	fmt.Println("📋 当前已登录的账号:")
+	for i, acc := range accounts {
+		marker := ""
+		if acc.IsDefault {
+			marker += " (默认)"
+		}
+		if !acc.IsActive {
+			marker += " (未激活)"
+		}
+		if membership := acc.Membership(); membership != "" {
+			marker = " · " + membership + marker
+		}
+		fmt.Printf("  %d. %s - %s (UID: %s)%s\n",
+			i+1, acc.Name, acc.Nickname, acc.UID, marker)
+	}
+	fmt.Println()
+}
+
+// exportAccount 导出账号到文件
+func exportAccount(accountName, outFile, passphrase string) error {
+	if outFile == "" {
+		outFile = accountName + "_account.json"
+	}
+
+	data, err := auth.NewAccountManager().ExportAccount(accountName, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(outFile, data, 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ 账号 '%s' 已导出到 %s\n", accountName, outFile)
+	if passphrase == "" {
+		fmt.Println("⚠️  导出文件包含明文cookies，请妥善保管，导入后及时删除（可使用 -passphrase 加密）")
+	}
+	return nil
+}
+
+// importAccount 从文件导入账号并校验登录状态
+func importAccount(inFile, passphrase string) error {
+	data, err := os.ReadFile(inFile)
+	if err != nil {
+		return err
+	}
+
+	account, err := auth.NewAccountManager().ImportAccount(data, passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ 账号 '%s' 已导入 - %s (UID: %s)\n", account.Name, account.Nickname, account.UID)
+
+	_, _, err = auth.NewLoginService().CheckLoginStatus(context.Background(), account.Name, true)
+	switch {
+	case errors.Is(err, auth.ErrSessionExpired):
+		fmt.Printf("⚠️  导入的登录会话已过期，请重新登录: ./bilibili-login -account %s\n", account.Name)
+	case err != nil:
+		fmt.Printf("⚠️  校验登录状态失败: %v\n", err)
+	default:
+		fmt.Println("🔓 登录会话有效，可以直接使用")
+	}
+	return nil
+}
+
+// printQRCode 在终端以字符形式显示二维码
+func printQRCode(content string) {
+	qr, err := qrcode.New(content, qrcode.Low)
+	if err != nil {
+		fmt.Printf("⚠️  生成二维码失败: %v\n", err)
+		fmt.Printf("请在手机上打开以下链接登录: %s\n", content)
+		return
+	}
+	fmt.Println(qr.ToSmallString(false))
+	fmt.Printf("如果二维码显示异常，请在手机上打开: %s\n\n", content)
 }
@@ -4,13 +4,17 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
 	"github.com/shirenchuang/bilibili-mcp/internal/browser"
 	"github.com/shirenchuang/bilibili-mcp/internal/mcp"
 	"github.com/shirenchuang/bilibili-mcp/pkg/config"
@@ -45,28 +49,50 @@ func findConfigFile(defaultPath string) string {
 
 func main() {
 	// 解析命令行参数
-	var configPath string
+	var (
+		configPath string
+		logLevel   string
+		transport  string
+	)
 	flag.StringVar(&configPath, "config", "config.yaml", "配置文件路径")
+	flag.StringVar(&logLevel, "log-level", "", "日志级别 (debug/info/warn/error)，覆盖配置文件和环境变量")
+	flag.StringVar(&transport, "transport", "http", "传输方式 (http/stdio)，stdio 适用于 Claude Desktop 等通过子进程启动的客户端")
 	flag.Parse()
 
+	if transport != "http" && transport != "stdio" {
+		fmt.Fprintf(os.Stderr, "不支持的传输方式: %s，支持: http, stdio\n", transport)
+		os.Exit(1)
+	}
+
 	// 智能查找配置文件
 	configPath = findConfigFile(configPath)
 
 	// 加载配置
 	cfg, err := config.Load(configPath)
 	if err != nil {
-		fmt.Printf("加载配置失败: %v\n", err)
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
 		os.Exit(1)
 	}
 
+	// 命令行参数和环境变量可覆盖日志级别
+	cfg.ApplyLogLevelOverride(logLevel)
+
+	// stdio模式下stdout专用于协议消息，日志只能输出到stderr和日志文件
+	if transport == "stdio" {
+		logger.SetConsoleOutput(os.Stderr)
+	}
+
 	// 初始化日志系统
 	if err := logger.Init(cfg); err != nil {
-		fmt.Printf("初始化日志系统失败: %v\n", err)
+		fmt.Fprintf(os.Stderr, "初始化日志系统失败: %v\n", err)
 		os.Exit(1)
 	}
 
 	logger.Info("bilibili-mcp 服务启动中...")
 	logger.Infof("配置文件: %s", configPath)
+	if proxyURL := cfg.EffectiveProxyURL(); proxyURL != "" {
+		logger.Infof("🌐 使用代理: %s", api.RedactProxyURL(proxyURL))
+	}
 
 	// 初始化浏览器池
 	logger.Info("初始化浏览器池...")
@@ -80,6 +106,26 @@ func main() {
 	// 创建MCP服务器
 	mcpServer := mcp.NewServer(cfg, browserPool)
 
+	// 可选：后台检查所有账号的登录状态
+	if cfg.Accounts.CheckOnStartup {
+		go mcpServer.LogAccountHealth(context.Background())
+	}
+
+	// 收到SIGHUP时重新加载配置
+	go watchReload(configPath, logLevel, mcpServer)
+
+	if transport == "stdio" {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		logger.Info("MCP服务器以stdio模式运行，等待客户端消息...")
+		if err := mcpServer.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Errorf("stdio服务异常退出: %v", err)
+		}
+		logger.Info("服务器已关闭")
+		return
+	}
+
 	// 创建HTTP服务器
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
@@ -91,6 +137,15 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// 监听非本机地址时，任何能访问该端口的人都可以使用已登录的B站账号
+	if !isLoopback(cfg.Server.Host) {
+		if cfg.Server.AuthToken == "" {
+			logger.Warnf("⚠️ MCP服务监听在 %q，局域网/公网中的其他设备都可以访问并操作已登录的B站账号！强烈建议设置 server.auth_token 开启访问令牌", cfg.Server.Host)
+		} else {
+			logger.Warnf("⚠️ MCP服务监听在 %q，可以从网络访问，已开启访问令牌校验", cfg.Server.Host)
+		}
+	}
+
 	// 启动HTTP服务器
 	go func() {
 		logger.Infof("MCP服务器启动在 http://%s:%s/mcp", cfg.Server.Host, cfg.Server.Port)
@@ -123,12 +178,54 @@ func main() {
 	logger.Info("服务器已关闭")
 }
 
+// watchReload 收到SIGHUP时重新加载配置文件，只有限流、转录和日志级别设置会立即生效
+func watchReload(configPath, logLevel string, mcpServer *mcp.Server) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	for range hup {
+		logger.Infof("🔄 收到SIGHUP，重新加载配置: %s", configPath)
+		previousLevel := config.Get().Logging.Level
+		// 先解析新配置，合并出可热更新的部分后再替换全局配置
+		cfg, err := config.Parse(configPath)
+		if err != nil {
+			logger.Errorf("重新加载配置失败，继续使用当前配置: %v", err)
+			continue
+		}
+		cfg.ApplyLogLevelOverride(logLevel)
+
+		applied := mcpServer.UpdateConfig(cfg)
+		config.Set(applied)
+		if applied.Logging.Level != previousLevel {
+			if err := logger.SetLevel(applied.Logging.Level); err != nil {
+				logger.Warnf("切换日志级别失败: %v", err)
+			} else {
+				logger.Infof("📝 日志级别已切换为 %s", applied.Logging.Level)
+			}
+		}
+		logger.Info("✅ 配置已重新加载")
+	}
+}
+
+// isLoopback 判断监听地址是否只允许本机访问，空地址表示监听所有网卡
+func isLoopback(host string) bool {
+	host = strings.Trim(strings.TrimSpace(host), "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // printUsageInfo 打印使用说明
 func printUsageInfo(cfg *config.Config) {
 	fmt.Println()
 	fmt.Println("🚀 bilibili-mcp 服务已启动！")
 	fmt.Println()
 	fmt.Printf("📡 MCP服务地址: http://%s:%s/mcp\n", cfg.Server.Host, cfg.Server.Port)
+	if !isLoopback(cfg.Server.Host) && cfg.Server.AuthToken == "" {
+		fmt.Println("⚠️  当前监听地址可以从网络访问且未设置 server.auth_token，其他设备可以直接操作你的B站账号！")
+	}
 	fmt.Println()
 	fmt.Println("📋 使用步骤:")
 	fmt.Println("1. 首次使用请先登录B站账号:")
@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"127.0.0.2", true},
+		{"::1", true},
+		{"[::1]", true},
+		{"localhost", true},
+		{"LocalHost", true},
+		{" 127.0.0.1 ", true},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"", false},
+		{"192.168.1.10", false},
+		{"example.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := isLoopback(tt.host); got != tt.want {
+			t.Errorf("isLoopback(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
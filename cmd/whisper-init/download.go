@@ -0,0 +1,160 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// huggingFaceModelURL whisper.cpp 模型在 Hugging Face 上的下载地址
+const huggingFaceModelURL = "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-%s.bin"
+
+var (
+	// modelNamePattern 合法的模型名称，如 base、small.en、large-v3、large-v3-turbo-q5_0
+	modelNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+	// sha256Pattern Hugging Face 在 X-Linked-Etag 中返回的LFS文件SHA256
+	sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// downloadModelFromHuggingFace 从 Hugging Face 下载 ggml-<模型>.bin 到 destDir，
+// 显示下载进度，并用服务端提供的SHA256校验文件，返回模型文件路径
+func downloadModelFromHuggingFace(modelName, destDir string) (string, error) {
+	if !modelNamePattern.MatchString(modelName) {
+		return "", errors.Errorf("无效的模型名称: %s", modelName)
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", errors.Wrap(err, "创建模型目录失败")
+	}
+
+	modelPath := filepath.Join(destDir, fmt.Sprintf("ggml-%s.bin", modelName))
+	tempPath := modelPath + ".downloading"
+	url := fmt.Sprintf(huggingFaceModelURL, modelName)
+
+	// 下载地址会重定向到CDN，SHA256只在重定向前的响应头中提供
+	var expectedSHA256 string
+	client := &http.Client{
+		Timeout: 2 * time.Hour,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("重定向次数过多")
+			}
+			if req.Response != nil && expectedSHA256 == "" {
+				expectedSHA256 = linkedSHA256(req.Response.Header)
+			}
+			return nil
+		},
+	}
+
+	fmt.Printf("📥 下载模型: %s\n", url)
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", errors.Wrap(err, "下载模型失败")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errors.Errorf("模型 %s 不存在，请检查模型名称", modelName)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("下载模型失败: %s", resp.Status)
+	}
+	if expectedSHA256 == "" {
+		expectedSHA256 = linkedSHA256(resp.Header)
+	}
+
+	file, err := os.Create(tempPath)
+	if err != nil {
+		return "", errors.Wrap(err, "创建模型文件失败")
+	}
+
+	hash := sha256.New()
+	progress := &progressWriter{total: resp.ContentLength, start: time.Now()}
+	written, err := io.Copy(io.MultiWriter(file, hash, progress), resp.Body)
+	file.Close()
+	progress.finish()
+	if err != nil {
+		os.Remove(tempPath)
+		return "", errors.Wrap(err, "下载模型失败")
+	}
+	if resp.ContentLength > 0 && written != resp.ContentLength {
+		os.Remove(tempPath)
+		return "", errors.Errorf("模型下载不完整: 预期 %d 字节，实际 %d 字节", resp.ContentLength, written)
+	}
+
+	actualSHA256 := hex.EncodeToString(hash.Sum(nil))
+	switch {
+	case expectedSHA256 == "":
+		fmt.Printf("⚠️  服务端未提供校验值，跳过SHA256校验 (SHA256: %s)\n", actualSHA256)
+	case actualSHA256 != expectedSHA256:
+		os.Remove(tempPath)
+		return "", errors.Errorf("模型SHA256校验失败: 预期 %s，实际 %s", expectedSHA256, actualSHA256)
+	default:
+		fmt.Printf("✅ SHA256校验通过: %s\n", actualSHA256)
+	}
+
+	if err := os.Rename(tempPath, modelPath); err != nil {
+		os.Remove(tempPath)
+		return "", errors.Wrap(err, "保存模型文件失败")
+	}
+	return modelPath, nil
+}
+
+// linkedSHA256 从 Hugging Face 响应头中读取LFS文件的SHA256
+func linkedSHA256(header http.Header) string {
+	etag := strings.ToLower(strings.Trim(header.Get("X-Linked-Etag"), `"`))
+	if sha256Pattern.MatchString(etag) {
+		return etag
+	}
+	return ""
+}
+
+// progressWriter 在终端显示下载进度条
+type progressWriter struct {
+	total      int64
+	written    int64
+	start      time.Time
+	lastRender time.Time
+}
+
+// progressBarWidth 进度条宽度（字符数）
+const progressBarWidth = 30
+
+// Write 实现io.Writer接口，累计字节数并定期刷新进度条
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if time.Since(p.lastRender) >= 200*time.Millisecond {
+		p.render()
+		p.lastRender = time.Now()
+	}
+	return len(b), nil
+}
+
+// render 输出当前进度
+func (p *progressWriter) render() {
+	speed := float64(p.written) / time.Since(p.start).Seconds() / (1024 * 1024)
+	if p.total <= 0 {
+		fmt.Printf("\r   %.1f MB  %.2f MB/s", float64(p.written)/(1024*1024), speed)
+		return
+	}
+
+	ratio := float64(p.written) / float64(p.total)
+	filled := int(ratio * progressBarWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+	fmt.Printf("\r   [%s] %5.1f%%  %.1f/%.1f MB  %.2f MB/s",
+		bar, ratio*100, float64(p.written)/(1024*1024), float64(p.total)/(1024*1024), speed)
+}
+
+// finish 输出最终进度并换行
+func (p *progressWriter) finish() {
+	p.render()
+	fmt.Println()
+}
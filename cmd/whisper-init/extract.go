@@ -0,0 +1,147 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// extractArchive 根据扩展名选择解压方式（支持 .tar.gz/.tgz 和 .zip）
+func extractArchive(archivePath, destDir string) error {
+	lower := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return extractTarGz(archivePath, destDir)
+	case strings.HasSuffix(lower, ".zip"):
+		return extractZip(archivePath, destDir)
+	default:
+		return errors.Errorf("不支持的压缩格式: %s", filepath.Base(archivePath))
+	}
+}
+
+// extractTarGz 使用纯Go解压tar.gz，保留目录结构和文件权限，不依赖系统tar命令
+func extractTarGz(archivePath, destDir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return errors.Wrap(err, "打开压缩文件失败")
+	}
+	defer file.Close()
+
+	gzReader, err := gzip.NewReader(file)
+	if err != nil {
+		return errors.Wrap(err, "读取gzip数据失败")
+	}
+	defer gzReader.Close()
+
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "读取tar条目失败")
+		}
+
+		target, err := safeJoin(destDir, header.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := header.FileInfo().Mode().Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode|0700); err != nil {
+				return errors.Wrapf(err, "创建目录失败: %s", target)
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tarReader, mode); err != nil {
+				return err
+			}
+		default:
+			// 模型归档中不应包含链接或设备文件，直接跳过
+			continue
+		}
+	}
+
+	return nil
+}
+
+// extractZip 使用纯Go解压zip，保留目录结构和文件权限，不依赖系统unzip命令
+func extractZip(archivePath, destDir string) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return errors.Wrap(err, "打开压缩文件失败")
+	}
+	defer reader.Close()
+
+	for _, entry := range reader.File {
+		target, err := safeJoin(destDir, entry.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := entry.Mode().Perm()
+		if entry.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, mode|0700); err != nil {
+				return errors.Wrapf(err, "创建目录失败: %s", target)
+			}
+			continue
+		}
+
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+
+		src, err := entry.Open()
+		if err != nil {
+			return errors.Wrapf(err, "读取压缩条目失败: %s", entry.Name)
+		}
+		err = writeFile(target, src, mode)
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFile 将数据写入目标文件，必要时创建父目录
+func writeFile(target string, src io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return errors.Wrapf(err, "创建目录失败: %s", filepath.Dir(target))
+	}
+
+	if mode == 0 {
+		mode = 0644
+	}
+
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return errors.Wrapf(err, "创建文件失败: %s", target)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return errors.Wrapf(err, "写入文件失败: %s", target)
+	}
+
+	return nil
+}
+
+// safeJoin 拼接解压路径，防止条目通过 ../ 写到目标目录之外
+func safeJoin(destDir, name string) (string, error) {
+	target := filepath.Join(destDir, name)
+	cleanDest := filepath.Clean(destDir) + string(os.PathSeparator)
+	if target != filepath.Clean(destDir) && !strings.HasPrefix(target, cleanDest) {
+		return "", errors.Errorf("压缩条目路径非法: %s", name)
+	}
+	return target, nil
+}
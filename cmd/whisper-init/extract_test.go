@@ -0,0 +1,151 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type archiveEntry struct {
+	name     string
+	body     string
+	mode     int64
+	typeflag byte
+}
+
+// writeTarGz 在临时目录中生成tar.gz测试文件
+func writeTarGz(t *testing.T, entries []archiveEntry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: entry.mode, Typeflag: entry.typeflag, Size: int64(len(entry.body))}
+		if entry.typeflag == tar.TypeSymlink {
+			header.Linkname, header.Size = entry.body, 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Size > 0 {
+			if _, err := tw.Write([]byte(entry.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "model.tar.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtractTarGz(t *testing.T) {
+	archive := writeTarGz(t, []archiveEntry{
+		{name: "ggml-base-encoder.mlmodelc/", mode: 0755, typeflag: tar.TypeDir},
+		{name: "ggml-base-encoder.mlmodelc/model.mil", body: "program", mode: 0644, typeflag: tar.TypeReg},
+		{name: "ggml-base-encoder.mlmodelc/weights/weight.bin", body: "weights", mode: 0600, typeflag: tar.TypeReg},
+		{name: "bin/whisper-cli", body: "#!/bin/sh\n", mode: 0755, typeflag: tar.TypeReg},
+		{name: "bin/link", body: "whisper-cli", mode: 0777, typeflag: tar.TypeSymlink},
+	})
+	dest := t.TempDir()
+
+	if err := extractArchive(archive, dest); err != nil {
+		t.Fatalf("extractArchive: %v", err)
+	}
+
+	files := map[string]struct {
+		body string
+		mode os.FileMode
+	}{
+		"ggml-base-encoder.mlmodelc/model.mil":          {"program", 0644},
+		"ggml-base-encoder.mlmodelc/weights/weight.bin": {"weights", 0600},
+		"bin/whisper-cli": {"#!/bin/sh\n", 0755},
+	}
+	for name, want := range files {
+		path := filepath.Join(dest, filepath.FromSlash(name))
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%s not extracted: %v", name, err)
+		}
+		if string(got) != want.body {
+			t.Errorf("%s = %q, want %q", name, got, want.body)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want.mode {
+			t.Errorf("%s mode = %v, want %v", name, info.Mode().Perm(), want.mode)
+		}
+	}
+
+	if _, err := os.Lstat(filepath.Join(dest, "bin", "link")); !os.IsNotExist(err) {
+		t.Error("symlink entry should be skipped")
+	}
+}
+
+func TestExtractTarGzRejectsTraversal(t *testing.T) {
+	archive := writeTarGz(t, []archiveEntry{
+		{name: "../escape.txt", body: "oops", mode: 0644, typeflag: tar.TypeReg},
+	})
+	dest := filepath.Join(t.TempDir(), "dest")
+
+	if err := extractTarGz(archive, dest); err == nil {
+		t.Fatal("expected error for path traversal")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "escape.txt")); !os.IsNotExist(err) {
+		t.Fatal("file written outside destination")
+	}
+}
+
+func TestExtractTarGzInvalidData(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "broken.tar.gz")
+	if err := os.WriteFile(archive, []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := extractTarGz(archive, t.TempDir()); err == nil {
+		t.Fatal("expected error for invalid gzip data")
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("models/ggml-tiny.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("tiny"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(t.TempDir(), "model.zip")
+	if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := t.TempDir()
+	if err := extractArchive(archive, dest); err != nil {
+		t.Fatalf("extractArchive: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dest, "models", "ggml-tiny.bin"))
+	if err != nil || string(got) != "tiny" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+
+	if err := extractArchive(filepath.Join(dest, "model.rar"), dest); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
@@ -40,11 +41,26 @@ type SystemInfo struct {
 }
 
 func main() {
+	var modelName string
+	flag.StringVar(&modelName, "model", "", "只下载指定的模型到models目录（如 base、small、medium、large-v3），不执行完整初始化")
+	flag.Parse()
+
 	fmt.Println("🎤 Whisper.cpp 初始化工具")
 	fmt.Println("============================")
 
 	setup := &WhisperSetup{}
 
+	if modelName != "" {
+		modelPath, err := downloadModelFromHuggingFace(modelName, setup.findModelsDir())
+		if err != nil {
+			logger.Errorf("下载模型失败: %v", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\n🎉 模型下载完成: %s\n", modelPath)
+		fmt.Println("   将 features.whisper.default_model 设置为该模型名称即可使用")
+		return
+	}
+
 	// 0. 检测系统信息
 	sysInfo := detectSystemInfo()
 	displaySystemInfo(sysInfo)
@@ -202,6 +218,10 @@ func (w *WhisperSetup) checkPrebuiltModels() error {
 		coreMLFound := 0
 		for _, model := range coreMLModels {
 			modelPath := filepath.Join(modelsDir, model)
+			if _, err := os.Stat(modelPath); err != nil {
+				// 尝试解压同目录下的压缩包
+				w.extractCoreMLArchive(modelsDir, model)
+			}
 			if _, err := os.Stat(modelPath); err == nil {
 				coreMLFound++
 				fmt.Printf("🚀 找到 Core ML 加速模型: %s\n", model)
@@ -238,7 +258,7 @@ func (w *WhisperSetup) checkPrebuiltModels() error {
 			fmt.Println("   大小: ~6MB (压缩包)")
 			fmt.Println("   下载地址: https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-base-encoder.mlmodelc.zip")
 			fmt.Println("   直接下载: curl -L -o ggml-base-encoder.mlmodelc.zip 'https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-base-encoder.mlmodelc.zip?download=true'")
-			fmt.Println("   解压命令: unzip ggml-base-encoder.mlmodelc.zip && rm ggml-base-encoder.mlmodelc.zip")
+			fmt.Println("   放入目录后重新运行此工具即可自动解压 (支持 .zip 和 .tar.gz)")
 		}
 
 		fmt.Println()
@@ -251,6 +271,23 @@ func (w *WhisperSetup) checkPrebuiltModels() error {
 	return nil
 }
 
+// extractCoreMLArchive 查找并解压 Core ML 模型压缩包
+func (w *WhisperSetup) extractCoreMLArchive(modelsDir, model string) {
+	for _, ext := range []string{".zip", ".tar.gz", ".tgz"} {
+		archivePath := filepath.Join(modelsDir, model+ext)
+		if _, err := os.Stat(archivePath); err != nil {
+			continue
+		}
+
+		fmt.Printf("📦 正在解压 Core ML 模型: %s\n", filepath.Base(archivePath))
+		if err := extractArchive(archivePath, modelsDir); err != nil {
+			fmt.Printf("⚠️  解压失败: %v\n", err)
+			continue
+		}
+		return
+	}
+}
+
 // checkExistingInstallation 检查现有安装
 func (w *WhisperSetup) checkExistingInstallation() error {
 	fmt.Println("\n2️⃣  检查现有 Whisper.cpp 安装...")
@@ -479,26 +516,10 @@ func (w *WhisperSetup) setupModels() error {
 	return errors.New("无法设置模型：既没有预制模型，也没有安装whisper.cpp")
 }
 
-// downloadModel 下载模型
+// downloadModel 下载模型，直接从 Hugging Face 下载，不依赖whisper.cpp的下载脚本
 func (w *WhisperSetup) downloadModel(modelsPath, modelName string) error {
-	// 使用whisper.cpp提供的下载脚本
-	downloadScript := filepath.Join(modelsPath, "download-ggml-model.sh")
-
-	// 检查下载脚本是否存在
-	if _, err := os.Stat(downloadScript); err != nil {
-		return errors.New("下载脚本不存在，请手动下载模型或使用预制模型")
-	}
-
-	// 执行下载脚本
-	cmd := exec.Command("bash", downloadScript, modelName)
-	cmd.Dir = modelsPath
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return errors.Wrap(err, "下载脚本执行失败")
-	}
-
-	return nil
+	_, err := downloadModelFromHuggingFace(modelName, modelsPath)
+	return err
 }
 
 // updateConfig 更新配置文件
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BV号与AV号互转所需的常量（B站公开的编码算法）
+const (
+	bvXorCode  = 23442827791579
+	bvMaskCode = 2251799813685247
+	bvMaxAid   = 1 << 51
+	bvBase     = 58
+	bvLength   = 12
+	bvAlphabet = "FcwAPNKTMug3GV5Lj7EJnHpWsx4tb8haYeviqBz6rkCy12mUSDQX9RdoZf"
+)
+
+// Bv2Av 将BV号离线转换为AV号，无需请求接口
+func Bv2Av(bvid string) (int64, error) {
+	if len(bvid) != bvLength || !strings.EqualFold(bvid[:3], "BV1") {
+		return 0, errors.Errorf("无效的BV号: %s", bvid)
+	}
+
+	chars := []byte(bvid)
+	chars[3], chars[9] = chars[9], chars[3]
+	chars[4], chars[7] = chars[7], chars[4]
+
+	var tmp int64
+	for _, ch := range chars[3:] {
+		idx := strings.IndexByte(bvAlphabet, ch)
+		if idx < 0 {
+			return 0, errors.Errorf("无效的BV号: %s", bvid)
+		}
+		tmp = tmp*bvBase + int64(idx)
+	}
+
+	aid := (tmp & bvMaskCode) ^ bvXorCode
+	if aid <= 0 {
+		return 0, errors.Errorf("无效的BV号: %s", bvid)
+	}
+	return aid, nil
+}
+
+// Av2Bv 将AV号离线转换为BV号
+func Av2Bv(aid int64) string {
+	chars := []byte("BV1000000000")
+	idx := bvLength - 1
+	for tmp := (bvMaxAid | aid) ^ bvXorCode; tmp > 0; tmp /= bvBase {
+		chars[idx] = bvAlphabet[tmp%bvBase]
+		idx--
+	}
+
+	chars[3], chars[9] = chars[9], chars[3]
+	chars[4], chars[7] = chars[7], chars[4]
+	return string(chars)
+}
@@ -0,0 +1,66 @@
+package api
+
+import "testing"
+
+func TestBvAvConversion(t *testing.T) {
+	tests := []struct {
+		bvid string
+		aid  int64
+	}{
+		{"BV1xx411c7mQ", 1},
+		{"BV1xx411c7mD", 2},
+		{"BV17x411w7KC", 170001},
+		{"BV1y7411Q7Eq", 99999999},
+		{"BV1Q541167Qg", 455017605},
+		{"BV1mK4y1C7Bz", 882584971},
+		{"BV1LZ4Q1Y7ou", 1 << 32},
+		{"BV1L9Uoa9EUx", 111298867365120},
+		{"BV1aPPTfmvQq", bvMaxAid - 1}, // 编码可表示的最大AV号
+	}
+
+	for _, tt := range tests {
+		aid, err := Bv2Av(tt.bvid)
+		if err != nil {
+			t.Errorf("Bv2Av(%q) error: %v", tt.bvid, err)
+		} else if aid != tt.aid {
+			t.Errorf("Bv2Av(%q) = %d, want %d", tt.bvid, aid, tt.aid)
+		}
+
+		if bvid := Av2Bv(tt.aid); bvid != tt.bvid {
+			t.Errorf("Av2Bv(%d) = %q, want %q", tt.aid, bvid, tt.bvid)
+		}
+	}
+}
+
+func TestBv2AvLowercasePrefix(t *testing.T) {
+	aid, err := Bv2Av("bv17x411w7KC")
+	if err != nil || aid != 170001 {
+		t.Fatalf("Bv2Av(lowercase prefix) = %d, %v", aid, err)
+	}
+}
+
+func TestBv2AvInvalid(t *testing.T) {
+	for _, bvid := range []string{
+		"",
+		"BV17x411w7K",   // 长度不足
+		"BV17x411w7KCC", // 长度过长
+		"AV17x411w7KC",  // 前缀错误
+		"BV27x411w7KC",  // 第三位必须是1
+		"BV17x411w7K0",  // 0 不在编码表中
+		"BV17x411w7KI",  // I 不在编码表中
+		"BV17x411w7Kl",  // l 不在编码表中
+	} {
+		if aid, err := Bv2Av(bvid); err == nil {
+			t.Errorf("Bv2Av(%q) = %d, want error", bvid, aid)
+		}
+	}
+}
+
+func TestBvAvRoundTrip(t *testing.T) {
+	for _, aid := range []int64{1, 9, 57, 58, 3364, 195112, 1<<30 - 1, 1 << 40, bvMaxAid - 2} {
+		got, err := Bv2Av(Av2Bv(aid))
+		if err != nil || got != aid {
+			t.Errorf("round trip %d: got %d, %v", aid, got, err)
+		}
+	}
+}
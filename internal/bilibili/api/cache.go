@@ -0,0 +1,105 @@
+package api
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirenchuang/bilibili-mcp/pkg/config"
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultVideoInfoCacheTTL 视频信息缓存默认有效期
+const defaultVideoInfoCacheTTL = 5 * time.Minute
+
+// maxVideoInfoCacheEntries 缓存条目上限，超过时清理过期条目
+const maxVideoInfoCacheEntries = 1000
+
+// videoInfoCacheEntry 视频信息缓存条目
+type videoInfoCacheEntry struct {
+	resp      *VideoInfoResponse
+	expiresAt time.Time
+}
+
+// videoInfoCache 进程内共享的视频信息缓存（各请求的Client独立创建，因此使用包级缓存）
+var videoInfoCache = struct {
+	sync.RWMutex
+	entries map[string]videoInfoCacheEntry
+}{entries: make(map[string]videoInfoCacheEntry)}
+
+// videoInfoGroup 合并并发的相同视频信息请求
+var videoInfoGroup singleflight.Group
+
+// dedupeRequestsEnabled 是否合并并发的相同请求，默认开启
+func dedupeRequestsEnabled() bool {
+	if cfg := config.Get(); cfg != nil {
+		return cfg.Bilibili.DedupeRequests
+	}
+	return true
+}
+
+// videoInfoCacheTTL 获取缓存有效期，配置为0时禁用缓存
+func videoInfoCacheTTL() time.Duration {
+	if cfg := config.Get(); cfg != nil {
+		return cfg.Bilibili.VideoInfoCacheTTL
+	}
+	return defaultVideoInfoCacheTTL
+}
+
+// videoCacheKey 统一视频ID作为缓存键（AV号不区分大小写）
+func videoCacheKey(videoID string) string {
+	if strings.HasPrefix(videoID, "AV") {
+		return strings.ToLower(videoID)
+	}
+	return videoID
+}
+
+// cloneVideoInfo 复制视频信息响应（包括切片字段），避免调用方修改缓存或并发请求共享的结果
+func cloneVideoInfo(resp *VideoInfoResponse) *VideoInfoResponse {
+	if resp == nil {
+		return nil
+	}
+	clone := *resp
+	clone.Data.Pages = append(resp.Data.Pages[:0:0], resp.Data.Pages...)
+	clone.Data.Subtitle.List = append(resp.Data.Subtitle.List[:0:0], resp.Data.Subtitle.List...)
+	clone.Data.Staff = append(resp.Data.Staff[:0:0], resp.Data.Staff...)
+	clone.Data.Tags = append(resp.Data.Tags[:0:0], resp.Data.Tags...)
+	return &clone
+}
+
+// getCachedVideoInfo 读取未过期的缓存，返回缓存条目的副本
+func getCachedVideoInfo(videoID string) (*VideoInfoResponse, bool) {
+	videoInfoCache.RLock()
+	defer videoInfoCache.RUnlock()
+
+	entry, ok := videoInfoCache.entries[videoCacheKey(videoID)]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return cloneVideoInfo(entry.resp), true
+}
+
+// storeVideoInfo 写入缓存，仅缓存成功的响应
+func storeVideoInfo(videoID string, resp *VideoInfoResponse) {
+	ttl := videoInfoCacheTTL()
+	if ttl <= 0 || resp == nil || resp.Code != 0 {
+		return
+	}
+
+	videoInfoCache.Lock()
+	defer videoInfoCache.Unlock()
+
+	now := time.Now()
+	if len(videoInfoCache.entries) >= maxVideoInfoCacheEntries {
+		for key, entry := range videoInfoCache.entries {
+			if now.After(entry.expiresAt) {
+				delete(videoInfoCache.entries, key)
+			}
+		}
+	}
+
+	videoInfoCache.entries[videoCacheKey(videoID)] = videoInfoCacheEntry{
+		resp:      cloneVideoInfo(resp),
+		expiresAt: now.Add(ttl),
+	}
+}
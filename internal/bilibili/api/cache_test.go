@@ -0,0 +1,39 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCachedVideoInfoIsCopy(t *testing.T) {
+	videoInfoCache.Lock()
+	videoInfoCache.entries = make(map[string]videoInfoCacheEntry)
+	videoInfoCache.Unlock()
+
+	var resp VideoInfoResponse
+	if err := json.Unmarshal([]byte(`{"code":0,"data":{"title":"原始标题","pages":[{"part":"P1"}]}}`), &resp); err != nil {
+		t.Fatal(err)
+	}
+	storeVideoInfo("BV1test", &resp)
+
+	// 写入缓存后修改原响应，不应影响缓存
+	resp.Data.Title = "调用方修改"
+	resp.Data.Pages[0].Part = "调用方修改"
+
+	first, ok := getCachedVideoInfo("BV1test")
+	if !ok {
+		t.Fatal("cache miss")
+	}
+	if first.Data.Title != "原始标题" || first.Data.Pages[0].Part != "P1" {
+		t.Fatalf("cache shares memory with the stored response: %q %q", first.Data.Title, first.Data.Pages[0].Part)
+	}
+
+	// 修改读取到的副本，不应影响下一次读取
+	first.Data.Title = "读取方修改"
+	first.Data.Pages[0].Part = "读取方修改"
+
+	second, _ := getCachedVideoInfo("BV1test")
+	if second.Data.Title != "原始标题" || second.Data.Pages[0].Part != "P1" {
+		t.Fatalf("cache returned a shared pointer: %q %q", second.Data.Title, second.Data.Pages[0].Part)
+	}
+}
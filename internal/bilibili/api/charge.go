@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// 充电B币数量范围
+const (
+	MinChargeBP = 2
+	MaxChargeBP = 9999
+)
+
+// 充电订单状态
+const (
+	ChargeStatusSuccess      = 4  // 充电成功
+	ChargeStatusBelowMinimum = -2 // 低于最低充电数量
+	ChargeStatusNoBalance    = -4 // B币余额不足
+)
+
+// ErrChargeResultUnknown 充电请求已发出但没有拿到明确结果（连接中断、5xx或响应无法解析），
+// 服务端可能已经扣款，不能自动重新提交
+var ErrChargeResultUnknown = errors.New("充电结果未知，服务端可能已经扣款")
+
+// ChargeResponse 充电API响应
+type ChargeResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Mid     int64  `json:"mid"`      // 充电用户UID
+		UpMid   int64  `json:"up_mid"`   // UP主UID
+		OrderNo string `json:"order_no"` // 订单号
+		BpNum   string `json:"bp_num"`   // 充电B币数量
+		Exp     int    `json:"exp"`      // 获得的经验
+		Status  int    `json:"status"`   // 订单状态
+		Msg     string `json:"msg"`      // 状态说明
+	} `json:"data"`
+}
+
+// ChargeUser 使用B币余额为UP主充电，会实际花费B币。
+// 请求只发送一次，无法确定是否扣款时返回 ErrChargeResultUnknown
+func (c *Client) ChargeUser(upMid int64, count int) (*ChargeResponse, error) {
+	if count < MinChargeBP || count > MaxChargeBP {
+		return nil, errors.Errorf("充电数量必须在 %d-%d B币之间", MinChargeBP, MaxChargeBP)
+	}
+
+	csrf, ok := c.cookies["bili_jct"]
+	if !ok || csrf == "" {
+		return nil, errors.New("缺少CSRF token，请确保已登录")
+	}
+
+	mid := strconv.FormatInt(upMid, 10)
+	data := url.Values{
+		"bp_num":              {strconv.Itoa(count)},
+		"is_bp_remains_prior": {"true"},
+		"up_mid":              {mid},
+		"otype":               {"up"},
+		"oid":                 {mid},
+		"csrf":                {csrf},
+	}
+
+	// 涉及真实扣款，不经过带重试的 makeRequest
+	req, err := http.NewRequestWithContext(c.context(), "POST", "https://api.bilibili.com/x/ugcpay/web/v2/trade/elec/pay/quick", strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "创建POST请求失败")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	req.Header.Set("Cookie", c.getCookieString())
+	for key, value := range c.getHeaders("https://space.bilibili.com/" + mid) {
+		req.Header.Set(key, value)
+	}
+
+	status, body, err := c.doOnce(req, data)
+	if err != nil {
+		return nil, errors.Wrap(ErrChargeResultUnknown, err.Error())
+	}
+	if status >= http.StatusInternalServerError {
+		return nil, errors.Wrapf(ErrChargeResultUnknown, "HTTP %d", status)
+	}
+
+	var resp ChargeResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(ErrChargeResultUnknown, "解析充电API响应失败")
+	}
+
+	return &resp, nil
+}
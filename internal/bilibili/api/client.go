@@ -1,9 +1,10 @@
 package api
 
 import (
+	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -11,22 +12,39 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/pkg/config"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
 )
 
 // Client B站API客户端
 type Client struct {
 	httpClient *http.Client
 	cookies    map[string]string
+	noCache    bool            // 跳过视频信息缓存
+	debugHTTP  bool            // 记录请求和响应，用于调试
+	ctx        context.Context // 请求上下文，控制重试的截止时间
 }
 
 // NewClient 创建API客户端
 func NewClient(cookies map[string]string) *Client {
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second, // 增加到60秒，支持较慢的API请求
-		},
-		cookies: cookies,
+	client := &Client{
+		httpClient: NewHTTPClient(60 * time.Second), // 60秒超时，支持较慢的API请求
+		cookies:    cookies,
 	}
+	if cfg := config.Get(); cfg != nil {
+		client.debugHTTP = cfg.Bilibili.DebugHTTP
+	}
+	return client
+}
+
+// SetHTTPClient 替换发送API请求使用的HTTP客户端，如自定义代理或传输层
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// SetNoCache 设置是否跳过视频信息缓存（结果仍会写入缓存）
+func (c *Client) SetNoCache(noCache bool) {
+	c.noCache = noCache
 }
 
 // getHeaders 获取标准请求头
@@ -77,15 +95,20 @@ func (c *Client) makeRequest(method, url string, data url.Values, headers map[st
 		req.Header.Set(key, value)
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, errors.Wrap(err, "HTTP请求失败")
+	// GET参数已拼接在URL中，只有POST需要单独记录表单
+	form := data
+	if method != "POST" {
+		form = nil
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	status, body, err := c.doRequest(req, form)
 	if err != nil {
-		return nil, errors.Wrap(err, "读取响应失败")
+		return nil, err
+	}
+
+	// HTTP 412 是风控拦截页，响应体不是JSON
+	if status == http.StatusPreconditionFailed {
+		return nil, errRiskControl
 	}
 
 	return body, nil
@@ -100,6 +123,15 @@ type NavResponse struct {
 		Uname   string `json:"uname"`
 		Mid     int64  `json:"mid"`
 		Face    string `json:"face"`
+		WbiImg  struct {
+			ImgURL string `json:"img_url"`
+			SubURL string `json:"sub_url"`
+		} `json:"wbi_img"` // WBI签名密钥
+		LevelInfo struct {
+			CurrentLevel int `json:"current_level"` // 账号等级
+		} `json:"level_info"`
+		VipStatus int `json:"vipStatus"` // 大会员状态：1=有效
+		VipType   int `json:"vipType"`   // 大会员类型：1=月度 2=年度及以上
 	} `json:"data"`
 }
 
@@ -255,39 +287,18 @@ type VideoInfoResponse struct {
 // videoIDToAID 辅助函数：将BV号或AV号转换为AID
 func (c *Client) videoIDToAID(videoID string) (int64, error) {
 	if strings.HasPrefix(videoID, "BV") {
-		// 调用API转换BV到AID
-		apiURL := fmt.Sprintf("https://api.bilibili.com/x/web-interface/view?bvid=%s", videoID)
-		req, err := http.NewRequest("GET", apiURL, nil)
-		if err != nil {
-			return 0, errors.Wrap(err, "创建请求失败")
+		// 优先离线转换，格式异常时再通过视频信息接口转换（共享视频信息缓存）
+		if aid, err := Bv2Av(videoID); err == nil {
+			return aid, nil
 		}
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-		req.Header.Set("Referer", "https://www.bilibili.com")
-
-		resp, err := c.httpClient.Do(req)
+		videoInfo, err := c.GetVideoInfo(videoID)
 		if err != nil {
-			return 0, errors.Wrap(err, "API请求失败")
+			return 0, errors.Wrap(err, "BV转AID失败")
 		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return 0, errors.Wrap(err, "读取响应失败")
+		if videoInfo.Code != 0 {
+			return 0, errors.Errorf("BV转AID API返回错误: code %d", videoInfo.Code)
 		}
-
-		var viewResp struct {
-			Code int `json:"code"`
-			Data struct {
-				AID int64 `json:"aid"`
-			} `json:"data"`
-		}
-		if err := json.Unmarshal(body, &viewResp); err != nil {
-			return 0, errors.Wrap(err, "解析BV转AID API响应失败")
-		}
-		if viewResp.Code != 0 {
-			return 0, errors.Errorf("BV转AID API返回错误: code %d", viewResp.Code)
-		}
-		return viewResp.Data.AID, nil
+		return videoInfo.Data.Aid, nil
 	} else if strings.HasPrefix(videoID, "av") || strings.HasPrefix(videoID, "AV") {
 		aidStr := strings.TrimPrefix(strings.ToLower(videoID), "av")
 		aid, err := strconv.ParseInt(aidStr, 10, 64)
@@ -299,6 +310,14 @@ func (c *Client) videoIDToAID(videoID string) (int64, error) {
 	return 0, errors.New("无效的视频ID格式，应为BV号或AV号")
 }
 
+// videoIDParams 根据视频ID类型构建bvid或aid请求参数
+func videoIDParams(videoID string) url.Values {
+	if strings.HasPrefix(videoID, "av") || strings.HasPrefix(videoID, "AV") {
+		return url.Values{"aid": {strings.TrimPrefix(strings.ToLower(videoID), "av")}}
+	}
+	return url.Values{"bvid": {videoID}}
+}
+
 // getVideoAid 从videoID获取aid (已废弃，使用videoIDToAID)
 func (c *Client) getVideoAid(videoID string) (int64, error) {
 	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
@@ -323,13 +342,36 @@ func (c *Client) getVideoAid(videoID string) (int64, error) {
 	return resp.Data.Aid, nil
 }
 
-// GetVideoInfo 获取视频信息
+// GetVideoInfo 获取视频信息，成功结果会在TTL内缓存
 func (c *Client) GetVideoInfo(videoID string) (*VideoInfoResponse, error) {
-	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
-	data := url.Values{
-		"bvid": {videoID},
+	if !c.noCache {
+		if cached, ok := getCachedVideoInfo(videoID); ok {
+			return cached, nil
+		}
 	}
 
+	if !dedupeRequestsEnabled() {
+		return c.fetchVideoInfo(videoID)
+	}
+
+	// 并发的相同请求共享同一次结果，BV转AID也经过这里
+	value, err, shared := videoInfoGroup.Do(videoCacheKey(videoID), func() (interface{}, error) {
+		return c.fetchVideoInfo(videoID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if shared {
+		return cloneVideoInfo(value.(*VideoInfoResponse)), nil
+	}
+	return value.(*VideoInfoResponse), nil
+}
+
+// fetchVideoInfo 请求视频信息接口并写入缓存
+func (c *Client) fetchVideoInfo(videoID string) (*VideoInfoResponse, error) {
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	data := videoIDParams(videoID)
+
 	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/web-interface/view", data, headers)
 	if err != nil {
 		return nil, err
@@ -340,6 +382,7 @@ func (c *Client) GetVideoInfo(videoID string) (*VideoInfoResponse, error) {
 		return nil, errors.Wrap(err, "解析视频信息API响应失败")
 	}
 
+	storeVideoInfo(videoID, &resp)
 	return &resp, nil
 }
 
@@ -392,6 +435,139 @@ func (c *Client) LikeVideo(videoID string, like int) (*LikeResponse, error) {
 	return &resp, nil
 }
 
+// DislikeVideo 标记视频为不喜欢，dislike: 1=不喜欢 2=取消不喜欢
+func (c *Client) DislikeVideo(videoID string, dislike int) (*LikeResponse, error) {
+	csrf, exists := c.cookies["bili_jct"]
+	if !exists || csrf == "" {
+		return nil, errors.New("缺少CSRF token (bili_jct)")
+	}
+
+	data := videoIDParams(videoID)
+	data.Set("csrf", csrf)
+
+	apiURL := "https://api.bilibili.com/x/web-interface/archive/dislike"
+	if dislike == 2 {
+		apiURL = "https://api.bilibili.com/x/web-interface/archive/dislike/cancel"
+	}
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	body, err := c.makeRequest("POST", apiURL, data, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp LikeResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析不喜欢API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// ShareResponse 分享视频API响应
+type ShareResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    int64  `json:"data"` // 分享后视频的分享数
+}
+
+// ShareVideo 分享视频，增加视频的分享数
+func (c *Client) ShareVideo(videoID string) (*ShareResponse, error) {
+	aid, err := c.videoIDToAID(videoID)
+	if err != nil {
+		return nil, errors.Wrap(err, "转换视频ID为AID失败")
+	}
+
+	csrf, exists := c.cookies["bili_jct"]
+	if !exists || csrf == "" {
+		return nil, errors.New("缺少CSRF token (bili_jct)")
+	}
+
+	data := url.Values{
+		"aid":  {strconv.FormatInt(aid, 10)},
+		"csrf": {csrf},
+	}
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	body, err := c.makeRequest("POST", "https://api.bilibili.com/x/web-interface/share/add", data, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ShareResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析分享API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// TripleResponse 一键三连API响应
+type TripleResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Like     bool `json:"like"`     // 是否点赞成功
+		Coin     bool `json:"coin"`     // 是否投币成功
+		Fav      bool `json:"fav"`      // 是否收藏成功
+		Multiply int  `json:"multiply"` // 投币数量
+	} `json:"data"`
+}
+
+// TripleVideo 一键三连（点赞+投币+收藏）
+func (c *Client) TripleVideo(videoID string) (*TripleResponse, error) {
+	csrf, exists := c.cookies["bili_jct"]
+	if !exists || csrf == "" {
+		return nil, errors.New("缺少CSRF token (bili_jct)")
+	}
+
+	data := videoIDParams(videoID)
+	data.Set("csrf", csrf)
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	body, err := c.makeRequest("POST", "https://api.bilibili.com/x/web-interface/archive/like/triple", data, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp TripleResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析一键三连API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// RelationResponse 视频互动状态API响应
+type RelationResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Attention bool `json:"attention"`  // 是否关注UP主
+		Favorite  bool `json:"favorite"`   // 是否收藏
+		SeasonFav bool `json:"season_fav"` // 是否收藏合集
+		Like      bool `json:"like"`       // 是否点赞
+		Dislike   bool `json:"dislike"`    // 是否不喜欢
+		Coin      int  `json:"coin"`       // 已投币数量
+	} `json:"data"`
+}
+
+// GetVideoRelation 获取当前账号与视频的互动状态（点赞/投币/收藏/不喜欢），未登录时接口返回默认值
+func (c *Client) GetVideoRelation(videoID string) (*RelationResponse, error) {
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/web-interface/archive/relation", videoIDParams(videoID), headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp RelationResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析视频互动状态API响应失败")
+	}
+
+	return &resp, nil
+}
+
 // PlayUrlResponse 视频播放地址API响应
 type PlayUrlResponse struct {
 	Code    int    `json:"code"`
@@ -428,7 +604,7 @@ func (c *Client) GetPlayUrl(videoID string) (*PlayUrlResponse, error) {
 	}
 
 	if videoInfo.Code != 0 {
-		return nil, errors.Errorf("获取视频信息失败: %s (code: %d)", videoInfo.Message, videoInfo.Code)
+		return nil, errors.Wrap(NewAPIError(videoInfo.Code, videoInfo.Message), "获取视频信息失败")
 	}
 
 	// 使用第一个分P的CID
@@ -470,15 +646,9 @@ func (c *Client) GetPlayUrl(videoID string) (*PlayUrlResponse, error) {
 		req.Header.Set("Cookie", cookieStr)
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, errors.Wrap(err, "API请求失败")
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	_, body, err := c.doRequest(req, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, "读取响应失败")
+		return nil, err
 	}
 
 	var playUrlResp PlayUrlResponse
@@ -526,18 +696,57 @@ type UserVideosResponse struct {
 	} `json:"data"`
 }
 
+// ErrSpaceSearchRateLimited 空间投稿搜索被风控，携带错误码以便调用方按API错误分类处理
+var ErrSpaceSearchRateLimited = NewAPIError(CodeRequestBlocked, "B站正在限制空间投稿搜索，请稍后再试，或指定已登录的账号")
+
+// errRiskControl 单次请求被风控拦截（HTTP 412）
+var errRiskControl = NewAPIError(CodeRequestBlocked, "请求被风控拦截")
+
 // GetUserVideos 获取用户投稿视频列表
+// 该接口最容易触发风控(-412)，触发时按配置退避后携带buvid3并重新签名重试
 func (c *Client) GetUserVideos(userID string, page, pageSize int) (*UserVideosResponse, error) {
-	// 构建API请求参数 - 使用更稳定的参数组合
+	retries, backoff := 1, 3*time.Second
+	if cfg := config.Get(); cfg != nil {
+		retries = cfg.Bilibili.SpaceSearchRetries
+		if cfg.Bilibili.SpaceSearchBackoff > 0 {
+			backoff = cfg.Bilibili.SpaceSearchBackoff
+		}
+	}
+
+	resp, err := c.searchSpaceVideos(userID, page, pageSize)
+	for attempt := 1; attempt <= retries && isRiskControlled(resp, err); attempt++ {
+		wait := backoff * time.Duration(attempt)
+		logger.Warnf("⚠️ 空间投稿搜索触发风控，%v 后进行第 %d 次重试 - 用户: %s", wait, attempt, userID)
+		if err := c.sleep(wait); err != nil {
+			return nil, errors.Wrap(err, "等待重试时请求已取消")
+		}
+
+		c.ensureBuvid3()
+		invalidateWbiKey()
+		resp, err = c.searchSpaceVideos(userID, page, pageSize)
+	}
+
+	if isRiskControlled(resp, err) {
+		return nil, ErrSpaceSearchRateLimited
+	}
+	return resp, err
+}
+
+// searchSpaceVideos 发起一次WBI签名的空间投稿搜索请求
+func (c *Client) searchSpaceVideos(userID string, page, pageSize int) (*UserVideosResponse, error) {
 	params := url.Values{
 		"mid":   {userID},
 		"pn":    {fmt.Sprintf("%d", page)},
 		"ps":    {fmt.Sprintf("%d", pageSize)},
 		"order": {"pubdate"}, // 按发布时间排序
-		"jsonp": {"jsonp"},   // 添加jsonp参数提高兼容性
 	}
 
-	apiURL := fmt.Sprintf("https://api.bilibili.com/x/space/arc/search?%s", params.Encode())
+	query, err := c.signWbi(params)
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := fmt.Sprintf("https://api.bilibili.com/x/space/wbi/arc/search?%s", query)
 	req, err := http.NewRequest("GET", apiURL, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "创建请求失败")
@@ -558,15 +767,13 @@ func (c *Client) GetUserVideos(userID string, page, pageSize int) (*UserVideosRe
 		req.Header.Set("Cookie", cookieStr)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	status, body, err := c.doRequest(req, nil)
 	if err != nil {
-		return nil, errors.Wrap(err, "API请求失败")
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, errors.Wrap(err, "读取响应失败")
+	if status == http.StatusPreconditionFailed {
+		return nil, errRiskControl
 	}
 
 	var userVideosResp UserVideosResponse
@@ -577,6 +784,31 @@ func (c *Client) GetUserVideos(userID string, page, pageSize int) (*UserVideosRe
 	return &userVideosResp, nil
 }
 
+// isRiskControlled 判断响应是否被风控拦截
+func isRiskControlled(resp *UserVideosResponse, err error) bool {
+	if err != nil {
+		return errors.Is(err, errRiskControl)
+	}
+	return resp != nil && resp.Code == -412
+}
+
+// ensureBuvid3 确保请求携带buvid3设备标识，未登录请求缺少该cookie时极易被风控
+func (c *Client) ensureBuvid3() {
+	if c.cookies == nil {
+		c.cookies = make(map[string]string)
+	}
+	if c.cookies["buvid3"] != "" {
+		return
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return
+	}
+	c.cookies["buvid3"] = fmt.Sprintf("%X-%X-%X-%X-%X%05dinfoc",
+		buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16], time.Now().UnixNano()%100000)
+}
+
 // CoinVideoResponse 投币视频API响应
 type CoinVideoResponse struct {
 	Code    int    `json:"code"`
@@ -637,6 +869,30 @@ type FavoriteVideoResponse struct {
 
 // FavoriteVideo 收藏视频
 func (c *Client) FavoriteVideo(videoID string, folderIDs []string, addMedia bool) (*FavoriteVideoResponse, error) {
+	// 如果没有指定收藏夹，尝试获取用户默认收藏夹
+	if len(folderIDs) == 0 {
+		folderIDs = c.defaultFavoriteFolderIDs()
+	}
+
+	return c.DealFavorite(videoID, folderIDs, nil)
+}
+
+// UnfavoriteVideo 将视频移出收藏夹，未指定收藏夹时移出默认收藏夹
+func (c *Client) UnfavoriteVideo(videoID string, folderIDs []string) (*FavoriteVideoResponse, error) {
+	if len(folderIDs) == 0 {
+		folderIDs = c.defaultFavoriteFolderIDs()
+	}
+
+	return c.DealFavorite(videoID, nil, folderIDs)
+}
+
+// DealFavorite 修改视频所在的收藏夹：加入 addIDs 中的收藏夹，同时移出 delIDs 中的收藏夹，
+// 两者同时指定即可在收藏夹之间移动视频
+func (c *Client) DealFavorite(videoID string, addIDs, delIDs []string) (*FavoriteVideoResponse, error) {
+	if len(addIDs) == 0 && len(delIDs) == 0 {
+		return nil, errors.New("至少需要指定一个要加入或移出的收藏夹")
+	}
+
 	// 转换videoID为AID
 	aid, err := c.videoIDToAID(videoID)
 	if err != nil {
@@ -649,23 +905,11 @@ func (c *Client) FavoriteVideo(videoID string, folderIDs []string, addMedia bool
 		return nil, errors.New("缺少CSRF token，请确保已登录")
 	}
 
-	// 如果没有指定收藏夹，尝试获取用户默认收藏夹
-	if len(folderIDs) == 0 {
-		// 先尝试获取用户的收藏夹列表来找到默认收藏夹
-		defaultFolders, err := c.getDefaultFavoriteFolder()
-		if err != nil {
-			// 如果获取失败，使用通用的默认值
-			folderIDs = []string{"1"}
-		} else {
-			folderIDs = defaultFolders
-		}
-	}
-
 	data := url.Values{
 		"rid":           {fmt.Sprintf("%d", aid)},
 		"type":          {"2"}, // 视频类型
-		"add_media_ids": {strings.Join(folderIDs, ",")},
-		"del_media_ids": {""},
+		"add_media_ids": {strings.Join(addIDs, ",")},
+		"del_media_ids": {strings.Join(delIDs, ",")},
 		"csrf":          {csrf},
 	}
 
@@ -686,55 +930,31 @@ func (c *Client) FavoriteVideo(videoID string, folderIDs []string, addMedia bool
 	return &resp, nil
 }
 
-// getDefaultFavoriteFolder 获取用户的默认收藏夹ID
-func (c *Client) getDefaultFavoriteFolder() ([]string, error) {
-	// 尝试获取用户的收藏夹列表
-	headers := c.getHeaders("https://www.bilibili.com")
-
-	// 构建获取收藏夹列表的URL
-	// 需要用户的mid，但我们这里先尝试不指定mid的方式
-	apiURL := "https://api.bilibili.com/x/v3/fav/folder/created/list-all?up_mid=0"
-
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return []string{"1"}, nil // 返回默认值
-	}
-
-	// 设置headers
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
-
-	resp, err := c.httpClient.Do(req)
+// defaultFavoriteFolderIDs 获取用户的默认收藏夹ID，获取失败时使用通用的默认值
+func (c *Client) defaultFavoriteFolderIDs() []string {
+	defaultFolders, err := c.getDefaultFavoriteFolder()
 	if err != nil {
-		return []string{"1"}, nil // 返回默认值
+		return []string{"1"}
 	}
-	defer resp.Body.Close()
+	return defaultFolders
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+// getDefaultFavoriteFolder 获取用户的默认收藏夹ID
+func (c *Client) getDefaultFavoriteFolder() ([]string, error) {
+	// 收藏夹列表需要当前登录用户的mid
+	nav, err := c.GetNavInfo()
+	if err != nil || nav.Code != 0 || !nav.Data.IsLogin {
 		return []string{"1"}, nil // 返回默认值
 	}
 
-	var favResp struct {
-		Code int `json:"code"`
-		Data struct {
-			List []struct {
-				ID    int64  `json:"id"`
-				Title string `json:"title"`
-				Attr  int    `json:"attr"`
-			} `json:"list"`
-		} `json:"data"`
-	}
-
-	if err := json.Unmarshal(body, &favResp); err != nil || favResp.Code != 0 {
+	favResp, err := c.ListFavoriteFolders(nav.Data.Mid)
+	if err != nil || favResp.Code != 0 {
 		return []string{"1"}, nil // 返回默认值
 	}
 
-	// 寻找默认收藏夹（通常是第一个或者title为"默认收藏夹"的）
+	// 默认收藏夹总是列表中的第一个
 	if len(favResp.Data.List) > 0 {
-		defaultFolder := favResp.Data.List[0]
-		return []string{fmt.Sprintf("%d", defaultFolder.ID)}, nil
+		return []string{fmt.Sprintf("%d", favResp.Data.List[0].ID)}, nil
 	}
 
 	return []string{"1"}, nil // 返回默认值
@@ -956,32 +1176,11 @@ func (c *Client) GetVideoStream(videoID string, cid int64, quality int, fnval in
 		params.Set("try_look", "1")
 	}
 
-	// 构建请求URL
-	apiURL := "https://api.bilibili.com/x/player/wbi/playurl?" + params.Encode()
-
-	// 创建请求
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "创建请求失败")
-	}
-
-	// 设置headers
+	// 携带账号cookies请求，大会员清晰度和无损/杜比音轨只对已登录的会话返回
 	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
-
-	// 发送请求
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, errors.Wrap(err, "发送请求失败")
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/player/wbi/playurl", params, headers)
 	if err != nil {
-		return nil, errors.Wrap(err, "读取响应失败")
+		return nil, err
 	}
 
 	// 解析响应
@@ -992,7 +1191,7 @@ func (c *Client) GetVideoStream(videoID string, cid int64, quality int, fnval in
 
 	// 检查API响应状态
 	if streamResp.Code != 0 {
-		return nil, fmt.Errorf("获取视频流失败: %s (code: %d)", streamResp.Message, streamResp.Code)
+		return nil, errors.Wrap(NewAPIError(streamResp.Code, streamResp.Message), "获取视频流失败")
 	}
 
 	return &streamResp, nil
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// rewriteTransport 将所有请求转发到测试服务器，保留原请求的路径和参数
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newTestClient 创建请求全部发往测试服务器的客户端
+func newTestClient(t *testing.T, server *httptest.Server, cookies map[string]string) *Client {
+	t.Helper()
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient(cookies)
+	c.SetHTTPClient(&http.Client{Transport: rewriteTransport{target: target}})
+	return c
+}
+
+func TestGetVideoStreamSendsCookies(t *testing.T) {
+	var gotPath, gotSession string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if cookie, err := r.Cookie("SESSDATA"); err == nil {
+			gotSession = cookie.Value
+		}
+		w.Write([]byte(`{"code":0,"data":{"quality":120}}`))
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server, map[string]string{"SESSDATA": "vip-session"})
+	resp, err := c.GetVideoStream("BV1xx411c7mD", 1, 120, 4048, "pc")
+	if err != nil {
+		t.Fatalf("GetVideoStream: %v", err)
+	}
+
+	if gotPath != "/x/player/wbi/playurl" {
+		t.Fatalf("request path = %q", gotPath)
+	}
+	// 取流请求必须携带账号cookies，否则只能拿到未登录的清晰度
+	if gotSession != "vip-session" {
+		t.Fatalf("SESSDATA cookie = %q, want the account session", gotSession)
+	}
+	if resp.Data.Quality != 120 {
+		t.Fatalf("quality = %d", resp.Data.Quality)
+	}
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// 评论详情接口的业务错误码
+const (
+	CommentCodeDeleted      = 12022 // 评论已被删除
+	CommentCodeNotExist     = 12009 // 评论主体不存在
+	CommentCodeAreaDisabled = 12002 // 评论区已关闭
+)
+
+// CommentReply 评论内容
+type CommentReply struct {
+	Rpid      int64 `json:"rpid"`      // 评论ID
+	Oid       int64 `json:"oid"`       // 评论区对象ID（视频AV号）
+	Root      int64 `json:"root"`      // 根评论ID，为0时本身是根评论
+	Parent    int64 `json:"parent"`    // 父评论ID
+	Like      int64 `json:"like"`      // 点赞数
+	Rcount    int64 `json:"rcount"`    // 回复数
+	State     int   `json:"state"`     // 评论状态
+	Ctime     int64 `json:"ctime"`     // 发布时间
+	Invisible bool  `json:"invisible"` // 是否对他人不可见
+	Member    struct {
+		Mid   string `json:"mid"`   // 评论者UID
+		Uname string `json:"uname"` // 评论者用户名
+	} `json:"member"`
+	Content struct {
+		Message string `json:"message"` // 评论内容
+	} `json:"content"`
+}
+
+// CommentDetailResponse 评论详情API响应
+type CommentDetailResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Root *CommentReply `json:"root"`
+	} `json:"data"`
+}
+
+// GetCommentDetail 获取视频评论详情，oid为视频AV号
+func (c *Client) GetCommentDetail(oid int64, rpid string) (*CommentDetailResponse, error) {
+	params := url.Values{
+		"type": {"1"}, // 1: 视频评论区
+		"oid":  {strconv.FormatInt(oid, 10)},
+		"root": {rpid},
+		"ps":   {"1"},
+	}
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/av%d", oid))
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/v2/reply/detail", params, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp CommentDetailResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析评论详情API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// 评论排序方式
+const (
+	CommentSortTime = 0 // 按时间
+	CommentSortLike = 1 // 按热度（点赞）
+)
+
+// CommentListResponse 评论列表API响应
+type CommentListResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Cursor struct {
+			IsBegin  bool  `json:"is_begin"`  // 是否第一页
+			IsEnd    bool  `json:"is_end"`    // 是否最后一页
+			Next     int   `json:"next"`      // 下一页
+			AllCount int64 `json:"all_count"` // 评论总数
+		} `json:"cursor"`
+		Replies    []CommentReply `json:"replies"`
+		TopReplies []CommentReply `json:"top_replies"` // 置顶评论
+	} `json:"data"`
+}
+
+// GetComments 获取视频评论列表，sort: 0=按时间 1=按热度
+func (c *Client) GetComments(videoID string, page, pageSize int, sort int) (*CommentListResponse, error) {
+	aid, err := c.videoIDToAID(videoID)
+	if err != nil {
+		return nil, errors.Wrap(err, "转换视频ID为AID失败")
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	// reply/main 的 mode: 2=按时间 3=按热度
+	mode := "3"
+	if sort == CommentSortTime {
+		mode = "2"
+	}
+
+	params := url.Values{
+		"type": {"1"}, // 1: 视频评论区
+		"oid":  {strconv.FormatInt(aid, 10)},
+		"mode": {mode},
+		"next": {strconv.Itoa(page)},
+		"ps":   {strconv.Itoa(pageSize)},
+	}
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/v2/reply/main", params, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp CommentListResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析评论列表API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// BaseResponse 只包含业务状态码的通用API响应
+type BaseResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// DeleteComment 删除自己发表的视频评论
+func (c *Client) DeleteComment(videoID string, rpid int64) (*BaseResponse, error) {
+	return c.commentAction("https://api.bilibili.com/x/v2/reply/del", videoID, rpid, nil, "删除评论")
+}
+
+// LikeComment 点赞或取消点赞视频评论，action: 1=点赞 0=取消点赞
+func (c *Client) LikeComment(videoID string, rpid int64, action int) (*BaseResponse, error) {
+	extra := url.Values{"action": {strconv.Itoa(action)}}
+	return c.commentAction("https://api.bilibili.com/x/v2/reply/action", videoID, rpid, extra, "点赞评论")
+}
+
+// PinComment 置顶或取消置顶视频评论，只有视频UP主可以操作
+func (c *Client) PinComment(videoID string, rpid int64, pin bool) (*BaseResponse, error) {
+	action := "0"
+	if pin {
+		action = "1"
+	}
+	extra := url.Values{"action": {action}}
+	return c.commentAction("https://api.bilibili.com/x/v2/reply/top", videoID, rpid, extra, "置顶评论")
+}
+
+// commentAction 对视频评论执行需要登录的操作，extra为接口额外参数
+func (c *Client) commentAction(apiURL, videoID string, rpid int64, extra url.Values, action string) (*BaseResponse, error) {
+	csrf, exists := c.cookies["bili_jct"]
+	if !exists || csrf == "" {
+		return nil, errors.New("缺少CSRF token，请确保已登录")
+	}
+
+	aid, err := c.videoIDToAID(videoID)
+	if err != nil {
+		return nil, errors.Wrap(err, "转换视频ID为AID失败")
+	}
+
+	data := url.Values{
+		"oid":  {strconv.FormatInt(aid, 10)},
+		"type": {"1"}, // 1: 视频评论区
+		"rpid": {strconv.FormatInt(rpid, 10)},
+		"csrf": {csrf},
+	}
+	for key, values := range extra {
+		data[key] = values
+	}
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	body, err := c.makeRequest("POST", apiURL, data, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp BaseResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrapf(err, "解析%sAPI响应失败", action)
+	}
+
+	return &resp, nil
+}
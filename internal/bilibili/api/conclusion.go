@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// VideoConclusionResponse AI视频总结API响应
+type VideoConclusionResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Code        int `json:"code"` // 0:有总结 -1:不支持 1:暂无总结
+		ModelResult struct {
+			ResultType int    `json:"result_type"` // 0:无总结 1:仅摘要 2:摘要和提纲
+			Summary    string `json:"summary"`     // 视频摘要
+			Outline    []struct {
+				Title       string `json:"title"`     // 提纲标题
+				Timestamp   int64  `json:"timestamp"` // 提纲起始时间(秒)
+				PartOutline []struct {
+					Timestamp int64  `json:"timestamp"` // 要点时间(秒)
+					Content   string `json:"content"`   // 要点内容
+				} `json:"part_outline"`
+			} `json:"outline"`
+		} `json:"model_result"`
+		Stid string `json:"stid"`
+	} `json:"data"`
+}
+
+// HasSummary 是否存在AI总结
+func (r *VideoConclusionResponse) HasSummary() bool {
+	return r.Code == 0 && r.Data.Code == 0 && r.Data.ModelResult.Summary != ""
+}
+
+// GetVideoConclusion 获取B站AI生成的视频总结（WBI签名接口）
+func (c *Client) GetVideoConclusion(videoID, cid string, upMid int64) (*VideoConclusionResponse, error) {
+	params := videoIDParams(videoID)
+	params.Set("cid", cid)
+	params.Set("up_mid", strconv.FormatInt(upMid, 10))
+
+	query, err := c.signWbi(params)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/web-interface/view/conclusion/get?"+query, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp VideoConclusionResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析视频总结API响应失败")
+	}
+	checkWbiResponse(resp.Code)
+
+	return &resp, nil
+}
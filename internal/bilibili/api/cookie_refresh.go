@@ -0,0 +1,221 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RefreshTokenCookie 保存refresh_token使用的名称，与网页端localStorage中的键名一致
+const RefreshTokenCookie = "ac_time_value"
+
+// refreshPublicKey 生成CorrespondPath使用的B站公钥
+const refreshPublicKey = `-----BEGIN PUBLIC KEY-----
+MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQDLgd2OAkcGVtoE3ThUREbio0Eg
+Uc/prcajMKXvkCKFCWhJYJcLkcM2DKKcSeFpD/j6Boy538YXnR6VhcuUJOhH2x71
+nzPjfdTcqMz7djHum0qSZA0AyCBDABUqCrfNgCiJ00Ra7GmRj+YCK1NJEuewlb40
+JNrRuoEUXpabUzGB8QIDAQAB
+-----END PUBLIC KEY-----`
+
+// refreshCSRFPattern 从correspond页面中提取refresh_csrf
+var refreshCSRFPattern = regexp.MustCompile(`<div id="1-name">([^<]+)</div>`)
+
+// CookieInfoResponse 检查cookie是否需要刷新的API响应
+type CookieInfoResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Refresh   bool  `json:"refresh"`   // 是否需要刷新
+		Timestamp int64 `json:"timestamp"` // 服务端毫秒时间戳，用于生成CorrespondPath
+	} `json:"data"`
+}
+
+// CookieRefreshResponse 刷新cookie的API响应
+type CookieRefreshResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		RefreshToken string `json:"refresh_token"` // 新的refresh_token
+	} `json:"data"`
+}
+
+// CookieRefreshResult 刷新得到的新cookies和refresh_token
+type CookieRefreshResult struct {
+	Cookies      []*http.Cookie
+	RefreshToken string
+}
+
+// GetCookieInfo 检查当前cookie是否需要刷新
+func (c *Client) GetCookieInfo() (*CookieInfoResponse, error) {
+	csrf, ok := c.cookies["bili_jct"]
+	if !ok || csrf == "" {
+		return nil, errors.New("缺少CSRF token，请确保已登录")
+	}
+
+	params := url.Values{
+		"csrf": {csrf},
+	}
+
+	headers := c.getHeaders("https://www.bilibili.com")
+	body, err := c.makeRequest("GET", "https://passport.bilibili.com/x/passport-login/web/cookie/info", params, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp CookieInfoResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析cookie状态API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// correspondPath 使用公钥加密 refresh_<毫秒时间戳>，得到correspond页面路径
+func correspondPath(timestamp int64) (string, error) {
+	block, _ := pem.Decode([]byte(refreshPublicKey))
+	if block == nil {
+		return "", errors.New("解析刷新公钥失败")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return "", errors.Wrap(err, "解析刷新公钥失败")
+	}
+	publicKey, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return "", errors.New("刷新公钥不是RSA公钥")
+	}
+
+	encrypted, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, []byte(fmt.Sprintf("refresh_%d", timestamp)), nil)
+	if err != nil {
+		return "", errors.Wrap(err, "生成CorrespondPath失败")
+	}
+	return hex.EncodeToString(encrypted), nil
+}
+
+// GetRefreshCSRF 访问correspond页面获取刷新cookie所需的refresh_csrf
+func (c *Client) GetRefreshCSRF(timestamp int64) (string, error) {
+	path, err := correspondPath(timestamp)
+	if err != nil {
+		return "", err
+	}
+
+	headers := c.getHeaders("https://www.bilibili.com")
+	headers["Accept"] = "text/html,application/xhtml+xml,*/*"
+	body, err := c.makeRequest("GET", "https://www.bilibili.com/correspond/1/"+path, nil, headers)
+	if err != nil {
+		return "", err
+	}
+
+	match := refreshCSRFPattern.FindSubmatch(body)
+	if match == nil {
+		return "", errors.New("correspond页面中未找到refresh_csrf，cookie可能已失效")
+	}
+	return strings.TrimSpace(string(match[1])), nil
+}
+
+// RefreshCookie 使用refresh_csrf和refresh_token刷新cookie，新cookie从响应的Set-Cookie中获取。
+// 刷新后需要使用新cookie调用 ConfirmCookieRefresh 使旧的refresh_token失效
+func (c *Client) RefreshCookie(refreshCSRF, refreshToken string) (*CookieRefreshResult, error) {
+	csrf, ok := c.cookies["bili_jct"]
+	if !ok || csrf == "" {
+		return nil, errors.New("缺少CSRF token，请确保已登录")
+	}
+
+	data := url.Values{
+		"csrf":          {csrf},
+		"refresh_csrf":  {refreshCSRF},
+		"source":        {"main_web"},
+		"refresh_token": {refreshToken},
+	}
+
+	// 需要读取Set-Cookie，不经过 makeRequest；刷新不是幂等操作，也不重试
+	req, err := http.NewRequestWithContext(c.context(), "POST", "https://passport.bilibili.com/x/passport-login/web/cookie/refresh", strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "创建POST请求失败")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	req.Header.Set("Cookie", c.getCookieString())
+	for key, value := range c.getHeaders("https://www.bilibili.com") {
+		req.Header.Set(key, value)
+	}
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "HTTP请求失败")
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "读取响应失败")
+	}
+	c.logHTTP(req, data, httpResp.StatusCode, body)
+
+	var resp CookieRefreshResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析刷新cookie API响应失败")
+	}
+	if resp.Code != 0 {
+		return nil, errors.Wrap(NewAPIError(resp.Code, resp.Message), "刷新cookie失败")
+	}
+
+	cookies := httpResp.Cookies()
+	if !hasCookie(cookies, "SESSDATA") || !hasCookie(cookies, "bili_jct") {
+		return nil, errors.New("刷新cookie响应中缺少SESSDATA或bili_jct")
+	}
+
+	return &CookieRefreshResult{
+		Cookies:      cookies,
+		RefreshToken: resp.Data.RefreshToken,
+	}, nil
+}
+
+// ConfirmCookieRefresh 使用新cookie确认刷新，使旧的refresh_token失效
+func (c *Client) ConfirmCookieRefresh(oldRefreshToken string) error {
+	csrf, ok := c.cookies["bili_jct"]
+	if !ok || csrf == "" {
+		return errors.New("缺少CSRF token，请确保已登录")
+	}
+
+	data := url.Values{
+		"csrf":          {csrf},
+		"refresh_token": {oldRefreshToken},
+	}
+
+	headers := c.getHeaders("https://www.bilibili.com")
+	body, err := c.makeRequest("POST", "https://passport.bilibili.com/x/passport-login/web/confirm/refresh", data, headers)
+	if err != nil {
+		return err
+	}
+
+	var resp BaseResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return errors.Wrap(err, "解析确认刷新API响应失败")
+	}
+	if resp.Code != 0 {
+		return errors.Wrap(NewAPIError(resp.Code, resp.Message), "确认刷新cookie失败")
+	}
+	return nil
+}
+
+// hasCookie 检查cookie列表中是否包含指定名称的非空cookie
+func hasCookie(cookies []*http.Cookie, name string) bool {
+	for _, cookie := range cookies {
+		if cookie.Name == name && cookie.Value != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DanmakuItem 单条弹幕
+type DanmakuItem struct {
+	Time      float64 `json:"time"`      // 出现时间(秒)
+	Mode      int     `json:"mode"`      // 类型 1-3:滚动 4:底部 5:顶部 6:逆向 7:高级 8:代码
+	FontSize  int     `json:"font_size"` // 字号
+	Color     int     `json:"color"`     // 颜色(十进制RGB)
+	Timestamp int64   `json:"timestamp"` // 发送时间(Unix时间戳)
+	Pool      int     `json:"pool"`      // 弹幕池 0:普通 1:字幕 2:特殊
+	UserHash  string  `json:"user_hash"` // 发送者mid哈希
+	DmID      string  `json:"dmid"`      // 弹幕ID
+	Content   string  `json:"content"`   // 弹幕内容
+}
+
+// danmakuXML XML弹幕文件结构
+type danmakuXML struct {
+	XMLName xml.Name `xml:"i"`
+	Items   []struct {
+		P       string `xml:"p,attr"`
+		Content string `xml:",chardata"`
+	} `xml:"d"`
+}
+
+// GetDanmakuXML 获取视频分P的原始XML弹幕
+func (c *Client) GetDanmakuXML(cid int64) ([]byte, error) {
+	apiURL := fmt.Sprintf("https://api.bilibili.com/x/v1/dm/list.so?oid=%d", cid)
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "创建请求失败")
+	}
+
+	headers := c.getHeaders("https://www.bilibili.com")
+	headers["Accept"] = "*/*"
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	status, body, err := c.doRequest(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, errors.Errorf("获取弹幕失败: HTTP %d", status)
+	}
+
+	return inflateDanmaku(body)
+}
+
+// inflateDanmaku 解压弹幕数据：接口通常返回deflate压缩的XML，未压缩时原样返回
+func inflateDanmaku(body []byte) ([]byte, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("<")) {
+		return body, nil
+	}
+
+	flateReader := flate.NewReader(bytes.NewReader(body))
+	defer flateReader.Close()
+
+	data, err := io.ReadAll(flateReader)
+	if err != nil {
+		return nil, errors.Wrap(err, "解压弹幕数据失败")
+	}
+	return data, nil
+}
+
+// GetDanmaku 获取并解析视频分P的弹幕
+func (c *Client) GetDanmaku(cid int64) ([]DanmakuItem, []byte, error) {
+	raw, err := c.GetDanmakuXML(cid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, err := ParseDanmakuXML(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return items, raw, nil
+}
+
+// ParseDanmakuXML 解析XML格式弹幕，结果按出现时间排序
+func ParseDanmakuXML(data []byte) ([]DanmakuItem, error) {
+	var doc danmakuXML
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "解析弹幕XML失败")
+	}
+
+	items := make([]DanmakuItem, 0, len(doc.Items))
+	for _, d := range doc.Items {
+		// p属性: 时间,类型,字号,颜色,发送时间,弹幕池,用户哈希,弹幕ID[,权重]
+		fields := strings.Split(d.P, ",")
+		if len(fields) < 8 {
+			continue
+		}
+
+		item := DanmakuItem{
+			UserHash: fields[6],
+			DmID:     fields[7],
+			Content:  d.Content,
+		}
+		item.Time, _ = strconv.ParseFloat(fields[0], 64)
+		item.Mode, _ = strconv.Atoi(fields[1])
+		item.FontSize, _ = strconv.Atoi(fields[2])
+		item.Color, _ = strconv.Atoi(fields[3])
+		item.Timestamp, _ = strconv.ParseInt(fields[4], 10, 64)
+		item.Pool, _ = strconv.Atoi(fields[5])
+
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Time < items[j].Time
+	})
+
+	return items, nil
+}
+
+// 弹幕发送类型
+const (
+	DanmakuModeScroll = 1 // 滚动弹幕
+	DanmakuModeBottom = 4 // 底部弹幕
+	DanmakuModeTop    = 5 // 顶部弹幕
+)
+
+// DanmakuColorWhite 默认弹幕颜色(白色)
+const DanmakuColorWhite = 16777215
+
+// DanmakuResponse 发送弹幕API响应
+type DanmakuResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Action  string `json:"action"`
+		DmID    int64  `json:"dmid"`
+		DmIDStr string `json:"dmid_str"`
+	} `json:"data"`
+}
+
+// SendDanmaku 在视频分P的指定位置发送弹幕，progressMs 为弹幕出现时间(毫秒)
+func (c *Client) SendDanmaku(videoID string, cid int64, progressMs int, message string, mode, color int) (*DanmakuResponse, error) {
+	csrf, exists := c.cookies["bili_jct"]
+	if !exists || csrf == "" {
+		return nil, errors.New("缺少CSRF token (bili_jct)")
+	}
+
+	data := videoIDParams(videoID)
+	data.Set("type", "1")
+	data.Set("oid", strconv.FormatInt(cid, 10))
+	data.Set("msg", message)
+	data.Set("progress", strconv.Itoa(progressMs))
+	data.Set("mode", strconv.Itoa(mode))
+	data.Set("color", strconv.Itoa(color))
+	data.Set("fontsize", "25")
+	data.Set("pool", "0")
+	data.Set("rnd", strconv.FormatInt(time.Now().UnixNano()/int64(time.Microsecond), 10))
+	data.Set("csrf", csrf)
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	body, err := c.makeRequest("POST", "https://api.bilibili.com/x/v2/dm/post", data, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp DanmakuResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析发送弹幕API响应失败")
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"bytes"
+	"compress/flate"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+const testDanmakuXML = `<?xml version="1.0" encoding="UTF-8"?><i><d p="1.5,1,25,16777215,1700000000,0,abc,1001">第一条</d><d p="0.5,5,25,255,1700000001,0,def,1002">第二条</d></i>`
+
+// deflate 压缩测试数据
+func deflate(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(data))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestGetDanmakuRetriesAndInflates(t *testing.T) {
+	compressed := deflate(t, testDanmakuXML)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次请求返回5xx，验证弹幕请求与其他GET请求一样会重试
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Encoding", "deflate")
+		w.Write(compressed)
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server, map[string]string{})
+	items, raw, err := c.GetDanmaku(1)
+	if err != nil {
+		t.Fatalf("GetDanmaku: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Fatalf("requests = %d, want 2", n)
+	}
+	if string(raw) != testDanmakuXML {
+		t.Fatalf("raw XML was not inflated: %q", raw)
+	}
+	if len(items) != 2 || items[0].Content != "第二条" {
+		t.Fatalf("items = %+v", items)
+	}
+}
+
+func TestInflateDanmakuPlainXML(t *testing.T) {
+	data, err := inflateDanmaku([]byte(testDanmakuXML))
+	if err != nil || string(data) != testDanmakuXML {
+		t.Fatalf("inflateDanmaku = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// debugBodyLimit 调试日志中响应体的最大长度
+const debugBodyLimit = 2048
+
+// sensitiveParams 调试日志中需要脱敏的参数
+var sensitiveParams = []string{"csrf", "biliCSRF", "csrf_token", "access_key", "refresh_token", "refresh_csrf", RefreshTokenCookie}
+
+// sensitiveBodyHosts 响应体包含登录凭据的域名（如扫码登录返回的跳转地址中带有SESSDATA），不记录响应体
+var sensitiveBodyHosts = []string{"passport.bilibili.com"}
+
+// sensitiveBodyPaths 响应体包含刷新凭据的路径前缀（correspond页面中带有refresh_csrf），不记录响应体
+var sensitiveBodyPaths = []string{"/correspond/"}
+
+// logHTTP 在开启 bilibili.debug_http 时记录请求和响应，cookie不会被记录
+func (c *Client) logHTTP(req *http.Request, form url.Values, status int, body []byte) {
+	if !c.debugHTTP {
+		return
+	}
+
+	reqURL := *req.URL
+	reqURL.RawQuery = redactParams(reqURL.Query()).Encode()
+
+	if len(form) > 0 {
+		logger.Infof("🐞 [HTTP] %s %s form=%s", req.Method, reqURL.String(), redactParams(form).Encode())
+	} else {
+		logger.Infof("🐞 [HTTP] %s %s", req.Method, reqURL.String())
+	}
+
+	if !bodyLoggable(req) {
+		logger.Infof("🐞 [HTTP] status=%d body(%d bytes)=<已隐藏>", status, len(body))
+	} else if len(body) > debugBodyLimit {
+		logger.Infof("🐞 [HTTP] status=%d body(%d bytes)=%s...", status, len(body), body[:debugBodyLimit])
+	} else {
+		logger.Infof("🐞 [HTTP] status=%d body=%s", status, body)
+	}
+}
+
+// bodyLoggable 判断请求的响应体是否可以写入调试日志
+func bodyLoggable(req *http.Request) bool {
+	for _, host := range sensitiveBodyHosts {
+		if req.URL.Hostname() == host {
+			return false
+		}
+	}
+	for _, prefix := range sensitiveBodyPaths {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// redactParams 复制参数并隐藏敏感字段
+func redactParams(params url.Values) url.Values {
+	redacted := url.Values{}
+	for k, v := range params {
+		redacted[k] = v
+	}
+	for _, key := range sensitiveParams {
+		if redacted.Get(key) != "" {
+			redacted.Set(key, "***")
+		}
+	}
+	return redacted
+}
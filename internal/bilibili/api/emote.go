@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"net/url"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Emote 评论表情，Text形如 "[tv_doge]"，写入评论内容即可显示为表情
+type Emote struct {
+	ID          int64  `json:"id"`           // 表情ID
+	Text        string `json:"text"`         // 表情代码
+	URL         string `json:"url"`          // 表情图片地址
+	PackageID   int64  `json:"package_id"`   // 所属表情包ID
+	PackageName string `json:"package_name"` // 所属表情包名称
+}
+
+// EmotePanelResponse 评论表情面板API响应
+type EmotePanelResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Packages []struct {
+			ID    int64  `json:"id"`
+			Text  string `json:"text"` // 表情包名称
+			Emote []struct {
+				ID   int64  `json:"id"`
+				Text string `json:"text"`
+				URL  string `json:"url"`
+			} `json:"emote"`
+		} `json:"packages"`
+	} `json:"data"`
+}
+
+// emoteCache 进程内共享的表情列表，表情面板很少变化，成功获取后在进程生命周期内复用
+var emoteCache struct {
+	sync.Mutex
+	emotes map[string]Emote
+}
+
+// GetEmotes 获取评论可用的表情，按表情代码索引
+func (c *Client) GetEmotes() (map[string]Emote, error) {
+	emoteCache.Lock()
+	defer emoteCache.Unlock()
+	if emoteCache.emotes != nil {
+		return emoteCache.emotes, nil
+	}
+
+	params := url.Values{"business": {"reply"}}
+	headers := c.getHeaders("https://www.bilibili.com")
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/emote/user/panel/web", params, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp EmotePanelResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析表情面板API响应失败")
+	}
+	if resp.Code != 0 {
+		return nil, errors.Wrap(NewAPIError(resp.Code, resp.Message), "获取表情列表失败")
+	}
+
+	emotes := make(map[string]Emote)
+	for _, pkg := range resp.Data.Packages {
+		for _, emote := range pkg.Emote {
+			emotes[emote.Text] = Emote{
+				ID:          emote.ID,
+				Text:        emote.Text,
+				URL:         emote.URL,
+				PackageID:   pkg.ID,
+				PackageName: pkg.Text,
+			}
+		}
+	}
+
+	emoteCache.emotes = emotes
+	return emotes, nil
+}
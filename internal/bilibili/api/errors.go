@@ -0,0 +1,35 @@
+package api
+
+import "fmt"
+
+// 常见的B站API通用错误码
+const (
+	CodeNotLoggedIn    = -101  // 账号未登录
+	CodeCSRFFailed     = -111  // csrf校验失败
+	CodeBadRequest     = -400  // 请求错误
+	CodeForbidden      = -403  // 访问权限不足
+	CodeNotFound       = -404  // 内容不存在
+	CodeRiskControl    = -352  // 风控校验失败
+	CodeRequestBlocked = -412  // 请求被拦截
+	CodeServerError    = -500  // 服务器错误
+	CodeUnavailable    = -503  // 服务暂时不可用
+	CodeTooFrequent    = -509  // 请求过于频繁
+	CodeTooFast        = -799  // 请求过于频繁，请稍后再试
+	CodeVideoInvisible = 62002 // 稿件不可见
+	CodeVideoReviewing = 62004 // 稿件审核中
+)
+
+// APIError B站API返回的业务错误（HTTP成功但code非0）
+type APIError struct {
+	Code    int
+	Message string
+}
+
+// NewAPIError 创建API业务错误，可用 errors.Wrap 添加操作说明
+func NewAPIError(code int, message string) error {
+	return &APIError{Code: code, Message: message}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s (code: %d)", e.Message, e.Code)
+}
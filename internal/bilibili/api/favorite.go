@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// FavFolder 收藏夹
+type FavFolder struct {
+	ID         int64  `json:"id"`          // 收藏夹ID（收藏视频时使用）
+	Fid        int64  `json:"fid"`         // 原始收藏夹ID
+	Title      string `json:"title"`       // 收藏夹名称
+	Attr       int    `json:"attr"`        // 属性位，第0位为1表示私密
+	MediaCount int    `json:"media_count"` // 视频数量
+	FavState   int    `json:"fav_state"`   // 指定视频是否已在该收藏夹中
+}
+
+// IsPrivate 收藏夹是否私密
+func (f FavFolder) IsPrivate() bool {
+	return f.Attr&1 == 1
+}
+
+// FavFolderListResponse 收藏夹列表API响应
+type FavFolderListResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Count int         `json:"count"`
+		List  []FavFolder `json:"list"`
+	} `json:"data"`
+}
+
+// CreateFavResponse 创建收藏夹API响应
+type CreateFavResponse struct {
+	Code    int       `json:"code"`
+	Message string    `json:"message"`
+	Data    FavFolder `json:"data"`
+}
+
+// ListFavoriteFolders 获取用户创建的所有收藏夹
+func (c *Client) ListFavoriteFolders(upMid int64) (*FavFolderListResponse, error) {
+	params := url.Values{
+		"up_mid": {strconv.FormatInt(upMid, 10)},
+	}
+
+	headers := c.getHeaders("https://space.bilibili.com")
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/v3/fav/folder/created/list-all", params, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp FavFolderListResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析收藏夹列表API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// CreateFavoriteFolder 创建收藏夹
+func (c *Client) CreateFavoriteFolder(title string, private bool) (*CreateFavResponse, error) {
+	csrf, ok := c.cookies["bili_jct"]
+	if !ok || csrf == "" {
+		return nil, errors.New("缺少CSRF token，请确保已登录")
+	}
+
+	privacy := "0"
+	if private {
+		privacy = "1"
+	}
+
+	data := url.Values{
+		"title":   {title},
+		"privacy": {privacy},
+		"csrf":    {csrf},
+	}
+
+	headers := c.getHeaders("https://space.bilibili.com")
+	body, err := c.makeRequest("POST", "https://api.bilibili.com/x/v3/fav/folder/add", data, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp CreateFavResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析创建收藏夹API响应失败")
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// LogoutResponse 退出登录API响应
+type LogoutResponse struct {
+	Code    int    `json:"code"`
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+}
+
+// Logout 退出登录，使服务端会话失效
+func (c *Client) Logout() (*LogoutResponse, error) {
+	csrf, ok := c.cookies["bili_jct"]
+	if !ok || csrf == "" {
+		return nil, errors.New("缺少CSRF token，请确保已登录")
+	}
+
+	data := url.Values{
+		"biliCSRF": {csrf},
+	}
+
+	headers := c.getHeaders("https://www.bilibili.com")
+	body, err := c.makeRequest("POST", "https://passport.bilibili.com/login/exit/v2", data, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp LogoutResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析退出登录API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// 扫码登录轮询状态码
+const (
+	QRLoginSuccess    = 0     // 登录成功
+	QRLoginExpired    = 86038 // 二维码已失效
+	QRLoginScanned    = 86090 // 已扫码，等待手机端确认
+	QRLoginNotScanned = 86101 // 尚未扫码
+)
+
+// qrLoginCookieNames 扫码登录成功后跳转链接中携带的cookie
+var qrLoginCookieNames = []string{"SESSDATA", "bili_jct", "DedeUserID", "DedeUserID__ckMd5", "sid"}
+
+// QRGenerateResponse 申请登录二维码API响应
+type QRGenerateResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		URL       string `json:"url"`        // 二维码内容
+		QRCodeKey string `json:"qrcode_key"` // 轮询使用的密钥
+	} `json:"data"`
+}
+
+// QRPollResponse 扫码登录轮询API响应
+type QRPollResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		URL          string `json:"url"`           // 登录成功后的跳转链接，携带cookie参数
+		RefreshToken string `json:"refresh_token"` // 刷新cookie使用的token
+		Timestamp    int64  `json:"timestamp"`
+		Code         int    `json:"code"` // 扫码状态
+		Message      string `json:"message"`
+	} `json:"data"`
+}
+
+// GenerateLoginQR 申请登录二维码，返回轮询密钥和二维码内容
+func (c *Client) GenerateLoginQR() (qrcodeKey, qrURL string, err error) {
+	headers := c.getHeaders("https://passport.bilibili.com/login")
+	body, err := c.makeRequest("GET", "https://passport.bilibili.com/x/passport-login/web/qrcode/generate", nil, headers)
+	if err != nil {
+		return "", "", err
+	}
+
+	var resp QRGenerateResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", "", errors.Wrap(err, "解析登录二维码API响应失败")
+	}
+	if resp.Code != 0 {
+		return "", "", errors.Wrap(NewAPIError(resp.Code, resp.Message), "申请登录二维码失败")
+	}
+	if resp.Data.QRCodeKey == "" || resp.Data.URL == "" {
+		return "", "", errors.New("登录二维码API返回了空数据")
+	}
+
+	return resp.Data.QRCodeKey, resp.Data.URL, nil
+}
+
+// PollLoginQR 查询扫码状态，登录成功时返回登录cookies（含刷新cookie使用的refresh_token）
+func (c *Client) PollLoginQR(qrcodeKey string) (status int, cookies map[string]string, err error) {
+	params := url.Values{
+		"qrcode_key": {qrcodeKey},
+	}
+
+	headers := c.getHeaders("https://passport.bilibili.com/login")
+	body, err := c.makeRequest("GET", "https://passport.bilibili.com/x/passport-login/web/qrcode/poll", params, headers)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var resp QRPollResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, nil, errors.Wrap(err, "解析扫码状态API响应失败")
+	}
+	if resp.Code != 0 {
+		return 0, nil, errors.Wrap(NewAPIError(resp.Code, resp.Message), "查询扫码状态失败")
+	}
+	if resp.Data.Code != QRLoginSuccess {
+		return resp.Data.Code, nil, nil
+	}
+
+	cookies, err = parseQRLoginCookies(resp.Data.URL)
+	if err != nil {
+		return resp.Data.Code, nil, err
+	}
+	if resp.Data.RefreshToken != "" {
+		cookies[RefreshTokenCookie] = resp.Data.RefreshToken
+	}
+	return resp.Data.Code, cookies, nil
+}
+
+// parseQRLoginCookies 从登录成功的跳转链接中提取cookies
+func parseQRLoginCookies(rawURL string) (map[string]string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "解析登录跳转链接失败")
+	}
+
+	query := parsed.Query()
+	cookies := make(map[string]string, len(qrLoginCookieNames))
+	for _, name := range qrLoginCookieNames {
+		if value := query.Get(name); value != "" {
+			cookies[name] = value
+		}
+	}
+	if cookies["SESSDATA"] == "" || cookies["bili_jct"] == "" {
+		return nil, errors.New("登录跳转链接中缺少SESSDATA或bili_jct")
+	}
+
+	return cookies, nil
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// VideoChapter 视频分段章节
+type VideoChapter struct {
+	From  int64  `json:"from"`  // 起始时间(秒)
+	To    int64  `json:"to"`    // 结束时间(秒)
+	Title string `json:"title"` // 章节标题
+}
+
+// PlayerInfoResponse 播放器信息API响应
+type PlayerInfoResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Aid        int64 `json:"aid"`
+		Cid        int64 `json:"cid"`
+		ViewPoints []struct {
+			Type    int    `json:"type"`    // 2: 分段章节
+			From    int64  `json:"from"`    // 起始时间(秒)
+			To      int64  `json:"to"`      // 结束时间(秒)
+			Content string `json:"content"` // 章节标题
+			ImgURL  string `json:"imgUrl"`  // 章节截图
+		} `json:"view_points"`
+		Subtitle struct {
+			AllowSubmit bool           `json:"allow_submit"` // 是否允许提交字幕
+			Subtitles   []SubtitleMeta `json:"subtitles"`    // 字幕列表（含AI字幕）
+		} `json:"subtitle"`
+	} `json:"data"`
+}
+
+// SubtitleMeta 播放器接口返回的字幕信息
+type SubtitleMeta struct {
+	ID          int64  `json:"id"`           // 字幕ID
+	Lan         string `json:"lan"`          // 语言代码，AI字幕以 ai- 开头
+	LanDoc      string `json:"lan_doc"`      // 语言名称
+	IsLock      bool   `json:"is_lock"`      // 是否锁定
+	SubtitleURL string `json:"subtitle_url"` // 字幕文件URL
+	Type        int    `json:"type"`         // 0: 人工字幕 1: AI字幕
+	AiType      int    `json:"ai_type"`      // AI字幕类型
+	AiStatus    int    `json:"ai_status"`    // AI字幕状态
+}
+
+// IsAI 是否为AI生成的字幕
+func (m SubtitleMeta) IsAI() bool {
+	return m.Type == 1 || strings.HasPrefix(m.Lan, "ai-")
+}
+
+// GetPlayerInfo 获取播放器信息（WBI签名接口）
+func (c *Client) GetPlayerInfo(videoID string, cid int64) (*PlayerInfoResponse, error) {
+	params := videoIDParams(videoID)
+	params.Set("cid", strconv.FormatInt(cid, 10))
+
+	query, err := c.signWbi(params)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/player/wbi/v2?"+query, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp PlayerInfoResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析播放器信息API响应失败")
+	}
+	checkWbiResponse(resp.Code)
+
+	return &resp, nil
+}
+
+// GetVideoChapters 获取视频的分段章节，未设置章节时返回空列表
+func (c *Client) GetVideoChapters(videoID string, cid int64) ([]VideoChapter, error) {
+	resp, err := c.GetPlayerInfo(videoID, cid)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code != 0 {
+		return nil, errors.Wrap(NewAPIError(resp.Code, resp.Message), "获取播放器信息失败")
+	}
+
+	chapters := make([]VideoChapter, 0, len(resp.Data.ViewPoints))
+	for _, point := range resp.Data.ViewPoints {
+		chapters = append(chapters, VideoChapter{
+			From:  point.From,
+			To:    point.To,
+			Title: point.Content,
+		})
+	}
+
+	return chapters, nil
+}
+
+// GetSubtitleList 获取视频分P的字幕列表（包括AI字幕），需要登录cookies才能返回完整列表
+func (c *Client) GetSubtitleList(videoID string, cid int64) ([]SubtitleMeta, error) {
+	resp, err := c.GetPlayerInfo(videoID, cid)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code != 0 {
+		return nil, errors.Wrap(NewAPIError(resp.Code, resp.Message), "获取播放器信息失败")
+	}
+
+	list := make([]SubtitleMeta, 0, len(resp.Data.Subtitle.Subtitles))
+	for _, meta := range resp.Data.Subtitle.Subtitles {
+		if strings.HasPrefix(meta.SubtitleURL, "//") {
+			meta.SubtitleURL = "https:" + meta.SubtitleURL
+		}
+		list = append(list, meta)
+	}
+
+	return list, nil
+}
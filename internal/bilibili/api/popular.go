@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// PopularResponse 综合热门视频API响应
+type PopularResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		List   []ArchiveItem `json:"list"`
+		NoMore bool          `json:"no_more"` // 是否已是最后一页
+	} `json:"data"`
+}
+
+// RankingResponse 排行榜API响应
+type RankingResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Note string        `json:"note"` // 榜单说明
+		List []ArchiveItem `json:"list"`
+	} `json:"data"`
+}
+
+// GetPopularVideos 获取综合热门视频，pageSize最大50
+func (c *Client) GetPopularVideos(page, pageSize int) (*PopularResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 20
+	}
+
+	params := url.Values{
+		"pn": {strconv.Itoa(page)},
+		"ps": {strconv.Itoa(pageSize)},
+	}
+
+	headers := c.getHeaders("https://www.bilibili.com/v/popular/all")
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/web-interface/popular", params, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp PopularResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析热门视频API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// GetRanking 获取分区排行榜（WBI签名接口），tid为0时获取全站榜
+func (c *Client) GetRanking(tid int) (*RankingResponse, error) {
+	params := url.Values{
+		"rid":  {strconv.Itoa(tid)},
+		"type": {"all"},
+	}
+
+	query, err := c.signWbi(params)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := c.getHeaders("https://www.bilibili.com/v/popular/rank/all")
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/web-interface/ranking/v2?"+query, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp RankingResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析排行榜API响应失败")
+	}
+	checkWbiResponse(resp.Code)
+
+	return &resp, nil
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/shirenchuang/bilibili-mcp/pkg/config"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// NewHTTPClient 创建遵循代理配置的HTTP客户端，访问B站的请求都应通过它发出
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxyFunc()
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+// proxyFunc 返回代理选择函数：配置了 bilibili.proxy_url 时固定使用该代理，否则按环境变量（含NO_PROXY）选择
+func proxyFunc() func(*http.Request) (*url.URL, error) {
+	cfg := config.Get()
+	if cfg == nil || cfg.Bilibili.ProxyURL == "" {
+		return http.ProxyFromEnvironment
+	}
+
+	proxyURL, err := url.Parse(cfg.Bilibili.ProxyURL)
+	if err != nil || proxyURL.Host == "" {
+		logger.Warnf("⚠️ 代理地址无效，改用环境变量中的代理: %s", RedactProxyURL(cfg.Bilibili.ProxyURL))
+		return http.ProxyFromEnvironment
+	}
+	return http.ProxyURL(proxyURL)
+}
+
+// RedactProxyURL 隐藏代理地址中的密码，用于日志输出
+func RedactProxyURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.User == nil {
+		return rawURL
+	}
+	return parsed.Redacted()
+}
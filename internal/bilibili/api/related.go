@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ArchiveItem 视频列表项（相关推荐、热门、排行榜等接口通用）
+type ArchiveItem struct {
+	Aid      int64  `json:"aid"`      // 视频AV号
+	Bvid     string `json:"bvid"`     // 视频BV号
+	Title    string `json:"title"`    // 标题
+	Desc     string `json:"desc"`     // 简介
+	Pic      string `json:"pic"`      // 封面
+	Duration int64  `json:"duration"` // 时长(秒)
+	Pubdate  int64  `json:"pubdate"`  // 发布时间戳
+	Tname    string `json:"tname"`    // 分区名称
+	Owner    struct {
+		Mid  int64  `json:"mid"`  // UP主UID
+		Name string `json:"name"` // UP主昵称
+	} `json:"owner"`
+	Stat struct {
+		View    int64 `json:"view"`    // 播放量
+		Danmaku int64 `json:"danmaku"` // 弹幕数
+		Like    int64 `json:"like"`    // 点赞数
+	} `json:"stat"`
+}
+
+// RelatedResponse 相关推荐视频API响应
+type RelatedResponse struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    []ArchiveItem `json:"data"`
+}
+
+// GetRelatedVideos 获取视频的相关推荐，不需要登录
+func (c *Client) GetRelatedVideos(videoID string) (*RelatedResponse, error) {
+	params := videoIDParams(videoID)
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/web-interface/archive/related", params, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp RelatedResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析相关推荐API响应失败")
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FollowTag 关注分组
+type FollowTag struct {
+	TagID int64  `json:"tagid"` // 分组ID
+	Name  string `json:"name"`  // 分组名称
+	Count int    `json:"count"` // 分组内用户数量
+	Tip   string `json:"tip"`   // 分组提示
+}
+
+// FollowTagsResponse 关注分组列表API响应
+type FollowTagsResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    []FollowTag `json:"data"`
+}
+
+// CreateFollowTagResponse 创建关注分组API响应
+type CreateFollowTagResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		TagID int64 `json:"tagid"` // 新分组ID
+	} `json:"data"`
+}
+
+// RelationActionResponse 关系操作通用API响应
+type RelationActionResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// GetFollowTags 获取当前账号的关注分组列表
+func (c *Client) GetFollowTags() (*FollowTagsResponse, error) {
+	headers := c.getHeaders("https://space.bilibili.com")
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/relation/tags", nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp FollowTagsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析关注分组API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// CreateFollowTag 创建关注分组
+func (c *Client) CreateFollowTag(name string) (*CreateFollowTagResponse, error) {
+	csrf, ok := c.cookies["bili_jct"]
+	if !ok || csrf == "" {
+		return nil, errors.New("缺少CSRF token，请确保已登录")
+	}
+
+	data := url.Values{
+		"tag":  {name},
+		"csrf": {csrf},
+	}
+
+	headers := c.getHeaders("https://space.bilibili.com")
+	body, err := c.makeRequest("POST", "https://api.bilibili.com/x/relation/tag/create", data, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp CreateFollowTagResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析创建分组API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// AddUsersToFollowTags 将用户加入关注分组
+func (c *Client) AddUsersToFollowTags(userIDs []string, tagIDs []int64) (*RelationActionResponse, error) {
+	csrf, ok := c.cookies["bili_jct"]
+	if !ok || csrf == "" {
+		return nil, errors.New("缺少CSRF token，请确保已登录")
+	}
+
+	tags := make([]string, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		tags = append(tags, strconv.FormatInt(id, 10))
+	}
+
+	data := url.Values{
+		"fids":   {strings.Join(userIDs, ",")},
+		"tagids": {strings.Join(tags, ",")},
+		"csrf":   {csrf},
+	}
+
+	headers := c.getHeaders("https://space.bilibili.com")
+	body, err := c.makeRequest("POST", "https://api.bilibili.com/x/relation/tags/addUsers", data, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp RelationActionResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析设置分组API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// ResolveFollowTag 根据分组名称查找分组ID，不存在时自动创建
+func (c *Client) ResolveFollowTag(name string) (tagID int64, created bool, err error) {
+	tagsResp, err := c.GetFollowTags()
+	if err != nil {
+		return 0, false, errors.Wrap(err, "获取关注分组失败")
+	}
+	if tagsResp.Code != 0 {
+		return 0, false, errors.Wrap(NewAPIError(tagsResp.Code, tagsResp.Message), "获取关注分组失败")
+	}
+
+	for _, tag := range tagsResp.Data {
+		if tag.Name == name {
+			return tag.TagID, false, nil
+		}
+	}
+
+	createResp, err := c.CreateFollowTag(name)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "创建关注分组失败")
+	}
+	if createResp.Code != 0 {
+		return 0, false, errors.Wrap(NewAPIError(createResp.Code, createResp.Message), "创建关注分组失败")
+	}
+
+	return createResp.Data.TagID, true, nil
+}
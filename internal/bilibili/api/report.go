@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// VideoReportReasons 视频举报理由代码
+var VideoReportReasons = map[int]string{
+	2:  "违法违禁",
+	3:  "色情",
+	4:  "低俗",
+	5:  "赌博诈骗",
+	6:  "血腥暴力",
+	7:  "人身攻击",
+	8:  "与站内其他视频撞车",
+	9:  "引战",
+	10: "青少年不良信息",
+	52: "转载/自制错误",
+}
+
+// CommentReportReasons 评论举报理由代码
+var CommentReportReasons = map[int]string{
+	0:  "其他",
+	1:  "垃圾广告",
+	2:  "色情",
+	3:  "刷屏",
+	4:  "引战",
+	5:  "剧透",
+	7:  "人身攻击",
+	8:  "内容不相关",
+	9:  "违法违规",
+	10: "低俗",
+	12: "赌博诈骗",
+	13: "传播不实信息",
+	15: "侵犯隐私",
+	17: "青少年不良信息",
+}
+
+// ReportResponse 举报API响应
+type ReportResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ReportVideo 举报视频
+func (c *Client) ReportVideo(aid int64, reason int, detail string) (*ReportResponse, error) {
+	if _, ok := VideoReportReasons[reason]; !ok {
+		return nil, errors.Errorf("不支持的视频举报理由: %d", reason)
+	}
+
+	csrf, ok := c.cookies["bili_jct"]
+	if !ok || csrf == "" {
+		return nil, errors.New("缺少CSRF token，请确保已登录")
+	}
+
+	data := url.Values{
+		"aid":  {strconv.FormatInt(aid, 10)},
+		"tid":  {strconv.Itoa(reason)},
+		"desc": {detail},
+		"csrf": {csrf},
+	}
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/av%d", aid))
+	body, err := c.makeRequest("POST", "https://api.bilibili.com/x/web-interface/appeal/add", data, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ReportResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析举报视频API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// ReportComment 举报视频评论，oid为视频AV号
+func (c *Client) ReportComment(oid, rpid string, reason int) (*ReportResponse, error) {
+	if _, ok := CommentReportReasons[reason]; !ok {
+		return nil, errors.Errorf("不支持的评论举报理由: %d", reason)
+	}
+
+	csrf, ok := c.cookies["bili_jct"]
+	if !ok || csrf == "" {
+		return nil, errors.New("缺少CSRF token，请确保已登录")
+	}
+
+	data := url.Values{
+		"type":   {"1"}, // 1: 视频评论区
+		"oid":    {oid},
+		"rpid":   {rpid},
+		"reason": {strconv.Itoa(reason)},
+		"csrf":   {csrf},
+	}
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/av%s", oid))
+	body, err := c.makeRequest("POST", "https://api.bilibili.com/x/v2/reply/report", data, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ReportResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析举报评论API响应失败")
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/pkg/config"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// 默认重试策略
+const (
+	defaultRetryAttempts  = 3
+	defaultRetryBaseDelay = 500 * time.Millisecond
+)
+
+// SetContext 设置请求使用的上下文，上下文取消或临近截止时间时不再重试
+func (c *Client) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// context 返回请求使用的上下文
+func (c *Client) context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
+// sleep 退避等待指定时间，上下文取消时提前返回上下文的错误
+func (c *Client) sleep(d time.Duration) error {
+	ctx := c.context()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryPolicy 返回最大尝试次数和首次重试的退避时间
+func retryPolicy() (int, time.Duration) {
+	attempts, baseDelay := defaultRetryAttempts, defaultRetryBaseDelay
+	if cfg := config.Get(); cfg != nil {
+		if cfg.Bilibili.RetryAttempts > 0 {
+			attempts = cfg.Bilibili.RetryAttempts
+		}
+		if cfg.Bilibili.RetryBaseDelay > 0 {
+			baseDelay = cfg.Bilibili.RetryBaseDelay
+		}
+	}
+	return attempts, baseDelay
+}
+
+// doRequest 发送请求并读取响应体。
+// GET/HEAD请求的连接错误和429/5xx响应会按指数退避加随机抖动重试，其他4xx响应直接返回。
+// POST等写操作不重试：服务端可能已经处理了请求，重发会导致重复点赞、投币、评论甚至重复扣款
+func (c *Client) doRequest(req *http.Request, form url.Values) (int, []byte, error) {
+	ctx := c.context()
+	attempts, baseDelay := retryPolicy()
+	if !isIdempotent(req.Method) {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return 0, nil, errors.Wrap(err, "重建请求体失败")
+			}
+			attemptReq.Body = body
+		}
+
+		status, body, err := c.doOnce(attemptReq, form)
+		if attempt >= attempts || !shouldRetry(ctx, status, err) {
+			return status, body, err
+		}
+
+		delay := baseDelay << (attempt - 1)
+		delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return status, body, err
+		}
+
+		if err != nil {
+			logger.Warnf("⚠️ 请求失败，%v 后进行第 %d 次重试: %s %s: %v", delay.Round(time.Millisecond), attempt, req.Method, req.URL.Path, err)
+		} else {
+			logger.Warnf("⚠️ 请求返回HTTP %d，%v 后进行第 %d 次重试: %s %s", status, delay.Round(time.Millisecond), attempt, req.Method, req.URL.Path)
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, body, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// doOnce 发送一次请求并读取完整响应体
+func (c *Client) doOnce(req *http.Request, form url.Values) (int, []byte, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "HTTP请求失败")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, errors.Wrap(err, "读取响应失败")
+	}
+	c.logHTTP(req, form, resp.StatusCode, body)
+
+	return resp.StatusCode, body, nil
+}
+
+// isIdempotent 判断请求方法是否可以安全重试
+func isIdempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// shouldRetry 判断请求是否值得重试：连接错误、429和5xx可重试，上下文已结束时不重试
+func shouldRetry(ctx context.Context, status int, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestSleepReturnsWhenContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{}
+	c.SetContext(ctx)
+
+	// 退避期间取消上下文，应立即返回而不是等满退避时间
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	if err := c.sleep(time.Minute); err != context.Canceled {
+		t.Fatalf("sleep error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("sleep ignored cancellation, took %v", elapsed)
+	}
+}
+
+func TestSleepWithoutContext(t *testing.T) {
+	c := &Client{}
+	if err := c.sleep(time.Millisecond); err != nil {
+		t.Fatalf("sleep error = %v", err)
+	}
+}
+
+func TestMakeRequestRiskControl(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 风控拦截时返回HTML页面而不是JSON
+		w.WriteHeader(http.StatusPreconditionFailed)
+		w.Write([]byte("<html>412</html>"))
+	}))
+	defer server.Close()
+
+	c := NewClient(map[string]string{})
+	if _, err := c.makeRequest("GET", server.URL, nil, c.spaceHeaders("1")); !errors.Is(err, errRiskControl) {
+		t.Fatalf("makeRequest error = %v, want errRiskControl", err)
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"html"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// SearchOrders 视频搜索支持的排序方式
+var SearchOrders = []string{"totalrank", "click", "pubdate", "dm", "stow"}
+
+// VideoSearchResult 视频搜索结果
+type VideoSearchResult struct {
+	Aid         int64  `json:"aid"`          // 视频AV号
+	Bvid        string `json:"bvid"`         // 视频BV号
+	Title       string `json:"title"`        // 标题（原始数据包含<em>高亮标签）
+	Author      string `json:"author"`       // UP主
+	Mid         int64  `json:"mid"`          // UP主UID
+	Description string `json:"description"`  // 简介
+	Pic         string `json:"pic"`          // 封面
+	Play        int64  `json:"play"`         // 播放数
+	VideoReview int64  `json:"video_review"` // 弹幕数
+	Favorites   int64  `json:"favorites"`    // 收藏数
+	Duration    string `json:"duration"`     // 时长，如 "12:34"
+	Pubdate     int64  `json:"pubdate"`      // 发布时间戳
+}
+
+// SearchResponse 视频搜索API响应
+type SearchResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Page       int                 `json:"page"`       // 当前页
+		PageSize   int                 `json:"pagesize"`   // 每页数量
+		NumResults int                 `json:"numResults"` // 结果总数
+		NumPages   int                 `json:"numPages"`   // 总页数
+		Result     []VideoSearchResult `json:"result"`
+	} `json:"data"`
+}
+
+// SearchVideos 按关键词搜索视频（WBI签名接口），order为空时按综合排序
+func (c *Client) SearchVideos(keyword string, page int, order string) (*SearchResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if order == "" {
+		order = "totalrank"
+	}
+	if !isSearchOrder(order) {
+		return nil, errors.Errorf("不支持的排序方式: %s", order)
+	}
+
+	params := url.Values{
+		"search_type": {"video"},
+		"keyword":     {keyword},
+		"page":        {strconv.Itoa(page)},
+		"order":       {order},
+	}
+
+	query, err := c.signWbi(params)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := c.getHeaders("https://search.bilibili.com")
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/web-interface/wbi/search/type?"+query, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp SearchResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析视频搜索API响应失败")
+	}
+	checkWbiResponse(resp.Code)
+
+	for i := range resp.Data.Result {
+		result := &resp.Data.Result[i]
+		result.Title = html.UnescapeString(highlightTagPattern.ReplaceAllString(result.Title, ""))
+		result.Description = html.UnescapeString(result.Description)
+	}
+
+	return &resp, nil
+}
+
+// isSearchOrder 检查排序方式是否受支持
+func isSearchOrder(order string) bool {
+	for _, o := range SearchOrders {
+		if o == order {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package api
+
+// StreamPreset 播放地址参数预设，封装 fnval/platform/quality 的常用组合
+type StreamPreset struct {
+	Name        string // 预设名称
+	FnVal       int    // 视频流格式标识
+	Platform    string // 平台 (html5/pc)
+	Quality     int    // 目标清晰度
+	Description string // 预设说明
+}
+
+// streamPresets 内置预设
+var streamPresets = []StreamPreset{
+	{
+		Name:        "mobile_mp4",
+		FnVal:       1,
+		Platform:    "html5",
+		Quality:     64,
+		Description: "移动端MP4：音视频合一且无防盗链，最高720P",
+	},
+	{
+		Name:        "web_dash",
+		FnVal:       16,
+		Platform:    "pc",
+		Quality:     80,
+		Description: "网页端DASH：音视频分离，默认1080P",
+	},
+	{
+		Name:        "web_4k",
+		FnVal:       16 | 64 | 128, // DASH + HDR + 4K
+		Platform:    "pc",
+		Quality:     120,
+		Description: "网页端DASH高画质：请求4K/HDR，需要大会员账号",
+	},
+	{
+		Name:        "web_all",
+		FnVal:       4048, // 全部DASH特性
+		Platform:    "pc",
+		Quality:     127,
+		Description: "网页端DASH全部特性：返回所有编码、杜比/无损音轨及8K（取决于账号权限）",
+	},
+}
+
+// GetStreamPreset 根据名称获取预设
+func GetStreamPreset(name string) (StreamPreset, bool) {
+	for _, preset := range streamPresets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return StreamPreset{}, false
+}
+
+// StreamPresetNames 获取所有预设名称
+func StreamPresetNames() []string {
+	names := make([]string, 0, len(streamPresets))
+	for _, preset := range streamPresets {
+		names = append(names, preset.Name)
+	}
+	return names
+}
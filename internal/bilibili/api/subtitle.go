@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SubtitleLine 字幕文件中的单条字幕
+type SubtitleLine struct {
+	From     float64 `json:"from"`     // 起始时间(秒)
+	To       float64 `json:"to"`       // 结束时间(秒)
+	Location int     `json:"location"` // 显示位置
+	Content  string  `json:"content"`  // 字幕文本
+}
+
+// SubtitleContent 字幕文件内容（B站JSON字幕格式）
+type SubtitleContent struct {
+	FontSize        float64        `json:"font_size"`
+	FontColor       string         `json:"font_color"`
+	BackgroundAlpha float64        `json:"background_alpha"`
+	Body            []SubtitleLine `json:"body"`
+}
+
+// GetSubtitleContent 获取字幕文件内容，subtitleURL来自视频信息的字幕列表
+func (c *Client) GetSubtitleContent(subtitleURL string) (*SubtitleContent, error) {
+	if subtitleURL == "" {
+		return nil, errors.New("字幕地址为空")
+	}
+	if strings.HasPrefix(subtitleURL, "//") {
+		subtitleURL = "https:" + subtitleURL
+	}
+
+	headers := c.getHeaders("https://www.bilibili.com")
+	body, err := c.makeRequest("GET", subtitleURL, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var content SubtitleContent
+	if err := json.Unmarshal(body, &content); err != nil {
+		return nil, errors.Wrap(err, "解析字幕文件失败")
+	}
+
+	return &content, nil
+}
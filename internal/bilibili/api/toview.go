@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ToViewResponse 稍后再看操作API响应
+type ToViewResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ToViewItem 稍后再看列表中的视频
+type ToViewItem struct {
+	Aid      int64  `json:"aid"`
+	Bvid     string `json:"bvid"`
+	Title    string `json:"title"`
+	Duration int64  `json:"duration"` // 时长(秒)
+	Progress int64  `json:"progress"` // 观看进度(秒)，-1表示已看完
+	AddAt    int64  `json:"add_at"`   // 添加时间(Unix时间戳)
+	Owner    struct {
+		Mid  int64  `json:"mid"`
+		Name string `json:"name"`
+	} `json:"owner"`
+}
+
+// ToViewListResponse 稍后再看列表API响应
+type ToViewListResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Count int          `json:"count"`
+		List  []ToViewItem `json:"list"`
+	} `json:"data"`
+}
+
+// AddToWatchLater 将视频加入稍后再看
+func (c *Client) AddToWatchLater(videoID string) (*ToViewResponse, error) {
+	return c.modifyWatchLater("https://api.bilibili.com/x/v2/history/toview/add", videoID, "添加稍后再看")
+}
+
+// DeleteFromWatchLater 将视频移出稍后再看
+func (c *Client) DeleteFromWatchLater(videoID string) (*ToViewResponse, error) {
+	return c.modifyWatchLater("https://api.bilibili.com/x/v2/history/toview/del", videoID, "移除稍后再看")
+}
+
+// modifyWatchLater 修改稍后再看列表
+func (c *Client) modifyWatchLater(apiURL, videoID, action string) (*ToViewResponse, error) {
+	csrf, exists := c.cookies["bili_jct"]
+	if !exists || csrf == "" {
+		return nil, errors.New("缺少CSRF token (bili_jct)")
+	}
+
+	aid, err := c.videoIDToAID(videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := url.Values{
+		"aid":  {strconv.FormatInt(aid, 10)},
+		"csrf": {csrf},
+	}
+
+	headers := c.getHeaders(fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	body, err := c.makeRequest("POST", apiURL, data, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ToViewResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrapf(err, "解析%sAPI响应失败", action)
+	}
+
+	return &resp, nil
+}
+
+// GetWatchLater 获取稍后再看列表
+func (c *Client) GetWatchLater() (*ToViewListResponse, error) {
+	headers := c.getHeaders("https://www.bilibili.com/watchlater/")
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/v2/history/toview", nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ToViewListResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析稍后再看列表API响应失败")
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUserInfoRateLimited 用户空间信息接口被风控，携带错误码以便调用方按API错误分类处理
+var ErrUserInfoRateLimited = NewAPIError(CodeTooFast, "B站正在限制用户信息查询，请稍后再试，或指定已登录的账号")
+
+// UserInfoResponse 用户空间信息API响应
+type UserInfoResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Mid      int64  `json:"mid"`      // 用户UID
+		Name     string `json:"name"`     // 昵称
+		Sex      string `json:"sex"`      // 性别
+		Face     string `json:"face"`     // 头像
+		Sign     string `json:"sign"`     // 签名
+		Level    int    `json:"level"`    // 等级
+		Birthday string `json:"birthday"` // 生日
+		Official struct {
+			Role  int    `json:"role"`  // 认证类型 0:无
+			Title string `json:"title"` // 认证信息
+			Desc  string `json:"desc"`  // 认证备注
+			Type  int    `json:"type"`  // -1:无 0:个人认证 1:机构认证
+		} `json:"official"`
+		Vip struct {
+			Type   int `json:"type"`   // 0:无 1:月度大会员 2:年度及以上大会员
+			Status int `json:"status"` // 0:无 1:有
+			Label  struct {
+				Text string `json:"text"`
+			} `json:"label"`
+		} `json:"vip"`
+		IsFollowed bool `json:"is_followed"` // 当前账号是否已关注
+	} `json:"data"`
+}
+
+// RelationStatResponse 用户关系统计API响应
+type RelationStatResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Mid       int64 `json:"mid"`
+		Following int64 `json:"following"` // 关注数
+		Follower  int64 `json:"follower"`  // 粉丝数
+	} `json:"data"`
+}
+
+// UserRelationResponse 当前账号与目标用户的关系
+type UserRelationResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Mid       int64 `json:"mid"`
+		Attribute int   `json:"attribute"` // 关系属性 0:未关注 2:已关注 6:互相关注 128:已拉黑
+		Mtime     int64 `json:"mtime"`     // 关注时间
+		Special   int   `json:"special"`   // 是否特别关注
+	} `json:"data"`
+}
+
+// GetUserInfo 获取用户空间信息（WBI签名接口）
+func (c *Client) GetUserInfo(userID string) (*UserInfoResponse, error) {
+	// 未携带buvid3的空间接口请求极易被风控
+	c.ensureBuvid3()
+
+	query, err := c.signWbi(url.Values{"mid": {userID}})
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/space/wbi/acc/info?"+query, nil, c.spaceHeaders(userID))
+	if errors.Is(err, errRiskControl) {
+		return nil, ErrUserInfoRateLimited
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var resp UserInfoResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析用户信息API响应失败")
+	}
+	checkWbiResponse(resp.Code)
+
+	if resp.Code == CodeTooFast || resp.Code == CodeRequestBlocked {
+		return nil, ErrUserInfoRateLimited
+	}
+
+	return &resp, nil
+}
+
+// GetRelationStat 获取用户的关注数和粉丝数
+func (c *Client) GetRelationStat(userID string) (*RelationStatResponse, error) {
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/relation/stat", url.Values{"vmid": {userID}}, c.spaceHeaders(userID))
+	if errors.Is(err, errRiskControl) {
+		return nil, ErrUserInfoRateLimited
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var resp RelationStatResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析关系统计API响应失败")
+	}
+
+	if resp.Code == CodeTooFast || resp.Code == CodeRequestBlocked {
+		return nil, ErrUserInfoRateLimited
+	}
+
+	return &resp, nil
+}
+
+// GetRelation 查询当前账号与目标用户的关系（需要登录）
+func (c *Client) GetRelation(userID string) (*UserRelationResponse, error) {
+	headers := c.getHeaders("https://space.bilibili.com/" + userID)
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/relation", url.Values{"fid": {userID}}, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp UserRelationResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析关系API响应失败")
+	}
+
+	return &resp, nil
+}
+
+// spaceHeaders 模拟从用户空间页发起请求的请求头
+func (c *Client) spaceHeaders(userID string) map[string]string {
+	headers := c.getHeaders("https://space.bilibili.com/" + userID)
+	headers["Origin"] = "https://space.bilibili.com"
+	headers["Accept-Language"] = "zh-CN,zh;q=0.9,en;q=0.8"
+	headers["Sec-Fetch-Site"] = "same-site"
+	headers["Sec-Fetch-Mode"] = "cors"
+	headers["Sec-Fetch-Dest"] = "empty"
+	return headers
+}
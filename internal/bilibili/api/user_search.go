@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// spaceURLPattern 匹配用户空间链接中的mid，例如 space.bilibili.com/123 或 m.bilibili.com/space/123
+var spaceURLPattern = regexp.MustCompile(`(?i)(?:space\.bilibili\.com/|bilibili\.com/space/)(\d+)`)
+
+// highlightTagPattern 搜索结果中关键词高亮的HTML标签
+var highlightTagPattern = regexp.MustCompile(`<[^>]+>`)
+
+// UserSearchResult 用户搜索结果
+type UserSearchResult struct {
+	Mid      int64  `json:"mid"`       // 用户UID
+	Uname    string `json:"uname"`     // 用户名
+	Usign    string `json:"usign"`     // 个性签名
+	Fans     int64  `json:"fans"`      // 粉丝数
+	Videos   int64  `json:"videos"`    // 投稿数
+	Level    int    `json:"level"`     // 等级
+	Upic     string `json:"upic"`      // 头像
+	IsUpuser int    `json:"is_upuser"` // 是否为UP主
+}
+
+// SearchUsersResponse 用户搜索API响应
+type SearchUsersResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		NumResults int                `json:"numResults"` // 结果总数
+		Result     []UserSearchResult `json:"result"`
+	} `json:"data"`
+}
+
+// ParseUserID 从UID、"UID:123"或用户空间链接中解析mid，无法解析时返回false
+func ParseUserID(input string) (string, bool) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", false
+	}
+
+	if m := spaceURLPattern.FindStringSubmatch(input); m != nil {
+		return m[1], true
+	}
+
+	upper := strings.ToUpper(input)
+	for _, prefix := range []string{"UID:", "UID：", "UID"} {
+		if strings.HasPrefix(upper, prefix) {
+			input = strings.TrimSpace(input[len(prefix):])
+			break
+		}
+	}
+
+	if mid, err := strconv.ParseInt(input, 10, 64); err == nil && mid > 0 {
+		return input, true
+	}
+	return "", false
+}
+
+// SearchUsers 按用户名搜索用户（WBI签名接口）
+func (c *Client) SearchUsers(keyword string, page int) (*SearchUsersResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	params := url.Values{
+		"search_type": {"bili_user"},
+		"keyword":     {keyword},
+		"page":        {strconv.Itoa(page)},
+	}
+
+	query, err := c.signWbi(params)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := c.getHeaders("https://search.bilibili.com")
+	body, err := c.makeRequest("GET", "https://api.bilibili.com/x/web-interface/wbi/search/type?"+query, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp SearchUsersResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.Wrap(err, "解析用户搜索API响应失败")
+	}
+	checkWbiResponse(resp.Code)
+
+	for i := range resp.Data.Result {
+		resp.Data.Result[i].Uname = highlightTagPattern.ReplaceAllString(resp.Data.Result[i].Uname, "")
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/pkg/config"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+	"golang.org/x/sync/singleflight"
+)
+
+// mixinKeyEncTab WBI混合密钥重排表
+var mixinKeyEncTab = []int{
+	46, 47, 18, 2, 53, 8, 23, 32, 15, 50, 10, 31, 58, 3, 45, 35, 27, 43, 5, 49,
+	33, 9, 42, 19, 29, 28, 14, 39, 12, 38, 41, 13, 37, 48, 7, 16, 24, 55, 40,
+	61, 26, 17, 0, 1, 60, 51, 30, 4, 22, 25, 54, 21, 56, 59, 6, 63, 57, 62, 11,
+	36, 20, 34, 44, 52,
+}
+
+// wbiKeyMaxAge WBI密钥最长复用时间（B站密钥约每日更新，签名失败时会提前刷新）
+const wbiKeyMaxAge = 24 * time.Hour
+
+// wbiKeyFetchRetries 获取WBI密钥的重试次数
+const wbiKeyFetchRetries = 2
+
+// wbiKeyCacheFile 持久化WBI密钥的文件名（位于cookie目录下）
+const wbiKeyCacheFile = "wbi_key.json"
+
+// ErrWbiKeyUnavailable 无法获取WBI密钥，所有WBI签名接口均不可用
+var ErrWbiKeyUnavailable = errors.New("无法获取WBI签名密钥")
+
+// wbiKeyCache 全局WBI密钥缓存
+var wbiKeyCache struct {
+	sync.Mutex
+	mixinKey  string
+	fetchedAt time.Time
+	stale     bool // 签名失败后标记，下次使用前尝试刷新
+	loaded    bool // 是否已尝试从磁盘加载
+}
+
+// wbiKeyRecord 磁盘上的WBI密钥记录
+type wbiKeyRecord struct {
+	MixinKey  string    `json:"mixin_key"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// wbiKeyGroup 合并并发的WBI密钥刷新请求
+var wbiKeyGroup singleflight.Group
+
+// getMixinKey 获取WBI混合密钥，优先复用缓存，刷新失败时回退到最近一次可用的密钥。
+// 刷新在锁外进行（包含重试退避），并发调用只会触发一次导航接口请求
+func (c *Client) getMixinKey() (string, error) {
+	wbiKeyCache.Lock()
+	if !wbiKeyCache.loaded {
+		wbiKeyCache.loaded = true
+		if record, ok := loadWbiKeyRecord(); ok {
+			wbiKeyCache.mixinKey = record.MixinKey
+			wbiKeyCache.fetchedAt = record.FetchedAt
+		}
+	}
+	cachedKey := wbiKeyCache.mixinKey
+	usable := cachedKey != "" && time.Since(wbiKeyCache.fetchedAt) < wbiKeyMaxAge
+	stale := wbiKeyCache.stale
+	wbiKeyCache.Unlock()
+
+	if usable && !stale {
+		return cachedKey, nil
+	}
+
+	key, err, _ := wbiKeyGroup.Do("mixin_key", func() (interface{}, error) {
+		key, err := c.fetchMixinKey()
+		if err != nil {
+			return "", err
+		}
+
+		record := wbiKeyRecord{MixinKey: key, FetchedAt: time.Now()}
+		wbiKeyCache.Lock()
+		wbiKeyCache.mixinKey = record.MixinKey
+		wbiKeyCache.fetchedAt = record.FetchedAt
+		wbiKeyCache.stale = false
+		wbiKeyCache.Unlock()
+		saveWbiKeyRecord(record)
+		return key, nil
+	})
+	if err == nil {
+		return key.(string), nil
+	}
+
+	if usable {
+		logger.Warnf("刷新WBI密钥失败，继续使用缓存的密钥: %v", err)
+		return cachedKey, nil
+	}
+
+	return "", errors.WithMessage(ErrWbiKeyUnavailable, err.Error())
+}
+
+// fetchMixinKey 通过导航接口获取WBI密钥，失败时短暂退避后重试
+func (c *Client) fetchMixinKey() (string, error) {
+	var lastErr error
+	for attempt := 0; attempt <= wbiKeyFetchRetries; attempt++ {
+		if attempt > 0 {
+			if err := c.sleep(time.Duration(attempt) * 500 * time.Millisecond); err != nil {
+				return "", errors.Wrap(err, "等待重试时请求已取消")
+			}
+		}
+
+		nav, err := c.GetNavInfo()
+		if err != nil {
+			lastErr = errors.Wrap(err, "请求导航接口失败")
+			continue
+		}
+
+		imgKey := wbiKeyFromURL(nav.Data.WbiImg.ImgURL)
+		subKey := wbiKeyFromURL(nav.Data.WbiImg.SubURL)
+		if imgKey == "" || subKey == "" {
+			lastErr = errors.Errorf("响应中缺少wbi_img (code: %d)", nav.Code)
+			continue
+		}
+
+		return mixinKey(imgKey + subKey), nil
+	}
+	return "", lastErr
+}
+
+// invalidateWbiKey 标记缓存的WBI密钥需要刷新，刷新失败时仍可回退使用
+func invalidateWbiKey() {
+	wbiKeyCache.Lock()
+	defer wbiKeyCache.Unlock()
+	wbiKeyCache.stale = true
+}
+
+// checkWbiResponse 签名接口返回鉴权失败或风控时，标记密钥需要刷新
+func checkWbiResponse(code int) {
+	if code == -403 || code == -352 {
+		invalidateWbiKey()
+	}
+}
+
+// wbiKeyCachePath 获取WBI密钥缓存文件路径，未加载配置时不持久化
+func wbiKeyCachePath() string {
+	cfg := config.Get()
+	if cfg == nil || cfg.GetResolvedCookieDir() == "" {
+		return ""
+	}
+	return filepath.Join(cfg.GetResolvedCookieDir(), wbiKeyCacheFile)
+}
+
+// loadWbiKeyRecord 从磁盘加载上次获取的WBI密钥
+func loadWbiKeyRecord() (wbiKeyRecord, bool) {
+	var record wbiKeyRecord
+	path := wbiKeyCachePath()
+	if path == "" {
+		return record, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return record, false
+	}
+	if err := json.Unmarshal(data, &record); err != nil || record.MixinKey == "" {
+		return record, false
+	}
+	return record, true
+}
+
+// saveWbiKeyRecord 将WBI密钥写入磁盘，失败时仅记录日志
+func saveWbiKeyRecord(record wbiKeyRecord) {
+	path := wbiKeyCachePath()
+	if path == "" {
+		return
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		logger.Warnf("保存WBI密钥失败: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		logger.Warnf("保存WBI密钥失败: %v", err)
+	}
+}
+
+// signWbi 对请求参数进行WBI签名，返回参与签名的查询字符串（已附加w_rid），调用方必须原样发送
+func (c *Client) signWbi(params url.Values) (string, error) {
+	key, err := c.getMixinKey()
+	if err != nil {
+		return "", err
+	}
+	_, query := signWbiParams(params, key, time.Now())
+	return query, nil
+}
+
+// signWbiParams 使用混合密钥计算签名，返回过滤后的参数（包含wts和w_rid）以及与签名一致的查询字符串。
+// 参数值中的 "!'()*" 会被去掉，空格编码为%20，发送的值与签名的值保持一致。
+func signWbiParams(params url.Values, mixinKey string, now time.Time) (url.Values, string) {
+	filter := strings.NewReplacer("!", "", "'", "", "(", "", ")", "", "*", "")
+	signed := url.Values{}
+	for k, v := range params {
+		if len(v) > 0 {
+			signed.Set(k, filter.Replace(v[0]))
+		}
+	}
+	signed.Set("wts", strconv.FormatInt(now.Unix(), 10))
+
+	keys := make([]string, 0, len(signed))
+	for k := range signed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		parts = append(parts, wbiEscape(k)+"="+wbiEscape(signed.Get(k)))
+	}
+	query := strings.Join(parts, "&")
+
+	hash := md5.Sum([]byte(query + mixinKey))
+	wRid := hex.EncodeToString(hash[:])
+	signed.Set("w_rid", wRid)
+	return signed, query + "&w_rid=" + wRid
+}
+
+// wbiEscape 按WBI签名要求编码查询参数，空格编码为%20而不是+
+func wbiEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// mixinKey 按重排表生成32位混合密钥
+func mixinKey(raw string) string {
+	var b strings.Builder
+	for _, idx := range mixinKeyEncTab {
+		if idx < len(raw) {
+			b.WriteByte(raw[idx])
+		}
+	}
+	key := b.String()
+	if len(key) > 32 {
+		key = key[:32]
+	}
+	return key
+}
+
+// wbiKeyFromURL 从wbi_img的URL中提取密钥（文件名去掉扩展名）
+func wbiKeyFromURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	base := path.Base(rawURL)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
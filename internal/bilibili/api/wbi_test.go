@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMixinKey(t *testing.T) {
+	got := mixinKey("7cd084941338484aae1ad9425b84077c" + "4932caff0ff746eab6f01bf08b70ac45")
+	if want := "ea1db124af3c7062474693fa704f4ff8"; got != want {
+		t.Fatalf("mixinKey = %q, want %q", got, want)
+	}
+}
+
+func TestSignWbiParamsKnownVector(t *testing.T) {
+	params := url.Values{"foo": {"114"}, "bar": {"514"}, "zab": {"1919810"}}
+	signed, query := signWbiParams(params, "ea1db124af3c7062474693fa704f4ff8", time.Unix(1702204169, 0))
+
+	want := "bar=514&foo=114&wts=1702204169&zab=1919810&w_rid=8f6f2b5b3d485fe1886cec6a0be8c5d4"
+	if query != want {
+		t.Fatalf("query = %q, want %q", query, want)
+	}
+	if signed.Get("w_rid") != "8f6f2b5b3d485fe1886cec6a0be8c5d4" || signed.Get("wts") != "1702204169" {
+		t.Fatalf("signed values = %v", signed)
+	}
+	if _, ok := params["wts"]; ok {
+		t.Fatal("input params were modified")
+	}
+}
+
+func TestSignWbiParamsSendsSignedValues(t *testing.T) {
+	params := url.Values{"keyword": {"hello world (live)!"}, "page": {"1"}}
+	signed, query := signWbiParams(params, "ea1db124af3c7062474693fa704f4ff8", time.Unix(1702204169, 0))
+
+	if !strings.Contains(query, "keyword=hello%20world%20live") {
+		t.Fatalf("query does not use %%20 or keeps filtered characters: %q", query)
+	}
+	if strings.Contains(query, "+") {
+		t.Fatalf("query contains '+': %q", query)
+	}
+	if signed.Get("keyword") != "hello world live" {
+		t.Fatalf("signed keyword = %q, want filtered value", signed.Get("keyword"))
+	}
+
+	// 服务端解析到的参数必须与签名时使用的参数一致
+	parsed, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := range signed {
+		if parsed.Get(k) != signed.Get(k) {
+			t.Errorf("%s: sent %q, signed %q", k, parsed.Get(k), signed.Get(k))
+		}
+	}
+
+	req, err := http.NewRequest("GET", "https://api.bilibili.com/x/web-interface/wbi/search/type?"+query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.RawQuery != query {
+		t.Fatalf("request query = %q, want %q", req.URL.RawQuery, query)
+	}
+}
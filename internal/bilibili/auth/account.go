@@ -5,40 +5,76 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/shirenchuang/bilibili-mcp/pkg/config"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
 )
 
 // Account B站账号信息
 type Account struct {
-	Name      string    `json:"name"`       // 账号标识名
-	Username  string    `json:"username"`   // B站用户名
-	Nickname  string    `json:"nickname"`   // 昵称
-	UID       string    `json:"uid"`        // B站UID
-	Avatar    string    `json:"avatar"`     // 头像URL
-	IsDefault bool      `json:"is_default"` // 是否为默认账号
-	LoginTime time.Time `json:"login_time"` // 登录时间
-	LastUsed  time.Time `json:"last_used"`  // 最后使用时间
-	IsActive  bool      `json:"is_active"`  // 是否激活状态
+	Name      string    `json:"name"`                 // 账号标识名
+	Username  string    `json:"username"`             // B站用户名
+	Nickname  string    `json:"nickname"`             // 昵称
+	UID       string    `json:"uid"`                  // B站UID
+	Avatar    string    `json:"avatar"`               // 头像URL
+	IsDefault bool      `json:"is_default"`           // 是否为默认账号
+	LoginTime time.Time `json:"login_time"`           // 登录时间
+	LastUsed  time.Time `json:"last_used"`            // 最后使用时间
+	IsActive  bool      `json:"is_active"`            // 是否激活状态
+	Level     int       `json:"level,omitempty"`      // 账号等级，旧版账号文件中不存在时为0
+	VipStatus int       `json:"vip_status,omitempty"` // 大会员状态：1=有效
+	VipType   int       `json:"vip_type,omitempty"`   // 大会员类型：1=月度 2=年度及以上
+}
+
+// Membership 账号等级和大会员状态，如 "Lv6 · 大会员"，信息未知时返回空字符串
+func (a *Account) Membership() string {
+	var parts []string
+	if a.Level > 0 {
+		parts = append(parts, fmt.Sprintf("Lv%d", a.Level))
+	}
+	if a.VipStatus == 1 {
+		if a.VipType == 2 {
+			parts = append(parts, "年度大会员")
+		} else {
+			parts = append(parts, "大会员")
+		}
+	}
+	return strings.Join(parts, " · ")
 }
 
 // AccountManager 账号管理器
 type AccountManager struct {
-	configFile string
-	cookieDir  string
+	configFile        string
+	cookieDir         string
+	autoSelectDefault bool // 没有可用默认账号时自动选择最近使用的账号
+	encryptCookies    bool // 未设置环境变量口令时使用本机生成的密钥加密cookies
 }
 
 // NewAccountManager 创建账号管理器
 func NewAccountManager() *AccountManager {
 	cfg := config.Get()
 	return &AccountManager{
-		configFile: filepath.Join(cfg.Accounts.CookieDir, "accounts.json"),
-		cookieDir:  cfg.Accounts.CookieDir,
+		configFile:        filepath.Join(cfg.Accounts.CookieDir, "accounts.json"),
+		cookieDir:         cfg.Accounts.CookieDir,
+		autoSelectDefault: cfg.Accounts.AutoSelectDefault,
+		encryptCookies:    cfg.Accounts.EncryptCookies,
 	}
 }
 
+// validateAccountName 校验账号名称，账号名称会用于拼接cookies文件路径，不能包含路径分隔符或".."
+func validateAccountName(name string) error {
+	if name == "" {
+		return errors.New("账号名称不能为空")
+	}
+	if strings.ContainsAny(name, "/\\\x00") || strings.Contains(name, "..") {
+		return errors.Errorf("无效的账号名称: %q", name)
+	}
+	return nil
+}
+
 // SaveAccount 保存账号信息
 func (am *AccountManager) SaveAccount(account *Account) error {
 	// 确保目录存在
@@ -125,6 +161,13 @@ func (am *AccountManager) GetDefaultAccount() (*Account, error) {
 		}
 	}
 
+	// 开启自动选择时，将最近使用的账号设为默认账号
+	if am.autoSelectDefault {
+		if acc := mostRecentlyUsed(accounts); acc != nil {
+			return am.promoteDefaultAccount(accounts, acc.Name)
+		}
+	}
+
 	// 如果没有默认账号，返回第一个激活的账号
 	for _, acc := range accounts {
 		if acc.IsActive {
@@ -132,9 +175,53 @@ func (am *AccountManager) GetDefaultAccount() (*Account, error) {
 		}
 	}
 
+	if len(accounts) > 0 {
+		return nil, fmt.Errorf("存在 %d 个账号但没有可用的默认账号，请使用 switch_account 设置默认账号", len(accounts))
+	}
 	return nil, fmt.Errorf("没有可用的账号，请先登录")
 }
 
+// mostRecentlyUsed 选出最近使用的激活账号，没有激活账号时返回nil（已失效的账号不会被选中）
+func mostRecentlyUsed(accounts []Account) *Account {
+	var best *Account
+	for i := range accounts {
+		acc := &accounts[i]
+		if !acc.IsActive {
+			continue
+		}
+		if best == nil || lastActivity(acc).After(lastActivity(best)) {
+			best = acc
+		}
+	}
+	return best
+}
+
+// lastActivity 账号最近活动时间，未使用过时取登录时间
+func lastActivity(acc *Account) time.Time {
+	if acc.LastUsed.IsZero() {
+		return acc.LoginTime
+	}
+	return acc.LastUsed
+}
+
+// promoteDefaultAccount 将指定账号设为默认账号并保存，不修改账号的激活状态
+func (am *AccountManager) promoteDefaultAccount(accounts []Account, name string) (*Account, error) {
+	var selected *Account
+	for i := range accounts {
+		accounts[i].IsDefault = accounts[i].Name == name
+		if accounts[i].IsDefault {
+			selected = &accounts[i]
+		}
+	}
+
+	if err := am.saveAccountsToFile(accounts); err != nil {
+		return nil, errors.Wrap(err, "保存默认账号失败")
+	}
+
+	logger.Infof("👤 未设置默认账号，已自动选择最近使用的账号 '%s'", name)
+	return selected, nil
+}
+
 // SetDefaultAccount 设置默认账号
 func (am *AccountManager) SetDefaultAccount(name string) error {
 	accounts, err := am.LoadAccounts()
@@ -236,17 +323,18 @@ func (am *AccountManager) DeleteAccount(name string) error {
 		return fmt.Errorf("账号 '%s' 不存在", name)
 	}
 
-	// 如果删除的是默认账号，重新设置默认账号
-	if len(newAccounts) > 0 {
-		hasDefault := false
-		for _, acc := range newAccounts {
-			if acc.IsDefault {
-				hasDefault = true
-				break
-			}
+	// 如果删除的是默认账号，将最近使用的激活账号设为默认账号；没有激活账号时保持无默认账号
+	hasDefault := false
+	for _, acc := range newAccounts {
+		if acc.IsDefault {
+			hasDefault = true
+			break
 		}
-		if !hasDefault {
-			newAccounts[0].IsDefault = true
+	}
+	if !hasDefault {
+		if next := mostRecentlyUsed(newAccounts); next != nil {
+			next.IsDefault = true
+			logger.Infof("👤 默认账号已删除，'%s' 成为新的默认账号", next.Name)
 		}
 	}
 
@@ -263,6 +351,28 @@ func (am *AccountManager) saveAccountsToFile(accounts []Account) error {
 	return os.WriteFile(am.configFile, data, 0644)
 }
 
+// UpdateMembership 更新账号等级和大会员状态
+func (am *AccountManager) UpdateMembership(name string, level, vipStatus, vipType int) error {
+	accounts, err := am.LoadAccounts()
+	if err != nil {
+		return err
+	}
+
+	for i := range accounts {
+		if accounts[i].Name == name {
+			if accounts[i].Level == level && accounts[i].VipStatus == vipStatus && accounts[i].VipType == vipType {
+				return nil
+			}
+			accounts[i].Level = level
+			accounts[i].VipStatus = vipStatus
+			accounts[i].VipType = vipType
+			return am.saveAccountsToFile(accounts)
+		}
+	}
+
+	return fmt.Errorf("账号 '%s' 不存在", name)
+}
+
 // UpdateLastUsed 更新账号最后使用时间
 func (am *AccountManager) UpdateLastUsed(name string) error {
 	accounts, err := am.LoadAccounts()
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func testAccounts() []Account {
+	now := time.Now()
+	return []Account{
+		{Name: "old", IsActive: true, LastUsed: now.Add(-2 * time.Hour)},
+		{Name: "recent", IsActive: true, LastUsed: now.Add(-time.Hour)},
+		{Name: "expired", IsActive: false, LastUsed: now},
+	}
+}
+
+func findAccount(t *testing.T, am *AccountManager, name string) Account {
+	t.Helper()
+	accounts, err := am.LoadAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, acc := range accounts {
+		if acc.Name == name {
+			return acc
+		}
+	}
+	t.Fatalf("account %q not found", name)
+	return Account{}
+}
+
+func TestMostRecentlyUsedSkipsInactive(t *testing.T) {
+	if acc := mostRecentlyUsed(testAccounts()); acc == nil || acc.Name != "recent" {
+		t.Fatalf("got %+v, want recent", acc)
+	}
+	if acc := mostRecentlyUsed([]Account{{Name: "expired"}}); acc != nil {
+		t.Fatalf("inactive account selected: %+v", acc)
+	}
+}
+
+func TestGetDefaultAccountAutoSelect(t *testing.T) {
+	am := newTestAccountManager(t, false)
+	am.autoSelectDefault = true
+	if err := am.saveAccountsToFile(testAccounts()); err != nil {
+		t.Fatal(err)
+	}
+
+	acc, err := am.GetDefaultAccount()
+	if err != nil {
+		t.Fatalf("GetDefaultAccount: %v", err)
+	}
+	if acc.Name != "recent" {
+		t.Fatalf("default = %q, want recent", acc.Name)
+	}
+	if !findAccount(t, am, "recent").IsDefault {
+		t.Fatal("selected account was not saved as default")
+	}
+	if findAccount(t, am, "expired").IsActive {
+		t.Fatal("inactive account was reactivated")
+	}
+}
+
+func TestGetDefaultAccountOnlyInactive(t *testing.T) {
+	am := newTestAccountManager(t, false)
+	am.autoSelectDefault = true
+	if err := am.saveAccountsToFile([]Account{{Name: "expired", LastUsed: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if acc, err := am.GetDefaultAccount(); err == nil {
+		t.Fatalf("inactive account returned: %+v", acc)
+	}
+	if acc := findAccount(t, am, "expired"); acc.IsDefault || acc.IsActive {
+		t.Fatalf("inactive account modified: %+v", acc)
+	}
+}
+
+func TestDeleteDefaultAccountPromotesActive(t *testing.T) {
+	am := newTestAccountManager(t, false)
+	accounts := testAccounts()
+	accounts[0].IsDefault = true
+	if err := am.saveAccountsToFile(accounts); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := am.DeleteAccount("old"); err != nil {
+		t.Fatalf("DeleteAccount: %v", err)
+	}
+	if !findAccount(t, am, "recent").IsDefault {
+		t.Fatal("most recently used active account was not promoted")
+	}
+	if acc := findAccount(t, am, "expired"); acc.IsDefault || acc.IsActive {
+		t.Fatalf("inactive account modified: %+v", acc)
+	}
+
+	if err := am.DeleteAccount("recent"); err != nil {
+		t.Fatalf("DeleteAccount: %v", err)
+	}
+	if acc := findAccount(t, am, "expired"); acc.IsDefault || acc.IsActive {
+		t.Fatalf("inactive account promoted to default: %+v", acc)
+	}
+}
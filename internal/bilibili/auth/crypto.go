@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// CookieKeyEnv 设置后使用该口令派生cookies加密密钥，优先于本机生成的密钥
+	CookieKeyEnv = "BILIBILI_MCP_COOKIE_KEY"
+	// cookieKeyFile 本机生成的加密密钥文件，位于cookies目录下
+	cookieKeyFile = ".cookie_key"
+	// cookieCipherVersion 加密文件格式版本
+	cookieCipherVersion = "aes-256-gcm"
+	// kdfScrypt 口令派生密钥使用scrypt，盐随机生成并保存在文件头中
+	kdfScrypt = "scrypt"
+	// scrypt参数
+	scryptN       = 1 << 15
+	scryptR       = 8
+	scryptP       = 1
+	scryptSaltLen = 16
+)
+
+// encryptedCookieFile 加密后的cookies文件内容
+type encryptedCookieFile struct {
+	Encrypted string `json:"encrypted"`      // 加密算法
+	KDF       string `json:"kdf,omitempty"`  // 口令派生密钥的算法，为空表示使用本机密钥（或旧版SHA-256派生的口令密钥）
+	Salt      string `json:"salt,omitempty"` // base64编码的口令派生盐
+	Data      string `json:"data"`           // base64(nonce + 密文)
+}
+
+// cookieSecret cookies加密凭据：本机生成的随机密钥，或用户提供的口令（每个文件使用随机盐派生密钥）
+type cookieSecret struct {
+	key        []byte
+	passphrase string
+}
+
+// plaintextWarning 未启用加密时只提示一次
+var plaintextWarning sync.Once
+
+// derivedKeys 缓存口令派生的密钥，避免每次读取cookies都执行scrypt
+var derivedKeys struct {
+	sync.Mutex
+	keys map[[32]byte][]byte
+}
+
+// cookieKey 获取cookies加密凭据：优先使用环境变量口令，其次使用本机生成的密钥，未启用加密时返回nil
+func (am *AccountManager) cookieKey() (*cookieSecret, error) {
+	if passphrase := os.Getenv(CookieKeyEnv); passphrase != "" {
+		return &cookieSecret{passphrase: passphrase}, nil
+	}
+	if !am.encryptCookies {
+		return nil, nil
+	}
+
+	keyFile := filepath.Join(am.cookieDir, cookieKeyFile)
+	data, err := os.ReadFile(keyFile)
+	if err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) != 32 {
+			return nil, errors.Errorf("cookies加密密钥文件格式错误: %s", keyFile)
+		}
+		return &cookieSecret{key: key}, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "读取cookies加密密钥失败")
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.Wrap(err, "生成cookies加密密钥失败")
+	}
+	if err := os.MkdirAll(am.cookieDir, 0755); err != nil {
+		return nil, errors.Wrap(err, "创建cookies目录失败")
+	}
+	if err := os.WriteFile(keyFile, []byte(hex.EncodeToString(key)), 0600); err != nil {
+		return nil, errors.Wrap(err, "保存cookies加密密钥失败")
+	}
+	logger.Infof("🔑 已生成cookies加密密钥: %s（也可改用环境变量 %s 指定口令）", keyFile, CookieKeyEnv)
+	return &cookieSecret{key: key}, nil
+}
+
+// deriveKey 使用scrypt从口令和盐派生AES-256密钥，结果按口令和盐缓存
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	id := sha256.Sum256(append([]byte(passphrase+"\x00"), salt...))
+
+	derivedKeys.Lock()
+	defer derivedKeys.Unlock()
+	if key, ok := derivedKeys.keys[id]; ok {
+		return key, nil
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, errors.Wrap(err, "派生加密密钥失败")
+	}
+	if derivedKeys.keys == nil {
+		derivedKeys.keys = make(map[[32]byte][]byte)
+	}
+	derivedKeys.keys[id] = key
+	return key, nil
+}
+
+// legacyPassphraseKey 旧版本使用的口令密钥（单次无盐SHA-256），只用于读取旧文件
+func legacyPassphraseKey(passphrase string) []byte {
+	sum := sha256.Sum256([]byte(passphrase))
+	return sum[:]
+}
+
+// sealCookies 使用AES-GCM加密cookies数据，口令加密时为每个文件生成新的随机盐
+func sealCookies(secret *cookieSecret, plaintext []byte) ([]byte, error) {
+	file := encryptedCookieFile{Encrypted: cookieCipherVersion}
+
+	key := secret.key
+	if secret.passphrase != "" {
+		salt := make([]byte, scryptSaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, errors.Wrap(err, "生成加密盐失败")
+		}
+		var err error
+		if key, err = deriveKey(secret.passphrase, salt); err != nil {
+			return nil, err
+		}
+		file.KDF = kdfScrypt
+		file.Salt = base64.StdEncoding.EncodeToString(salt)
+	}
+
+	gcm, err := newCookieGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "生成加密随机数失败")
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	file.Data = base64.StdEncoding.EncodeToString(sealed)
+
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// fileKey 根据文件头选择解密密钥
+func (s *cookieSecret) fileKey(file *encryptedCookieFile) ([]byte, error) {
+	switch file.KDF {
+	case kdfScrypt:
+		if s.passphrase == "" {
+			return nil, errors.Errorf("cookies使用口令加密，请设置环境变量 %s", CookieKeyEnv)
+		}
+		salt, err := base64.StdEncoding.DecodeString(file.Salt)
+		if err != nil || len(salt) == 0 {
+			return nil, errors.New("加密cookies的盐格式错误")
+		}
+		return deriveKey(s.passphrase, salt)
+	case "":
+		if s.passphrase != "" {
+			return legacyPassphraseKey(s.passphrase), nil
+		}
+		return s.key, nil
+	default:
+		return nil, errors.Errorf("不支持的密钥派生算法: %s", file.KDF)
+	}
+}
+
+// openCookies 解密cookies数据，旧版明文文件（JSON数组）原样返回且encrypted为false
+func openCookies(secret *cookieSecret, data []byte) (plaintext []byte, encrypted bool, err error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return data, false, nil
+	}
+
+	var file encryptedCookieFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, true, errors.Wrap(err, "解析加密cookies失败")
+	}
+	if file.Encrypted != cookieCipherVersion {
+		return nil, true, errors.Errorf("不支持的cookies加密格式: %s", file.Encrypted)
+	}
+	if secret == nil {
+		return nil, true, errors.Errorf("cookies已加密但未配置密钥，请设置环境变量 %s 或开启 accounts.encrypt_cookies", CookieKeyEnv)
+	}
+	key, err := secret.fileKey(&file)
+	if err != nil {
+		return nil, true, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(file.Data)
+	if err != nil {
+		return nil, true, errors.Wrap(err, "解析加密cookies失败")
+	}
+	gcm, err := newCookieGCM(key)
+	if err != nil {
+		return nil, true, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, true, errors.New("加密cookies数据不完整")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err = gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, true, errors.New("解密cookies失败，加密密钥可能已变更")
+	}
+	return plaintext, true, nil
+}
+
+// legacyPassphraseFile 判断文件是否为旧版无盐口令密钥加密，读取后需要用scrypt重新加密
+func legacyPassphraseFile(secret *cookieSecret, data []byte) bool {
+	if secret == nil || secret.passphrase == "" {
+		return false
+	}
+	var file encryptedCookieFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return false
+	}
+	return file.Encrypted == cookieCipherVersion && file.KDF == ""
+}
+
+// newCookieGCM 创建AES-GCM加密器
+func newCookieGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "创建加密器失败")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "创建加密器失败")
+	}
+	return gcm, nil
+}
+
+// writeCookieData 写入账号的cookies文件，配置了密钥时加密存储
+func (am *AccountManager) writeCookieData(accountName string, plaintext []byte) error {
+	secret, err := am.cookieKey()
+	if err != nil {
+		return err
+	}
+
+	data := plaintext
+	if secret != nil {
+		if data, err = sealCookies(secret, plaintext); err != nil {
+			return err
+		}
+	} else {
+		plaintextWarning.Do(func() {
+			logger.Warnf("⚠️ cookies以明文保存，建议设置环境变量 %s 或开启 accounts.encrypt_cookies", CookieKeyEnv)
+		})
+	}
+
+	return os.WriteFile(am.GetCookieFile(accountName), data, 0600)
+}
+
+// readCookieData 读取并解密账号的cookies文件，配置了密钥时将旧版明文文件迁移为加密存储
+func (am *AccountManager) readCookieData(accountName string) ([]byte, error) {
+	data, err := os.ReadFile(am.GetCookieFile(accountName))
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := am.cookieKey()
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, encrypted, err := openCookies(secret, data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !encrypted && secret != nil:
+		if err := am.writeCookieData(accountName, plaintext); err != nil {
+			logger.Warnf("迁移账号 '%s' 的明文cookies失败: %v", accountName, err)
+		} else {
+			logger.Infof("🔐 账号 '%s' 的cookies已迁移为加密存储", accountName)
+		}
+	case legacyPassphraseFile(secret, data):
+		if err := am.writeCookieData(accountName, plaintext); err != nil {
+			logger.Warnf("升级账号 '%s' 的cookies加密密钥失败: %v", accountName, err)
+		} else {
+			logger.Infof("🔐 账号 '%s' 的cookies已改用scrypt派生密钥加密", accountName)
+		}
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testCookies = `[{"name":"SESSDATA","value":"abc","domain":".bilibili.com","path":"/"}]`
+
+func newTestAccountManager(t *testing.T, encrypt bool) *AccountManager {
+	t.Helper()
+	t.Setenv(CookieKeyEnv, "")
+	dir := t.TempDir()
+	return &AccountManager{configFile: filepath.Join(dir, "accounts.json"), cookieDir: dir, encryptCookies: encrypt}
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	secrets := map[string]*cookieSecret{
+		"key":        {key: bytes.Repeat([]byte{7}, 32)},
+		"passphrase": {passphrase: "correct horse"},
+	}
+	for name, secret := range secrets {
+		t.Run(name, func(t *testing.T) {
+			sealed, err := sealCookies(secret, []byte(testCookies))
+			if err != nil {
+				t.Fatalf("sealCookies: %v", err)
+			}
+			if bytes.Contains(sealed, []byte("SESSDATA")) {
+				t.Fatal("sealed data contains plaintext")
+			}
+
+			plaintext, encrypted, err := openCookies(secret, sealed)
+			if err != nil {
+				t.Fatalf("openCookies: %v", err)
+			}
+			if !encrypted || string(plaintext) != testCookies {
+				t.Fatalf("got encrypted=%v plaintext=%q", encrypted, plaintext)
+			}
+		})
+	}
+}
+
+func TestPassphraseUsesRandomSalt(t *testing.T) {
+	secret := &cookieSecret{passphrase: "correct horse"}
+	first, err := sealCookies(secret, []byte(testCookies))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := sealCookies(secret, []byte(testCookies))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var a, b encryptedCookieFile
+	if err := json.Unmarshal(first, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(second, &b); err != nil {
+		t.Fatal(err)
+	}
+	if a.KDF != kdfScrypt || a.Salt == "" {
+		t.Fatalf("missing kdf header: %+v", a)
+	}
+	if a.Salt == b.Salt {
+		t.Fatal("salt reused between files")
+	}
+}
+
+func TestOpenWithWrongPassphrase(t *testing.T) {
+	sealed, err := sealCookies(&cookieSecret{passphrase: "right"}, []byte(testCookies))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := openCookies(&cookieSecret{passphrase: "wrong"}, sealed); err == nil {
+		t.Fatal("expected error for wrong passphrase")
+	}
+	if _, _, err := openCookies(nil, sealed); err == nil {
+		t.Fatal("expected error without secret")
+	}
+}
+
+func TestReadCookieDataMigratesPlaintext(t *testing.T) {
+	am := newTestAccountManager(t, true)
+	if err := os.WriteFile(am.GetCookieFile("alice"), []byte(testCookies), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := am.readCookieData("alice")
+	if err != nil {
+		t.Fatalf("readCookieData: %v", err)
+	}
+	if string(plaintext) != testCookies {
+		t.Fatalf("got %q", plaintext)
+	}
+
+	stored, err := os.ReadFile(am.GetCookieFile("alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stored, []byte("SESSDATA")) {
+		t.Fatal("cookies file was not migrated to encrypted storage")
+	}
+
+	again, err := am.readCookieData("alice")
+	if err != nil || string(again) != testCookies {
+		t.Fatalf("read after migration: %q, %v", again, err)
+	}
+}
+
+func TestReadCookieDataPlaintextWithoutEncryption(t *testing.T) {
+	am := newTestAccountManager(t, false)
+	if err := am.writeCookieData("bob", []byte(testCookies)); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := os.ReadFile(am.GetCookieFile("bob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(stored) != testCookies {
+		t.Fatalf("expected plaintext file, got %q", stored)
+	}
+}
+
+func TestReadCookieDataUpgradesLegacyPassphraseKey(t *testing.T) {
+	am := newTestAccountManager(t, false)
+	t.Setenv(CookieKeyEnv, "legacy pass")
+
+	legacy, err := sealCookies(&cookieSecret{key: legacyPassphraseKey("legacy pass")}, []byte(testCookies))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(am.GetCookieFile("carol"), legacy, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := am.readCookieData("carol")
+	if err != nil || string(plaintext) != testCookies {
+		t.Fatalf("readCookieData: %q, %v", plaintext, err)
+	}
+
+	stored, err := os.ReadFile(am.GetCookieFile("carol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file encryptedCookieFile
+	if err := json.Unmarshal(stored, &file); err != nil {
+		t.Fatal(err)
+	}
+	if file.KDF != kdfScrypt {
+		t.Fatalf("legacy file was not upgraded: %+v", file)
+	}
+}
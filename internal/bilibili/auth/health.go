@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// defaultCheckConcurrency 账号健康检查的默认并发数
+const defaultCheckConcurrency = 4
+
+// sessionCheckTTL 会话校验结果的缓存时间，避免频繁请求导航接口
+const sessionCheckTTL = 60 * time.Second
+
+// ErrSessionExpired 账号的登录会话已失效
+var ErrSessionExpired = errors.New("登录会话已过期，请重新登录")
+
+// sessionCheck 缓存的会话校验结果
+type sessionCheck struct {
+	valid         bool
+	checkedAt     time.Time
+	cookieModTime time.Time // 校验时cookies文件的修改时间，文件被重写（如其他进程导入账号）后缓存失效
+}
+
+// AccountHealth 单个账号的健康检查结果
+type AccountHealth struct {
+	Name     string `json:"name"`            // 账号标识名
+	Nickname string `json:"nickname"`        // 昵称
+	UID      string `json:"uid"`             // B站UID
+	Valid    bool   `json:"valid"`           // 登录是否有效
+	Error    string `json:"error,omitempty"` // 检查失败原因
+
+	Level     int `json:"level,omitempty"`      // 账号等级
+	VipStatus int `json:"vip_status,omitempty"` // 大会员状态：1=有效
+	VipType   int `json:"vip_type,omitempty"`   // 大会员类型
+}
+
+// AccountHealthSummary 所有账号的健康检查汇总
+type AccountHealthSummary struct {
+	Results []AccountHealth `json:"results"` // 按账号列表顺序排列的结果
+	Valid   int             `json:"valid"`   // 有效账号数
+	Expired int             `json:"expired"` // 失效账号数
+}
+
+// CheckAllAccounts 并发验证所有账号的登录状态
+func (s *LoginService) CheckAllAccounts(ctx context.Context) (*AccountHealthSummary, error) {
+	accounts, err := s.accountManager.LoadAccounts()
+	if err != nil {
+		return nil, errors.Wrap(err, "加载账号列表失败")
+	}
+
+	concurrency := defaultCheckConcurrency
+	if s.config != nil && s.config.Accounts.CheckConcurrency > 0 {
+		concurrency = s.config.Accounts.CheckConcurrency
+	}
+
+	results := make([]AccountHealth, len(accounts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, account := range accounts {
+		wg.Add(1)
+		go func(i int, account Account) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = AccountHealth{Name: account.Name, Nickname: account.Nickname, UID: account.UID, Error: ctx.Err().Error()}
+				return
+			}
+
+			results[i] = s.checkAccount(account)
+		}(i, account)
+	}
+	wg.Wait()
+
+	summary := &AccountHealthSummary{Results: results}
+	for _, result := range results {
+		if result.Valid {
+			// 顺带补全旧版账号文件中缺少的等级和大会员信息
+			if err := s.accountManager.UpdateMembership(result.Name, result.Level, result.VipStatus, result.VipType); err != nil {
+				logger.Warnf("更新账号 '%s' 的会员信息失败: %v", result.Name, err)
+			}
+			summary.Valid++
+		} else {
+			summary.Expired++
+		}
+	}
+
+	return summary, nil
+}
+
+// checkAccount 使用账号cookies请求导航接口，验证登录是否有效
+func (s *LoginService) checkAccount(account Account) AccountHealth {
+	health := AccountHealth{
+		Name:     account.Name,
+		Nickname: account.Nickname,
+		UID:      account.UID,
+	}
+
+	cookieMap, err := s.LoadCookieMap(account.Name)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	navResp, err := api.NewClient(cookieMap).GetNavInfo()
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	if navResp.Code != 0 || !navResp.Data.IsLogin {
+		health.Error = fmt.Sprintf("登录已失效: %s (code: %d)", navResp.Message, navResp.Code)
+		return health
+	}
+
+	health.Valid = true
+	health.Nickname = navResp.Data.Uname
+	health.UID = fmt.Sprintf("%d", navResp.Data.Mid)
+	health.Level = navResp.Data.LevelInfo.CurrentLevel
+	health.VipStatus = navResp.Data.VipStatus
+	health.VipType = navResp.Data.VipType
+	return health
+}
+
+// validateSession 校验账号会话是否有效，结果按账号缓存 sessionCheckTTL，cookies被重写后缓存失效。
+// 网络错误不会被缓存，也不会被视为会话失效。
+func (s *LoginService) validateSession(accountName string) (bool, error) {
+	if cached, ok := s.cachedSession(accountName); ok {
+		return cached.valid, nil
+	}
+
+	cookieMap, err := s.LoadCookieMap(accountName)
+	if err != nil {
+		return false, err
+	}
+	// 读取时可能迁移了加密格式，在读取之后记录修改时间
+	modTime := s.cookieModTime(accountName)
+
+	navResp, err := api.NewClient(cookieMap).GetNavInfo()
+	if err != nil {
+		return false, errors.Wrap(err, "校验登录会话失败")
+	}
+	valid := navResp.Code == 0 && navResp.Data.IsLogin
+
+	s.sessionMu.Lock()
+	s.sessionChecks[accountName] = sessionCheck{valid: valid, checkedAt: time.Now(), cookieModTime: modTime}
+	s.sessionMu.Unlock()
+
+	return valid, nil
+}
+
+// cachedSession 返回仍然有效的会话校验缓存：未超过 sessionCheckTTL 且cookies文件未被重写
+func (s *LoginService) cachedSession(accountName string) (sessionCheck, bool) {
+	s.sessionMu.Lock()
+	cached, ok := s.sessionChecks[accountName]
+	s.sessionMu.Unlock()
+	if !ok || time.Since(cached.checkedAt) >= sessionCheckTTL || !cached.cookieModTime.Equal(s.cookieModTime(accountName)) {
+		return sessionCheck{}, false
+	}
+	return cached, true
+}
+
+// invalidateSession 清除账号的会话校验缓存，写入cookies后调用
+func (s *LoginService) invalidateSession(accountName string) {
+	s.sessionMu.Lock()
+	delete(s.sessionChecks, accountName)
+	s.sessionMu.Unlock()
+}
+
+// cookieModTime 返回账号cookies文件的修改时间，文件不存在时返回零值
+func (s *LoginService) cookieModTime(accountName string) time.Time {
+	info, err := os.Stat(s.accountManager.GetCookieFile(accountName))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
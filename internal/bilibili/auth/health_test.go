@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+func newTestLoginService(t *testing.T) *LoginService {
+	t.Helper()
+	return &LoginService{
+		accountManager: newTestAccountManager(t, false),
+		sessionChecks:  make(map[string]sessionCheck),
+	}
+}
+
+// cacheSession 模拟一次成功的会话校验
+func cacheSession(s *LoginService, name string) {
+	s.sessionChecks[name] = sessionCheck{valid: true, checkedAt: time.Now(), cookieModTime: s.cookieModTime(name)}
+}
+
+func TestSessionCacheHit(t *testing.T) {
+	s := newTestLoginService(t)
+	if err := s.accountManager.writeCookieData("alice", []byte(testCookies)); err != nil {
+		t.Fatal(err)
+	}
+	cacheSession(s, "alice")
+
+	if cached, ok := s.cachedSession("alice"); !ok || !cached.valid {
+		t.Fatal("fresh session check was not cached")
+	}
+
+	s.sessionChecks["alice"] = sessionCheck{valid: true, checkedAt: time.Now().Add(-sessionCheckTTL), cookieModTime: s.cookieModTime("alice")}
+	if _, ok := s.cachedSession("alice"); ok {
+		t.Fatal("expired session check was used")
+	}
+}
+
+func TestSaveCookiesInvalidatesSession(t *testing.T) {
+	s := newTestLoginService(t)
+	cacheSession(s, "alice")
+
+	if err := s.saveCookies("alice", []playwright.Cookie{{Name: "SESSDATA", Value: "new"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.cachedSession("alice"); ok {
+		t.Fatal("session cache kept after saveCookies")
+	}
+}
+
+func TestCookieRewriteInvalidatesSession(t *testing.T) {
+	s := newTestLoginService(t)
+	if err := s.accountManager.writeCookieData("alice", []byte(testCookies)); err != nil {
+		t.Fatal(err)
+	}
+	cacheSession(s, "alice")
+
+	// 模拟其他进程（如 login -import）重写cookies文件
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(s.accountManager.GetCookieFile("alice"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.cachedSession("alice"); ok {
+		t.Fatal("session cache kept after cookies file changed")
+	}
+}
+
+func TestImportAccountInvalidatesSession(t *testing.T) {
+	s := newTestLoginService(t)
+	if err := s.accountManager.writeCookieData("alice", []byte(`[]`)); err != nil {
+		t.Fatal(err)
+	}
+	// 将旧文件的修改时间调早，避免与导入写入落在同一时间精度内
+	earlier := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(s.accountManager.GetCookieFile("alice"), earlier, earlier); err != nil {
+		t.Fatal(err)
+	}
+	cacheSession(s, "alice")
+
+	if _, err := s.accountManager.ImportAccount(testBundle(t, "alice"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.cachedSession("alice"); ok {
+		t.Fatal("session cache kept after ImportAccount")
+	}
+}
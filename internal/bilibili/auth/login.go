@@ -8,10 +8,12 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/playwright-community/playwright-go"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
 	"github.com/shirenchuang/bilibili-mcp/pkg/config"
 	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
 )
@@ -20,6 +22,9 @@ import (
 type LoginService struct {
 	accountManager *AccountManager
 	config         *config.Config
+
+	sessionMu     sync.Mutex
+	sessionChecks map[string]sessionCheck // 账号会话校验结果缓存
 }
 
 // NewLoginService 创建登录服务
@@ -27,6 +32,7 @@ func NewLoginService() *LoginService {
 	return &LoginService{
 		accountManager: NewAccountManager(),
 		config:         config.Get(),
+		sessionChecks:  make(map[string]sessionCheck),
 	}
 }
 
@@ -110,6 +116,15 @@ func (s *LoginService) Login(ctx context.Context, accountName string) error {
 		return errors.New("未获取到有效的cookies")
 	}
 
+	// 网页端把刷新cookie使用的refresh_token保存在localStorage中
+	if token, err := page.Evaluate(`() => localStorage.getItem("ac_time_value")`); err == nil {
+		if refreshToken, ok := token.(string); ok && refreshToken != "" {
+			cookies = append(cookies, refreshTokenCookie(refreshToken, float64(time.Now().Add(qrCookieLifetime).Unix())))
+		}
+	} else {
+		logger.Warnf("读取refresh_token失败，将无法自动刷新cookies: %v", err)
+	}
+
 	// 保存cookies
 	if err := s.saveCookies(accountName, cookies); err != nil {
 		return errors.Wrap(err, "保存cookies失败")
@@ -125,6 +140,9 @@ func (s *LoginService) Login(ctx context.Context, accountName string) error {
 		IsActive:  true,
 		LoginTime: time.Now(),
 		LastUsed:  time.Now(),
+		Level:     userInfo.Level,
+		VipStatus: userInfo.VipStatus,
+		VipType:   userInfo.VipType,
 	}
 
 	if err := s.accountManager.SaveAccount(account); err != nil {
@@ -137,9 +155,7 @@ func (s *LoginService) Login(ctx context.Context, accountName string) error {
 
 // LoadCookies 加载指定账号的cookies
 func (s *LoginService) LoadCookies(accountName string) ([]playwright.Cookie, error) {
-	cookieFile := s.accountManager.GetCookieFile(accountName)
-
-	data, err := os.ReadFile(cookieFile)
+	data, err := s.accountManager.readCookieData(accountName)
 	if err != nil {
 		return nil, errors.Wrapf(err, "读取账号 '%s' 的cookies失败", accountName)
 	}
@@ -152,8 +168,32 @@ func (s *LoginService) LoadCookies(accountName string) ([]playwright.Cookie, err
 	return cookies, nil
 }
 
-// CheckLoginStatus 检查指定账号的登录状态
-func (s *LoginService) CheckLoginStatus(ctx context.Context, accountName string) (bool, *Account, error) {
+// LoadCookieMap 直接读取账号保存的cookies并返回 名称→值 映射，用于构建API客户端而无需启动浏览器。
+// accountName 为空时使用默认账号
+func (s *LoginService) LoadCookieMap(accountName string) (map[string]string, error) {
+	if accountName == "" {
+		account, err := s.accountManager.GetDefaultAccount()
+		if err != nil {
+			return nil, errors.Wrap(err, "获取默认账号失败")
+		}
+		accountName = account.Name
+	}
+
+	cookies, err := s.LoadCookies(accountName)
+	if err != nil {
+		return nil, err
+	}
+
+	cookieMap := make(map[string]string, len(cookies))
+	for _, cookie := range cookies {
+		cookieMap[cookie.Name] = cookie.Value
+	}
+	return cookieMap, nil
+}
+
+// CheckLoginStatus 检查指定账号的登录状态。
+// validate 为 true 时会请求导航接口校验会话，会话失效的账号会被停用并返回 ErrSessionExpired。
+func (s *LoginService) CheckLoginStatus(ctx context.Context, accountName string, validate bool) (bool, *Account, error) {
 	// 如果没有指定账号，使用默认账号
 	var account *Account
 	var err error
@@ -177,40 +217,114 @@ func (s *LoginService) CheckLoginStatus(ctx context.Context, accountName string)
 		return false, account, nil
 	}
 
+	if validate {
+		valid, err := s.validateSession(accountName)
+		if err != nil {
+			return false, account, err
+		}
+		if !valid {
+			if err := s.accountManager.DeactivateAccount(accountName); err != nil {
+				logger.Warnf("停用账号 '%s' 失败: %v", accountName, err)
+			} else {
+				account.IsActive = false
+			}
+			return false, account, ErrSessionExpired
+		}
+	}
+
 	// 更新最后使用时间
 	s.accountManager.UpdateLastUsed(accountName)
 
 	return true, account, nil
 }
 
+// Logout 退出指定账号：先使服务端会话失效，再删除本地账号和cookies
+func (s *LoginService) Logout(accountName string) error {
+	if _, err := s.accountManager.GetAccount(accountName); err != nil {
+		return err
+	}
+
+	cookieMap, err := s.LoadCookieMap(accountName)
+	if err == nil {
+		resp, err := api.NewClient(cookieMap).Logout()
+		if err != nil {
+			return errors.Wrap(err, "注销服务端会话失败")
+		}
+		// -101 表示会话已失效，视为注销成功
+		if resp.Code != 0 && resp.Code != -101 {
+			return errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "注销服务端会话失败")
+		}
+		logger.Infof("🔒 账号 '%s' 的服务端会话已注销", accountName)
+	} else {
+		logger.Warnf("账号 '%s' 没有本地cookies，跳过服务端注销", accountName)
+	}
+
+	if err := s.accountManager.DeleteAccount(accountName); err != nil {
+		return errors.Wrap(err, "删除本地账号失败")
+	}
+
+	logger.Infof("账号 '%s' 已退出登录", accountName)
+	return nil
+}
+
+// DeleteAccount 删除本地账号和cookies，不注销服务端会话
+func (s *LoginService) DeleteAccount(accountName string) error {
+	if err := s.accountManager.DeleteAccount(accountName); err != nil {
+		return err
+	}
+
+	s.invalidateSession(accountName)
+
+	logger.Infof("🗑️ 账号 '%s' 已从本地删除", accountName)
+	return nil
+}
+
 // ListAccounts 列出所有账号
 func (s *LoginService) ListAccounts() ([]Account, error) {
 	return s.accountManager.LoadAccounts()
 }
 
+// HasDefaultSession 只读检查是否存在激活的默认账号且其cookies文件存在，
+// 不解密、不迁移cookies，也不会自动选择默认账号，供健康检查使用
+func (s *LoginService) HasDefaultSession() bool {
+	accounts, err := s.accountManager.LoadAccounts()
+	if err != nil {
+		return false
+	}
+	for _, acc := range accounts {
+		if acc.IsDefault && acc.IsActive {
+			_, err := os.Stat(s.accountManager.GetCookieFile(acc.Name))
+			return err == nil
+		}
+	}
+	return false
+}
+
 // SwitchAccount 切换默认账号
 func (s *LoginService) SwitchAccount(accountName string) error {
 	return s.accountManager.SetDefaultAccount(accountName)
 }
 
-// saveCookies 保存cookies到文件
+// saveCookies 保存cookies到文件，配置了密钥时加密存储，并清除该账号的会话校验缓存
 func (s *LoginService) saveCookies(accountName string, cookies []playwright.Cookie) error {
-	cookieFile := s.accountManager.GetCookieFile(accountName)
-
 	data, err := json.MarshalIndent(cookies, "", "  ")
 	if err != nil {
 		return errors.Wrap(err, "序列化cookies失败")
 	}
 
-	return os.WriteFile(cookieFile, data, 0644)
+	defer s.invalidateSession(accountName)
+	return s.accountManager.writeCookieData(accountName, data)
 }
 
 // UserInfo 用户信息
 type UserInfo struct {
-	Username string
-	Nickname string
-	UID      string
-	Avatar   string
+	Username  string
+	Nickname  string
+	UID       string
+	Avatar    string
+	Level     int
+	VipStatus int
+	VipType   int
 }
 
 // isLoggedIn 检查是否已经登录
@@ -286,9 +400,7 @@ func (s *LoginService) getUserInfo(page playwright.Page) (*UserInfo, error) {
 	}
 
 	// 创建HTTP客户端并设置cookies
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+	client := api.NewHTTPClient(10 * time.Second)
 
 	// 构建cookie字符串
 	var cookieStr strings.Builder
@@ -334,6 +446,8 @@ func (s *LoginService) getUserInfo(page playwright.Page) (*UserInfo, error) {
 			LevelInfo struct {
 				CurrentLevel int `json:"current_level"`
 			} `json:"level_info"`
+			VipStatus int `json:"vipStatus"`
+			VipType   int `json:"vipType"`
 		} `json:"data"`
 	}
 
@@ -342,7 +456,7 @@ func (s *LoginService) getUserInfo(page playwright.Page) (*UserInfo, error) {
 	}
 
 	if navResp.Code != 0 {
-		return nil, errors.Errorf("API返回错误: %s (code: %d)", navResp.Message, navResp.Code)
+		return nil, errors.Wrap(api.NewAPIError(navResp.Code, navResp.Message), "API返回错误")
 	}
 
 	if !navResp.Data.IsLogin {
@@ -351,10 +465,13 @@ func (s *LoginService) getUserInfo(page playwright.Page) (*UserInfo, error) {
 
 	// 构建用户信息
 	userInfo := &UserInfo{
-		Username: navResp.Data.Uname,
-		Nickname: navResp.Data.Uname,
-		UID:      fmt.Sprintf("%d", navResp.Data.Mid),
-		Avatar:   navResp.Data.Face,
+		Username:  navResp.Data.Uname,
+		Nickname:  navResp.Data.Uname,
+		UID:       fmt.Sprintf("%d", navResp.Data.Mid),
+		Avatar:    navResp.Data.Face,
+		Level:     navResp.Data.LevelInfo.CurrentLevel,
+		VipStatus: navResp.Data.VipStatus,
+		VipType:   navResp.Data.VipType,
 	}
 
 	return userInfo, nil
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/playwright-community/playwright-go"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+const (
+	// qrPollInterval 扫码状态轮询间隔
+	qrPollInterval = 2 * time.Second
+	// qrLoginTimeout 扫码登录超时时间
+	qrLoginTimeout = 5 * time.Minute
+	// qrCookieLifetime 扫码登录cookie的有效期
+	qrCookieLifetime = 180 * 24 * time.Hour
+)
+
+// LoginWithQR 通过API扫码登录指定账号，不需要启动浏览器。
+// render 用于展示二维码内容，二维码失效时会自动刷新并再次调用。
+func (s *LoginService) LoginWithQR(ctx context.Context, accountName string, render func(qrURL string)) error {
+	logger.Infof("开始为账号 '%s' 进行扫码登录", accountName)
+
+	client := api.NewClient(map[string]string{})
+	qrcodeKey, qrURL, err := client.GenerateLoginQR()
+	if err != nil {
+		return err
+	}
+	render(qrURL)
+
+	timeout := time.After(qrLoginTimeout)
+	ticker := time.NewTicker(qrPollInterval)
+	defer ticker.Stop()
+
+	lastStatus := -1
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+			return errors.New("登录超时，请重试")
+		case <-ticker.C:
+		}
+
+		status, cookies, err := client.PollLoginQR(qrcodeKey)
+		if err != nil {
+			logger.Warnf("查询扫码状态失败: %v", err)
+			continue
+		}
+
+		switch status {
+		case api.QRLoginSuccess:
+			logger.Info("检测到扫码登录成功")
+			return s.completeQRLogin(accountName, cookies)
+		case api.QRLoginScanned:
+			if lastStatus != status {
+				fmt.Println("📱 已扫码，请在手机上确认登录")
+			}
+		case api.QRLoginExpired:
+			fmt.Println("⌛ 二维码已失效，正在刷新...")
+			qrcodeKey, qrURL, err = client.GenerateLoginQR()
+			if err != nil {
+				return err
+			}
+			render(qrURL)
+		case api.QRLoginNotScanned:
+			logger.Debug("继续等待用户扫码...")
+		default:
+			logger.Warnf("未知的扫码状态: %d", status)
+		}
+		lastStatus = status
+	}
+}
+
+// completeQRLogin 保存扫码登录得到的cookies和账号信息
+func (s *LoginService) completeQRLogin(accountName string, cookieMap map[string]string) error {
+	expires := float64(time.Now().Add(qrCookieLifetime).Unix())
+	cookies := make([]playwright.Cookie, 0, len(cookieMap))
+	for name, value := range cookieMap {
+		cookies = append(cookies, playwright.Cookie{
+			Name:     name,
+			Value:    value,
+			Domain:   ".bilibili.com",
+			Path:     "/",
+			Expires:  expires,
+			HttpOnly: name == "SESSDATA",
+			Secure:   true,
+		})
+	}
+
+	if err := s.saveCookies(accountName, cookies); err != nil {
+		return errors.Wrap(err, "保存cookies失败")
+	}
+
+	userInfo := &UserInfo{
+		Username: "未知用户",
+		Nickname: "未知昵称",
+		UID:      cookieMap["DedeUserID"],
+	}
+	nav, err := api.NewClient(cookieMap).GetNavInfo()
+	switch {
+	case err != nil:
+		logger.Warnf("获取用户信息失败: %v", err)
+	case nav.Code != 0 || !nav.Data.IsLogin:
+		logger.Warnf("获取用户信息失败: %s (code: %d)", nav.Message, nav.Code)
+	default:
+		userInfo = &UserInfo{
+			Username:  nav.Data.Uname,
+			Nickname:  nav.Data.Uname,
+			UID:       fmt.Sprintf("%d", nav.Data.Mid),
+			Avatar:    nav.Data.Face,
+			Level:     nav.Data.LevelInfo.CurrentLevel,
+			VipStatus: nav.Data.VipStatus,
+			VipType:   nav.Data.VipType,
+		}
+	}
+
+	account := &Account{
+		Name:      accountName,
+		Username:  userInfo.Username,
+		Nickname:  userInfo.Nickname,
+		UID:       userInfo.UID,
+		Avatar:    userInfo.Avatar,
+		IsActive:  true,
+		LoginTime: time.Now(),
+		LastUsed:  time.Now(),
+		Level:     userInfo.Level,
+		VipStatus: userInfo.VipStatus,
+		VipType:   userInfo.VipType,
+	}
+
+	if err := s.accountManager.SaveAccount(account); err != nil {
+		return errors.Wrap(err, "保存账号信息失败")
+	}
+
+	logger.Infof("账号 '%s' 登录成功！用户: %s (UID: %s)", accountName, userInfo.Nickname, userInfo.UID)
+	return nil
+}
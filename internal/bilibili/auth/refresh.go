@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/playwright-community/playwright-go"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// RefreshCookies 按官方流程刷新账号的cookies：检查是否需要刷新，获取refresh_csrf，
+// 用refresh_token换取新cookies并保存，最后用旧refresh_token确认刷新。
+// 不需要刷新时返回false；accountName 为空时使用默认账号
+func (s *LoginService) RefreshCookies(accountName string) (bool, error) {
+	if accountName == "" {
+		account, err := s.accountManager.GetDefaultAccount()
+		if err != nil {
+			return false, errors.Wrap(err, "获取默认账号失败")
+		}
+		accountName = account.Name
+	} else if _, err := s.accountManager.GetAccount(accountName); err != nil {
+		return false, err
+	}
+
+	cookies, err := s.LoadCookies(accountName)
+	if err != nil {
+		return false, err
+	}
+	cookieMap := make(map[string]string, len(cookies))
+	for _, cookie := range cookies {
+		cookieMap[cookie.Name] = cookie.Value
+	}
+
+	client := api.NewClient(cookieMap)
+	info, err := client.GetCookieInfo()
+	if err != nil {
+		return false, errors.Wrap(err, "检查cookie状态失败")
+	}
+	if info.Code == -101 {
+		return false, ErrSessionExpired
+	}
+	if info.Code != 0 {
+		return false, errors.Wrap(api.NewAPIError(info.Code, info.Message), "检查cookie状态失败")
+	}
+	if !info.Data.Refresh {
+		logger.Infof("账号 '%s' 的cookies无需刷新", accountName)
+		return false, nil
+	}
+
+	oldRefreshToken := cookieMap[api.RefreshTokenCookie]
+	if oldRefreshToken == "" {
+		return false, errors.New("账号缺少refresh_token，无法刷新cookies，请重新登录")
+	}
+
+	refreshCSRF, err := client.GetRefreshCSRF(info.Data.Timestamp)
+	if err != nil {
+		return false, errors.Wrap(err, "获取refresh_csrf失败")
+	}
+
+	result, err := client.RefreshCookie(refreshCSRF, oldRefreshToken)
+	if err != nil {
+		return false, err
+	}
+
+	refreshed := mergeRefreshedCookies(cookies, result)
+	if err := s.saveCookies(accountName, refreshed); err != nil {
+		return false, errors.Wrap(err, "保存cookies失败")
+	}
+	logger.Infof("🔄 账号 '%s' 的cookies已刷新", accountName)
+
+	// 新cookies已保存，确认失败只影响旧refresh_token的失效，不影响使用
+	newCookieMap := make(map[string]string, len(refreshed))
+	for _, cookie := range refreshed {
+		newCookieMap[cookie.Name] = cookie.Value
+	}
+	if err := api.NewClient(newCookieMap).ConfirmCookieRefresh(oldRefreshToken); err != nil {
+		logger.Warnf("确认刷新账号 '%s' 的cookies失败: %v", accountName, err)
+	}
+
+	return true, nil
+}
+
+// mergeRefreshedCookies 用刷新得到的cookies覆盖同名旧cookies，并更新refresh_token
+func mergeRefreshedCookies(cookies []playwright.Cookie, result *api.CookieRefreshResult) []playwright.Cookie {
+	merged := make([]playwright.Cookie, 0, len(cookies)+len(result.Cookies))
+	index := make(map[string]int, len(cookies))
+	for _, cookie := range cookies {
+		index[cookie.Name] = len(merged)
+		merged = append(merged, cookie)
+	}
+
+	set := func(cookie playwright.Cookie) {
+		if i, ok := index[cookie.Name]; ok {
+			merged[i] = cookie
+			return
+		}
+		index[cookie.Name] = len(merged)
+		merged = append(merged, cookie)
+	}
+
+	expires := float64(time.Now().Add(qrCookieLifetime).Unix())
+	for _, cookie := range result.Cookies {
+		domain := cookie.Domain
+		if domain == "" {
+			domain = ".bilibili.com"
+		}
+		path := cookie.Path
+		if path == "" {
+			path = "/"
+		}
+		cookieExpires := expires
+		if !cookie.Expires.IsZero() {
+			cookieExpires = float64(cookie.Expires.Unix())
+		}
+		set(playwright.Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   domain,
+			Path:     path,
+			Expires:  cookieExpires,
+			HttpOnly: cookie.HttpOnly,
+			Secure:   cookie.Secure,
+		})
+	}
+
+	if result.RefreshToken != "" {
+		set(refreshTokenCookie(result.RefreshToken, expires))
+	}
+	return merged
+}
+
+// refreshTokenCookie 将refresh_token包装为cookie，与账号cookies一起（加密）保存
+func refreshTokenCookie(refreshToken string, expires float64) playwright.Cookie {
+	return playwright.Cookie{
+		Name:    api.RefreshTokenCookie,
+		Value:   refreshToken,
+		Domain:  ".bilibili.com",
+		Path:    "/",
+		Expires: expires,
+		Secure:  true,
+	}
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/playwright-community/playwright-go"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// accountExportVersion 账号导出文件格式版本
+const accountExportVersion = 1
+
+// accountExport 账号导出文件：账号信息与cookies打包，用于在机器之间迁移登录状态
+type accountExport struct {
+	Version    int             `json:"version"`     // 文件格式版本
+	ExportedAt time.Time       `json:"exported_at"` // 导出时间
+	Account    Account         `json:"account"`     // 账号信息
+	Cookies    json.RawMessage `json:"cookies"`     // cookies数组，设置口令时为加密后的内容
+}
+
+// ExportAccount 导出账号信息和cookies，passphrase非空时使用该口令加密cookies
+func (am *AccountManager) ExportAccount(name, passphrase string) ([]byte, error) {
+	account, err := am.GetAccount(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cookies, err := am.readCookieData(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "读取账号 '%s' 的cookies失败", name)
+	}
+
+	if passphrase != "" {
+		if cookies, err = sealCookies(&cookieSecret{passphrase: passphrase}, cookies); err != nil {
+			return nil, err
+		}
+	}
+
+	account.IsDefault = false
+	return json.MarshalIndent(accountExport{
+		Version:    accountExportVersion,
+		ExportedAt: time.Now(),
+		Account:    *account,
+		Cookies:    cookies,
+	}, "", "  ")
+}
+
+// ImportAccount 导入ExportAccount生成的文件：重建cookies文件并注册账号，同名账号会被覆盖
+func (am *AccountManager) ImportAccount(data []byte, passphrase string) (*Account, error) {
+	var bundle accountExport
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, errors.Wrap(err, "解析账号导出文件失败")
+	}
+	if bundle.Version != accountExportVersion {
+		return nil, errors.Errorf("不支持的账号导出文件版本: %d", bundle.Version)
+	}
+	if bundle.Account.Name == "" || len(bundle.Cookies) == 0 {
+		return nil, errors.New("账号导出文件缺少账号信息或cookies")
+	}
+	if err := validateAccountName(bundle.Account.Name); err != nil {
+		return nil, err
+	}
+
+	var secret *cookieSecret
+	if passphrase != "" {
+		secret = &cookieSecret{passphrase: passphrase}
+	}
+	cookies, encrypted, err := openCookies(secret, bundle.Cookies)
+	if err != nil {
+		if encrypted && secret == nil {
+			return nil, errors.New("导出文件的cookies已加密，请提供导出时使用的口令")
+		}
+		return nil, err
+	}
+
+	var parsed []playwright.Cookie
+	if err := json.Unmarshal(cookies, &parsed); err != nil || len(parsed) == 0 {
+		return nil, errors.New("导出文件中的cookies无效")
+	}
+
+	if err := os.MkdirAll(am.cookieDir, 0755); err != nil {
+		return nil, errors.Wrap(err, "创建cookies目录失败")
+	}
+	if err := am.writeCookieData(bundle.Account.Name, cookies); err != nil {
+		return nil, errors.Wrap(err, "保存cookies失败")
+	}
+
+	// 覆盖同名账号时保留其默认账号标记，否则导入当前默认账号后会没有默认账号
+	account := bundle.Account
+	account.IsDefault = false
+	if existing, err := am.GetAccount(account.Name); err == nil {
+		account.IsDefault = existing.IsDefault
+	}
+	account.IsActive = true
+	if err := am.SaveAccount(&account); err != nil {
+		return nil, errors.Wrap(err, "保存账号信息失败")
+	}
+
+	logger.Infof("📥 已导入账号 '%s' (UID: %s)", account.Name, account.UID)
+	return &account, nil
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testBundle(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := json.Marshal(accountExport{
+		Version:    accountExportVersion,
+		ExportedAt: time.Now(),
+		Account:    Account{Name: name, UID: "1"},
+		Cookies:    json.RawMessage(testCookies),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestImportAccountRejectsPathTraversal(t *testing.T) {
+	am := newTestAccountManager(t, false)
+	for _, name := range []string{"../evil", "a/b", `a\b`, ".."} {
+		if _, err := am.ImportAccount(testBundle(t, name), ""); err == nil {
+			t.Errorf("ImportAccount(%q) succeeded", name)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(am.cookieDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() == "evil_bilibili_cookies.json" {
+			t.Fatal("cookies written outside cookie dir")
+		}
+	}
+}
+
+func TestImportAccountKeepsDefault(t *testing.T) {
+	am := newTestAccountManager(t, false)
+	if err := am.SaveAccount(&Account{Name: "main", IsDefault: true, IsActive: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := am.ImportAccount(testBundle(t, "main"), "")
+	if err != nil {
+		t.Fatalf("ImportAccount: %v", err)
+	}
+	if !account.IsDefault {
+		t.Fatal("import over the default account cleared the default flag")
+	}
+	if def, err := am.GetDefaultAccount(); err != nil || def.Name != "main" {
+		t.Fatalf("GetDefaultAccount: %v, %v", def, err)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := newTestAccountManager(t, false)
+	if err := src.SaveAccount(&Account{Name: "alice", UID: "42"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.writeCookieData("alice", []byte(testCookies)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := src.ExportAccount("alice", "secret")
+	if err != nil {
+		t.Fatalf("ExportAccount: %v", err)
+	}
+
+	dst := newTestAccountManager(t, false)
+	if _, err := dst.ImportAccount(data, ""); err == nil {
+		t.Fatal("import without passphrase should fail")
+	}
+	account, err := dst.ImportAccount(data, "secret")
+	if err != nil {
+		t.Fatalf("ImportAccount: %v", err)
+	}
+	if account.UID != "42" {
+		t.Fatalf("got account %+v", account)
+	}
+	cookies, err := dst.readCookieData("alice")
+	if err != nil || string(cookies) != testCookies {
+		t.Fatalf("imported cookies: %q, %v", cookies, err)
+	}
+}
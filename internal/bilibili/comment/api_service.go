@@ -4,7 +4,6 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
-	"github.com/playwright-community/playwright-go"
 	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
 	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
 )
@@ -14,20 +13,8 @@ type APICommentService struct {
 	apiClient *api.Client
 }
 
-// NewAPICommentService 创建API评论服务
-func NewAPICommentService(page playwright.Page) (*APICommentService, error) {
-	// 从playwright页面获取cookies
-	cookies, err := page.Context().Cookies()
-	if err != nil {
-		return nil, errors.Wrap(err, "获取cookies失败")
-	}
-
-	// 转换为map格式
-	cookieMap := make(map[string]string)
-	for _, cookie := range cookies {
-		cookieMap[cookie.Name] = cookie.Value
-	}
-
+// NewAPICommentService 使用账号cookies创建API评论服务
+func NewAPICommentService(cookieMap map[string]string) (*APICommentService, error) {
 	// 创建API客户端
 	apiClient := api.NewClient(cookieMap)
 
@@ -59,7 +46,7 @@ func (s *APICommentService) PostComment(ctx context.Context, videoID, content st
 	}
 
 	if resp.Code != 0 {
-		return 0, errors.Errorf("评论发表失败: %s (code: %d)", resp.Message, resp.Code)
+		return 0, errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "评论发表失败")
 	}
 
 	logger.Infof("评论发表成功 - 视频: %s, 评论ID: %d", videoID, resp.Data.Rpid)
@@ -0,0 +1,100 @@
+package download
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+)
+
+// 音质选项
+const (
+	AudioQualityDefault = "default" // 带宽最高的标准音轨
+	AudioQualityHigh    = "high"    // 最好的可用音轨：无损 > 杜比 > 标准
+	AudioQualityFLAC    = "flac"    // Hi-Res无损音轨
+	AudioQualityDolby   = "dolby"   // 杜比全景声音轨
+)
+
+// dolbyAudioFnval 请求杜比音轨需要附加的fnval标识
+const dolbyAudioFnval = 256
+
+// ParseAudioQuality 校验音质选项，空值表示默认
+func ParseAudioQuality(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "":
+		return AudioQualityDefault, nil
+	case AudioQualityDefault, AudioQualityHigh, AudioQualityFLAC, AudioQualityDolby:
+		return value, nil
+	default:
+		return "", errors.Errorf("不支持的音质: %s，支持: default, high, flac, dolby", value)
+	}
+}
+
+// isPremiumAudio 是否请求了无损或杜比音轨
+func isPremiumAudio(quality string) bool {
+	return quality == AudioQualityHigh || quality == AudioQualityFLAC || quality == AudioQualityDolby
+}
+
+// audioSelection 音轨选择结果
+type audioSelection struct {
+	Stream api.DASHStream
+	Tier   string // 实际选择的音轨：default/flac/dolby
+	Note   string // 请求的音轨不可用时的提示
+}
+
+// selectAudioStream 按音质选项选择音轨，请求的无损/杜比音轨不存在时回退到最佳标准音轨
+func selectAudioStream(dash *api.DASHInfo, quality string) (audioSelection, error) {
+	var flac, dolby *api.DASHStream
+	if dash.FLAC != nil && dash.FLAC.Audio.BaseURL != "" {
+		flac = &dash.FLAC.Audio
+	}
+	if dash.Dolby != nil && len(dash.Dolby.Audio) > 0 {
+		dolby = &dash.Dolby.Audio[0]
+	}
+
+	switch quality {
+	case AudioQualityFLAC:
+		if flac != nil {
+			return audioSelection{Stream: *flac, Tier: AudioQualityFLAC}, nil
+		}
+	case AudioQualityDolby:
+		if dolby != nil {
+			return audioSelection{Stream: *dolby, Tier: AudioQualityDolby}, nil
+		}
+	case AudioQualityHigh:
+		if flac != nil {
+			return audioSelection{Stream: *flac, Tier: AudioQualityFLAC}, nil
+		}
+		if dolby != nil {
+			return audioSelection{Stream: *dolby, Tier: AudioQualityDolby}, nil
+		}
+	}
+
+	if len(dash.Audio) == 0 {
+		return audioSelection{}, errors.New("该视频没有可用的音频流")
+	}
+	best := dash.Audio[0]
+	for _, audio := range dash.Audio {
+		if audio.Bandwidth > best.Bandwidth {
+			best = audio
+		}
+	}
+
+	selection := audioSelection{Stream: best, Tier: AudioQualityDefault}
+	switch quality {
+	case AudioQualityFLAC:
+		selection.Note = "该视频未提供Hi-Res无损音轨（通常需要大会员），已回退到最佳标准音轨"
+	case AudioQualityDolby:
+		selection.Note = "该视频未提供杜比全景声音轨（通常需要大会员），已回退到最佳标准音轨"
+	}
+	return selection, nil
+}
+
+// audioFileSuffix 无损/杜比音轨的文件名后缀，避免与标准音轨的已下载文件混淆
+func audioFileSuffix(tier string) string {
+	if tier == AudioQualityDefault {
+		return ""
+	}
+	return "_" + tier
+}
@@ -0,0 +1,99 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+)
+
+// rewriteTransport 将API请求转发到测试服务器
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// premiumAudioServer 模拟playurl接口：只有携带大会员SESSDATA且fnval包含杜比标识时才返回无损音轨
+func premiumAudioServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/x/player/wbi/playurl":
+			fnval, _ := strconv.Atoi(r.URL.Query().Get("fnval"))
+			flac := ""
+			if cookie, err := r.Cookie("SESSDATA"); err == nil && cookie.Value == "vip" && fnval&dolbyAudioFnval != 0 {
+				flac = fmt.Sprintf(`,"flac":{"display":true,"audio":{"id":30251,"baseUrl":%q,"bandwidth":1500000}}`, server.URL+"/flac.m4a")
+			}
+			fmt.Fprintf(w, `{"code":0,"data":{"quality":80,"dash":{"video":[],"audio":[{"id":30280,"baseUrl":%q,"bandwidth":320000}]%s}}}`, server.URL+"/standard.m4a", flac)
+		case "/flac.m4a":
+			w.Write([]byte("flac-audio"))
+		case "/standard.m4a":
+			w.Write([]byte("standard-audio"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return server
+}
+
+func TestDownloadFLACAudioWithAccountCookies(t *testing.T) {
+	server := premiumAudioServer(t)
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		cookies  map[string]string
+		wantTier string
+		wantBody string
+	}{
+		{"大会员账号获取无损音轨", map[string]string{"SESSDATA": "vip"}, AudioQualityFLAC, "flac-audio"},
+		{"未登录回退到标准音轨", map[string]string{}, AudioQualityDefault, "standard-audio"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiClient := api.NewClient(tt.cookies)
+			apiClient.SetHTTPClient(&http.Client{Transport: rewriteTransport{target: target}})
+			s := newTestMediaService(t)
+			s.apiClient = apiClient
+
+			opts := DownloadOptions{AudioQuality: AudioQualityFLAC}
+			streamData, err := s.getDASHStream("BV1xx411c7mD", 1, opts)
+			if err != nil {
+				t.Fatalf("getDASHStream: %v", err)
+			}
+
+			result := &MediaDownloadResult{VideoID: "BV1xx411c7mD"}
+			result, err = s.downloadAudioOnly(context.Background(), result, streamData, "title", opts.AudioQuality)
+			if err != nil {
+				t.Fatalf("downloadAudioOnly: %v", err)
+			}
+			if result.AudioQuality != tt.wantTier {
+				t.Fatalf("audio tier = %q, want %q (notes: %s)", result.AudioQuality, tt.wantTier, result.Notes)
+			}
+			got, err := os.ReadFile(result.AudioPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.wantBody {
+				t.Fatalf("downloaded %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
@@ -49,7 +49,7 @@ func (s *AudioDownloadService) DownloadAudio(ctx context.Context, videoID string
 	}
 
 	if videoInfo.Code != 0 {
-		return nil, errors.Errorf("获取视频信息失败: %s (code: %d)", videoInfo.Message, videoInfo.Code)
+		return nil, errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "获取视频信息失败")
 	}
 
 	// 获取播放地址
@@ -59,7 +59,7 @@ func (s *AudioDownloadService) DownloadAudio(ctx context.Context, videoID string
 	}
 
 	if playUrl.Code != 0 {
-		return nil, errors.Errorf("获取播放地址失败: %s (code: %d)", playUrl.Message, playUrl.Code)
+		return nil, errors.Wrap(api.NewAPIError(playUrl.Code, playUrl.Message), "获取播放地址失败")
 	}
 
 	// 检查是否有音频流
@@ -141,9 +141,7 @@ func (s *AudioDownloadService) downloadAudioStream(ctx context.Context, audioURL
 	req.Header.Set("Connection", "keep-alive")
 
 	// 发送请求
-	client := &http.Client{
-		Timeout: 10 * time.Minute, // 10分钟超时，足够下载大文件
-	}
+	client := api.NewHTTPClient(10 * time.Minute) // 10分钟超时，足够下载大文件
 
 	resp, err := client.Do(req)
 	if err != nil {
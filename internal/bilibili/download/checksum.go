@@ -0,0 +1,53 @@
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// fileSHA256 计算文件的SHA256，返回十六进制字符串
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err, "打开文件失败")
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", errors.Wrap(err, "计算SHA256失败")
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// fillChecksums 为下载结果中存在的文件计算SHA256
+func fillChecksums(result *MediaDownloadResult) error {
+	files := []struct {
+		path string
+		sum  *string
+	}{
+		{result.AudioPath, &result.AudioSHA256},
+		{result.VideoPath, &result.VideoSHA256},
+		{result.MergedPath, &result.MergedSHA256},
+	}
+
+	for _, f := range files {
+		if f.path == "" {
+			continue
+		}
+		// 自动合并成功后中间文件会被删除
+		if _, err := os.Stat(f.path); os.IsNotExist(err) {
+			continue
+		}
+		sum, err := fileSHA256(f.path)
+		if err != nil {
+			return errors.Wrapf(err, "校验文件 %s 失败", f.path)
+		}
+		*f.sum = sum
+	}
+	return nil
+}
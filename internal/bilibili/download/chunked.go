@@ -0,0 +1,165 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+const (
+	// defaultParallelChunks 默认的分块并发下载连接数
+	defaultParallelChunks = 4
+	// minChunkedSize 小于该大小的文件使用单连接下载，分块的额外请求得不偿失
+	minChunkedSize = 8 * 1024 * 1024
+	// chunkBufferSize 每个分块读取时使用的缓冲区大小
+	chunkBufferSize = 256 * 1024
+)
+
+// probeRangeSupport 用HEAD请求探测文件大小以及服务端是否支持Range请求
+func (s *MediaDownloadService) probeRangeSupport(ctx context.Context, streamURL, videoID string) (int64, bool) {
+	resp, err := s.sendStreamRequest(ctx, "HEAD", streamURL, videoID, "")
+	if err != nil {
+		logger.Debugf("探测Range支持失败，使用单连接下载: %v", err)
+		return 0, false
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.ContentLength <= 0 {
+		return 0, false
+	}
+	if !strings.EqualFold(resp.Header.Get("Accept-Ranges"), "bytes") {
+		return 0, false
+	}
+	return resp.ContentLength, true
+}
+
+// downloadChunked 将文件按字节范围分成多块并发下载，写入预分配的临时文件。
+// 分块下载的临时文件中间可能有空洞，无法按文件大小续传，因此失败时总是删除
+func (s *MediaDownloadService) downloadChunked(ctx context.Context, streamURL, outputPath, videoID string, totalSize int64) (int64, error) {
+	tempPath := outputPath + ".downloading"
+	filename := filepath.Base(outputPath)
+
+	tempFile, err := os.OpenFile(tempPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, errors.Wrap(err, "创建临时文件失败")
+	}
+	if err := tempFile.Truncate(totalSize); err != nil {
+		tempFile.Close()
+		os.Remove(tempPath)
+		return 0, errors.Wrap(err, "预分配文件空间失败")
+	}
+
+	chunks := s.parallelChunks
+	chunkSize := (totalSize + int64(chunks) - 1) / int64(chunks)
+	logger.Infof("[开始下载] %s: 文件大小 %.2f MB, %d 个连接并发下载", filename, float64(totalSize)/(1024*1024), chunks)
+
+	// 各分块的进度汇总到同一个跟踪器
+	tracker := NewProgressTracker(filename, totalSize)
+	tracker.onProgress = progressFromContext(ctx)
+	var progressMu sync.Mutex
+	var downloaded int64
+	addProgress := func(n int64) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		downloaded += n
+		tracker.Update(downloaded)
+	}
+
+	chunkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, chunks)
+	for start := int64(0); start < totalSize; start += chunkSize {
+		end := start + chunkSize - 1
+		if end >= totalSize {
+			end = totalSize - 1
+		}
+		wg.Add(1)
+		go func(start, end int64) {
+			defer wg.Done()
+			if err := s.downloadChunk(chunkCtx, streamURL, videoID, tempFile, start, end, addProgress); err != nil {
+				errCh <- err
+				cancel() // 任意分块失败时取消其他分块
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	close(errCh)
+	tempFile.Close()
+
+	if err := <-errCh; err != nil {
+		os.Remove(tempPath)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			logger.Warnf("⏱️ 下载中断: %s (已下载 %.2f MB, 分块下载的未完成文件无法续传，已删除)", filename, float64(downloaded)/(1024*1024))
+			return downloaded, &PartialDownloadError{
+				Path:       tempPath,
+				Downloaded: downloaded,
+				Total:      totalSize,
+				Kept:       false,
+				Err:        ctxErr,
+			}
+		}
+		return 0, errors.Wrap(err, "分块下载失败")
+	}
+
+	tracker.Finish(totalSize)
+
+	// 重命名为最终文件
+	if err := os.Rename(tempPath, outputPath); err != nil {
+		os.Remove(tempPath)
+		return 0, errors.Wrap(err, "重命名文件失败")
+	}
+
+	return totalSize, nil
+}
+
+// downloadChunk 下载 [start, end] 字节范围并写入文件的对应位置
+func (s *MediaDownloadService) downloadChunk(ctx context.Context, streamURL, videoID string, file *os.File, start, end int64, onProgress func(int64)) error {
+	resp, err := s.sendStreamRequest(ctx, "GET", streamURL, videoID, fmt.Sprintf("bytes=%d-%d", start, end))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent || !contentRangeStartsAt(resp.Header.Get("Content-Range"), start) {
+		return errors.Errorf("分块 %d-%d 请求失败: %s", start, end, resp.Status)
+	}
+
+	body := throttle(ctx, resp.Body)
+	buf := make([]byte, chunkBufferSize)
+	offset := start
+	for offset <= end {
+		n, readErr := body.Read(buf)
+		if n > 0 {
+			// 服务端多返回的数据不写入，避免覆盖下一个分块
+			if remaining := end - offset + 1; int64(n) > remaining {
+				n = int(remaining)
+			}
+			if _, err := file.WriteAt(buf[:n], offset); err != nil {
+				return errors.Wrap(err, "写入文件失败")
+			}
+			offset += int64(n)
+			onProgress(int64(n))
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return errors.Wrapf(readErr, "分块 %d-%d 下载失败", start, end)
+		}
+	}
+
+	if offset != end+1 {
+		return errors.Errorf("分块 %d-%d 数据不完整: 收到 %d 字节", start, end, offset-start)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// rangeServer 返回提供payload的测试服务器，acceptRanges为false时忽略Range头并且不返回Accept-Ranges
+func rangeServer(t *testing.T, payload []byte, acceptRanges bool, rangeRequests *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			atomic.AddInt32(rangeRequests, 1)
+		}
+		if acceptRanges {
+			http.ServeContent(w, r, "stream.m4s", time.Time{}, bytes.NewReader(payload))
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		if r.Method != http.MethodHead {
+			w.Write(payload)
+		}
+	}))
+}
+
+func TestDownloadStreamChunked(t *testing.T) {
+	payload := testPayload(minChunkedSize + 12345)
+
+	var rangeRequests int32
+	server := rangeServer(t, payload, true, &rangeRequests)
+	defer server.Close()
+
+	s := newTestMediaService(t)
+	s.parallelChunks = 4
+	outputPath := filepath.Join(s.outputDir, "video.m4s")
+
+	written, err := s.downloadStream(context.Background(), server.URL, outputPath, "BV1test")
+	if err != nil {
+		t.Fatalf("downloadStream: %v", err)
+	}
+	if written != int64(len(payload)) {
+		t.Fatalf("written = %d, want %d", written, len(payload))
+	}
+	if n := atomic.LoadInt32(&rangeRequests); n != 4 {
+		t.Fatalf("range requests = %d, want 4", n)
+	}
+
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatal("chunked download does not match payload")
+	}
+}
+
+func TestDownloadStreamWithoutAcceptRanges(t *testing.T) {
+	payload := testPayload(minChunkedSize + 777)
+
+	var rangeRequests int32
+	server := rangeServer(t, payload, false, &rangeRequests)
+	defer server.Close()
+
+	s := newTestMediaService(t)
+	s.parallelChunks = 4
+	outputPath := filepath.Join(s.outputDir, "video.m4s")
+
+	if _, ok := s.probeRangeSupport(context.Background(), server.URL, "BV1test"); ok {
+		t.Fatal("probe reported Range support without Accept-Ranges")
+	}
+
+	written, err := s.downloadStream(context.Background(), server.URL, outputPath, "BV1test")
+	if err != nil {
+		t.Fatalf("downloadStream: %v", err)
+	}
+	if written != int64(len(payload)) {
+		t.Fatalf("written = %d, want %d", written, len(payload))
+	}
+	if n := atomic.LoadInt32(&rangeRequests); n != 0 {
+		t.Fatalf("range requests = %d, want single connection", n)
+	}
+
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatal("single connection download does not match payload")
+	}
+}
+
+func TestDownloadChunkedUnevenSplit(t *testing.T) {
+	payload := testPayload(1000003)
+
+	var rangeRequests int32
+	server := rangeServer(t, payload, true, &rangeRequests)
+	defer server.Close()
+
+	s := newTestMediaService(t)
+	s.parallelChunks = 3
+	outputPath := filepath.Join(s.outputDir, "audio.m4s")
+
+	if _, err := s.downloadChunked(context.Background(), server.URL, outputPath, "BV1test", int64(len(payload))); err != nil {
+		t.Fatalf("downloadChunked: %v", err)
+	}
+
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatal("chunked download does not match payload byte for byte")
+	}
+}
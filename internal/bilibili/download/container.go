@@ -0,0 +1,83 @@
+package download
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// 合并输出的容器格式
+const (
+	OutputFormatMP4  = "mp4"
+	OutputFormatMKV  = "mkv"
+	OutputFormatWebM = "webm"
+)
+
+// OutputFormats 支持的输出容器格式
+var OutputFormats = []string{OutputFormatMP4, OutputFormatMKV, OutputFormatWebM}
+
+// normalizeOutputFormat 校验并规范化输出格式，空值默认为mp4
+func normalizeOutputFormat(format string) (string, error) {
+	format = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(format), "."))
+	if format == "" {
+		return OutputFormatMP4, nil
+	}
+	for _, supported := range OutputFormats {
+		if format == supported {
+			return format, nil
+		}
+	}
+	return "", errors.Errorf("不支持的输出格式: %s，支持: %s", format, strings.Join(OutputFormats, ", "))
+}
+
+// mergeCodecArgs 根据容器和音视频编码生成ffmpeg编码参数，需要重新编码时返回提示信息
+func mergeCodecArgs(format, videoCodec, audioCodec string) (args []string, warning string) {
+	switch format {
+	case OutputFormatWebM:
+		// WebM仅支持VP8/VP9/AV1视频和Vorbis/Opus音频
+		var reencoded []string
+		if strings.HasPrefix(videoCodec, "av01") || strings.HasPrefix(videoCodec, "vp") {
+			args = append(args, "-c:v", "copy")
+		} else {
+			args = append(args, "-c:v", "libvpx-vp9", "-crf", "32", "-b:v", "0")
+			reencoded = append(reencoded, fmt.Sprintf("视频(%s→VP9)", codecFamily(videoCodec)))
+		}
+		if strings.HasPrefix(audioCodec, "opus") || strings.HasPrefix(audioCodec, "vorbis") {
+			args = append(args, "-c:a", "copy")
+		} else {
+			args = append(args, "-c:a", "libopus", "-b:a", "128k")
+			reencoded = append(reencoded, fmt.Sprintf("音频(%s→Opus)", codecFamily(audioCodec)))
+		}
+		if len(reencoded) > 0 {
+			warning = fmt.Sprintf("WebM不支持当前编码，需要重新编码%s，耗时较长", strings.Join(reencoded, "和"))
+		}
+		return args, warning
+	case OutputFormatMP4:
+		// FLAC/E-AC-3音轨放入MP4需要显式允许实验性封装
+		if strings.HasPrefix(audioCodec, "fLaC") || strings.HasPrefix(audioCodec, "flac") {
+			return []string{"-c", "copy", "-strict", "experimental"}, "无损FLAC音轨在部分播放器中的MP4兼容性较差，建议使用mkv"
+		}
+		return []string{"-c", "copy"}, ""
+	default:
+		// MKV可以直接封装B站所有编码
+		return []string{"-c", "copy"}, ""
+	}
+}
+
+// codecFamily 提取编码名称的主体部分，如 avc1.640032 -> avc1
+func codecFamily(codec string) string {
+	if codec == "" {
+		return "未知"
+	}
+	if idx := strings.Index(codec, "."); idx > 0 {
+		return codec[:idx]
+	}
+	return codec
+}
+
+// buildMergeCommand 生成可直接执行的ffmpeg合并命令
+func buildMergeCommand(videoPath, audioPath, outputPath string, codecArgs []string) string {
+	return fmt.Sprintf("ffmpeg -i \"%s\" -i \"%s\" %s \"%s\"",
+		videoPath, audioPath, strings.Join(codecArgs, " "), outputPath)
+}
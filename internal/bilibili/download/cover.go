@@ -0,0 +1,123 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// CoverService 视频封面下载服务
+type CoverService struct {
+	apiClient *api.Client
+}
+
+// NewCoverService 创建封面下载服务
+func NewCoverService(apiClient *api.Client) *CoverService {
+	return &CoverService{apiClient: apiClient}
+}
+
+// CoverDownloadResult 封面下载结果
+type CoverDownloadResult struct {
+	VideoID  string `json:"video_id"`  // 视频ID
+	Title    string `json:"title"`     // 视频标题
+	CoverURL string `json:"cover_url"` // 封面地址
+	FilePath string `json:"file_path"` // 封面文件路径
+	FileSize int64  `json:"file_size"` // 文件大小(字节)
+}
+
+// Download 下载视频封面，保存为 <标题>_<BV号>_cover.jpg
+func (s *CoverService) Download(ctx context.Context, videoID, outputDir string) (*CoverDownloadResult, error) {
+	logger.Infof("🖼️ 开始下载封面 - 视频ID: %s", videoID)
+
+	videoInfo, err := s.apiClient.GetVideoInfo(videoID)
+	if err != nil {
+		return nil, errors.Wrap(err, "获取视频信息失败")
+	}
+	if videoInfo.Code != 0 {
+		return nil, errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "获取视频信息失败")
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, errors.Wrap(err, "创建输出目录失败")
+	}
+
+	filename := fmt.Sprintf("%s_%s_cover.jpg", sanitizeFilename(videoInfo.Data.Title), videoInfo.Data.Bvid)
+	outputPath := filepath.Join(outputDir, filename)
+
+	coverURL := NormalizeCoverURL(videoInfo.Data.Pic)
+	size, err := DownloadCover(ctx, coverURL, outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("✅ 封面下载完成: %s", outputPath)
+
+	return &CoverDownloadResult{
+		VideoID:  videoID,
+		Title:    videoInfo.Data.Title,
+		CoverURL: coverURL,
+		FilePath: outputPath,
+		FileSize: size,
+	}, nil
+}
+
+// NormalizeCoverURL 将接口返回的协议相对或http封面地址统一为https
+func NormalizeCoverURL(coverURL string) string {
+	if strings.HasPrefix(coverURL, "//") {
+		return "https:" + coverURL
+	}
+	if strings.HasPrefix(coverURL, "http://") {
+		return "https://" + strings.TrimPrefix(coverURL, "http://")
+	}
+	return coverURL
+}
+
+// DownloadCover 下载视频封面到指定路径，返回文件大小
+func DownloadCover(ctx context.Context, coverURL, outputPath string) (int64, error) {
+	if coverURL == "" {
+		return 0, errors.New("封面地址为空")
+	}
+	coverURL = NormalizeCoverURL(coverURL)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", coverURL, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "创建封面请求失败")
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Referer", "https://www.bilibili.com")
+
+	client := api.NewHTTPClient(1 * time.Minute)
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "下载封面失败")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, errors.Errorf("下载封面失败: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return 0, errors.Wrap(err, "创建封面文件失败")
+	}
+	defer file.Close()
+
+	written, err := io.Copy(file, resp.Body)
+	if err != nil {
+		file.Close()
+		os.Remove(outputPath)
+		return 0, errors.Wrap(err, "写入封面文件失败")
+	}
+
+	return written, nil
+}
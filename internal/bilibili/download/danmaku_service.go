@@ -0,0 +1,250 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// DanmakuFormat 弹幕输出格式
+type DanmakuFormat string
+
+const (
+	DanmakuFormatJSON DanmakuFormat = "json" // 解析后的JSON
+	DanmakuFormatXML  DanmakuFormat = "xml"  // B站原始XML
+	DanmakuFormatASS  DanmakuFormat = "ass"  // ASS字幕，可直接烧录或外挂
+)
+
+// ASS弹幕渲染参数
+const (
+	assPlayResX       = 1920
+	assPlayResY       = 1080
+	assFontSize       = 48
+	assScrollDuration = 8.0 // 滚动弹幕停留时间(秒)
+	assFixedDuration  = 4.0 // 顶部/底部弹幕停留时间(秒)
+)
+
+// DanmakuDownloadService 弹幕下载服务
+type DanmakuDownloadService struct {
+	apiClient *api.Client
+	outputDir string
+}
+
+// NewDanmakuDownloadService 创建弹幕下载服务
+func NewDanmakuDownloadService(apiClient *api.Client, outputDir string) *DanmakuDownloadService {
+	return &DanmakuDownloadService{
+		apiClient: apiClient,
+		outputDir: outputDir,
+	}
+}
+
+// DanmakuDownloadResult 弹幕下载结果
+type DanmakuDownloadResult struct {
+	VideoID  string        `json:"video_id"`  // 视频ID
+	Title    string        `json:"title"`     // 视频标题
+	CID      int64         `json:"cid"`       // 分P的CID
+	Format   DanmakuFormat `json:"format"`    // 输出格式
+	Count    int           `json:"count"`     // 弹幕数量
+	FilePath string        `json:"file_path"` // 输出文件路径
+	FileSize int64         `json:"file_size"` // 文件大小(字节)
+}
+
+// DownloadDanmaku 下载视频弹幕并保存为指定格式
+func (s *DanmakuDownloadService) DownloadDanmaku(ctx context.Context, videoID string, cid int64, format DanmakuFormat) (*DanmakuDownloadResult, error) {
+	logger.Infof("💬 开始下载弹幕 - 视频ID: %s, 格式: %s", videoID, format)
+
+	videoInfo, err := s.apiClient.GetVideoInfo(videoID)
+	if err != nil {
+		return nil, errors.Wrap(err, "获取视频信息失败")
+	}
+	if videoInfo.Code != 0 {
+		return nil, errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "获取视频信息失败")
+	}
+
+	if cid == 0 {
+		if len(videoInfo.Data.Pages) == 0 {
+			return nil, errors.New("视频没有可用的分P")
+		}
+		cid = videoInfo.Data.Pages[0].Cid
+	}
+
+	items, raw, err := s.apiClient.GetDanmaku(cid)
+	if err != nil {
+		return nil, errors.Wrap(err, "获取弹幕失败")
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	var content []byte
+	switch format {
+	case DanmakuFormatXML:
+		content = raw
+	case DanmakuFormatJSON:
+		content, err = json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return nil, errors.Wrap(err, "序列化弹幕失败")
+		}
+	case DanmakuFormatASS:
+		content = []byte(ConvertDanmakuToASS(items, videoInfo.Data.Title))
+	default:
+		return nil, errors.Errorf("不支持的弹幕格式: %s", format)
+	}
+
+	if err := os.MkdirAll(s.outputDir, 0755); err != nil {
+		return nil, errors.Wrap(err, "创建输出目录失败")
+	}
+
+	filename := fmt.Sprintf("%s_%s_%d.%s", sanitizeFilename(videoInfo.Data.Title), videoID, cid, format)
+	outputPath := filepath.Join(s.outputDir, filename)
+	if err := os.WriteFile(outputPath, content, 0644); err != nil {
+		return nil, errors.Wrap(err, "写入弹幕文件失败")
+	}
+
+	logger.Infof("✅ 弹幕下载完成: %s (%d 条)", outputPath, len(items))
+
+	return &DanmakuDownloadResult{
+		VideoID:  videoID,
+		Title:    videoInfo.Data.Title,
+		CID:      cid,
+		Format:   format,
+		Count:    len(items),
+		FilePath: outputPath,
+		FileSize: int64(len(content)),
+	}, nil
+}
+
+// ConvertDanmakuToASS 将弹幕转换为ASS字幕
+// 滚动弹幕从右向左移动，顶部/底部弹幕居中固定显示，颜色取自弹幕的RGB值
+func ConvertDanmakuToASS(items []api.DanmakuItem, title string) string {
+	sorted := make([]api.DanmakuItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time < sorted[j].Time
+	})
+
+	var b strings.Builder
+	b.WriteString("[Script Info]\n")
+	b.WriteString(fmt.Sprintf("Title: %s\n", title))
+	b.WriteString("ScriptType: v4.00+\n")
+	b.WriteString("WrapStyle: 2\n")
+	b.WriteString("ScaledBorderAndShadow: yes\n")
+	b.WriteString(fmt.Sprintf("PlayResX: %d\n", assPlayResX))
+	b.WriteString(fmt.Sprintf("PlayResY: %d\n\n", assPlayResY))
+
+	b.WriteString("[V4+ Styles]\n")
+	b.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	b.WriteString(fmt.Sprintf("Style: Danmaku,Microsoft YaHei,%d,&H33FFFFFF,&H33FFFFFF,&H33000000,&H33000000,0,0,0,0,100,100,0,0,1,1,0,7,0,0,0,1\n\n", assFontSize))
+
+	b.WriteString("[Events]\n")
+	b.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+
+	lineHeight := float64(assFontSize) + 4
+	rows := int(float64(assPlayResY) / lineHeight)
+	scrollRows := make([]float64, rows) // 每行滚动弹幕完全进入屏幕的时间
+	topRows := make([]float64, rows)    // 每行顶部弹幕结束时间
+	bottomRows := make([]float64, rows) // 每行底部弹幕结束时间
+
+	for _, item := range sorted {
+		text := escapeASSText(item.Content)
+		if text == "" {
+			continue
+		}
+
+		width := float64(utf8.RuneCountInString(item.Content) * assFontSize)
+		color := assColor(item.Color)
+		start := item.Time
+
+		switch item.Mode {
+		case 4: // 底部
+			row := allocateRow(bottomRows, start, start+assFixedDuration)
+			y := float64(assPlayResY) - float64(row)*lineHeight - 10
+			b.WriteString(fmt.Sprintf("Dialogue: 2,%s,%s,Danmaku,,0,0,0,,{\\an2\\pos(%d,%d)%s}%s\n",
+				assTime(start), assTime(start+assFixedDuration), assPlayResX/2, int(y), color, text))
+		case 5: // 顶部
+			row := allocateRow(topRows, start, start+assFixedDuration)
+			y := float64(row)*lineHeight + 10
+			b.WriteString(fmt.Sprintf("Dialogue: 2,%s,%s,Danmaku,,0,0,0,,{\\an8\\pos(%d,%d)%s}%s\n",
+				assTime(start), assTime(start+assFixedDuration), assPlayResX/2, int(y), color, text))
+		case 1, 2, 3, 6: // 滚动
+			// 弹幕尾部进入屏幕后该行才可放置下一条
+			enterTime := start + assScrollDuration*width/(float64(assPlayResX)+width)
+			row := allocateRow(scrollRows, start, enterTime)
+			y := float64(row) * lineHeight
+			fromX, toX := assPlayResX, -int(width)
+			if item.Mode == 6 {
+				fromX, toX = toX, fromX
+			}
+			b.WriteString(fmt.Sprintf("Dialogue: 1,%s,%s,Danmaku,,0,0,0,,{\\move(%d,%d,%d,%d)%s}%s\n",
+				assTime(start), assTime(start+assScrollDuration), fromX, int(y), toX, int(y), color, text))
+		default:
+			// 高级弹幕和代码弹幕无法转换，跳过
+			continue
+		}
+	}
+
+	return b.String()
+}
+
+// allocateRow 为弹幕分配行号，优先选择已空闲的行，否则选择最早空闲的行
+func allocateRow(rows []float64, start, busyUntil float64) int {
+	best := 0
+	for i, freeAt := range rows {
+		if freeAt <= start {
+			rows[i] = busyUntil
+			return i
+		}
+		if freeAt < rows[best] {
+			best = i
+		}
+	}
+	rows[best] = busyUntil
+	return best
+}
+
+// assTime 格式化ASS时间戳 H:MM:SS.cc
+func assTime(seconds float64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	centis := int64(seconds*100 + 0.5)
+	h := centis / 360000
+	m := (centis / 6000) % 60
+	sec := (centis / 100) % 60
+	cs := centis % 100
+	return fmt.Sprintf("%d:%02d:%02d.%02d", h, m, sec, cs)
+}
+
+// assColor 将十进制RGB颜色转换为ASS颜色标签（白色返回空字符串）
+func assColor(rgb int) string {
+	if rgb == 0xFFFFFF {
+		return ""
+	}
+	r := (rgb >> 16) & 0xFF
+	g := (rgb >> 8) & 0xFF
+	bl := rgb & 0xFF
+	return fmt.Sprintf("\\c&H%02X%02X%02X&", bl, g, r)
+}
+
+// escapeASSText 转义ASS特殊字符
+func escapeASSText(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.ReplaceAll(text, "\\", "\\\\")
+	text = strings.ReplaceAll(text, "{", "\\{")
+	text = strings.ReplaceAll(text, "}", "\\}")
+	text = strings.ReplaceAll(text, "\r", "")
+	text = strings.ReplaceAll(text, "\n", "\\N")
+	return text
+}
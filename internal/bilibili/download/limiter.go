@@ -0,0 +1,93 @@
+package download
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/pkg/config"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+)
+
+// defaultMaxConcurrent 默认同时下载的流数量
+const defaultMaxConcurrent = 2
+
+// 所有下载服务共享的并发和带宽限制，首次下载时按配置创建
+var (
+	limitsOnce sync.Once
+	streamSem  *semaphore.Weighted
+	bandwidth  *rate.Limiter // 为nil时不限速
+)
+
+// initLimits 按配置创建并发信号量和带宽限速器
+func initLimits() {
+	limitsOnce.Do(func() {
+		maxConcurrent := defaultMaxConcurrent
+		var bytesPerSec int64
+		if cfg := config.Get(); cfg != nil {
+			if cfg.Download.MaxConcurrent > 0 {
+				maxConcurrent = cfg.Download.MaxConcurrent
+			}
+			bytesPerSec = cfg.Download.MaxBytesPerSec
+		}
+
+		streamSem = semaphore.NewWeighted(int64(maxConcurrent))
+		if bytesPerSec > 0 {
+			// 桶容量为一秒的流量，单次读取不会超过桶容量
+			bandwidth = rate.NewLimiter(rate.Limit(bytesPerSec), int(bytesPerSec))
+			logger.Infof("🚦 下载限制: 最多 %d 个并发流, 总带宽 %.2f MB/s", maxConcurrent, float64(bytesPerSec)/(1024*1024))
+		} else {
+			logger.Infof("🚦 下载限制: 最多 %d 个并发流", maxConcurrent)
+		}
+	})
+}
+
+// acquireStreamSlot 获取一个下载槽位，返回的release必须调用以释放槽位
+func acquireStreamSlot(ctx context.Context, filename string) (func(), error) {
+	initLimits()
+
+	if !streamSem.TryAcquire(1) {
+		logger.Infof("⏳ 下载并发已满，等待空闲槽位: %s", filename)
+		if err := streamSem.Acquire(ctx, 1); err != nil {
+			return nil, errors.Wrap(err, "等待下载槽位时中断")
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { streamSem.Release(1) })
+	}, nil
+}
+
+// throttledReader 按全局带宽限制读取数据的Reader（令牌桶）
+type throttledReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *rate.Limiter
+}
+
+// throttle 为reader加上全局带宽限制，未配置限速时原样返回
+func throttle(ctx context.Context, reader io.Reader) io.Reader {
+	initLimits()
+	if bandwidth == nil {
+		return reader
+	}
+	return &throttledReader{ctx: ctx, reader: reader, limiter: bandwidth}
+}
+
+// Read 实现io.Reader接口，读取后按读取的字节数消耗令牌
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if burst := r.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+	"github.com/shirenchuang/bilibili-mcp/pkg/config"
 	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
 )
 
@@ -29,6 +30,23 @@ const (
 	MediaTypeMerged MediaType = "merged" // 音视频合并
 )
 
+// ProgressFunc 下载进度回调，downloaded为当前文件已下载的字节数，total未知时小于等于0
+type ProgressFunc func(filename string, downloaded, total int64)
+
+// progressKey 在context中传递进度回调的键
+type progressKey struct{}
+
+// withProgress 将进度回调放入context，供各个下载步骤使用
+func withProgress(ctx context.Context, onProgress ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, onProgress)
+}
+
+// progressFromContext 取出context中的进度回调，没有时返回nil
+func progressFromContext(ctx context.Context) ProgressFunc {
+	onProgress, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return onProgress
+}
+
 // ProgressTracker 进度跟踪器
 type ProgressTracker struct {
 	filename   string
@@ -37,6 +55,9 @@ type ProgressTracker struct {
 	startTime  time.Time
 	lastUpdate time.Time
 	lastLogged int64
+
+	resumedFrom int64        // 续传开始时已下载的字节数，不计入速度
+	onProgress  ProgressFunc // 进度回调，与进度日志同频触发
 }
 
 // NewProgressTracker 创建进度跟踪器
@@ -63,6 +84,7 @@ func (p *ProgressTracker) Update(downloaded int64) {
 
 	if now.Sub(p.lastUpdate) >= 2*time.Second || progressPercent-lastProgressPercent >= 5 {
 		p.logProgress(downloaded, now)
+		p.report(downloaded)
 		p.lastUpdate = now
 		p.lastLogged = downloaded
 	}
@@ -73,7 +95,7 @@ func (p *ProgressTracker) logProgress(downloaded int64, now time.Time) {
 	if p.totalSize <= 0 {
 		// 未知文件大小
 		elapsed := now.Sub(p.startTime)
-		speed := float64(downloaded) / elapsed.Seconds()
+		speed := float64(downloaded-p.resumedFrom) / elapsed.Seconds()
 		logger.Infof("[下载进度] %s: 已下载 %.2f MB, 速度: %.2f MB/s, 用时: %v",
 			p.filename,
 			float64(downloaded)/(1024*1024),
@@ -83,7 +105,7 @@ func (p *ProgressTracker) logProgress(downloaded int64, now time.Time) {
 		// 已知文件大小
 		progressPercent := float64(downloaded) * 100 / float64(p.totalSize)
 		elapsed := now.Sub(p.startTime)
-		speed := float64(downloaded) / elapsed.Seconds()
+		speed := float64(downloaded-p.resumedFrom) / elapsed.Seconds()
 
 		// 预估剩余时间
 		remaining := time.Duration(0)
@@ -102,16 +124,31 @@ func (p *ProgressTracker) logProgress(downloaded int64, now time.Time) {
 	}
 }
 
+// report 触发进度回调
+func (p *ProgressTracker) report(downloaded int64) {
+	if p.onProgress != nil {
+		p.onProgress(p.filename, downloaded, p.totalSize)
+	}
+}
+
+// Resume 从已下载的字节数继续跟踪进度（断点续传）
+func (p *ProgressTracker) Resume(offset int64) {
+	atomic.StoreInt64(&p.downloaded, offset)
+	p.resumedFrom = offset
+	p.lastLogged = offset
+}
+
 // Finish 完成下载时的日志
 func (p *ProgressTracker) Finish(downloaded int64) {
 	elapsed := time.Since(p.startTime)
-	avgSpeed := float64(downloaded) / elapsed.Seconds()
+	avgSpeed := float64(downloaded-p.resumedFrom) / elapsed.Seconds()
 
 	logger.Infof("[下载完成] %s: %.2f MB, 平均速度: %.2f MB/s, 总用时: %v",
 		p.filename,
 		float64(downloaded)/(1024*1024),
 		avgSpeed/(1024*1024),
 		elapsed.Round(time.Second))
+	p.report(downloaded)
 }
 
 // ProgressReader 带进度跟踪的Reader
@@ -142,16 +179,63 @@ func (pr *ProgressReader) Read(p []byte) (int, error) {
 
 // MediaDownloadService 媒体下载服务
 type MediaDownloadService struct {
-	apiClient *api.Client
-	outputDir string
+	apiClient   *api.Client
+	outputDir   string
+	keepPartial bool   // 超时或取消时保留未完成的文件
+	platform    string // 默认请求的平台标识
+	verifyMerge bool   // 合并后校验输出文件
+
+	filenameTemplate string // 文件名模板（空=自动）
+	partNaming       bool   // 多P视频在文件名中加入分P标题
+	maxQuality       int    // 默认清晰度上限（0=不限制）
+	parallelChunks   int    // 分块并发下载的连接数（<=1表示单连接）
 }
 
 // NewMediaDownloadService 创建媒体下载服务
 func NewMediaDownloadService(apiClient *api.Client, outputDir string) *MediaDownloadService {
-	return &MediaDownloadService{
-		apiClient: apiClient,
-		outputDir: outputDir,
+	service := &MediaDownloadService{
+		apiClient:      apiClient,
+		outputDir:      outputDir,
+		platform:       "html5", // html5流没有防盗链，下载不易出现403
+		verifyMerge:    true,
+		partNaming:     true,
+		parallelChunks: defaultParallelChunks,
+	}
+	if cfg := config.Get(); cfg != nil {
+		service.keepPartial = cfg.Download.KeepPartial
+		service.verifyMerge = cfg.Download.VerifyMerge
+		service.filenameTemplate = cfg.Download.FilenameTemplate
+		service.partNaming = cfg.Download.PartNaming
+		service.parallelChunks = cfg.Download.ParallelChunks
+		if maxQuality, err := ParseMaxQuality(cfg.Download.MaxQuality); err != nil {
+			logger.Warnf("忽略无效的 download.max_quality 配置: %v", err)
+		} else {
+			service.maxQuality = maxQuality
+		}
+		if cfg.Download.Platform != "" {
+			service.platform = cfg.Download.Platform
+		}
 	}
+	return service
+}
+
+// PartialDownloadError 下载因超时或取消而中断
+type PartialDownloadError struct {
+	Path       string // 未完成文件路径（.downloading）
+	Downloaded int64  // 已下载字节数
+	Total      int64  // 文件总大小（未知时为-1）
+	Kept       bool   // 是否保留了未完成文件
+	Err        error  // 原始的context错误
+}
+
+// Error 实现error接口
+func (e *PartialDownloadError) Error() string {
+	return fmt.Sprintf("下载中断: 已下载 %d 字节: %v", e.Downloaded, e.Err)
+}
+
+// Unwrap 返回原始错误，便于判断context.DeadlineExceeded
+func (e *PartialDownloadError) Unwrap() error {
+	return e.Err
 }
 
 // QualityInfo 清晰度信息
@@ -166,12 +250,15 @@ type QualityInfo struct {
 
 // MediaDownloadResult 媒体下载结果
 type MediaDownloadResult struct {
-	VideoID     string    `json:"video_id"`     // 视频ID
-	Title       string    `json:"title"`        // 视频标题
-	MediaType   MediaType `json:"media_type"`   // 媒体类型
-	Quality     int       `json:"quality"`      // 清晰度
-	QualityDesc string    `json:"quality_desc"` // 清晰度描述
-	Duration    int       `json:"duration"`     // 时长(秒)
+	VideoID     string    `json:"video_id"`             // 视频ID
+	Title       string    `json:"title"`                // 视频标题
+	MediaType   MediaType `json:"media_type"`           // 媒体类型
+	Quality     int       `json:"quality"`              // 清晰度
+	QualityDesc string    `json:"quality_desc"`         // 清晰度描述
+	Duration    int       `json:"duration"`             // 时长(秒)
+	Page        int       `json:"page,omitempty"`       // 分P序号
+	PartTitle   string    `json:"part_title,omitempty"` // 分P标题
+	CoverURL    string    `json:"cover_url,omitempty"`  // 封面地址
 
 	// 文件路径（全路径）
 	AudioPath  string `json:"audio_path,omitempty"`  // 音频文件路径
@@ -183,6 +270,11 @@ type MediaDownloadResult struct {
 	VideoSize  int64 `json:"video_size,omitempty"`  // 视频文件大小
 	MergedSize int64 `json:"merged_size,omitempty"` // 合并文件大小
 
+	// 文件SHA256（设置 VerifyChecksum 时计算）
+	AudioSHA256  string `json:"audio_sha256,omitempty"`  // 音频文件SHA256
+	VideoSHA256  string `json:"video_sha256,omitempty"`  // 视频文件SHA256
+	MergedSHA256 string `json:"merged_sha256,omitempty"` // 合并文件SHA256
+
 	// 流信息
 	AudioURL string `json:"audio_url,omitempty"` // 音频流地址
 	VideoURL string `json:"video_url,omitempty"` // 视频流地址
@@ -192,6 +284,7 @@ type MediaDownloadResult struct {
 	AvailableQualities []QualityInfo `json:"available_qualities"` // 所有可用清晰度
 
 	// 提示信息
+	AudioQuality  string `json:"audio_quality,omitempty"` // 实际下载的音轨：default/flac/dolby
 	MergeRequired bool   `json:"merge_required"`          // 是否需要合并
 	MergeCommand  string `json:"merge_command,omitempty"` // 合并命令
 	Notes         string `json:"notes,omitempty"`         // 提示信息
@@ -202,6 +295,29 @@ type DownloadOptions struct {
 	MediaType MediaType // 媒体类型
 	Quality   int       // 清晰度 (0=自动选择最佳)
 	CID       int64     // 视频分P的CID
+	FnVal     int       // 视频流格式 (0=自动，1=仅MP4，其他值直接请求DASH)
+	Platform  string    // 平台标识 (空=使用配置，默认html5；pc需要匹配的Referer)
+	TagAudio  bool      // 仅音频下载时嵌入封面和标题/UP主元数据
+	AutoMerge bool      // 音视频分离时自动调用ffmpeg合并，成功后删除中间文件
+
+	VerifyChecksum bool // 下载完成后计算文件SHA256并写入结果
+
+	OutputFormat string // 合并输出的容器格式 (mp4/mkv/webm，空=mp4)
+
+	// 清晰度上限 (0=使用配置)，自动选择时不会超过该清晰度
+	MaxQuality int
+
+	// 文件名模板 (空=使用配置)，支持 {title}、{part_title}、{page}，视频ID和清晰度会自动追加
+	FilenameTemplate string
+
+	// 合并偏好 (nil=默认策略：标清优先MP4；true=选择无需合并的最高清晰度MP4；false=选择最高清晰度的DASH)
+	PreferNoMerge *bool
+
+	// 音质 (default/high/flac/dolby，空=default)，无损/杜比音轨不可用时回退到最佳标准音轨
+	AudioQuality string
+
+	// 下载进度回调 (可选)，与进度日志同频调用；音视频分离时每个文件分别上报
+	OnProgress ProgressFunc
 }
 
 // DownloadMedia 下载媒体文件
@@ -209,6 +325,26 @@ func (s *MediaDownloadService) DownloadMedia(ctx context.Context, videoID string
 	logger.Infof("🚀 开始下载媒体 - 视频ID: %s, 类型: %s, 清晰度: %d, CID: %d",
 		videoID, opts.MediaType, opts.Quality, opts.CID)
 
+	outputFormat, err := normalizeOutputFormat(opts.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+	if opts.AudioQuality, err = ParseAudioQuality(opts.AudioQuality); err != nil {
+		return nil, err
+	}
+
+	if opts.OnProgress != nil {
+		ctx = withProgress(ctx, opts.OnProgress)
+	}
+
+	if opts.MaxQuality == 0 {
+		opts.MaxQuality = s.maxQuality
+	}
+	if opts.MaxQuality > 0 {
+		opts.Quality = capQuality(opts.Quality, opts.MaxQuality)
+		logger.Infof("📏 清晰度上限: %s", getQualityDescription(opts.MaxQuality))
+	}
+
 	// 获取视频信息
 	logger.Infof("📋 正在获取视频信息...")
 	videoInfo, err := s.apiClient.GetVideoInfo(videoID)
@@ -217,7 +353,7 @@ func (s *MediaDownloadService) DownloadMedia(ctx context.Context, videoID string
 	}
 
 	if videoInfo.Code != 0 {
-		return nil, errors.Errorf("获取视频信息失败: %s (code: %d)", videoInfo.Message, videoInfo.Code)
+		return nil, errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "获取视频信息失败")
 	}
 
 	logger.Infof("✅ 视频信息获取成功: %s", videoInfo.Data.Title)
@@ -241,23 +377,27 @@ func (s *MediaDownloadService) DownloadMedia(ctx context.Context, videoID string
 
 	if opts.MediaType == MediaTypeMerged {
 		// 对于合并类型，优先尝试获取包含音频的完整视频
-		streamResult, err := s.getOptimalStream(videoID, cid, opts.Quality)
+		streamResult, err := s.getOptimalStream(videoID, cid, opts)
 		if err != nil {
 			return nil, errors.Wrap(err, "获取播放地址失败")
 		}
 		streamData = streamResult.StreamData
 		currentQuality = streamResult.CurrentQuality
 		availableQualities = streamResult.AvailableQualities
+
+		// DASH只有视频没有音频时，再次请求确认是无声视频而不是临时缺失
+		if streamData.DASH != nil && len(streamData.DASH.Video) > 0 && len(streamData.DASH.Audio) == 0 {
+			streamData, err = s.confirmSilentVideo(videoID, cid, streamData.Quality, opts)
+			if err != nil {
+				return nil, err
+			}
+		}
 	} else {
 		// 对于单独的音频或视频，使用DASH格式
-		playUrlResp, err := s.apiClient.GetPlayUrl(videoID)
+		streamData, err = s.getDASHStream(videoID, cid, opts)
 		if err != nil {
 			return nil, errors.Wrap(err, "获取播放地址失败")
 		}
-		if playUrlResp.Code != 0 {
-			return nil, errors.Errorf("获取播放地址失败: %s (code: %d)", playUrlResp.Message, playUrlResp.Code)
-		}
-		streamData = convertPlayUrlToStreamData(playUrlResp)
 
 		// 为单独的音频或视频创建简单的质量信息
 		currentQuality = QualityInfo{
@@ -271,12 +411,15 @@ func (s *MediaDownloadService) DownloadMedia(ctx context.Context, videoID string
 		availableQualities, _ = s.getAvailableQualities(videoID, cid)
 	}
 
+	capDASHVideo(streamData, opts.MaxQuality)
+
 	logger.Infof("✅ 播放地址获取成功")
 
 	// 创建结果对象
 	result := &MediaDownloadResult{
 		VideoID:            videoID,
 		Title:              videoInfo.Data.Title,
+		CoverURL:           NormalizeCoverURL(videoInfo.Data.Pic),
 		MediaType:          opts.MediaType,
 		Quality:            streamData.Quality,
 		QualityDesc:        getQualityDescription(streamData.Quality),
@@ -291,42 +434,63 @@ func (s *MediaDownloadService) DownloadMedia(ctx context.Context, videoID string
 		return nil, errors.Wrap(err, "创建输出目录失败")
 	}
 
-	// 清理文件名
-	cleanTitle := sanitizeFilename(videoInfo.Data.Title)
+	// 根据模板和分P标题生成文件名
+	part := findPart(videoInfo, cid)
+	if part.Total > 1 {
+		result.Page = part.Page
+		result.PartTitle = part.Title
+	}
+	template := s.filenameTemplate
+	if opts.FilenameTemplate != "" {
+		template = opts.FilenameTemplate
+	}
+	cleanTitle := buildBaseFilename(template, s.partNaming, videoInfo.Data.Title, part)
 	logger.Infof("📝 处理文件名: %s -> %s", videoInfo.Data.Title, cleanTitle)
 
 	// 根据媒体类型下载
 	logger.Infof("⬇️ 开始下载 %s 类型的媒体文件...", opts.MediaType)
 	switch opts.MediaType {
 	case MediaTypeAudio:
-		return s.downloadAudioOnly(ctx, result, streamData, cleanTitle)
+		result, err = s.downloadAudioOnly(ctx, result, streamData, cleanTitle, opts.AudioQuality)
+		if err == nil && opts.TagAudio {
+			s.tagAudio(ctx, result, videoInfo)
+		}
 	case MediaTypeVideo:
-		return s.downloadVideoOnly(ctx, result, streamData, cleanTitle)
+		result, err = s.downloadVideoOnly(ctx, result, streamData, cleanTitle)
 	case MediaTypeMerged:
-		return s.downloadMerged(ctx, result, streamData, cleanTitle)
+		result, err = s.downloadMerged(ctx, result, streamData, cleanTitle, outputFormat, opts.AutoMerge, opts.AudioQuality)
 	default:
 		return nil, errors.Errorf("不支持的媒体类型: %s", opts.MediaType)
 	}
+	if err != nil || !opts.VerifyChecksum {
+		return result, err
+	}
+
+	// 元数据写入和合并会改写文件，校验和在所有处理完成后计算
+	logger.Infof("🔐 计算文件SHA256...")
+	if err := fillChecksums(result); err != nil {
+		return result, err
+	}
+	return result, nil
 }
 
 // downloadAudioOnly 仅下载音频
-func (s *MediaDownloadService) downloadAudioOnly(ctx context.Context, result *MediaDownloadResult, streamData *VideoStreamData, cleanTitle string) (*MediaDownloadResult, error) {
-	if streamData.DASH == nil || len(streamData.DASH.Audio) == 0 {
+func (s *MediaDownloadService) downloadAudioOnly(ctx context.Context, result *MediaDownloadResult, streamData *VideoStreamData, cleanTitle, audioQuality string) (*MediaDownloadResult, error) {
+	if streamData.DASH == nil {
 		return nil, errors.New("该视频没有可用的音频流")
 	}
 
 	logger.Infof("🎵 选择最佳音频流...")
-	// 选择最佳音频流
-	bestAudio := streamData.DASH.Audio[0]
-	for _, audio := range streamData.DASH.Audio {
-		if audio.Bandwidth > bestAudio.Bandwidth {
-			bestAudio = audio
-		}
+	selection, err := selectAudioStream(streamData.DASH, audioQuality)
+	if err != nil {
+		return nil, err
 	}
-	logger.Infof("✅ 已选择音频流: 带宽 %d kbps", bestAudio.Bandwidth/1000)
+	bestAudio := selection.Stream
+	result.AudioQuality = selection.Tier
+	logger.Infof("✅ 已选择音频流: %s, 带宽 %d kbps", selection.Tier, bestAudio.Bandwidth/1000)
 
 	// 生成文件路径
-	filename := fmt.Sprintf("%s_%s_audio.m4a", cleanTitle, result.VideoID)
+	filename := fmt.Sprintf("%s_%s_audio%s.m4a", cleanTitle, result.VideoID, audioFileSuffix(selection.Tier))
 	audioPath := filepath.Join(s.outputDir, filename)
 
 	// 转换为绝对路径
@@ -342,6 +506,9 @@ func (s *MediaDownloadService) downloadAudioOnly(ctx context.Context, result *Me
 		logger.Infof("音频文件已存在: %s", absPath)
 		result.AudioSize = fileInfo.Size()
 		result.Notes = "文件已存在，跳过下载"
+		if selection.Note != "" {
+			result.Notes += "；" + selection.Note
+		}
 		return result, nil
 	}
 
@@ -353,12 +520,55 @@ func (s *MediaDownloadService) downloadAudioOnly(ctx context.Context, result *Me
 
 	result.AudioSize = fileSize
 	result.Notes = "音频下载完成"
+	if selection.Note != "" {
+		result.Notes += "；" + selection.Note
+	}
 
 	logger.Infof("音频下载完成: %s (大小: %.2f MB)", absPath, float64(fileSize)/(1024*1024))
 
 	return result, nil
 }
 
+// tagAudio 为下载的音频嵌入封面和元数据，失败时仅记录在提示信息中
+func (s *MediaDownloadService) tagAudio(ctx context.Context, result *MediaDownloadResult, videoInfo *api.VideoInfoResponse) {
+	meta := AudioMetadata{
+		Title:   videoInfo.Data.Title,
+		Artist:  videoInfo.Data.Owner.Name,
+		Comment: fmt.Sprintf("https://www.bilibili.com/video/%s", videoInfo.Data.Bvid),
+	}
+
+	coverPath := ""
+	if videoInfo.Data.Pic != "" {
+		tempCover := strings.TrimSuffix(result.AudioPath, filepath.Ext(result.AudioPath)) + "_cover.jpg"
+		if _, err := DownloadCover(ctx, videoInfo.Data.Pic, tempCover); err != nil {
+			logger.Warnf("下载封面失败，仅写入文字元数据: %v", err)
+		} else {
+			coverPath = tempCover
+			defer os.Remove(tempCover)
+		}
+	}
+
+	if err := TagAudioFile(ctx, result.AudioPath, coverPath, meta); err != nil {
+		if errors.Is(err, ErrFFmpegNotFound) {
+			logger.Warnf("未找到ffmpeg，跳过音频元数据写入")
+			result.Notes += "；未找到ffmpeg，已跳过封面和元数据写入"
+		} else {
+			logger.Warnf("写入音频元数据失败: %v", err)
+			result.Notes += fmt.Sprintf("；写入元数据失败: %v", err)
+		}
+		return
+	}
+
+	if info, err := os.Stat(result.AudioPath); err == nil {
+		result.AudioSize = info.Size()
+	}
+	if coverPath != "" {
+		result.Notes += "；已嵌入封面和元数据"
+	} else {
+		result.Notes += "；已写入元数据"
+	}
+}
+
 // downloadVideoOnly 仅下载视频
 func (s *MediaDownloadService) downloadVideoOnly(ctx context.Context, result *MediaDownloadResult, streamData *VideoStreamData, cleanTitle string) (*MediaDownloadResult, error) {
 	if streamData.DASH == nil || len(streamData.DASH.Video) == 0 {
@@ -414,10 +624,10 @@ func (s *MediaDownloadService) downloadVideoOnly(ctx context.Context, result *Me
 }
 
 // downloadMerged 下载合并的音视频文件
-func (s *MediaDownloadService) downloadMerged(ctx context.Context, result *MediaDownloadResult, streamData *VideoStreamData, cleanTitle string) (*MediaDownloadResult, error) {
+func (s *MediaDownloadService) downloadMerged(ctx context.Context, result *MediaDownloadResult, streamData *VideoStreamData, cleanTitle, outputFormat string, autoMerge bool, audioQuality string) (*MediaDownloadResult, error) {
 	// 对于DASH格式，需要分别下载音频和视频然后合并
 	if streamData.DASH != nil {
-		return s.downloadAndMerge(ctx, result, streamData, cleanTitle)
+		return s.downloadAndMerge(ctx, result, streamData, cleanTitle, outputFormat, autoMerge, audioQuality)
 	}
 
 	// 对于MP4格式，直接下载
@@ -428,20 +638,23 @@ func (s *MediaDownloadService) downloadMerged(ctx context.Context, result *Media
 	return nil, errors.New("没有可用的视频流")
 }
 
-// downloadAndMerge 下载DASH格式并提示合并
-func (s *MediaDownloadService) downloadAndMerge(ctx context.Context, result *MediaDownloadResult, streamData *VideoStreamData, cleanTitle string) (*MediaDownloadResult, error) {
-	if len(streamData.DASH.Audio) == 0 || len(streamData.DASH.Video) == 0 {
-		return nil, errors.New("该视频缺少音频或视频流")
+// downloadAndMerge 下载DASH格式音视频，开启autoMerge时使用ffmpeg合并，否则提示手动合并
+func (s *MediaDownloadService) downloadAndMerge(ctx context.Context, result *MediaDownloadResult, streamData *VideoStreamData, cleanTitle, outputFormat string, autoMerge bool, audioQuality string) (*MediaDownloadResult, error) {
+	if len(streamData.DASH.Video) == 0 {
+		return nil, errors.New("该视频缺少视频流")
+	}
+	if len(streamData.DASH.Audio) == 0 {
+		return s.downloadSilentVideo(ctx, result, streamData, cleanTitle, outputFormat)
 	}
 
 	logger.Infof("🎯 选择最佳音视频流...")
-	// 选择最佳音频流
-	bestAudio := streamData.DASH.Audio[0]
-	for _, audio := range streamData.DASH.Audio {
-		if audio.Bandwidth > bestAudio.Bandwidth {
-			bestAudio = audio
-		}
+	// 选择音频流
+	selection, err := selectAudioStream(streamData.DASH, audioQuality)
+	if err != nil {
+		return nil, err
 	}
+	bestAudio := selection.Stream
+	result.AudioQuality = selection.Tier
 
 	// 选择匹配清晰度的视频流
 	var bestVideo *api.DASHStream
@@ -459,9 +672,9 @@ func (s *MediaDownloadService) downloadAndMerge(ctx context.Context, result *Med
 		bestAudio.Bandwidth/1000, result.QualityDesc, bestVideo.Width, bestVideo.Height)
 
 	// 生成文件路径
-	audioFilename := fmt.Sprintf("%s_%s_audio.m4a", cleanTitle, result.VideoID)
+	audioFilename := fmt.Sprintf("%s_%s_audio%s.m4a", cleanTitle, result.VideoID, audioFileSuffix(selection.Tier))
 	videoFilename := fmt.Sprintf("%s_%s_video_%s.m4v", cleanTitle, result.VideoID, result.QualityDesc)
-	mergedFilename := fmt.Sprintf("%s_%s_%s.mp4", cleanTitle, result.VideoID, result.QualityDesc)
+	mergedFilename := fmt.Sprintf("%s_%s_%s.%s", cleanTitle, result.VideoID, result.QualityDesc, outputFormat)
 
 	audioPath := filepath.Join(s.outputDir, audioFilename)
 	videoPath := filepath.Join(s.outputDir, videoFilename)
@@ -483,6 +696,7 @@ func (s *MediaDownloadService) downloadAndMerge(ctx context.Context, result *Med
 	if fileInfo, err := os.Stat(absMergedPath); err == nil {
 		logger.Infof("合并文件已存在: %s", absMergedPath)
 		result.MergedSize = fileInfo.Size()
+		result.MergeRequired = false
 		result.Notes = "合并文件已存在，跳过下载"
 		return result, nil
 	}
@@ -517,9 +731,45 @@ func (s *MediaDownloadService) downloadAndMerge(ctx context.Context, result *Med
 		result.VideoSize = videoSize
 	}
 
-	// 生成合并命令
-	result.MergeCommand = fmt.Sprintf("ffmpeg -i \"%s\" -i \"%s\" -c copy \"%s\"",
-		absVideoPath, absAudioPath, absMergedPath)
+	// 根据目标容器生成合并命令，编码不兼容时需要重新编码
+	codecArgs, formatWarning := mergeCodecArgs(outputFormat, bestVideo.Codecs, bestAudio.Codecs)
+	result.MergeCommand = buildMergeCommand(absVideoPath, absAudioPath, absMergedPath, codecArgs)
+	if formatWarning != "" {
+		logger.Warnf("⚠️ %s", formatWarning)
+	}
+
+	mergeNote := ""
+	if autoMerge {
+		err := mergeAudioVideo(ctx, absVideoPath, absAudioPath, absMergedPath, codecArgs, float64(result.Duration), s.verifyMerge)
+		var mergeErr *MergeError
+		switch {
+		case err == nil:
+			if fileInfo, statErr := os.Stat(absMergedPath); statErr == nil {
+				result.MergedSize = fileInfo.Size()
+			}
+			removeIntermediates(absVideoPath, absAudioPath)
+			result.AudioPath, result.VideoPath = "", ""
+			result.AudioSize, result.VideoSize = 0, 0
+			result.MergeRequired = false
+			result.MergeCommand = ""
+			result.Notes = "音频和视频已自动合并，中间文件已删除"
+			if formatWarning != "" {
+				result.Notes += "；" + formatWarning
+			}
+			if selection.Note != "" {
+				result.Notes += "；" + selection.Note
+			}
+			return result, nil
+		case errors.Is(err, ErrFFmpegNotFound):
+			logger.Warnf("未找到ffmpeg，跳过自动合并")
+			mergeNote = "；未找到ffmpeg，无法自动合并"
+		case errors.As(err, &mergeErr):
+			logger.Warnf("自动合并失败，保留中间文件: %v", mergeErr)
+			mergeNote = fmt.Sprintf("；自动合并失败（已保留音视频文件）: %v", mergeErr)
+		default:
+			return nil, err
+		}
+	}
 
 	if audioExists && videoExists {
 		result.Notes = "音频和视频文件已存在，请使用ffmpeg合并"
@@ -530,6 +780,79 @@ func (s *MediaDownloadService) downloadAndMerge(ctx context.Context, result *Med
 	} else {
 		result.Notes = "音频和视频下载完成，请使用ffmpeg合并"
 	}
+	result.Notes += mergeNote
+	if formatWarning != "" {
+		result.Notes += "；" + formatWarning
+	}
+	if selection.Note != "" {
+		result.Notes += "；" + selection.Note
+	}
+
+	return result, nil
+}
+
+// confirmSilentVideo 重新请求播放地址，确认视频确实没有音轨。
+// 响应码正常且依然没有音频流时视为无声视频；响应异常时视为临时缺失并返回错误。
+func (s *MediaDownloadService) confirmSilentVideo(videoID string, cid int64, quality int, opts DownloadOptions) (*VideoStreamData, error) {
+	fnval := dashFnval(opts)
+
+	streamResp, err := s.apiClient.GetVideoStream(videoID, cid, quality, fnval, s.resolvePlatform(opts))
+	if err != nil {
+		return nil, errors.Wrap(err, "音频流缺失，重新获取播放地址失败")
+	}
+	if streamResp.Code != 0 || streamResp.Data == nil || streamResp.Data.DASH == nil {
+		return nil, errors.Wrap(api.NewAPIError(streamResp.Code, streamResp.Message), "音频流暂时不可用，请稍后重试")
+	}
+
+	if len(streamResp.Data.DASH.Audio) == 0 {
+		logger.Infof("🔇 确认该视频没有音轨，将仅下载视频流")
+	} else {
+		logger.Infof("✅ 重新获取到音频流")
+	}
+	return streamResp.Data, nil
+}
+
+// downloadSilentVideo 下载没有音轨的视频，DASH视频流本身即为可直接播放的MP4
+func (s *MediaDownloadService) downloadSilentVideo(ctx context.Context, result *MediaDownloadResult, streamData *VideoStreamData, cleanTitle, outputFormat string) (*MediaDownloadResult, error) {
+	bestVideo := &streamData.DASH.Video[0]
+	for i, video := range streamData.DASH.Video {
+		if video.ID == result.Quality {
+			bestVideo = &streamData.DASH.Video[i]
+			break
+		}
+	}
+
+	filename := fmt.Sprintf("%s_%s_%s.mp4", cleanTitle, result.VideoID, result.QualityDesc)
+	absPath, err := filepath.Abs(filepath.Join(s.outputDir, filename))
+	if err != nil {
+		return nil, errors.Wrap(err, "获取绝对路径失败")
+	}
+	result.MergedPath = absPath
+	result.VideoURL = bestVideo.BaseURL
+	result.MergeRequired = false
+	result.CurrentQuality.HasAudio = false
+
+	notes := "该视频没有音轨（无声视频），已直接保存视频流，无需合并"
+	if outputFormat != OutputFormatMP4 {
+		notes += fmt.Sprintf("；无声视频固定保存为mp4，忽略输出格式 %s", outputFormat)
+	}
+
+	if fileInfo, err := os.Stat(absPath); err == nil {
+		logger.Infof("无声视频文件已存在: %s", absPath)
+		result.MergedSize = fileInfo.Size()
+		result.Notes = "文件已存在，跳过下载；" + notes
+		return result, nil
+	}
+
+	fileSize, err := s.downloadStream(ctx, bestVideo.BaseURL, absPath, result.VideoID)
+	if err != nil {
+		return nil, errors.Wrap(err, "下载视频失败")
+	}
+
+	result.MergedSize = fileSize
+	result.Notes = notes
+
+	logger.Infof("无声视频下载完成: %s (大小: %.2f MB)", absPath, float64(fileSize)/(1024*1024))
 
 	return result, nil
 }
@@ -577,40 +900,69 @@ func (s *MediaDownloadService) downloadMP4(ctx context.Context, result *MediaDow
 	return result, nil
 }
 
-// downloadStream 下载流文件
+// downloadStream 下载流文件，存在未完成的 .downloading 文件时使用Range请求断点续传
 func (s *MediaDownloadService) downloadStream(ctx context.Context, streamURL, outputPath, videoID string) (int64, error) {
-	// 创建HTTP请求
-	req, err := http.NewRequestWithContext(ctx, "GET", streamURL, nil)
+	tempPath := outputPath + ".downloading"
+
+	// 全局限制同时下载的流数量，避免触发CDN限流
+	release, err := acquireStreamSlot(ctx, filepath.Base(outputPath))
 	if err != nil {
-		return 0, errors.Wrap(err, "创建请求失败")
+		return 0, err
 	}
+	defer release()
 
-	// 设置必要的请求头
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Referer", fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
-	req.Header.Set("Accept", "*/*")
-	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
-	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
-	req.Header.Set("Connection", "keep-alive")
+	// 检查是否有可续传的未完成文件
+	var offset int64
+	if info, err := os.Stat(tempPath); err == nil && info.Size() > 0 {
+		offset = info.Size()
+	}
 
-	// 发送请求
-	client := &http.Client{
-		Timeout: 30 * time.Minute, // 30分钟超时，足够下载大文件
+	// 没有未完成文件时，服务端支持Range的大文件使用分块并发下载
+	if offset == 0 && s.parallelChunks > 1 {
+		if totalSize, ok := s.probeRangeSupport(ctx, streamURL, videoID); ok && totalSize >= minChunkedSize {
+			return s.downloadChunked(ctx, streamURL, outputPath, videoID, totalSize)
+		}
 	}
 
-	resp, err := client.Do(req)
+	resp, err := s.requestStream(ctx, streamURL, videoID, offset)
 	if err != nil {
-		return 0, errors.Wrap(err, "HTTP请求失败")
+		return 0, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	switch {
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent && contentRangeStartsAt(resp.Header.Get("Content-Range"), offset):
+		logger.Infof("⏯️ 断点续传: %s (已下载 %.2f MB)", filepath.Base(outputPath), float64(offset)/(1024*1024))
+	case offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// 未完成文件与服务端文件不匹配，丢弃后重新下载
+		resp.Body.Close()
+		logger.Warnf("未完成文件无法续传，重新下载: %s", filepath.Base(outputPath))
+		os.Remove(tempPath)
+		offset = 0
+		resp, err = s.requestStream(ctx, streamURL, videoID, 0)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return 0, errors.Errorf("HTTP请求失败: %d %s", resp.StatusCode, resp.Status)
+		}
+	case resp.StatusCode == http.StatusOK:
+		// 服务端不支持Range时返回完整内容，从头开始
+		if offset > 0 {
+			logger.Warnf("服务端不支持断点续传，重新下载: %s", filepath.Base(outputPath))
+			offset = 0
+		}
+	default:
 		return 0, errors.Errorf("HTTP请求失败: %d %s", resp.StatusCode, resp.Status)
 	}
 
-	// 创建临时文件
-	tempPath := outputPath + ".downloading"
-	tempFile, err := os.Create(tempPath)
+	// 续传时追加写入，否则截断重写
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	tempFile, err := os.OpenFile(tempPath, flags, 0644)
 	if err != nil {
 		return 0, errors.Wrap(err, "创建临时文件失败")
 	}
@@ -618,29 +970,69 @@ func (s *MediaDownloadService) downloadStream(ctx context.Context, streamURL, ou
 
 	// 获取文件大小和文件名
 	contentLength := resp.ContentLength
+	totalSize := contentLength
+	if contentLength > 0 {
+		totalSize = offset + contentLength
+	}
 	filename := filepath.Base(outputPath)
 
-	// 创建进度跟踪器
-	tracker := NewProgressTracker(filename, contentLength)
+	// 创建进度跟踪器，续传时从已下载的字节数开始
+	tracker := NewProgressTracker(filename, totalSize)
+	tracker.Resume(offset)
+	tracker.onProgress = progressFromContext(ctx)
 
-	if contentLength > 0 {
-		logger.Infof("[开始下载] %s: 文件大小 %.2f MB", filename, float64(contentLength)/(1024*1024))
+	if totalSize > 0 {
+		logger.Infof("[开始下载] %s: 文件大小 %.2f MB", filename, float64(totalSize)/(1024*1024))
 	} else {
 		logger.Infof("[开始下载] %s: 文件大小未知", filename)
 	}
 
 	// 创建带进度跟踪的Reader
 	progressReader := NewProgressReader(resp.Body, tracker)
+	progressReader.total = offset
 
-	// 复制数据，同时跟踪进度
-	written, err := io.Copy(tempFile, progressReader)
+	// 复制数据，同时跟踪进度和限制带宽
+	written, err := io.Copy(tempFile, throttle(ctx, progressReader))
 	if err != nil {
-		os.Remove(tempPath)
+		tempFile.Close()
+		downloaded := offset + written
+
+		// 因超时或取消中断时返回已下载的进度
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			partial := &PartialDownloadError{
+				Path:       tempPath,
+				Downloaded: downloaded,
+				Total:      totalSize,
+				Kept:       s.keepPartial,
+				Err:        ctxErr,
+			}
+			if !s.keepPartial {
+				os.Remove(tempPath)
+			}
+			logger.Warnf("⏱️ 下载中断: %s (已下载 %.2f MB, 保留未完成文件: %v)", filename, float64(downloaded)/(1024*1024), s.keepPartial)
+			return downloaded, partial
+		}
+
+		// 网络中断时保留未完成文件，下次下载可断点续传
+		logger.Warnf("下载中断，保留未完成文件以便续传: %s (已下载 %.2f MB)", tempPath, float64(downloaded)/(1024*1024))
 		return 0, errors.Wrap(err, "下载数据失败")
 	}
 
+	// 连接提前断开时可能没有返回错误，按Content-Length检查数据是否完整
+	if contentLength > 0 && written != contentLength {
+		tempFile.Close()
+		if written > contentLength {
+			os.Remove(tempPath)
+			return 0, errors.Errorf("下载数据大小异常: 预期 %d 字节，实际收到 %d 字节", contentLength, written)
+		}
+		logger.Warnf("下载数据不完整，保留未完成文件以便续传: %s (预期 %d 字节，实际 %d 字节)", tempPath, contentLength, written)
+		return 0, errors.Errorf("下载数据不完整: 预期 %d 字节，实际收到 %d 字节，重新下载时将从断点续传", contentLength, written)
+	}
+
+	total := offset + written
+
 	// 输出完成日志
-	tracker.Finish(written)
+	tracker.Finish(total)
 
 	tempFile.Close()
 
@@ -650,7 +1042,50 @@ func (s *MediaDownloadService) downloadStream(ctx context.Context, streamURL, ou
 		return 0, errors.Wrap(err, "重命名文件失败")
 	}
 
-	return written, nil
+	return total, nil
+}
+
+// requestStream 发起流下载请求，offset大于0时携带Range头
+func (s *MediaDownloadService) requestStream(ctx context.Context, streamURL, videoID string, offset int64) (*http.Response, error) {
+	rangeHeader := ""
+	if offset > 0 {
+		rangeHeader = fmt.Sprintf("bytes=%d-", offset)
+	}
+	return s.sendStreamRequest(ctx, "GET", streamURL, videoID, rangeHeader)
+}
+
+// sendStreamRequest 发送带B站请求头的流请求，rangeHeader非空时设置Range头
+func (s *MediaDownloadService) sendStreamRequest(ctx context.Context, method, streamURL, videoID, rangeHeader string) (*http.Response, error) {
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, method, streamURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "创建请求失败")
+	}
+
+	// 设置必要的请求头
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Referer", fmt.Sprintf("https://www.bilibili.com/video/%s", videoID))
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	req.Header.Set("Connection", "keep-alive")
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+
+	// 发送请求
+	client := api.NewHTTPClient(30 * time.Minute) // 30分钟超时，足够下载大文件
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "HTTP请求失败")
+	}
+	return resp, nil
+}
+
+// contentRangeStartsAt 检查Content-Range响应头是否从指定位置开始，例如 "bytes 100-199/200"
+func contentRangeStartsAt(contentRange string, offset int64) bool {
+	return strings.HasPrefix(contentRange, fmt.Sprintf("bytes %d-", offset))
 }
 
 // StreamResult 流获取结果
@@ -661,7 +1096,9 @@ type StreamResult struct {
 }
 
 // getOptimalStream 获取最优的视频流，优先尝试包含音频的完整视频
-func (s *MediaDownloadService) getOptimalStream(videoID string, cid int64, preferredQuality int) (*StreamResult, error) {
+func (s *MediaDownloadService) getOptimalStream(videoID string, cid int64, opts DownloadOptions) (*StreamResult, error) {
+	preferredQuality := opts.Quality
+
 	logger.Infof("🎯 分析可用清晰度和最优下载策略...")
 
 	// 1. 先获取所有可用的清晰度信息
@@ -671,8 +1108,80 @@ func (s *MediaDownloadService) getOptimalStream(videoID string, cid int64, prefe
 		availableQualities = []QualityInfo{}
 	}
 
-	// 2. 尝试获取包含音频的完整视频（MP4格式）
+	// 2. 按合并偏好选择策略（显式指定fnval的预设优先）
+	preferNoMerge := opts.PreferNoMerge != nil && *opts.PreferNoMerge && opts.FnVal == 0
+	preferHighest := opts.PreferNoMerge != nil && !*opts.PreferNoMerge && opts.FnVal == 0
+
+	if preferNoMerge {
+		if result := s.tryBestMP4Stream(videoID, cid, opts, availableQualities); result != nil {
+			return result, nil
+		}
+		logger.Warnf("⚠️  该视频没有可用的MP4格式，只能下载音视频分离格式")
+	} else if !preferHighest && opts.FnVal <= 1 {
+		// 尝试获取包含音频的完整视频（MP4格式），显式指定DASH格式时跳过
+		if result := s.tryMP4Stream(videoID, cid, opts, availableQualities); result != nil {
+			return result, nil
+		}
+		if opts.FnVal == 1 {
+			return nil, errors.New("该视频没有可用的MP4格式，请尝试其他预设")
+		}
+	}
+
+	// 3. 如果没有找到MP4格式，使用DASH格式（音视频分离）
+	logger.Infof("⚠️  未找到包含音频的完整视频，使用音视频分离格式")
+
+	targetQuality := preferredQuality
+	if targetQuality == 0 {
+		targetQuality = capQuality(80, opts.MaxQuality) // 默认1080P
+		if ladder := capQualities(availableQualities, opts.MaxQuality); preferHighest && len(ladder) > 0 {
+			targetQuality = ladder[0].Quality // 可用清晰度按从高到低排列
+		}
+	}
+
+	fnval := dashFnval(opts)
+
+	streamResp, err := s.apiClient.GetVideoStream(videoID, cid, targetQuality, fnval, s.resolvePlatform(opts))
+	if err != nil {
+		// 回退到GetPlayUrl
+		return s.fallbackToPlayUrl(videoID, availableQualities)
+	}
+
+	if streamResp.Code != 0 {
+		// 回退到GetPlayUrl
+		return s.fallbackToPlayUrl(videoID, availableQualities)
+	}
+
+	// 从DASH数据中获取实际清晰度信息
+	capDASHVideo(streamResp.Data, opts.MaxQuality)
+	actualQuality := targetQuality
+	width, height := 0, 0
+	if streamResp.Data.DASH != nil && len(streamResp.Data.DASH.Video) > 0 {
+		video := streamResp.Data.DASH.Video[0]
+		actualQuality = video.ID
+		width = video.Width
+		height = video.Height
+	}
+
+	currentQuality := QualityInfo{
+		Quality:     actualQuality,
+		Description: getQualityDescription(actualQuality),
+		Width:       width,
+		Height:      height,
+		HasAudio:    false, // DASH格式音视频分离
+		Available:   true,
+	}
+
+	return &StreamResult{
+		StreamData:         streamResp.Data,
+		CurrentQuality:     currentQuality,
+		AvailableQualities: availableQualities,
+	}, nil
+}
+
+// tryMP4Stream 尝试获取包含音频的完整视频（MP4格式），找不到时返回nil
+func (s *MediaDownloadService) tryMP4Stream(videoID string, cid int64, opts DownloadOptions, availableQualities []QualityInfo) *StreamResult {
 	logger.Infof("🎯 尝试获取包含音频的完整视频...")
+	preferredQuality := opts.Quality
 
 	// 根据用户需求选择尝试的清晰度顺序
 	var qualities []int
@@ -688,7 +1197,10 @@ func (s *MediaDownloadService) getOptimalStream(videoID string, cid int64, prefe
 
 	// 尝试MP4格式
 	for _, quality := range qualities {
-		streamResp, err := s.apiClient.GetVideoStream(videoID, cid, quality, 1, "html5")
+		if opts.MaxQuality > 0 && quality > opts.MaxQuality {
+			continue
+		}
+		streamResp, err := s.apiClient.GetVideoStream(videoID, cid, quality, 1, s.resolvePlatform(opts))
 		if err != nil {
 			continue
 		}
@@ -710,53 +1222,102 @@ func (s *MediaDownloadService) getOptimalStream(videoID string, cid int64, prefe
 				StreamData:         streamResp.Data,
 				CurrentQuality:     currentQuality,
 				AvailableQualities: availableQualities,
-			}, nil
+			}
 		}
 	}
 
-	// 3. 如果没有找到MP4格式，使用DASH格式（音视频分离）
-	logger.Infof("⚠️  未找到包含音频的完整视频，使用音视频分离格式")
+	return nil
+}
 
-	targetQuality := preferredQuality
-	if targetQuality == 0 {
-		targetQuality = 80 // 默认1080P
+// tryBestMP4Stream 获取无需合并的最高清晰度MP4，找不到时返回nil
+func (s *MediaDownloadService) tryBestMP4Stream(videoID string, cid int64, opts DownloadOptions, availableQualities []QualityInfo) *StreamResult {
+	logger.Infof("🎯 查找无需合并的最高清晰度MP4...")
+
+	// MP4接口会返回不高于请求清晰度的最佳MP4，因此从最高清晰度开始请求
+	qualities := make([]int, 0, len(availableQualities)+1)
+	if opts.Quality > 0 {
+		qualities = append(qualities, opts.Quality)
+	} else {
+		for _, info := range availableQualities {
+			qualities = append(qualities, info.Quality)
+		}
+		if len(qualities) == 0 {
+			qualities = []int{80, 64, 32, 16}
+		}
 	}
 
-	streamResp, err := s.apiClient.GetVideoStream(videoID, cid, targetQuality, 16, "html5")
-	if err != nil {
-		// 回退到GetPlayUrl
-		return s.fallbackToPlayUrl(videoID, availableQualities)
+	for _, quality := range qualities {
+		if opts.MaxQuality > 0 && quality > opts.MaxQuality {
+			continue
+		}
+		streamResp, err := s.apiClient.GetVideoStream(videoID, cid, quality, 1, s.resolvePlatform(opts))
+		if err != nil || streamResp.Code != 0 || streamResp.Data == nil || len(streamResp.Data.DURL) == 0 {
+			continue
+		}
+
+		actualQuality := streamResp.Data.Quality
+		if actualQuality == 0 {
+			actualQuality = quality
+		}
+		logger.Infof("✅ 找到无需合并的MP4: %s", getQualityDescription(actualQuality))
+
+		return &StreamResult{
+			StreamData: streamResp.Data,
+			CurrentQuality: QualityInfo{
+				Quality:     actualQuality,
+				Description: getQualityDescription(actualQuality),
+				HasAudio:    true,
+				Available:   true,
+			},
+			AvailableQualities: availableQualities,
+		}
 	}
 
-	if streamResp.Code != 0 {
-		// 回退到GetPlayUrl
-		return s.fallbackToPlayUrl(videoID, availableQualities)
+	return nil
+}
+
+// getDASHStream 获取指定分P的DASH流，失败时回退到GetPlayUrl
+func (s *MediaDownloadService) getDASHStream(videoID string, cid int64, opts DownloadOptions) (*VideoStreamData, error) {
+	quality := opts.Quality
+	if quality == 0 {
+		quality = capQuality(80, opts.MaxQuality)
 	}
+	fnval := dashFnval(opts)
 
-	// 从DASH数据中获取实际清晰度信息
-	actualQuality := targetQuality
-	width, height := 0, 0
-	if streamResp.Data.DASH != nil && len(streamResp.Data.DASH.Video) > 0 {
-		video := streamResp.Data.DASH.Video[0]
-		actualQuality = video.ID
-		width = video.Width
-		height = video.Height
+	streamResp, err := s.apiClient.GetVideoStream(videoID, cid, quality, fnval, s.resolvePlatform(opts))
+	if err == nil && streamResp.Code == 0 && streamResp.Data != nil && streamResp.Data.DASH != nil {
+		return streamResp.Data, nil
 	}
 
-	currentQuality := QualityInfo{
-		Quality:     actualQuality,
-		Description: getQualityDescription(actualQuality),
-		Width:       width,
-		Height:      height,
-		HasAudio:    false, // DASH格式音视频分离
-		Available:   true,
+	logger.Warnf("获取DASH流失败，回退到GetPlayUrl: %v", err)
+	playUrlResp, err := s.apiClient.GetPlayUrl(videoID)
+	if err != nil {
+		return nil, err
 	}
+	if playUrlResp.Code != 0 {
+		return nil, api.NewAPIError(playUrlResp.Code, playUrlResp.Message)
+	}
+	return convertPlayUrlToStreamData(playUrlResp), nil
+}
 
-	return &StreamResult{
-		StreamData:         streamResp.Data,
-		CurrentQuality:     currentQuality,
-		AvailableQualities: availableQualities,
-	}, nil
+// dashFnval 获取DASH请求使用的fnval，请求无损/杜比音轨时附加杜比标识
+func dashFnval(opts DownloadOptions) int {
+	fnval := opts.FnVal
+	if fnval <= 1 {
+		fnval = 16
+	}
+	if isPremiumAudio(opts.AudioQuality) {
+		fnval |= dolbyAudioFnval
+	}
+	return fnval
+}
+
+// resolvePlatform 获取请求使用的平台标识，仅在明确指定时使用pc
+func (s *MediaDownloadService) resolvePlatform(opts DownloadOptions) string {
+	if opts.Platform != "" {
+		return opts.Platform
+	}
+	return s.platform
 }
 
 // fallbackToPlayUrl 回退到GetPlayUrl
@@ -767,7 +1328,7 @@ func (s *MediaDownloadService) fallbackToPlayUrl(videoID string, availableQualit
 		return nil, errors.Wrap(err, "获取播放地址失败")
 	}
 	if playUrlResp.Code != 0 {
-		return nil, errors.Errorf("获取播放地址失败: %s (code: %d)", playUrlResp.Message, playUrlResp.Code)
+		return nil, errors.Wrap(api.NewAPIError(playUrlResp.Code, playUrlResp.Message), "获取播放地址失败")
 	}
 
 	streamData := convertPlayUrlToStreamData(playUrlResp)
@@ -0,0 +1,125 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// testPayload 生成固定种子的随机数据
+func testPayload(size int) []byte {
+	payload := make([]byte, size)
+	rand.New(rand.NewSource(int64(size))).Read(payload)
+	return payload
+}
+
+// newTestMediaService 创建不读取全局配置的下载服务
+func newTestMediaService(t *testing.T) *MediaDownloadService {
+	t.Helper()
+	return &MediaDownloadService{outputDir: t.TempDir()}
+}
+
+func TestDownloadStreamResume(t *testing.T) {
+	payload := testPayload(64 * 1024)
+	half := len(payload) / 2
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		rangeHeader := r.Header.Get("Range")
+		if rangeHeader == "" {
+			// 声明完整长度但只发送一半数据，模拟连接中断
+			w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+			w.WriteHeader(http.StatusOK)
+			w.Write(payload[:half])
+			return
+		}
+
+		start, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+		if err != nil || start != half {
+			t.Errorf("unexpected Range header: %q", rangeHeader)
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(payload)-1, len(payload)))
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)-start))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(payload[start:])
+	}))
+	defer server.Close()
+
+	s := newTestMediaService(t)
+	outputPath := filepath.Join(s.outputDir, "video.m4s")
+
+	if _, err := s.downloadStream(context.Background(), server.URL, outputPath, "BV1test"); err == nil {
+		t.Fatal("first download should fail on truncated body")
+	}
+	partial, err := os.ReadFile(outputPath + ".downloading")
+	if err != nil {
+		t.Fatalf("partial file not kept: %v", err)
+	}
+	if !bytes.Equal(partial, payload[:len(partial)]) {
+		t.Fatal("partial file does not match payload prefix")
+	}
+
+	written, err := s.downloadStream(context.Background(), server.URL, outputPath, "BV1test")
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if written != int64(len(payload)) {
+		t.Fatalf("written = %d, want %d", written, len(payload))
+	}
+
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatal("resumed file does not match payload")
+	}
+	if _, err := os.Stat(outputPath + ".downloading"); !os.IsNotExist(err) {
+		t.Fatal("temporary file left behind")
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Fatalf("requests = %d, want 2", n)
+	}
+}
+
+func TestDownloadStreamDetectsTruncatedBody(t *testing.T) {
+	payload := testPayload(32 * 1024)
+	sent := 10 * 1024
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(payload[:sent])
+	}))
+	defer server.Close()
+
+	s := newTestMediaService(t)
+	outputPath := filepath.Join(s.outputDir, "audio.m4s")
+
+	if _, err := s.downloadStream(context.Background(), server.URL, outputPath, "BV1test"); err == nil {
+		t.Fatal("truncated body was not detected")
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Fatal("truncated download was renamed to the final file")
+	}
+
+	partial, err := os.ReadFile(outputPath + ".downloading")
+	if err != nil {
+		t.Fatalf(".downloading file not kept: %v", err)
+	}
+	if !bytes.Equal(partial, payload[:sent]) {
+		t.Fatalf("kept %d bytes, want the %d bytes received", len(partial), sent)
+	}
+}
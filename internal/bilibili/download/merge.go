@@ -0,0 +1,126 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// mergeDurationTolerance 合并结果时长与预期时长允许的误差(秒)
+const mergeDurationTolerance = 2.0
+
+// MergeError ffmpeg合并失败，中间文件会被保留以便手动处理
+type MergeError struct {
+	Stderr string // ffmpeg错误输出
+	Err    error  // 原始错误
+}
+
+// Error 实现error接口
+func (e *MergeError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("ffmpeg合并失败: %v: %s", e.Err, e.Stderr)
+	}
+	return fmt.Sprintf("ffmpeg合并失败: %v", e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e *MergeError) Unwrap() error {
+	return e.Err
+}
+
+// mergeAudioVideo 使用ffmpeg合并音视频并校验输出。
+// 仅在返回nil时调用方才可以删除中间文件；失败时会删除可能损坏的输出文件。
+func mergeAudioVideo(ctx context.Context, videoPath, audioPath, outputPath string, codecArgs []string, expectedDuration float64, verify bool) error {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return ErrFFmpegNotFound
+	}
+
+	args := []string{"-y", "-hide_banner", "-loglevel", "error", "-i", videoPath, "-i", audioPath}
+	args = append(args, codecArgs...)
+	args = append(args, outputPath)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
+	cmd.Stderr = &stderr
+
+	logger.Infof("🔧 开始合并音视频: %s", outputPath)
+	runErr := cmd.Run()
+	stderrText := strings.TrimSpace(stderr.String())
+	if stderrText != "" {
+		logger.Warnf("ffmpeg输出: %s", stderrText)
+	}
+
+	if runErr != nil {
+		os.Remove(outputPath)
+		return &MergeError{Stderr: stderrText, Err: runErr}
+	}
+
+	if verify {
+		if err := verifyMergedOutput(ctx, outputPath, expectedDuration); err != nil {
+			os.Remove(outputPath)
+			return &MergeError{Stderr: stderrText, Err: err}
+		}
+	} else if info, err := os.Stat(outputPath); err != nil || info.Size() == 0 {
+		os.Remove(outputPath)
+		return &MergeError{Stderr: stderrText, Err: errors.New("合并后的文件不存在或为空")}
+	}
+
+	logger.Infof("✅ 音视频合并完成: %s", outputPath)
+	return nil
+}
+
+// verifyMergedOutput 使用ffprobe检查合并结果可以解析且时长与预期一致，未安装ffprobe时仅检查文件非空
+func verifyMergedOutput(ctx context.Context, outputPath string, expectedDuration float64) error {
+	info, err := os.Stat(outputPath)
+	if err != nil {
+		return errors.Wrap(err, "合并后的文件不存在")
+	}
+	if info.Size() == 0 {
+		return errors.New("合并后的文件为空")
+	}
+
+	ffprobePath, err := exec.LookPath("ffprobe")
+	if err != nil {
+		logger.Warnf("未找到ffprobe，跳过合并结果时长校验")
+		return nil
+	}
+
+	output, err := exec.CommandContext(ctx, ffprobePath,
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		outputPath,
+	).Output()
+	if err != nil {
+		return errors.Wrap(err, "ffprobe无法解析合并后的文件")
+	}
+
+	duration, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return errors.Errorf("ffprobe返回了无效的时长: %q", strings.TrimSpace(string(output)))
+	}
+
+	if expectedDuration > 0 && math.Abs(duration-expectedDuration) > mergeDurationTolerance {
+		return errors.Errorf("合并后的时长 %.1f 秒与预期 %.1f 秒不一致", duration, expectedDuration)
+	}
+
+	return nil
+}
+
+// removeIntermediates 合并成功后删除中间文件
+func removeIntermediates(paths ...string) {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			logger.Warnf("删除中间文件失败: %s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+)
+
+// fakeTools 在临时目录中生成假的ffmpeg/ffprobe脚本并放到PATH最前面
+func fakeTools(t *testing.T, scripts map[string]string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg scripts require a POSIX shell")
+	}
+	dir := t.TempDir()
+	for name, body := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// failingFFmpeg 写出部分输出文件后以非零状态退出
+const failingFFmpeg = `for last; do :; done
+printf 'partial' > "$last"
+echo "Non-monotonous DTS in output stream" >&2
+exit 1
+`
+
+// workingFFmpeg 将两个输入拼接到输出文件
+const workingFFmpeg = `for last; do :; done
+cat "$6" "$8" > "$last"
+`
+
+func TestMergeAudioVideoFailureKeepsInputs(t *testing.T) {
+	fakeTools(t, map[string]string{"ffmpeg": failingFFmpeg})
+	dir := t.TempDir()
+	videoPath := filepath.Join(dir, "video.m4v")
+	audioPath := filepath.Join(dir, "audio.m4a")
+	outputPath := filepath.Join(dir, "merged.mp4")
+	os.WriteFile(videoPath, []byte("video"), 0644)
+	os.WriteFile(audioPath, []byte("audio"), 0644)
+
+	err := mergeAudioVideo(context.Background(), videoPath, audioPath, outputPath, []string{"-c", "copy"}, 10, true)
+	var mergeErr *MergeError
+	if !errors.As(err, &mergeErr) {
+		t.Fatalf("err = %v, want *MergeError", err)
+	}
+	if !strings.Contains(mergeErr.Stderr, "Non-monotonous DTS") {
+		t.Errorf("stderr not reported: %q", mergeErr.Stderr)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Error("broken merged output was not removed")
+	}
+	for _, path := range []string{videoPath, audioPath} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("intermediate %s removed: %v", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestMergeAudioVideoDurationMismatch(t *testing.T) {
+	fakeTools(t, map[string]string{
+		"ffmpeg":  workingFFmpeg,
+		"ffprobe": "echo 3.000000\n",
+	})
+	dir := t.TempDir()
+	videoPath := filepath.Join(dir, "video.m4v")
+	audioPath := filepath.Join(dir, "audio.m4a")
+	outputPath := filepath.Join(dir, "merged.mp4")
+	os.WriteFile(videoPath, []byte("video"), 0644)
+	os.WriteFile(audioPath, []byte("audio"), 0644)
+
+	err := mergeAudioVideo(context.Background(), videoPath, audioPath, outputPath, []string{"-c", "copy"}, 60, true)
+	var mergeErr *MergeError
+	if !errors.As(err, &mergeErr) {
+		t.Fatalf("err = %v, want *MergeError", err)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Error("output with wrong duration was not removed")
+	}
+
+	if err := mergeAudioVideo(context.Background(), videoPath, audioPath, outputPath, []string{"-c", "copy"}, 3, true); err != nil {
+		t.Fatalf("merge with matching duration failed: %v", err)
+	}
+}
+
+func TestDownloadAndMergeFailingMerge(t *testing.T) {
+	fakeTools(t, map[string]string{"ffmpeg": failingFFmpeg})
+	audio, video := testPayload(4096), testPayload(8192)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/audio" {
+			w.Write(audio)
+			return
+		}
+		w.Write(video)
+	}))
+	defer server.Close()
+
+	s := newTestMediaService(t)
+	s.verifyMerge = true
+	result := &MediaDownloadResult{VideoID: "BV1test", Quality: 80, QualityDesc: "1080P", Duration: 10}
+	streamData := &VideoStreamData{DASH: &api.DASHInfo{
+		Video: []api.DASHStream{{ID: 80, BaseURL: server.URL + "/video", Codecs: "avc1.640032"}},
+		Audio: []api.DASHStream{{ID: 30280, BaseURL: server.URL + "/audio", Codecs: "mp4a.40.2"}},
+	}}
+
+	result, err := s.downloadAndMerge(context.Background(), result, streamData, "title", "mp4", true, "")
+	if err != nil {
+		t.Fatalf("downloadAndMerge: %v", err)
+	}
+	if !result.MergeRequired || result.MergeCommand == "" {
+		t.Errorf("manual merge not suggested: %+v", result)
+	}
+	if !strings.Contains(result.Notes, "自动合并失败") || !strings.Contains(result.Notes, "Non-monotonous DTS") {
+		t.Errorf("notes do not report ffmpeg error: %q", result.Notes)
+	}
+	if _, err := os.Stat(result.MergedPath); !os.IsNotExist(err) {
+		t.Error("broken merged file was kept")
+	}
+
+	for path, want := range map[string][]byte{result.AudioPath: audio, result.VideoPath: video} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("intermediate %s removed: %v", filepath.Base(path), err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("intermediate %s changed", filepath.Base(path))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package download
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+)
+
+// 文件名模板变量
+const (
+	templateTitle     = "{title}"      // 视频标题
+	templatePartTitle = "{part_title}" // 分P标题
+	templatePage      = "{page}"       // 分P序号
+)
+
+// defaultPartTemplate 多P视频默认的文件名模板
+const defaultPartTemplate = "{title}_P{page}_{part_title}"
+
+// 模板中标题和分P标题的最大长度(字节)，保证整体不超过 sanitizeFilename 的长度限制时分P标题不会被截掉
+const (
+	maxTemplateTitleLen = 60
+	maxPartTitleLen     = 30
+)
+
+// partInfo 分P信息
+type partInfo struct {
+	Page  int    // 分P序号
+	Title string // 分P标题
+	Total int    // 总分P数
+}
+
+// findPart 根据CID查找分P信息，未找到时返回第一个分P
+func findPart(videoInfo *api.VideoInfoResponse, cid int64) partInfo {
+	pages := videoInfo.Data.Pages
+	if len(pages) == 0 {
+		return partInfo{Page: 1}
+	}
+	for _, page := range pages {
+		if page.Cid == cid {
+			return partInfo{Page: page.Page, Title: page.Part, Total: len(pages)}
+		}
+	}
+	return partInfo{Page: pages[0].Page, Title: pages[0].Part, Total: len(pages)}
+}
+
+// buildBaseFilename 根据模板生成文件名主体（不含视频ID和扩展名）。
+// 模板为空时，单P视频只使用标题，多P视频在开启分P命名时使用 defaultPartTemplate。
+func buildBaseFilename(template string, partNaming bool, title string, part partInfo) string {
+	if template == "" {
+		if !partNaming || part.Total <= 1 {
+			return sanitizeFilename(title)
+		}
+		template = defaultPartTemplate
+	}
+
+	partTitle := part.Title
+	if partTitle == "" || partTitle == title {
+		partTitle = fmt.Sprintf("P%d", part.Page)
+	}
+
+	name := strings.NewReplacer(
+		templateTitle, truncateUTF8(sanitizeFilename(title), maxTemplateTitleLen),
+		templatePartTitle, truncateUTF8(sanitizeFilename(partTitle), maxPartTitleLen),
+		templatePage, fmt.Sprintf("%02d", part.Page),
+	).Replace(template)
+
+	return sanitizeFilename(name)
+}
+
+// truncateUTF8 按字节截断字符串，不会截断多字节字符
+func truncateUTF8(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	for maxLen > 0 && !utf8.RuneStart(s[maxLen]) {
+		maxLen--
+	}
+	return s[:maxLen]
+}
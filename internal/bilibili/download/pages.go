@@ -0,0 +1,94 @@
+package download
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// allPagesTemplate 下载全部分P时的文件名模板，视频标题已体现在子目录名中
+const allPagesTemplate = "P{page}_{part_title}"
+
+// PageDownloadResult 单个分P的下载结果
+type PageDownloadResult struct {
+	Page      int                  `json:"page"`             // 分P序号
+	PartTitle string               `json:"part_title"`       // 分P标题
+	CID       int64                `json:"cid"`              // 分P的CID
+	Result    *MediaDownloadResult `json:"result,omitempty"` // 下载结果（失败时为空）
+	Error     string               `json:"error,omitempty"`  // 失败原因
+}
+
+// AllPagesResult 多P视频全部分P的下载结果
+type AllPagesResult struct {
+	VideoID   string               `json:"video_id"`   // 视频ID
+	Title     string               `json:"title"`      // 视频标题
+	OutputDir string               `json:"output_dir"` // 分P文件所在的子目录
+	Pages     []PageDownloadResult `json:"pages"`      // 各分P结果
+}
+
+// DownloadAllPages 依次下载视频的全部分P到以视频标题命名的子目录，已存在的文件会跳过。
+// 单个分P失败时记录错误并继续，超时或取消时停止并返回已完成的部分
+func (s *MediaDownloadService) DownloadAllPages(ctx context.Context, videoID string, opts DownloadOptions) (*AllPagesResult, error) {
+	videoInfo, err := s.apiClient.GetVideoInfo(videoID)
+	if err != nil {
+		return nil, errors.Wrap(err, "获取视频信息失败")
+	}
+	if videoInfo.Code != 0 {
+		return nil, errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "获取视频信息失败")
+	}
+	if len(videoInfo.Data.Pages) == 0 {
+		return nil, errors.New("视频没有分P信息")
+	}
+
+	outputDir, err := filepath.Abs(filepath.Join(s.outputDir, sanitizeFilename(videoInfo.Data.Title)))
+	if err != nil {
+		return nil, errors.Wrap(err, "获取绝对路径失败")
+	}
+
+	// 使用独立的服务实例写入子目录，不影响原服务的输出目录
+	pageService := *s
+	pageService.outputDir = outputDir
+	pageService.partNaming = true
+	if opts.FilenameTemplate == "" {
+		opts.FilenameTemplate = allPagesTemplate
+	}
+
+	result := &AllPagesResult{
+		VideoID:   videoID,
+		Title:     videoInfo.Data.Title,
+		OutputDir: outputDir,
+	}
+
+	total := len(videoInfo.Data.Pages)
+	for _, page := range videoInfo.Data.Pages {
+		logger.Infof("📑 下载分P %d/%d: %s", page.Page, total, page.Part)
+
+		pageOpts := opts
+		pageOpts.CID = page.Cid
+		pageResult := PageDownloadResult{
+			Page:      page.Page,
+			PartTitle: page.Part,
+			CID:       page.Cid,
+		}
+
+		media, err := pageService.DownloadMedia(ctx, videoID, pageOpts)
+		if err != nil {
+			pageResult.Error = err.Error()
+			result.Pages = append(result.Pages, pageResult)
+			if ctx.Err() != nil {
+				logger.Warnf("⏱️ 下载中断，已完成 %d/%d 个分P", len(result.Pages)-1, total)
+				return result, err
+			}
+			logger.Warnf("分P %d 下载失败，继续下一个: %v", page.Page, err)
+			continue
+		}
+
+		pageResult.Result = media
+		result.Pages = append(result.Pages, pageResult)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package download
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// qualityCaps 分辨率上限对应的最高清晰度代码，例如1080p上限包含1080P+和1080P60
+var qualityCaps = map[string]int{
+	"360p":  16,
+	"480p":  32,
+	"720p":  74,
+	"1080p": 116,
+	"4k":    120,
+	"8k":    127,
+}
+
+// ParseMaxQuality 解析清晰度上限，支持清晰度代码（如80）或分辨率（如1080p/4k），空字符串表示不限制
+func ParseMaxQuality(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, nil
+	}
+	if q, ok := qualityCaps[value]; ok {
+		return q, nil
+	}
+	if q, err := strconv.Atoi(value); err == nil && q >= 0 {
+		return q, nil
+	}
+	return 0, errors.Errorf("不支持的清晰度上限: %s，支持: 360p, 480p, 720p, 1080p, 4k, 8k 或清晰度代码", value)
+}
+
+// capQuality 将清晰度限制在上限内，maxQuality为0时不限制
+func capQuality(quality, maxQuality int) int {
+	if maxQuality > 0 && quality > maxQuality {
+		return maxQuality
+	}
+	return quality
+}
+
+// capQualities 过滤掉超过上限的清晰度，maxQuality为0时原样返回
+func capQualities(qualities []QualityInfo, maxQuality int) []QualityInfo {
+	if maxQuality <= 0 {
+		return qualities
+	}
+	capped := make([]QualityInfo, 0, len(qualities))
+	for _, info := range qualities {
+		if info.Quality <= maxQuality {
+			capped = append(capped, info)
+		}
+	}
+	return capped
+}
+
+// capDASHVideo 移除DASH中超过上限的视频流，全部超过上限时只保留最低画质的视频流
+func capDASHVideo(streamData *VideoStreamData, maxQuality int) {
+	if maxQuality <= 0 || streamData == nil || streamData.DASH == nil || len(streamData.DASH.Video) == 0 {
+		return
+	}
+
+	videos := streamData.DASH.Video[:0:0]
+	best := 0
+	for _, video := range streamData.DASH.Video {
+		if video.ID <= maxQuality {
+			videos = append(videos, video)
+			if video.ID > best {
+				best = video.ID
+			}
+		}
+	}
+	if len(videos) == 0 {
+		// 没有不超过上限的画质时退而选择最低画质（同一画质可能有多种编码）
+		best = streamData.DASH.Video[0].ID
+		for _, video := range streamData.DASH.Video {
+			if video.ID < best {
+				best = video.ID
+			}
+		}
+		for _, video := range streamData.DASH.Video {
+			if video.ID == best {
+				videos = append(videos, video)
+			}
+		}
+		maxQuality = best
+	}
+
+	streamData.DASH.Video = videos
+	if streamData.Quality > maxQuality {
+		streamData.Quality = best
+	}
+}
@@ -0,0 +1,56 @@
+package download
+
+import (
+	"testing"
+
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+)
+
+// dashStreamData 构造包含指定画质视频流的DASH数据
+func dashStreamData(quality int, ids ...int) *VideoStreamData {
+	data := &VideoStreamData{Quality: quality, DASH: &api.DASHInfo{}}
+	for _, id := range ids {
+		data.DASH.Video = append(data.DASH.Video, api.DASHStream{ID: id})
+	}
+	return data
+}
+
+// streamIDs 返回视频流的画质ID列表
+func streamIDs(data *VideoStreamData) []int {
+	ids := make([]int, 0, len(data.DASH.Video))
+	for _, video := range data.DASH.Video {
+		ids = append(ids, video.ID)
+	}
+	return ids
+}
+
+func TestCapDASHVideo(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        *VideoStreamData
+		maxQuality  int
+		wantIDs     []int
+		wantQuality int
+	}{
+		{"不限制", dashStreamData(80, 80, 64, 32), 0, []int{80, 64, 32}, 80},
+		{"移除超过上限的画质", dashStreamData(80, 80, 64, 64, 32), 64, []int{64, 64, 32}, 64},
+		{"全部超过上限时选择最低画质", dashStreamData(120, 120, 80, 112, 80), 32, []int{80, 80}, 80},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capDASHVideo(tt.data, tt.maxQuality)
+			ids := streamIDs(tt.data)
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("streams = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("streams = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+			if tt.data.Quality != tt.wantQuality {
+				t.Fatalf("quality = %d, want %d", tt.data.Quality, tt.wantQuality)
+			}
+		})
+	}
+}
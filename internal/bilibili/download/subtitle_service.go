@@ -0,0 +1,149 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/subtitles"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// ErrNoSubtitle 视频没有可用的官方字幕
+var ErrNoSubtitle = errors.New("该视频没有可用的官方字幕")
+
+// SubtitleInfo 可用字幕的语言信息
+type SubtitleInfo struct {
+	Language     string `json:"language"`      // 语言代码
+	LanguageName string `json:"language_name"` // 语言名称
+}
+
+// SubtitleDownloadService 字幕下载服务
+type SubtitleDownloadService struct {
+	apiClient *api.Client
+	outputDir string
+}
+
+// NewSubtitleDownloadService 创建字幕下载服务
+func NewSubtitleDownloadService(apiClient *api.Client, outputDir string) *SubtitleDownloadService {
+	return &SubtitleDownloadService{
+		apiClient: apiClient,
+		outputDir: outputDir,
+	}
+}
+
+// SubtitleDownloadResult 字幕下载结果
+type SubtitleDownloadResult struct {
+	VideoID      string         `json:"video_id"`      // 视频ID
+	Title        string         `json:"title"`         // 视频标题
+	Language     string         `json:"language"`      // 字幕语言代码
+	LanguageName string         `json:"language_name"` // 字幕语言名称
+	Count        int            `json:"count"`         // 字幕条数
+	FilePath     string         `json:"file_path"`     // SRT文件路径
+	FileSize     int64          `json:"file_size"`     // 文件大小(字节)
+	Text         string         `json:"text"`          // 字幕纯文本
+	Available    []SubtitleInfo `json:"available"`     // 所有可用字幕
+}
+
+// DownloadSubtitle 下载指定语言的官方字幕并保存为SRT，language为空时优先选择中文
+func (s *SubtitleDownloadService) DownloadSubtitle(ctx context.Context, videoID, language string) (*SubtitleDownloadResult, error) {
+	logger.Infof("📜 开始下载字幕 - 视频ID: %s, 语言: %s", videoID, language)
+
+	videoInfo, err := s.apiClient.GetVideoInfo(videoID)
+	if err != nil {
+		return nil, errors.Wrap(err, "获取视频信息失败")
+	}
+	if videoInfo.Code != 0 {
+		return nil, errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "获取视频信息失败")
+	}
+
+	list := videoInfo.Data.Subtitle.List
+	if len(list) == 0 {
+		return nil, ErrNoSubtitle
+	}
+
+	available := make([]SubtitleInfo, 0, len(list))
+	for _, item := range list {
+		available = append(available, SubtitleInfo{Language: item.Lan, LanguageName: item.LanDoc})
+	}
+
+	selected := -1
+	if language != "" {
+		for i, item := range list {
+			if strings.EqualFold(item.Lan, language) {
+				selected = i
+				break
+			}
+		}
+		if selected < 0 {
+			return nil, errors.Errorf("没有 %s 语言的字幕，可用语言: %s", language, formatSubtitleLanguages(available))
+		}
+	} else {
+		selected = 0
+		for i, item := range list {
+			if strings.HasPrefix(item.Lan, "zh") {
+				selected = i
+				break
+			}
+		}
+	}
+	chosen := list[selected]
+
+	content, err := s.apiClient.GetSubtitleContent(chosen.SubtitleURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "获取字幕内容失败")
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	segments := make([]subtitles.Segment, 0, len(content.Body))
+	for _, line := range content.Body {
+		segments = append(segments, subtitles.Segment{From: line.From, To: line.To, Content: line.Content})
+	}
+
+	if err := os.MkdirAll(s.outputDir, 0755); err != nil {
+		return nil, errors.Wrap(err, "创建输出目录失败")
+	}
+
+	filename := fmt.Sprintf("%s_%s_%s.srt", sanitizeFilename(videoInfo.Data.Title), videoID, chosen.Lan)
+	outputPath, err := filepath.Abs(filepath.Join(s.outputDir, filename))
+	if err != nil {
+		return nil, errors.Wrap(err, "获取绝对路径失败")
+	}
+
+	srt := subtitles.SegmentsToSRT(segments)
+	if err := os.WriteFile(outputPath, []byte(srt), 0644); err != nil {
+		return nil, errors.Wrap(err, "写入字幕文件失败")
+	}
+
+	logger.Infof("✅ 字幕下载完成: %s (%d 条)", outputPath, len(segments))
+
+	return &SubtitleDownloadResult{
+		VideoID:      videoID,
+		Title:        videoInfo.Data.Title,
+		Language:     chosen.Lan,
+		LanguageName: chosen.LanDoc,
+		Count:        len(segments),
+		FilePath:     outputPath,
+		FileSize:     int64(len(srt)),
+		Text:         subtitles.ToPlainText(segments),
+		Available:    available,
+	}, nil
+}
+
+// formatSubtitleLanguages 格式化可用字幕语言列表
+func formatSubtitleLanguages(available []SubtitleInfo) string {
+	parts := make([]string, 0, len(available))
+	for _, info := range available {
+		parts = append(parts, fmt.Sprintf("%s(%s)", info.Language, info.LanguageName))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,66 @@
+package download
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// ErrFFmpegNotFound 系统中未安装ffmpeg
+var ErrFFmpegNotFound = errors.New("未找到ffmpeg，请先安装ffmpeg")
+
+// AudioMetadata 写入音频文件的元数据
+type AudioMetadata struct {
+	Title   string // 标题
+	Artist  string // 艺术家（UP主昵称）
+	Comment string // 备注（视频地址）
+}
+
+// TagAudioFile 使用ffmpeg为音频嵌入封面和元数据，coverPath为空时仅写入元数据
+func TagAudioFile(ctx context.Context, audioPath, coverPath string, meta AudioMetadata) error {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return ErrFFmpegNotFound
+	}
+
+	// 输出到同目录的临时文件，成功后替换原文件
+	ext := filepath.Ext(audioPath)
+	tempPath := strings.TrimSuffix(audioPath, ext) + ".tagging" + ext
+
+	args := []string{"-y", "-hide_banner", "-loglevel", "error", "-i", audioPath}
+	if coverPath != "" {
+		args = append(args, "-i", coverPath, "-map", "0:a", "-map", "1:v", "-disposition:v:0", "attached_pic")
+	} else {
+		args = append(args, "-map", "0:a")
+	}
+	args = append(args, "-c", "copy")
+	if meta.Title != "" {
+		args = append(args, "-metadata", "title="+meta.Title)
+	}
+	if meta.Artist != "" {
+		args = append(args, "-metadata", "artist="+meta.Artist, "-metadata", "album_artist="+meta.Artist)
+	}
+	if meta.Comment != "" {
+		args = append(args, "-metadata", "comment="+meta.Comment)
+	}
+	args = append(args, tempPath)
+
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(tempPath)
+		return errors.Wrapf(err, "ffmpeg写入元数据失败: %s", strings.TrimSpace(string(output)))
+	}
+
+	if err := os.Rename(tempPath, audioPath); err != nil {
+		os.Remove(tempPath)
+		return errors.Wrap(err, "替换音频文件失败")
+	}
+
+	logger.Infof("🏷️ 已写入音频元数据: %s", filepath.Base(audioPath))
+	return nil
+}
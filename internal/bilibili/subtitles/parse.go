@@ -0,0 +1,92 @@
+package subtitles
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseSRT 解析SRT字幕，也兼容WebVTT（时间戳用"."分隔毫秒、可省略小时、带WEBVTT头和cue设置）。
+// 没有时间轴行的块（如VTT的NOTE、STYLE）和时间轴无法解析的块会被跳过，
+// 只有所有带时间轴的块都无法解析时才返回错误
+func ParseSRT(content string) ([]Segment, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.TrimPrefix(content, "\ufeff") // 去掉UTF-8 BOM
+
+	var segments []Segment
+	var firstErr error
+	for _, block := range strings.Split(content, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+
+		// 找到时间轴行，之前的是序号或cue标识，之后的是字幕文本
+		timing := -1
+		for i, line := range lines {
+			if strings.Contains(line, "-->") {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 {
+			continue
+		}
+
+		from, to, err := parseTimingLine(lines[timing])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		text := make([]string, 0, len(lines)-timing-1)
+		for _, line := range lines[timing+1:] {
+			if line = strings.TrimSpace(line); line != "" {
+				text = append(text, line)
+			}
+		}
+		segments = append(segments, Segment{From: from, To: to, Content: strings.Join(text, "\n")})
+	}
+	if len(segments) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return segments, nil
+}
+
+// parseTimingLine 解析 "00:00:01,000 --> 00:00:02,500" 形式的时间轴行，忽略VTT的cue设置
+func parseTimingLine(line string) (float64, float64, error) {
+	parts := strings.SplitN(line, "-->", 2)
+	end := strings.Fields(parts[1])
+	if len(end) == 0 {
+		return 0, 0, errors.Errorf("无效的字幕时间轴: %q", line)
+	}
+
+	from, err := ParseTimestamp(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := ParseTimestamp(end[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	return from, to, nil
+}
+
+// ParseTimestamp 解析 hh:mm:ss,mmm、hh:mm:ss.mmm 或 mm:ss.mmm 形式的时间戳，返回秒数
+func ParseTimestamp(timestamp string) (float64, error) {
+	timestamp = strings.TrimSpace(timestamp)
+	fields := strings.Split(strings.Replace(timestamp, ",", ".", 1), ":")
+	if len(fields) < 2 || len(fields) > 3 {
+		return 0, errors.Errorf("无效的字幕时间戳: %q", timestamp)
+	}
+
+	var seconds float64
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil || value < 0 || (i == len(fields)-1 && strings.ContainsAny(field, "eE")) {
+			return 0, errors.Errorf("无效的字幕时间戳: %q", timestamp)
+		}
+		seconds = seconds*60 + value
+	}
+	return seconds, nil
+}
@@ -0,0 +1,114 @@
+package subtitles
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+var testSegments = []Segment{
+	{From: 0, To: 1.5, Content: "第一句"},
+	{From: 1.5, To: 4.25, Content: "第二句\n两行"},
+	{From: 61.001, To: 3725.999, Content: "跨越小时"},
+}
+
+// assertSegments 比较片段，时间按毫秒精度比较
+func assertSegments(t *testing.T, got, want []Segment) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d segments, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if math.Abs(got[i].From-want[i].From) > 0.0005 || math.Abs(got[i].To-want[i].To) > 0.0005 || got[i].Content != want[i].Content {
+			t.Errorf("segment %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSRTRoundTrip(t *testing.T) {
+	segments, err := ParseSRT(SegmentsToSRT(testSegments))
+	if err != nil {
+		t.Fatalf("ParseSRT: %v", err)
+	}
+	assertSegments(t, segments, testSegments)
+}
+
+func TestVTTRoundTrip(t *testing.T) {
+	vtt := ToVTT(testSegments)
+	if !strings.HasPrefix(vtt, "WEBVTT\n\n") {
+		t.Fatalf("missing WEBVTT header: %q", vtt)
+	}
+	segments, err := ParseSRT(vtt)
+	if err != nil {
+		t.Fatalf("ParseSRT: %v", err)
+	}
+	assertSegments(t, segments, testSegments)
+}
+
+func TestSRTToVTTToSRT(t *testing.T) {
+	srt := SegmentsToSRT(testSegments)
+	fromSRT, err := ParseSRT(srt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromVTT, err := ParseSRT(ToVTT(fromSRT))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := SegmentsToSRT(fromVTT); got != srt {
+		t.Fatalf("SRT changed after VTT round trip:\n%s\nwant:\n%s", got, srt)
+	}
+}
+
+func TestParseSRTFormats(t *testing.T) {
+	content := "\ufeffWEBVTT\r\n\r\nNOTE 注释块\r\n\r\ncue-1\r\n00:01.000 --> 00:02.500 align:start position:10%\r\n你好\r\n\r\n2\r\n00:00:03,000 --> 00:00:04,000\r\n世界\r\n"
+	segments, err := ParseSRT(content)
+	if err != nil {
+		t.Fatalf("ParseSRT: %v", err)
+	}
+	assertSegments(t, segments, []Segment{
+		{From: 1, To: 2.5, Content: "你好"},
+		{From: 3, To: 4, Content: "世界"},
+	})
+}
+
+func TestParseSRTSkipsMalformedBlocks(t *testing.T) {
+	content := "1\n00:00:01,000 --> 00:00:02,000\n好的\n\n2\n00:00:xx,000 --> 00:00:03,000\n坏的\n\n3\n00:00:04,000 --> 00:00:05,000\n也好\n"
+	segments, err := ParseSRT(content)
+	if err != nil {
+		t.Fatalf("ParseSRT: %v", err)
+	}
+	assertSegments(t, segments, []Segment{
+		{From: 1, To: 2, Content: "好的"},
+		{From: 4, To: 5, Content: "也好"},
+	})
+
+	if _, err := ParseSRT("1\nbad --> worse\n文本\n"); err == nil {
+		t.Fatal("expected error when no block can be parsed")
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"00:00:01,000", 1},
+		{"00:00:01.250", 1.25},
+		{"01:02:03,004", 3723.004},
+		{"02:03.500", 123.5},
+		{" 00:00:00,000 ", 0},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimestamp(tt.input)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ParseTimestamp(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "12", "1:2:3:4", "aa:bb", "00:-01.000", "00:01e3"} {
+		if got, err := ParseTimestamp(input); err == nil {
+			t.Errorf("ParseTimestamp(%q) = %v, want error", input, got)
+		}
+	}
+}
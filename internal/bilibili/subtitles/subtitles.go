@@ -0,0 +1,67 @@
+package subtitles
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Segment 带时间轴的字幕片段
+type Segment struct {
+	From    float64 `json:"from"`    // 起始时间(秒)
+	To      float64 `json:"to"`      // 结束时间(秒)
+	Content string  `json:"content"` // 字幕文本
+}
+
+// SegmentsToSRT 将字幕片段转换为SRT格式
+func SegmentsToSRT(segments []Segment) string {
+	var b strings.Builder
+	for i, seg := range segments {
+		b.WriteString(fmt.Sprintf("%d\n", i+1))
+		b.WriteString(fmt.Sprintf("%s --> %s\n", FormatSRTTime(seg.From), FormatSRTTime(seg.To)))
+		b.WriteString(strings.TrimSpace(seg.Content))
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+// ToVTT 将字幕片段转换为WebVTT格式
+func ToVTT(segments []Segment) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, seg := range segments {
+		b.WriteString(fmt.Sprintf("%s --> %s\n", FormatVTTTime(seg.From), FormatVTTTime(seg.To)))
+		b.WriteString(strings.TrimSpace(seg.Content))
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+// ToPlainText 提取字幕片段的纯文本，每个片段一行
+func ToPlainText(segments []Segment) string {
+	lines := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		if text := strings.TrimSpace(seg.Content); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatSRTTime 格式化为SRT时间戳 hh:mm:ss,mmm
+func FormatSRTTime(seconds float64) string {
+	return formatTime(seconds, ',')
+}
+
+// FormatVTTTime 格式化为WebVTT时间戳 hh:mm:ss.mmm
+func FormatVTTTime(seconds float64) string {
+	return formatTime(seconds, '.')
+}
+
+// formatTime 格式化为 hh:mm:ss<sep>mmm，毫秒四舍五入
+func formatTime(seconds float64, sep byte) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	ms := int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, (ms/60000)%60, (ms/1000)%60, sep, ms%1000)
+}
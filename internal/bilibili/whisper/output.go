@@ -0,0 +1,103 @@
+package whisper
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// 转录输出格式
+const (
+	OutputFormatSRT  = "srt"
+	OutputFormatJSON = "json"
+	OutputFormatVTT  = "vtt"
+	OutputFormatTXT  = "txt"
+)
+
+// outputFormatFlags 各输出格式对应的whisper-cli参数
+var outputFormatFlags = map[string]string{
+	OutputFormatSRT:  "-osrt",
+	OutputFormatJSON: "-oj",
+	OutputFormatVTT:  "-ovtt",
+	OutputFormatTXT:  "-otxt",
+}
+
+// Segment 带时间轴的转录片段，时间单位为秒
+type Segment struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+// NormalizeOutputFormat 校验输出格式，空值使用默认的SRT
+func NormalizeOutputFormat(format string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		return OutputFormatSRT, nil
+	}
+	if _, ok := outputFormatFlags[format]; !ok {
+		return "", errors.Errorf("不支持的输出格式: %s，支持: srt, json, vtt, txt", format)
+	}
+	return format, nil
+}
+
+// whisperJSONOutput whisper-cli -oj 输出的JSON结构（只解析需要的字段）
+type whisperJSONOutput struct {
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"` // 毫秒
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text string `json:"text"`
+	} `json:"transcription"`
+}
+
+// readTranscript 读取whisper生成的输出文件，返回纯文本，JSON格式同时返回时间轴片段
+func (s *Service) readTranscript(outputFile, format string) (string, []Segment, error) {
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "读取%s文件失败", strings.ToUpper(format))
+	}
+
+	switch format {
+	case OutputFormatJSON:
+		segments, err := parseJSONSegments(content)
+		if err != nil {
+			return "", nil, err
+		}
+		texts := make([]string, 0, len(segments))
+		for _, segment := range segments {
+			texts = append(texts, segment.Text)
+		}
+		return strings.Join(texts, " "), segments, nil
+	case OutputFormatTXT:
+		return strings.Join(strings.Fields(string(content)), " "), nil, nil
+	default:
+		// SRT和VTT都按时间轴块解析
+		return s.extractTextFromSRT(string(content)), nil, nil
+	}
+}
+
+// parseJSONSegments 解析whisper-cli输出的JSON为时间轴片段
+func parseJSONSegments(content []byte) ([]Segment, error) {
+	var output whisperJSONOutput
+	if err := json.Unmarshal(content, &output); err != nil {
+		return nil, errors.Wrap(err, "解析JSON转录结果失败")
+	}
+
+	segments := make([]Segment, 0, len(output.Transcription))
+	for _, item := range output.Transcription {
+		text := strings.TrimSpace(item.Text)
+		if text == "" {
+			continue
+		}
+		segments = append(segments, Segment{
+			Start: float64(item.Offsets.From) / 1000,
+			End:   float64(item.Offsets.To) / 1000,
+			Text:  text,
+		})
+	}
+	return segments, nil
+}
@@ -0,0 +1,36 @@
+package whisper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputBasePath(t *testing.T) {
+	audio := filepath.Join("downloads", "标题_BV1xx411c7mQ_audio.m4a")
+	transcribe := outputBasePath(audio, TaskTranscribe)
+	translate := outputBasePath(audio, TaskTranslate)
+
+	if want := filepath.Join("downloads", "标题_BV1xx411c7mQ_audio"); transcribe != want {
+		t.Fatalf("transcribe output = %q, want %q", transcribe, want)
+	}
+	if want := filepath.Join("downloads", "标题_BV1xx411c7mQ_audio.en"); translate != want {
+		t.Fatalf("translate output = %q, want %q", translate, want)
+	}
+}
+
+func TestReadTranscriptSkipsMalformedBlocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audio.srt")
+	content := "1\n00:00:01,000 --> 00:00:02,000\n你好\n\n2\n00:00:0x,000 --> 00:00:03,000\n损坏\n\n3\n00:00:04,000 --> 00:00:05,000\n世界\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text, _, err := (&Service{}).readTranscript(path, OutputFormatSRT)
+	if err != nil {
+		t.Fatalf("readTranscript: %v", err)
+	}
+	if text != "你好\n世界" {
+		t.Fatalf("text = %q, want the two valid blocks", text)
+	}
+}
@@ -6,12 +6,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/subtitles"
 	"github.com/shirenchuang/bilibili-mcp/pkg/config"
 	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
 )
@@ -27,7 +29,13 @@ type Service struct {
 type TranscribeResult struct {
 	AudioPath        string      `json:"audio_path"`
 	OutputPath       string      `json:"output_path"`
+	WAVPath          string      `json:"wav_path,omitempty"` // 保留的WAV中间文件（仅 keep_wav 开启时）
 	Text             string      `json:"text"`
+	OutputFormat     string      `json:"output_format"`
+	Task             string      `json:"task"`                     // transcribe=原语言转录，translate=翻译为英文
+	InitialPrompt    string      `json:"initial_prompt,omitempty"` // 使用的初始提示词
+	Temperature      float64     `json:"temperature"`              // 使用的采样温度
+	Segments         []Segment   `json:"segments,omitempty"`       // 仅JSON输出格式时解析
 	Duration         float64     `json:"duration"`
 	Model            string      `json:"model"`
 	Language         string      `json:"language"`
@@ -37,6 +45,55 @@ type TranscribeResult struct {
 	AvailableModels  []ModelInfo `json:"available_models"`
 }
 
+// InterruptedError 转录因调用方取消或超过超时时间而中断，与whisper执行失败区分
+type InterruptedError struct {
+	Timeout time.Duration // 超过配置的转录超时时间时为该时间，调用方取消或超时时为0
+	Err     error         // 原始的context错误
+}
+
+// Error 实现error接口
+func (e *InterruptedError) Error() string {
+	switch {
+	case e.Timeout > 0:
+		return fmt.Sprintf("转录超时：超过 %v 仍未完成，已清理中间文件", e.Timeout)
+	case errors.Is(e.Err, context.DeadlineExceeded):
+		return "转录超时：超过工具调用的时间限制，已清理中间文件"
+	default:
+		return "转录已取消，已清理中间文件"
+	}
+}
+
+// Unwrap 返回原始的context错误
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// IsInterrupted 判断错误是否为转录被取消或超时
+func IsInterrupted(err error) bool {
+	var interrupted *InterruptedError
+	return errors.As(err, &interrupted)
+}
+
+// detectLanguageSeconds 语言检测截取的音频时长（秒）
+const detectLanguageSeconds = 30
+
+// detectedLanguagePattern 匹配whisper-cli输出的语言检测结果，如 "auto-detected language: en (p = 0.987)"
+var detectedLanguagePattern = regexp.MustCompile(`auto-detected language:\s*(\S+)\s*\(p\s*=\s*([0-9.]+)\)`)
+
+// 转录任务
+const (
+	TaskTranscribe = "transcribe" // 按原语言转录
+	TaskTranslate  = "translate"  // 翻译为英文
+)
+
+// TranscribeOptions 单次转录的选项，零值使用配置中的默认值
+type TranscribeOptions struct {
+	OutputFormat  string   // 输出格式：srt/json/vtt/txt
+	Task          string   // 转录任务：transcribe/translate
+	InitialPrompt string   // 初始提示词，提供人名、术语等提高识别准确率
+	Temperature   *float64 // 采样温度（0-1），nil使用配置
+}
+
 // ModelInfo 模型信息
 type ModelInfo struct {
 	Name        string `json:"name"`
@@ -106,25 +163,84 @@ func (s *Service) findWhisperCLI() error {
 	return errors.New("未找到whisper-cli，请先运行 ./bilibili-whisper-init 进行初始化")
 }
 
-// TranscribeAudio 转录音频文件
-func (s *Service) TranscribeAudio(ctx context.Context, audioPath string) (*TranscribeResult, error) {
+// translateOutputSuffix 翻译任务输出文件名的后缀，避免覆盖同一音频的原语言转录结果
+const translateOutputSuffix = ".en"
+
+// outputBasePath 返回不含扩展名的输出文件路径：与音频文件同目录同名，翻译任务额外带上 .en 后缀
+func outputBasePath(audioPath, task string) string {
+	base := strings.TrimSuffix(audioPath, filepath.Ext(audioPath))
+	if task == TaskTranslate {
+		base += translateOutputSuffix
+	}
+	return base
+}
+
+// TranscribeAudio 转录音频文件，输出文件写在音频文件旁边
+func (s *Service) TranscribeAudio(ctx context.Context, audioPath string, opts TranscribeOptions) (*TranscribeResult, error) {
 	startTime := time.Now()
 
+	if opts.OutputFormat == "" {
+		opts.OutputFormat = s.config.OutputFormat
+	}
+	outputFormat, err := NormalizeOutputFormat(opts.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+	opts.OutputFormat = outputFormat
+
+	if opts.Task == "" {
+		opts.Task = s.config.Task
+	}
+	switch opts.Task {
+	case "", TaskTranscribe:
+		opts.Task = TaskTranscribe
+	case TaskTranslate:
+	default:
+		return nil, errors.Errorf("不支持的转录任务: %s，支持: transcribe, translate", opts.Task)
+	}
+
+	if opts.InitialPrompt == "" {
+		opts.InitialPrompt = s.config.InitialPrompt
+	}
+	if opts.Temperature == nil {
+		temperature := s.config.Temperature
+		opts.Temperature = &temperature
+	}
+	if *opts.Temperature < 0 || *opts.Temperature > 1 {
+		return nil, errors.Errorf("temperature 必须在0-1之间（当前: %g）", *opts.Temperature)
+	}
+
 	// 验证音频文件存在
 	if _, err := os.Stat(audioPath); err != nil {
 		return nil, errors.Wrap(err, "音频文件不存在")
 	}
 
 	// 转换为WAV格式（如果需要）
-	wavPath, err := s.ensureWAVFormat(audioPath)
+	wavPath, err := s.ensureWAVFormat(ctx, audioPath)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, &InterruptedError{Err: ctx.Err()}
+		}
 		return nil, errors.Wrap(err, "音频格式转换失败")
 	}
 
+	// 仅清理转换出的WAV中间文件，原始音频永远不会被删除；
+	// 开启 keep_wav 时只在转录成功后保留
+	keptWAVPath := ""
+	succeeded := false
+	if wavPath != audioPath {
+		if s.config.KeepWAV {
+			keptWAVPath = wavPath
+		}
+		defer func() {
+			if !succeeded || !s.config.KeepWAV {
+				s.removeIntermediateWAV(wavPath)
+			}
+		}()
+	}
+
 	// 准备输出路径
-	outputDir := filepath.Dir(audioPath)
-	outputBase := strings.TrimSuffix(filepath.Base(audioPath), filepath.Ext(audioPath))
-	outputPath := filepath.Join(outputDir, outputBase)
+	outputPath := outputBasePath(audioPath, opts.Task)
 
 	// 确定使用的模型
 	modelPath, modelName, err := s.getModelPath()
@@ -134,14 +250,25 @@ func (s *Service) TranscribeAudio(ctx context.Context, audioPath string) (*Trans
 
 	// 检测加速类型
 	accelerationType := s.detectAccelerationType(modelPath)
-	logger.Infof("开始转录音频: %s, 模型: %s, 加速: %s", audioPath, modelName, accelerationType)
+	logger.Infof("开始转录音频: %s, 模型: %s, 加速: %s, 任务: %s", audioPath, modelName, accelerationType, opts.Task)
 
 	// 执行转录
-	text, err := s.executeWhisper(ctx, wavPath, modelPath, outputPath)
-	if err != nil {
+	outputFile := outputPath + "." + opts.OutputFormat
+	if err := s.executeWhisper(ctx, wavPath, modelPath, outputPath, opts); err != nil {
+		removePartialOutput(outputFile, startTime)
+		if IsInterrupted(err) {
+			logger.Warnf("⏹️ %v: %s", err, audioPath)
+			return nil, err
+		}
 		return nil, errors.Wrap(err, "转录执行失败")
 	}
 
+	text, segments, err := s.readTranscript(outputFile, opts.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("📄 转录完成，提取文本长度: %d 字符", len(text))
+
 	// 计算处理时间
 	processTime := time.Since(startTime).Seconds()
 
@@ -151,8 +278,14 @@ func (s *Service) TranscribeAudio(ctx context.Context, audioPath string) (*Trans
 
 	result := &TranscribeResult{
 		AudioPath:        audioPath,
-		OutputPath:       outputPath + ".srt",
+		OutputPath:       outputFile,
+		WAVPath:          keptWAVPath,
 		Text:             text,
+		OutputFormat:     opts.OutputFormat,
+		Task:             opts.Task,
+		InitialPrompt:    opts.InitialPrompt,
+		Temperature:      *opts.Temperature,
+		Segments:         segments,
 		Model:            modelName,
 		Language:         s.config.Language,
 		AccelerationType: accelerationType,
@@ -161,12 +294,97 @@ func (s *Service) TranscribeAudio(ctx context.Context, audioPath string) (*Trans
 		AvailableModels:  availableModels,
 	}
 
+	succeeded = true
 	logger.Infof("转录完成: %s, 耗时: %.2fs", audioPath, processTime)
 	return result, nil
 }
 
+// removePartialOutput 删除本次转录中途失败时生成的输出文件，转录开始前已存在且未被改写的文件保留
+func removePartialOutput(outputFile string, startTime time.Time) {
+	info, err := os.Stat(outputFile)
+	if err != nil || info.ModTime().Before(startTime) {
+		return
+	}
+	if err := os.Remove(outputFile); err != nil {
+		logger.Warnf("删除未完成的转录输出失败: %s: %v", outputFile, err)
+		return
+	}
+	logger.Debugf("已删除未完成的转录输出: %s", outputFile)
+}
+
+// removeIntermediateWAV 删除转录用的WAV中间文件
+func (s *Service) removeIntermediateWAV(wavPath string) {
+	if err := os.Remove(wavPath); err != nil && !os.IsNotExist(err) {
+		logger.Warnf("删除WAV中间文件失败: %s: %v", wavPath, err)
+		return
+	}
+	logger.Debugf("已删除WAV中间文件: %s", wavPath)
+}
+
+// DetectLanguage 检测音频的语言，只截取开头30秒识别以保证速度。
+// 返回whisper识别出的语言代码和置信度
+func (s *Service) DetectLanguage(ctx context.Context, audioPath string) (string, float64, error) {
+	if _, err := os.Stat(audioPath); err != nil {
+		return "", 0, errors.Wrap(err, "音频文件不存在")
+	}
+
+	// 截取开头片段，原始WAV也需要截取，避免检测整段长音频
+	clipPath := strings.TrimSuffix(audioPath, filepath.Ext(audioPath)) + ".detect.wav"
+	if err := s.convertToWAV(ctx, audioPath, clipPath, detectLanguageSeconds); err != nil {
+		return "", 0, errors.Wrap(err, "音频格式转换失败")
+	}
+	defer s.removeIntermediateWAV(clipPath)
+
+	modelPath, modelName, err := s.getModelPath()
+	if err != nil {
+		return "", 0, errors.Wrap(err, "获取模型路径失败")
+	}
+
+	args := []string{
+		"-f", clipPath,
+		"-m", modelPath,
+		"-l", "auto",
+		"-dl", // 仅检测语言
+	}
+	if s.detectAccelerationType(modelPath) == "CPU" {
+		args = append(args, "-ng")
+		if s.config.CPUThreads > 0 {
+			args = append(args, "-t", strconv.Itoa(s.config.CPUThreads))
+		}
+	}
+
+	logger.Infof("🔍 检测音频语言: %s, 模型: %s", audioPath, modelName)
+	output, err := exec.CommandContext(ctx, s.whisperCLIPath, args...).CombinedOutput()
+	if err != nil {
+		logger.Errorf("📝 详细输出: %s", string(output))
+		return "", 0, errors.Wrap(err, "语言检测执行失败")
+	}
+
+	lang, confidence, err := parseDetectedLanguage(string(output))
+	if err != nil {
+		return "", 0, err
+	}
+
+	logger.Infof("✅ 检测到语言: %s (置信度: %.2f)", lang, confidence)
+	return lang, confidence, nil
+}
+
+// parseDetectedLanguage 从whisper-cli输出中解析检测到的语言和置信度
+func parseDetectedLanguage(output string) (string, float64, error) {
+	match := detectedLanguagePattern.FindStringSubmatch(output)
+	if match == nil {
+		return "", 0, errors.New("未能从whisper输出中解析出语言")
+	}
+
+	confidence, err := strconv.ParseFloat(match[2], 64)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "解析语言置信度失败")
+	}
+	return match[1], confidence, nil
+}
+
 // ensureWAVFormat 确保音频为WAV格式
-func (s *Service) ensureWAVFormat(audioPath string) (string, error) {
+func (s *Service) ensureWAVFormat(ctx context.Context, audioPath string) (string, error) {
 	ext := strings.ToLower(filepath.Ext(audioPath))
 	if ext == ".wav" {
 		return audioPath, nil
@@ -174,29 +392,46 @@ func (s *Service) ensureWAVFormat(audioPath string) (string, error) {
 
 	// 需要转换为WAV
 	wavPath := strings.TrimSuffix(audioPath, filepath.Ext(audioPath)) + ".wav"
+	if err := s.convertToWAV(ctx, audioPath, wavPath, 0); err != nil {
+		return "", err
+	}
+	return wavPath, nil
+}
 
+// convertToWAV 使用ffmpeg将音频转换为whisper需要的16kHz单声道WAV，maxSeconds大于0时只保留开头片段。
+// 转换失败或被取消时删除未完成的WAV文件
+func (s *Service) convertToWAV(ctx context.Context, audioPath, wavPath string, maxSeconds int) error {
 	logger.Infof("转换音频格式: %s -> %s", audioPath, wavPath)
 
-	cmd := exec.Command("ffmpeg",
+	args := []string{
 		"-y", // 覆盖输出文件
 		"-i", audioPath,
+	}
+	if maxSeconds > 0 {
+		args = append(args, "-t", strconv.Itoa(maxSeconds))
+	}
+	args = append(args,
 		"-ar", "16000", // 采样率16kHz
 		"-ac", "1", // 单声道
 		"-c:a", "pcm_s16le", // 16位PCM编码
 		"-hide_banner", // 隐藏版本信息
 		wavPath,
 	)
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
 
 	if err := cmd.Run(); err != nil {
-		return "", errors.Wrap(err, "ffmpeg转换失败")
+		if wavPath != audioPath {
+			s.removeIntermediateWAV(wavPath)
+		}
+		return errors.Wrap(err, "ffmpeg转换失败")
 	}
 
 	// 验证转换结果
 	if _, err := os.Stat(wavPath); err != nil {
-		return "", errors.New("转换后的WAV文件不存在")
+		return errors.New("转换后的WAV文件不存在")
 	}
 
-	return wavPath, nil
+	return nil
 }
 
 // getModelPath 获取模型路径和名称
@@ -278,20 +513,35 @@ func (s *Service) tryGetModel(modelName string) (string, string, error) {
 	return "", "", errors.Errorf("模型 %s 在以下位置都不存在: %v，请运行 ./whisper-init 下载模型", modelName, possiblePaths)
 }
 
-// executeWhisper 执行Whisper转录
-func (s *Service) executeWhisper(ctx context.Context, audioPath, modelPath, outputPath string) (string, error) {
-	// 检测系统和加速类型
-	accelerationType := s.detectAccelerationType(modelPath)
-	logger.Infof("🎯 检测到加速类型: %s", accelerationType)
-
-	// 构建命令参数
+// buildArgs 构建转录的公共命令参数，正常执行和降级执行共用
+func (s *Service) buildArgs(audioPath, modelPath, outputPath string, opts TranscribeOptions) []string {
 	args := []string{
 		"-f", audioPath,
 		"-m", modelPath,
-		"-osrt", // 输出SRT格式
+		outputFormatFlags[opts.OutputFormat], // 输出格式
 		"-l", s.config.Language,
 		"-of", outputPath,
 	}
+	if opts.Task == TaskTranslate {
+		args = append(args, "-tr") // 无论源语言是什么都输出英文
+	}
+	if opts.InitialPrompt != "" {
+		args = append(args, "--prompt", opts.InitialPrompt)
+	}
+	if opts.Temperature != nil && *opts.Temperature > 0 {
+		args = append(args, "-tp", strconv.FormatFloat(*opts.Temperature, 'f', -1, 64))
+	}
+	return args
+}
+
+// executeWhisper 执行Whisper转录，结果写入 outputPath.<格式>
+func (s *Service) executeWhisper(ctx context.Context, audioPath, modelPath, outputPath string, opts TranscribeOptions) error {
+	// 检测系统和加速类型
+	accelerationType := s.detectAccelerationType(modelPath)
+	logger.Infof("🎯 检测到加速类型: %s", accelerationType)
+
+	// 构建命令参数
+	args := s.buildArgs(audioPath, modelPath, outputPath, opts)
 
 	// 根据加速类型配置参数
 	switch accelerationType {
@@ -336,6 +586,14 @@ func (s *Service) executeWhisper(ctx context.Context, audioPath, modelPath, outp
 	// 解析输出日志，提取有用信息
 	s.parseWhisperOutput(string(output), accelerationType)
 
+	// 被取消或超时杀掉的进程不是加速模式的问题，不再降级重试
+	if err != nil && ctx.Err() != nil {
+		return &InterruptedError{Err: ctx.Err()}
+	}
+	if err != nil && timeoutCtx.Err() == context.DeadlineExceeded {
+		return &InterruptedError{Timeout: timeout, Err: context.DeadlineExceeded}
+	}
+
 	if err != nil {
 		logger.Errorf("❌ Whisper执行失败: %s", err)
 		logger.Errorf("📝 详细输出: %s", string(output))
@@ -343,60 +601,37 @@ func (s *Service) executeWhisper(ctx context.Context, audioPath, modelPath, outp
 		// 检查是否是Core ML相关错误，尝试降级
 		if strings.Contains(string(output), "Core ML") || strings.Contains(string(output), "failed to initialize") {
 			logger.Warn("⚠️  Core ML 初始化失败，尝试降级到 Metal/CPU 模式")
-			return s.executeWhisperFallback(ctx, audioPath, modelPath, outputPath, accelerationType)
+			return s.executeWhisperFallback(ctx, audioPath, modelPath, outputPath, accelerationType, opts)
 		}
 
 		// 检查是否是GPU相关错误
 		if strings.Contains(string(output), "CUDA") || strings.Contains(string(output), "Metal") {
 			logger.Warn("⚠️  GPU 加速失败，尝试降级到 CPU 模式")
-			return s.executeWhisperFallback(ctx, audioPath, modelPath, outputPath, accelerationType)
+			return s.executeWhisperFallback(ctx, audioPath, modelPath, outputPath, accelerationType, opts)
 		}
 
-		return "", errors.Wrap(err, "Whisper执行失败")
+		return errors.Wrap(err, "Whisper执行失败")
 	}
 
 	logger.Info("✅ Whisper 转录执行成功")
 
-	// 读取生成的SRT文件内容
-	srtPath := outputPath + ".srt"
-	if _, err := os.Stat(srtPath); err != nil {
-		return "", errors.New("SRT文件未生成")
+	// 检查输出文件是否生成
+	if _, err := os.Stat(outputPath + "." + opts.OutputFormat); err != nil {
+		return errors.Errorf("%s文件未生成", strings.ToUpper(opts.OutputFormat))
 	}
 
-	content, err := os.ReadFile(srtPath)
-	if err != nil {
-		return "", errors.Wrap(err, "读取SRT文件失败")
-	}
-
-	// 提取纯文本
-	text := s.extractTextFromSRT(string(content))
-
-	logger.Infof("📄 转录完成，提取文本长度: %d 字符", len(text))
-
-	return text, nil
+	return nil
 }
 
-// extractTextFromSRT 从SRT内容中提取纯文本
+// extractTextFromSRT 从SRT/VTT内容中提取纯文本，每条字幕一行。
+// 时间轴无法解析的块会被跳过，完全无法解析时返回原始内容，不让已完成的转录失败
 func (s *Service) extractTextFromSRT(srtContent string) string {
-	lines := strings.Split(srtContent, "\n")
-	var textLines []string
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		// 跳过序号行和时间戳行
-		if line == "" || isNumber(line) || strings.Contains(line, "-->") {
-			continue
-		}
-		textLines = append(textLines, line)
+	segments, err := subtitles.ParseSRT(srtContent)
+	if err != nil {
+		logger.Warnf("⚠️ 解析转录结果的时间轴失败，返回原始内容: %v", err)
+		return strings.TrimSpace(srtContent)
 	}
-
-	return strings.Join(textLines, " ")
-}
-
-// isNumber 检查字符串是否为数字
-func isNumber(s string) bool {
-	_, err := strconv.Atoi(s)
-	return err == nil
+	return subtitles.ToPlainText(segments)
 }
 
 // IsEnabled 检查Whisper功能是否启用
@@ -498,17 +733,11 @@ func (s *Service) parseWhisperOutput(output, expectedAcceleration string) {
 }
 
 // executeWhisperFallback 降级执行Whisper
-func (s *Service) executeWhisperFallback(ctx context.Context, audioPath, modelPath, outputPath, failedType string) (string, error) {
+func (s *Service) executeWhisperFallback(ctx context.Context, audioPath, modelPath, outputPath, failedType string, opts TranscribeOptions) error {
 	logger.Warnf("🔄 %s 模式失败，尝试降级处理", failedType)
 
 	var fallbackType string
-	args := []string{
-		"-f", audioPath,
-		"-m", modelPath,
-		"-osrt",
-		"-l", s.config.Language,
-		"-of", outputPath,
-	}
+	args := s.buildArgs(audioPath, modelPath, outputPath, opts)
 
 	// 根据失败的类型选择降级策略
 	switch failedType {
@@ -534,7 +763,7 @@ func (s *Service) executeWhisperFallback(ctx context.Context, audioPath, modelPa
 			args = append(args, "-t", strconv.Itoa(s.config.CPUThreads))
 		}
 	default:
-		return "", errors.New("所有加速模式都失败了")
+		return errors.New("所有加速模式都失败了")
 	}
 
 	logger.Infof("🔧 降级命令: %s %s", s.whisperCLIPath, strings.Join(args, " "))
@@ -545,22 +774,17 @@ func (s *Service) executeWhisperFallback(ctx context.Context, audioPath, modelPa
 
 	s.parseWhisperOutput(string(output), fallbackType)
 
+	if err != nil && ctx.Err() != nil {
+		return &InterruptedError{Err: ctx.Err()}
+	}
 	if err != nil {
 		logger.Errorf("❌ 降级模式也失败: %s", err)
 		logger.Errorf("📝 详细输出: %s", string(output))
-		return "", errors.Wrap(err, "降级模式转录失败")
+		return errors.Wrap(err, "降级模式转录失败")
 	}
 
 	logger.Infof("✅ 降级模式 (%s) 转录成功", fallbackType)
-
-	// 读取SRT文件
-	srtPath := outputPath + ".srt"
-	content, err := os.ReadFile(srtPath)
-	if err != nil {
-		return "", errors.Wrap(err, "读取SRT文件失败")
-	}
-
-	return s.extractTextFromSRT(string(content)), nil
+	return nil
 }
 
 // scanAvailableModels 扫描所有可用的模型
@@ -0,0 +1,188 @@
+package browser
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// contextJanitorInterval 空闲上下文清理的检查间隔
+const contextJanitorInterval = 30 * time.Second
+
+// authContext 按账号缓存的已登录浏览器上下文
+type authContext struct {
+	account     string
+	context     playwright.BrowserContext
+	cookieMtime time.Time // 加载时cookies文件的修改时间，文件变化说明账号重新登录过
+	lastUsed    time.Time
+	inUse       bool
+}
+
+// cookieModTime 获取cookies文件的修改时间，文件不存在时返回零值
+func cookieModTime(cookieFile string) time.Time {
+	info, err := os.Stat(cookieFile)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// acquireCachedContext 取出账号空闲且cookies未变化的缓存上下文，cookies已变化的上下文会被关闭
+func (p *BrowserPool) acquireCachedContext(accountName string, cookieMtime time.Time) *authContext {
+	p.mu.Lock()
+	entry, ok := p.contexts[accountName]
+	if !ok || entry.inUse {
+		p.mu.Unlock()
+		return nil
+	}
+	if entry.cookieMtime.Equal(cookieMtime) {
+		entry.inUse = true
+		entry.lastUsed = time.Now()
+		p.mu.Unlock()
+		return entry
+	}
+	delete(p.contexts, accountName)
+	p.mu.Unlock()
+
+	logger.Infof("账号 '%s' 的cookies已更新，重新创建浏览器上下文", accountName)
+	p.closeContext(entry.context)
+	return nil
+}
+
+// cacheContext 缓存新建的上下文，账号已有缓存或未开启复用时返回nil，调用方用完后需自行关闭
+func (p *BrowserPool) cacheContext(accountName string, browserCtx playwright.BrowserContext, cookieMtime time.Time) *authContext {
+	if p.config.Browser.ContextIdleTTL <= 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	if _, exists := p.contexts[accountName]; exists {
+		return nil
+	}
+
+	entry := &authContext{
+		account:     accountName,
+		context:     browserCtx,
+		cookieMtime: cookieMtime,
+		lastUsed:    time.Now(),
+		inUse:       true,
+	}
+	p.contexts[accountName] = entry
+	if !p.janitorStarted {
+		p.janitorStarted = true
+		go p.runContextJanitor()
+	}
+	return entry
+}
+
+// releaseContext 归还缓存的上下文，供同一账号的后续调用复用
+func (p *BrowserPool) releaseContext(entry *authContext) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry.inUse = false
+	entry.lastUsed = time.Now()
+}
+
+// discardContext 移除并关闭缓存的上下文（如上下文已失效）
+func (p *BrowserPool) discardContext(entry *authContext) {
+	p.mu.Lock()
+	if p.contexts[entry.account] == entry {
+		delete(p.contexts, entry.account)
+	}
+	p.mu.Unlock()
+	p.closeContext(entry.context)
+}
+
+// acquireContextSlot 占用一个上下文名额，达到上限时关闭最久未使用的空闲上下文，仍没有名额时排队等待
+func (p *BrowserPool) acquireContextSlot(ctx context.Context) error {
+	select {
+	case p.contextSlots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	p.evictLeastRecentlyUsed()
+
+	ctx, cancel := withAcquireTimeout(ctx)
+	defer cancel()
+
+	atomic.AddInt32(&p.waiting, 1)
+	defer atomic.AddInt32(&p.waiting, -1)
+
+	select {
+	case p.contextSlots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return acquireError(ctx)
+	}
+}
+
+// closeContext 关闭上下文并释放名额
+func (p *BrowserPool) closeContext(browserCtx playwright.BrowserContext) {
+	if err := browserCtx.Close(); err != nil {
+		logger.Debugf("关闭浏览器上下文失败: %v", err)
+	}
+	<-p.contextSlots
+}
+
+// evictLeastRecentlyUsed 关闭最久未使用的空闲上下文
+func (p *BrowserPool) evictLeastRecentlyUsed() {
+	p.mu.Lock()
+	var oldest *authContext
+	for _, entry := range p.contexts {
+		if !entry.inUse && (oldest == nil || entry.lastUsed.Before(oldest.lastUsed)) {
+			oldest = entry
+		}
+	}
+	if oldest != nil {
+		delete(p.contexts, oldest.account)
+	}
+	p.mu.Unlock()
+
+	if oldest != nil {
+		logger.Debugf("上下文数量已达上限，关闭账号 '%s' 的空闲上下文", oldest.account)
+		p.closeContext(oldest.context)
+	}
+}
+
+// evictIdleContexts 关闭空闲超过TTL的上下文
+func (p *BrowserPool) evictIdleContexts() {
+	ttl := p.config.Browser.ContextIdleTTL
+	var expired []*authContext
+
+	p.mu.Lock()
+	for account, entry := range p.contexts {
+		if !entry.inUse && time.Since(entry.lastUsed) > ttl {
+			delete(p.contexts, account)
+			expired = append(expired, entry)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, entry := range expired {
+		logger.Debugf("账号 '%s' 的浏览器上下文空闲超时，已关闭", entry.account)
+		p.closeContext(entry.context)
+	}
+}
+
+// runContextJanitor 定期关闭空闲超时的上下文，直到浏览器池关闭
+func (p *BrowserPool) runContextJanitor() {
+	ticker := time.NewTicker(contextJanitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopJanitor:
+			return
+		case <-ticker.C:
+			p.evictIdleContexts()
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package browser
 
 import (
+	"context"
+	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -11,6 +14,12 @@ import (
 	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
 )
 
+// defaultAcquireTimeout 请求未设置截止时间时，等待浏览器实例的最长时间
+const defaultAcquireTimeout = 30 * time.Second
+
+// ErrPoolTimeout 在请求截止时间内没有等到空闲的浏览器实例或上下文名额
+var ErrPoolTimeout = errors.New("获取浏览器实例超时，浏览器池繁忙")
+
 // BrowserPool 浏览器池
 type BrowserPool struct {
 	browsers   []*BrowserInstance
@@ -19,6 +28,12 @@ type BrowserPool struct {
 	config     *config.Config
 	playwright *playwright.Playwright
 	closed     bool
+	waiting    int32 // 正在排队等待浏览器实例的调用数
+
+	contexts       map[string]*authContext // 按账号缓存的已登录上下文
+	contextSlots   chan struct{}           // 打开的上下文名额，限制上下文总数
+	stopJanitor    chan struct{}
+	janitorStarted bool // 清理协程在首次缓存上下文时才启动，没有浏览器流程时不占用资源
 }
 
 // BrowserInstance 浏览器实例
@@ -41,6 +56,10 @@ func NewBrowserPool(cfg *config.Config) (*BrowserPool, error) {
 		available:  make(chan *BrowserInstance, cfg.Browser.PoolSize),
 		config:     cfg,
 		playwright: pw,
+
+		contexts:     make(map[string]*authContext),
+		contextSlots: make(chan struct{}, maxContexts(cfg)),
+		stopJanitor:  make(chan struct{}),
 	}
 
 	// 初始化浏览器实例
@@ -58,24 +77,60 @@ func NewBrowserPool(cfg *config.Config) (*BrowserPool, error) {
 	return pool, nil
 }
 
-// Get 获取一个可用的浏览器实例
-func (p *BrowserPool) Get() (*BrowserInstance, error) {
+// maxContexts 同时打开的浏览器上下文上限，未配置时为浏览器池大小的两倍
+func maxContexts(cfg *config.Config) int {
+	if cfg.Browser.MaxContexts > 0 {
+		return cfg.Browser.MaxContexts
+	}
+	if cfg.Browser.PoolSize > 0 {
+		return cfg.Browser.PoolSize * 2
+	}
+	return 1
+}
+
+// Get 获取一个可用的浏览器实例，池中没有空闲实例时排队等待，直到ctx截止（未设置截止时间时最多等待30秒）
+func (p *BrowserPool) Get(ctx context.Context) (*BrowserInstance, error) {
 	if p.closed {
 		return nil, errors.New("浏览器池已关闭")
 	}
 
+	ctx, cancel := withAcquireTimeout(ctx)
+	defer cancel()
+
+	atomic.AddInt32(&p.waiting, 1)
+	defer atomic.AddInt32(&p.waiting, -1)
+
 	select {
-	case instance := <-p.available:
+	case instance, ok := <-p.available:
+		if !ok {
+			return nil, errors.New("浏览器池已关闭")
+		}
 		p.mu.Lock()
 		instance.InUse = true
 		instance.LastUse = time.Now()
 		p.mu.Unlock()
 		return instance, nil
-	case <-time.After(30 * time.Second):
-		return nil, errors.New("获取浏览器实例超时")
+	case <-ctx.Done():
+		return nil, acquireError(ctx)
 	}
 }
 
+// withAcquireTimeout 请求没有截止时间时使用默认的等待上限
+func withAcquireTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, defaultAcquireTimeout)
+}
+
+// acquireError 等待超时返回ErrPoolTimeout，请求被取消时返回取消原因
+func acquireError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrPoolTimeout
+	}
+	return ctx.Err()
+}
+
 // Put 归还浏览器实例到池中
 func (p *BrowserPool) Put(instance *BrowserInstance) {
 	if p.closed {
@@ -95,53 +150,103 @@ func (p *BrowserPool) Put(instance *BrowserInstance) {
 	}
 }
 
-// GetWithAuth 获取带认证的浏览器页面
-func (p *BrowserPool) GetWithAuth(accountName string) (playwright.Page, func(), error) {
-	instance, err := p.Get()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	// 创建新的浏览器上下文
-	context, err := instance.Browser.NewContext(playwright.BrowserNewContextOptions{
-		UserAgent: playwright.String(p.config.Browser.UserAgent),
-		Viewport: &playwright.Size{
-			Width:  p.config.Browser.Viewport.Width,
-			Height: p.config.Browser.Viewport.Height,
-		},
-	})
-	if err != nil {
-		p.Put(instance)
-		return nil, nil, errors.Wrap(err, "创建浏览器上下文失败")
+// GetWithAuth 获取带认证的浏览器页面。同一账号的已登录上下文会被缓存复用，
+// cleanup 关闭页面并将上下文归还缓存；cookies文件变化（重新登录）时重新创建上下文
+func (p *BrowserPool) GetWithAuth(ctx context.Context, accountName string) (playwright.Page, func(), error) {
+	if p.closed {
+		return nil, nil, errors.New("浏览器池已关闭")
 	}
 
-	// 加载账号cookies
 	logger.Infof("GetWithAuth - 请求的账号名: '%s' (空表示默认账号)", accountName)
 
-	loginService := auth.NewLoginService()
+	accountManager := auth.NewAccountManager()
 
 	// 如果没有指定账号名，使用默认账号
 	if accountName == "" {
 		logger.Info("使用默认账号加载cookies")
-		// 获取默认账号信息
-		accountManager := auth.NewAccountManager()
 		defaultAccount, err := accountManager.GetDefaultAccount()
 		if err != nil {
 			logger.Errorf("获取默认账号失败: %v", err)
-			context.Close()
-			p.Put(instance)
 			return nil, nil, errors.Wrap(err, "获取默认账号失败")
 		}
 		accountName = defaultAccount.Name
 		logger.Infof("找到默认账号: %s", accountName)
 	}
 
-	cookies, err := loginService.LoadCookies(accountName)
+	cookieMtime := cookieModTime(accountManager.GetCookieFile(accountName))
+
+	// 优先复用该账号的缓存上下文
+	if entry := p.acquireCachedContext(accountName, cookieMtime); entry != nil {
+		page, err := entry.context.NewPage()
+		if err == nil {
+			logger.Debugf("复用账号 '%s' 的浏览器上下文", accountName)
+			return page, func() {
+				page.Close()
+				p.releaseContext(entry)
+			}, nil
+		}
+		logger.Warnf("缓存的浏览器上下文已失效，重新创建: %v", err)
+		p.discardContext(entry)
+	}
+
+	if err := p.acquireContextSlot(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	browserCtx, err := p.newAuthContext(ctx, accountName)
+	if err != nil {
+		<-p.contextSlots
+		return nil, nil, err
+	}
+
+	// 创建页面
+	page, err := browserCtx.NewPage()
+	if err != nil {
+		p.closeContext(browserCtx)
+		return nil, nil, errors.Wrap(err, "创建页面失败")
+	}
+
+	// 返回清理函数：已缓存的上下文归还复用，否则（同账号并发调用或未开启复用）直接关闭。
+	// 加载cookies时旧版明文文件可能被迁移重写，因此重新读取修改时间
+	cookieMtime = cookieModTime(accountManager.GetCookieFile(accountName))
+	entry := p.cacheContext(accountName, browserCtx, cookieMtime)
+	cleanup := func() {
+		page.Close()
+		if entry != nil {
+			p.releaseContext(entry)
+		} else {
+			p.closeContext(browserCtx)
+		}
+	}
+
+	return page, cleanup, nil
+}
+
+// newAuthContext 在空闲的浏览器实例上创建新的上下文并加载账号cookies
+func (p *BrowserPool) newAuthContext(ctx context.Context, accountName string) (playwright.BrowserContext, error) {
+	instance, err := p.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.Put(instance)
+
+	// 创建新的浏览器上下文
+	browserCtx, err := instance.Browser.NewContext(playwright.BrowserNewContextOptions{
+		UserAgent: playwright.String(p.config.Browser.UserAgent),
+		Viewport: &playwright.Size{
+			Width:  p.config.Browser.Viewport.Width,
+			Height: p.config.Browser.Viewport.Height,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "创建浏览器上下文失败")
+	}
+
+	cookies, err := auth.NewLoginService().LoadCookies(accountName)
 	if err != nil {
 		logger.Errorf("加载账号 '%s' 的cookies失败: %v", accountName, err)
-		context.Close()
-		p.Put(instance)
-		return nil, nil, errors.Wrapf(err, "加载账号 '%s' 的cookies失败", accountName)
+		browserCtx.Close()
+		return nil, errors.Wrapf(err, "加载账号 '%s' 的cookies失败", accountName)
 	}
 
 	// 检查是否包含bili_jct
@@ -170,28 +275,12 @@ func (p *BrowserPool) GetWithAuth(accountName string) (playwright.Page, func(),
 			SameSite: cookie.SameSite,
 		}
 	}
-	if err := context.AddCookies(optionalCookies); err != nil {
-		context.Close()
-		p.Put(instance)
-		return nil, nil, errors.Wrap(err, "设置cookies失败")
+	if err := browserCtx.AddCookies(optionalCookies); err != nil {
+		browserCtx.Close()
+		return nil, errors.Wrap(err, "设置cookies失败")
 	}
 
-	// 创建页面
-	page, err := context.NewPage()
-	if err != nil {
-		context.Close()
-		p.Put(instance)
-		return nil, nil, errors.Wrap(err, "创建页面失败")
-	}
-
-	// 返回清理函数
-	cleanup := func() {
-		page.Close()
-		context.Close()
-		p.Put(instance)
-	}
-
-	return page, cleanup, nil
+	return browserCtx, nil
 }
 
 // Close 关闭浏览器池
@@ -205,6 +294,13 @@ func (p *BrowserPool) Close() error {
 
 	p.closed = true
 	close(p.available)
+	close(p.stopJanitor)
+
+	// 关闭缓存的上下文
+	for account, entry := range p.contexts {
+		entry.context.Close()
+		delete(p.contexts, account)
+	}
 
 	// 关闭所有浏览器实例
 	for _, instance := range p.browsers {
@@ -224,17 +320,22 @@ func (p *BrowserPool) Close() error {
 
 // createBrowserInstance 创建浏览器实例
 func (p *BrowserPool) createBrowserInstance() (*BrowserInstance, error) {
+	args := []string{
+		"--no-sandbox",
+		"--disable-setuid-sandbox",
+		"--disable-dev-shm-usage",
+		"--disable-accelerated-2d-canvas",
+		"--no-first-run",
+		"--no-zygote",
+		"--disable-gpu",
+	}
+	if proxyServer := chromiumProxyServer(p.config.EffectiveProxyURL()); proxyServer != "" {
+		args = append(args, "--proxy-server="+proxyServer)
+	}
+
 	browser, err := p.playwright.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
 		Headless: playwright.Bool(p.config.Browser.Headless),
-		Args: []string{
-			"--no-sandbox",
-			"--disable-setuid-sandbox",
-			"--disable-dev-shm-usage",
-			"--disable-accelerated-2d-canvas",
-			"--no-first-run",
-			"--no-zygote",
-			"--disable-gpu",
-		},
+		Args:     args,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "启动浏览器失败")
@@ -264,6 +365,24 @@ func (p *BrowserPool) Stats() map[string]interface{} {
 		"total":     len(p.browsers),
 		"in_use":    inUseCount,
 		"available": len(p.browsers) - inUseCount,
+		"waiting":   atomic.LoadInt32(&p.waiting),
+		"contexts":  len(p.contextSlots),
+		"cached":    len(p.contexts),
 		"closed":    p.closed,
 	}
 }
+
+// chromiumProxyServer 转换为Chromium --proxy-server 参数格式（scheme://host:port），Chromium不支持代理地址中携带账号密码
+func chromiumProxyServer(proxyURL string) string {
+	if proxyURL == "" {
+		return ""
+	}
+	parsed, err := url.Parse(proxyURL)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	if parsed.User != nil {
+		logger.Warnf("⚠️ 浏览器代理不支持账号密码认证，已忽略代理地址中的认证信息")
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
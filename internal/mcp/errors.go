@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/auth"
+	"github.com/shirenchuang/bilibili-mcp/internal/browser"
+)
+
+// 工具错误分类，供客户端按类别处理
+const (
+	ErrorCodeNotLoggedIn      = "not_logged_in"     // 未登录或cookies无效
+	ErrorCodeSessionExpired   = "session_expired"   // 登录会话已过期
+	ErrorCodeRiskControl      = "risk_control"      // 触发B站风控
+	ErrorCodeRateLimited      = "rate_limited"      // 请求过于频繁
+	ErrorCodePermissionDenied = "permission_denied" // 权限不足
+	ErrorCodeNotFound         = "not_found"         // 内容不存在或不可见
+	ErrorCodeInvalidArgument  = "invalid_argument"  // 请求参数错误
+	ErrorCodeUpstream         = "upstream_error"    // B站服务端错误
+	ErrorCodeAPI              = "api_error"         // 其他B站API业务错误
+	ErrorCodeTimeout          = "timeout"           // 请求超时
+	ErrorCodeCanceled         = "canceled"          // 请求被取消
+	ErrorCodeBusy             = "busy"              // 浏览器池繁忙
+	ErrorCodeInternal         = "internal_error"    // 其他错误
+)
+
+// InvalidArgumentError 工具参数不合法
+type InvalidArgumentError struct {
+	Message string
+}
+
+func (e *InvalidArgumentError) Error() string {
+	return e.Message
+}
+
+// errorCategory 错误分类及是否值得重试
+type errorCategory struct {
+	code      string
+	retriable bool
+}
+
+// apiErrorCategories B站API错误码到错误分类的映射
+var apiErrorCategories = map[int]errorCategory{
+	api.CodeNotLoggedIn:         {ErrorCodeNotLoggedIn, false},
+	api.CodeCSRFFailed:          {ErrorCodeNotLoggedIn, false},
+	api.CodeRiskControl:         {ErrorCodeRiskControl, true},
+	api.CodeRequestBlocked:      {ErrorCodeRiskControl, true},
+	api.CodeTooFrequent:         {ErrorCodeRateLimited, true},
+	api.CodeTooFast:             {ErrorCodeRateLimited, true},
+	api.CodeForbidden:           {ErrorCodePermissionDenied, false},
+	api.CommentCodeAreaDisabled: {ErrorCodePermissionDenied, false},
+	api.CodeNotFound:            {ErrorCodeNotFound, false},
+	api.CodeVideoInvisible:      {ErrorCodeNotFound, false},
+	api.CodeVideoReviewing:      {ErrorCodeNotFound, false},
+	api.CommentCodeDeleted:      {ErrorCodeNotFound, false},
+	api.CommentCodeNotExist:     {ErrorCodeNotFound, false},
+	api.CodeBadRequest:          {ErrorCodeInvalidArgument, false},
+	api.CodeServerError:         {ErrorCodeUpstream, true},
+	api.CodeUnavailable:         {ErrorCodeUpstream, true},
+}
+
+// ErrorInfo 机器可读的错误描述，作为错误结果的第二个内容块（JSON文本）返回
+type ErrorInfo struct {
+	Code      string `json:"code"`               // 错误分类，取值见 ErrorCode* 常量
+	Message   string `json:"message"`            // 错误信息
+	Retriable bool   `json:"retriable"`          // 稍后重试是否可能成功
+	APICode   int    `json:"api_code,omitempty"` // B站API返回的原始错误码
+}
+
+// classifyError 根据错误链中的API错误码和已知错误归类
+func classifyError(err error) ErrorInfo {
+	info := ErrorInfo{Code: ErrorCodeInternal, Message: err.Error()}
+
+	var apiErr *api.APIError
+	var rateLimitErr *RateLimitError
+	var argumentErr *InvalidArgumentError
+	switch {
+	case errors.As(err, &apiErr):
+		info.APICode = apiErr.Code
+		info.Code = ErrorCodeAPI
+		if category, ok := apiErrorCategories[apiErr.Code]; ok {
+			info.Code = category.code
+			info.Retriable = category.retriable
+		}
+	case errors.As(err, &rateLimitErr):
+		info.Code = ErrorCodeRateLimited
+		info.Retriable = true
+	case errors.As(err, &argumentErr):
+		info.Code = ErrorCodeInvalidArgument
+	case errors.Is(err, auth.ErrSessionExpired):
+		info.Code = ErrorCodeSessionExpired
+	case errors.Is(err, browser.ErrPoolTimeout):
+		info.Code = ErrorCodeBusy
+		info.Retriable = true
+	case errors.Is(err, context.DeadlineExceeded):
+		info.Code = ErrorCodeTimeout
+		info.Retriable = true
+	case errors.Is(err, context.Canceled):
+		info.Code = ErrorCodeCanceled
+	}
+
+	return info
+}
+
+// errorContent 将错误描述编码为JSON内容块
+func errorContent(err error) (MCPContent, bool) {
+	payload, marshalErr := json.Marshal(classifyError(err))
+	if marshalErr != nil {
+		return MCPContent{}, false
+	}
+	return MCPContent{Type: "text", Text: string(payload)}, true
+}
@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantCode      string
+		wantRetriable bool
+		wantAPICode   int
+	}{
+		{"用户信息风控", errors.Wrap(api.ErrUserInfoRateLimited, "获取用户信息失败"), ErrorCodeRateLimited, true, api.CodeTooFast},
+		{"空间搜索风控", errors.Wrap(api.ErrSpaceSearchRateLimited, "获取用户视频列表失败"), ErrorCodeRiskControl, true, api.CodeRequestBlocked},
+		{"未登录", errors.Wrap(api.NewAPIError(api.CodeNotLoggedIn, "账号未登录"), "API返回错误"), ErrorCodeNotLoggedIn, false, api.CodeNotLoggedIn},
+		{"参数错误", &InvalidArgumentError{Message: "cid参数格式错误"}, ErrorCodeInvalidArgument, false, 0},
+		{"未知错误", errors.New("boom"), ErrorCodeInternal, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := classifyError(tt.err)
+			if info.Code != tt.wantCode || info.Retriable != tt.wantRetriable || info.APICode != tt.wantAPICode {
+				t.Fatalf("classifyError = %+v, want code=%s retriable=%v api_code=%d", info, tt.wantCode, tt.wantRetriable, tt.wantAPICode)
+			}
+		})
+	}
+}
+
+func TestCreateErrorResultEnvelope(t *testing.T) {
+	s := &Server{}
+	_, err := s.getInt64Arg(map[string]interface{}{"cid": "abc"}, "cid")
+	if err == nil {
+		t.Fatal("expected error for non-numeric cid")
+	}
+
+	result := s.createErrorResult(err)
+	if !result.IsError || len(result.Content) != 2 {
+		t.Fatalf("result = %+v, want error with JSON envelope", result)
+	}
+	var info ErrorInfo
+	if err := json.Unmarshal([]byte(result.Content[1].Text), &info); err != nil {
+		t.Fatalf("envelope is not JSON: %v", err)
+	}
+	if info.Code != ErrorCodeInvalidArgument {
+		t.Fatalf("envelope code = %q, want %q", info.Code, ErrorCodeInvalidArgument)
+	}
+}
@@ -4,49 +4,42 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
+	"unicode"
 
 	"github.com/pkg/errors"
 	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/auth"
 	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/comment"
 	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/download"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/subtitles"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/whisper"
 	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
 )
 
-// 频率限制器
-var (
-	rateLimiter = make(map[string]time.Time)
-	rateMutex   sync.RWMutex
-)
-
-// checkRateLimit 检查频率限制
-func checkRateLimit(operation string, minInterval time.Duration) error {
-	rateMutex.Lock()
-	defer rateMutex.Unlock()
-
-	now := time.Now()
-	if lastTime, exists := rateLimiter[operation]; exists {
-		if elapsed := now.Sub(lastTime); elapsed < minInterval {
-			return errors.Errorf("请求过于频繁，请等待 %.1f 秒后再试", (minInterval - elapsed).Seconds())
-		}
-	}
-
-	rateLimiter[operation] = now
-	return nil
-}
-
 // 认证相关处理器
 
 // handleCheckLoginStatus 检查登录状态
 func (s *Server) handleCheckLoginStatus(ctx context.Context, args map[string]interface{}) *MCPToolResult {
 	accountName := s.getAccountName(args)
+	validate, _ := args["validate"].(bool)
 
-	isLoggedIn, account, err := s.loginService.CheckLoginStatus(ctx, accountName)
+	isLoggedIn, account, err := s.loginService.CheckLoginStatus(ctx, accountName, validate)
+	if errors.Is(err, auth.ErrSessionExpired) {
+		return s.createToolResult(fmt.Sprintf("账号 '%s' 的登录会话已过期，已自动停用，请重新登录: ./bilibili-login -account %s", account.Name, account.Name), true)
+	}
 	if err != nil {
+		// 存在账号但没有可用的默认账号时，提示用户设置默认账号
+		if accountName == "" {
+			if accounts, listErr := s.loginService.ListAccounts(); listErr == nil && len(accounts) > 0 {
+				return s.createToolResult(fmt.Sprintf("存在 %d 个账号，但没有可用的默认账号，请运行 switch_account 设置默认账号（或在配置中开启 accounts.auto_select_default 自动选择最近使用的账号）", len(accounts)), false)
+			}
+		}
 		return s.createErrorResult(err)
 	}
 
@@ -60,6 +53,9 @@ func (s *Server) handleCheckLoginStatus(ctx context.Context, args map[string]int
 
 	result := fmt.Sprintf("已登录 - 账号: %s, 昵称: %s, UID: %s",
 		account.Name, account.Nickname, account.UID)
+	if accountName == "" && !account.IsDefault {
+		result += "\n提示: 当前没有设置默认账号，临时使用了第一个激活账号，可运行 switch_account 设置默认账号"
+	}
 	return s.createToolResult(result, false)
 }
 
@@ -74,10 +70,15 @@ func (s *Server) handleListAccounts(ctx context.Context, args map[string]interfa
 		return s.createToolResult("没有已登录的账号，请先运行登录工具: ./bilibili-login", false)
 	}
 
-	// 格式化账号列表
 	var result strings.Builder
 	result.WriteString("已登录的账号列表:\n")
+	writeAccountList(&result, accounts)
+
+	return s.createToolResult(result.String(), false)
+}
 
+// writeAccountList 格式化账号列表，标注默认和未激活的账号
+func writeAccountList(result *strings.Builder, accounts []auth.Account) {
 	for i, account := range accounts {
 		status := ""
 		if account.IsDefault {
@@ -86,12 +87,13 @@ func (s *Server) handleListAccounts(ctx context.Context, args map[string]interfa
 		if !account.IsActive {
 			status += " (未激活)"
 		}
+		if membership := account.Membership(); membership != "" {
+			status = " · " + membership + status
+		}
 
 		result.WriteString(fmt.Sprintf("%d. %s - %s (UID: %s)%s\n",
 			i+1, account.Name, account.Nickname, account.UID, status))
 	}
-
-	return s.createToolResult(result.String(), false)
 }
 
 // handleSwitchAccount 切换账号
@@ -108,6 +110,350 @@ func (s *Server) handleSwitchAccount(ctx context.Context, args map[string]interf
 	return s.createToolResult(fmt.Sprintf("已切换到账号: %s", accountName), false)
 }
 
+// handleLogoutAccount 退出账号并注销服务端会话
+func (s *Server) handleLogoutAccount(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	accountName, ok := args["account_name"].(string)
+	if !ok || accountName == "" {
+		return s.createToolResult("缺少account_name参数", true)
+	}
+
+	if err := s.loginService.Logout(accountName); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	return s.createToolResult(fmt.Sprintf("账号 '%s' 已退出登录，服务端会话已失效，本地cookies已删除", accountName), false)
+}
+
+// handleRefreshCookie 刷新账号cookies
+func (s *Server) handleRefreshCookie(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	accountName := s.getAccountName(args)
+	displayName := accountName
+	if displayName == "" {
+		displayName = "默认账号"
+	}
+
+	refreshed, err := s.loginService.RefreshCookies(accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+	if !refreshed {
+		return s.createToolResult(fmt.Sprintf("%s 的cookies仍然有效，无需刷新", displayName), false)
+	}
+
+	return s.createToolResult(fmt.Sprintf("✅ %s 的cookies已刷新并保存", displayName), false)
+}
+
+// handleDeleteAccount 删除本地账号和cookies，并返回剩余的账号列表
+func (s *Server) handleDeleteAccount(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	accountName, ok := args["account_name"].(string)
+	if !ok || accountName == "" {
+		return s.createToolResult("缺少account_name参数", true)
+	}
+
+	if err := s.loginService.DeleteAccount(accountName); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	accounts, err := s.loginService.ListAccounts()
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("账号 '%s' 已从本地删除（服务端会话未注销，需要时请使用 logout_account）\n\n", accountName))
+	if len(accounts) == 0 {
+		result.WriteString("没有剩余的账号，请运行登录工具: ./bilibili-login\n")
+	} else {
+		result.WriteString("剩余账号:\n")
+		writeAccountList(&result, accounts)
+	}
+
+	return s.createToolResult(result.String(), false)
+}
+
+// handleCheckAllAccounts 并发检查所有账号的登录状态
+func (s *Server) handleCheckAllAccounts(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	summary, err := s.loginService.CheckAllAccounts(ctx)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	if len(summary.Results) == 0 {
+		return s.createToolResult("没有已登录的账号，请先运行登录工具: ./bilibili-login", false)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("账号检查完成 - 有效: %d, 失效: %d\n", summary.Valid, summary.Expired))
+	for i, health := range summary.Results {
+		if health.Valid {
+			result.WriteString(fmt.Sprintf("%d. ✅ %s - %s (UID: %s)\n", i+1, health.Name, health.Nickname, health.UID))
+		} else {
+			result.WriteString(fmt.Sprintf("%d. ❌ %s - %s，请运行: ./bilibili-login -account %s\n", i+1, health.Name, health.Error, health.Name))
+		}
+	}
+
+	return s.createToolResult(result.String(), false)
+}
+
+// LogAccountHealth 检查所有账号并将结果写入日志，用于服务启动时的概览
+func (s *Server) LogAccountHealth(ctx context.Context) {
+	summary, err := s.loginService.CheckAllAccounts(ctx)
+	if err != nil {
+		logger.Warnf("账号检查失败: %v", err)
+		return
+	}
+
+	logger.Infof("🩺 账号检查完成 - 有效: %d, 失效: %d", summary.Valid, summary.Expired)
+	for _, health := range summary.Results {
+		if !health.Valid {
+			logger.Warnf("账号 '%s' 需要重新登录: %s", health.Name, health.Error)
+		}
+	}
+}
+
+// capabilityProbeVideoID 用于探测账号能力的公开视频（支持4K/HDR/杜比等多种格式）
+const capabilityProbeVideoID = "BV1GJ411x7h7"
+
+// capabilityCacheTTL 账号能力缓存有效期
+const capabilityCacheTTL = 1 * time.Hour
+
+// accountCapabilities 账号可用的清晰度/编码/音质能力矩阵
+type accountCapabilities struct {
+	Account        string    `json:"account"`         // 账号名称
+	ProbeVideo     string    `json:"probe_video"`     // 探测使用的视频
+	LoggedIn       bool      `json:"logged_in"`       // 是否已登录
+	MaxQuality     string    `json:"max_quality"`     // 实际可获取的最高清晰度
+	VideoQualities []string  `json:"video_qualities"` // 实际返回流的清晰度
+	AcceptQuality  []string  `json:"accept_quality"`  // 视频声明支持的全部清晰度
+	Codecs         []string  `json:"codecs"`          // 可用视频编码
+	AudioTiers     []string  `json:"audio_tiers"`     // 可用音质档位
+	MP4Available   bool      `json:"mp4_available"`   // 是否可获取音视频合一的MP4
+	Notes          []string  `json:"notes,omitempty"` // 说明
+	CheckedAt      time.Time `json:"checked_at"`      // 探测时间
+}
+
+// handleAccountCapabilities 探测当前账号可获取的清晰度、编码和音质
+func (s *Server) handleAccountCapabilities(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	accountName := s.getAccountName(args)
+	refresh, _ := args["refresh"].(bool)
+
+	cacheKey := accountName
+	if cacheKey == "" {
+		cacheKey = "(default)"
+	}
+
+	s.capabilityMutex.Lock()
+	cached, ok := s.capabilityCache[cacheKey]
+	s.capabilityMutex.Unlock()
+
+	if ok && !refresh && time.Since(cached.CheckedAt) < capabilityCacheTTL {
+		return s.createCapabilitiesResult(cached)
+	}
+
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	caps, err := probeAccountCapabilities(apiClient, capabilityProbeVideoID)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "探测账号能力失败"))
+	}
+	caps.Account = cacheKey
+
+	s.capabilityMutex.Lock()
+	s.capabilityCache[cacheKey] = caps
+	s.capabilityMutex.Unlock()
+
+	return s.createCapabilitiesResult(caps)
+}
+
+// createCapabilitiesResult 格式化账号能力结果
+func (s *Server) createCapabilitiesResult(caps *accountCapabilities) *MCPToolResult {
+	jsonData, err := json.MarshalIndent(caps, "", "  ")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+	return s.createToolResult(string(jsonData), false)
+}
+
+// probeAccountCapabilities 使用公开视频探测账号实际能获取的流
+func probeAccountCapabilities(apiClient *api.Client, videoID string) (*accountCapabilities, error) {
+	caps := &accountCapabilities{
+		ProbeVideo: videoID,
+		CheckedAt:  time.Now(),
+	}
+
+	if nav, err := apiClient.GetNavInfo(); err == nil && nav.Code == 0 {
+		caps.LoggedIn = nav.Data.IsLogin
+	}
+
+	videoInfo, err := apiClient.GetVideoInfo(videoID)
+	if err != nil {
+		return nil, err
+	}
+	if videoInfo.Code != 0 {
+		return nil, errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "获取探测视频信息失败")
+	}
+	cid := videoInfo.Data.Cid
+
+	// 请求全部DASH特性和最高清晰度，服务端只会返回账号有权限的流
+	streamResp, err := apiClient.GetVideoStream(videoID, cid, 127, 4048, "pc")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, q := range streamResp.Data.AcceptQuality {
+		caps.AcceptQuality = append(caps.AcceptQuality, getQualityDescription(q))
+	}
+
+	if dash := streamResp.Data.DASH; dash != nil {
+		seenQuality := make(map[int]bool)
+		seenCodec := make(map[string]bool)
+		maxQuality := 0
+		for _, video := range dash.Video {
+			if !seenQuality[video.ID] {
+				seenQuality[video.ID] = true
+				caps.VideoQualities = append(caps.VideoQualities, getQualityDescription(video.ID))
+			}
+			if video.ID > maxQuality {
+				maxQuality = video.ID
+			}
+			codec := codecName(video.CodecID, video.Codecs)
+			if !seenCodec[codec] {
+				seenCodec[codec] = true
+				caps.Codecs = append(caps.Codecs, codec)
+			}
+		}
+		if maxQuality > 0 {
+			caps.MaxQuality = getQualityDescription(maxQuality)
+		}
+
+		for _, audio := range dash.Audio {
+			caps.AudioTiers = append(caps.AudioTiers, audioTierName(audio.ID))
+		}
+		if dash.Dolby != nil && len(dash.Dolby.Audio) > 0 {
+			caps.AudioTiers = append(caps.AudioTiers, "杜比全景声")
+		}
+		if dash.FLAC != nil && dash.FLAC.Audio.BaseURL != "" {
+			caps.AudioTiers = append(caps.AudioTiers, "Hi-Res无损")
+		}
+	}
+
+	if mp4Resp, err := apiClient.GetVideoStream(videoID, cid, 64, 1, "html5"); err == nil && len(mp4Resp.Data.DURL) > 0 {
+		caps.MP4Available = true
+	}
+
+	if !caps.LoggedIn {
+		caps.Notes = append(caps.Notes, "未登录状态最高通常只能获取480P，请先登录")
+	} else if len(caps.AcceptQuality) > len(caps.VideoQualities) {
+		caps.Notes = append(caps.Notes, "部分清晰度未返回流，通常需要大会员")
+	}
+
+	return caps, nil
+}
+
+// codecName 获取视频编码名称
+func codecName(codecID int, codecs string) string {
+	switch codecID {
+	case 7:
+		return "AVC/H.264"
+	case 12:
+		return "HEVC/H.265"
+	case 13:
+		return "AV1"
+	default:
+		return codecs
+	}
+}
+
+// dashAudioStream 构建DASH音频流的输出信息
+func dashAudioStream(audio api.DASHStream) map[string]interface{} {
+	return map[string]interface{}{
+		"quality":    audioTierName(audio.ID),
+		"id":         audio.ID,
+		"url":        audio.BaseURL,
+		"backup_url": audio.BackupURL,
+		"codecs":     audio.Codecs,
+		"bandwidth":  audio.Bandwidth,
+	}
+}
+
+// audioTierName 获取音质档位名称
+func audioTierName(id int) string {
+	switch id {
+	case 30216:
+		return "64K"
+	case 30232:
+		return "132K"
+	case 30280:
+		return "192K"
+	case 30250:
+		return "杜比全景声"
+	case 30251:
+		return "Hi-Res无损"
+	default:
+		return fmt.Sprintf("ID_%d", id)
+	}
+}
+
+// formatAllPagesResult 格式化全部分P的下载汇总，err非空表示下载中途被中断
+func formatAllPagesResult(result *download.AllPagesResult, err error) string {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("🎉 分P下载完成: %s\n", result.Title))
+	message.WriteString(fmt.Sprintf("📁 输出目录: %s\n\n", result.OutputDir))
+
+	succeeded := 0
+	var totalSize int64
+	for _, page := range result.Pages {
+		if page.Result == nil {
+			message.WriteString(fmt.Sprintf("❌ P%d %s: %s\n", page.Page, page.PartTitle, page.Error))
+			continue
+		}
+		succeeded++
+
+		media := page.Result
+		message.WriteString(fmt.Sprintf("✅ P%d %s\n", page.Page, page.PartTitle))
+		files := []struct {
+			path string
+			size int64
+		}{
+			{media.MergedPath, media.MergedSize},
+			{media.VideoPath, media.VideoSize},
+			{media.AudioPath, media.AudioSize},
+		}
+		for _, file := range files {
+			// 未实际生成的文件大小为0（如需要合并时的合并文件）
+			if file.path == "" || file.size == 0 {
+				continue
+			}
+			totalSize += file.size
+			message.WriteString(fmt.Sprintf("   • %s (%s)\n", filepath.Base(file.path), formatFileSize(file.size)))
+		}
+		if media.Notes != "" {
+			message.WriteString(fmt.Sprintf("   📝 %s\n", media.Notes))
+		}
+	}
+
+	message.WriteString(fmt.Sprintf("\n📊 成功 %d/%d 个分P，共 %s\n", succeeded, len(result.Pages), formatFileSize(totalSize)))
+	if err != nil {
+		message.WriteString(fmt.Sprintf("⏱️ 下载被中断: %v，重新调用会跳过已下载的分P并续传未完成的文件\n", err))
+	}
+	return message.String()
+}
+
+// audioQualityLabel 无损/杜比音轨的标注，标准音轨返回空
+func audioQualityLabel(tier string) string {
+	switch tier {
+	case download.AudioQualityFLAC:
+		return " [Hi-Res无损]"
+	case download.AudioQualityDolby:
+		return " [杜比全景声]"
+	default:
+		return ""
+	}
+}
+
 // 评论相关处理器
 
 // handlePostComment 发表评论 - 使用API优先
@@ -116,27 +462,25 @@ func (s *Server) handlePostComment(ctx context.Context, args map[string]interfac
 	if !ok || videoID == "" {
 		return s.createToolResult("缺少video_id参数", true)
 	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
 
 	content, ok := args["content"].(string)
 	if !ok || content == "" {
 		return s.createToolResult("缺少content参数", true)
 	}
 
-	if err := s.validateVideoID(videoID); err != nil {
-		return s.createErrorResult(err)
-	}
-
 	accountName := s.getAccountName(args)
 
-	// 获取带认证的浏览器页面（仅用于获取cookies）
-	page, cleanup, err := s.browserPool.GetWithAuth(accountName)
+	cookieMap, err := s.loginService.LoadCookieMap(accountName)
 	if err != nil {
 		return s.createErrorResult(err)
 	}
-	defer cleanup()
 
 	// 创建API评论服务
-	apiCommentService, err := comment.NewAPICommentService(page)
+	apiCommentService, err := comment.NewAPICommentService(cookieMap)
 	if err != nil {
 		return s.createErrorResult(errors.Wrap(err, "创建API评论服务失败"))
 	}
@@ -171,7 +515,8 @@ func (s *Server) handlePostComment(ctx context.Context, args map[string]interfac
 // 		return s.createToolResult("缺少image_path参数", true)
 // 	}
 
-// 	if err := s.validateVideoID(videoID); err != nil {
+// 	videoID, err := normalizeVideoID(ctx, videoID)
+// 	if err != nil {
 // 		return s.createErrorResult(err)
 // 	}
 
@@ -181,7 +526,7 @@ func (s *Server) handlePostComment(ctx context.Context, args map[string]interfac
 // 	accountName := s.getAccountName(args)
 
 // 	// 获取带认证的浏览器页面，设置更长的超时时间
-// 	page, cleanup, err := s.browserPool.GetWithAuth(accountName)
+// 	page, cleanup, err := s.browserPool.GetWithAuth(ctx, accountName)
 // 	if err != nil {
 // 		return s.createErrorResult(err)
 // 	}
@@ -205,6 +550,10 @@ func (s *Server) handleReplyComment(ctx context.Context, args map[string]interfa
 	if !ok || videoID == "" {
 		return s.createToolResult("缺少video_id参数", true)
 	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
 
 	parentCommentID, ok := args["parent_comment_id"].(string)
 	if !ok || parentCommentID == "" {
@@ -216,37 +565,18 @@ func (s *Server) handleReplyComment(ctx context.Context, args map[string]interfa
 		return s.createToolResult("缺少content参数", true)
 	}
 
-	if err := s.validateVideoID(videoID); err != nil {
-		return s.createErrorResult(err)
-	}
-
 	accountName := s.getAccountName(args)
 
 	// 检查频率限制
-	rateLimitKey := fmt.Sprintf("reply_comment_%s_%s", accountName, videoID)
-	if err := checkRateLimit(rateLimitKey, 10*time.Second); err != nil {
+	if err := s.rateLimiter.Check(accountName, "reply_comment", videoID); err != nil {
 		return s.createErrorResult(err)
 	}
 
-	// 获取带认证的浏览器页面（仅用于获取cookies）
-	page, cleanup, err := s.browserPool.GetWithAuth(accountName)
+	// 直接读取账号cookies创建API客户端，无需启动浏览器
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
 	if err != nil {
 		return s.createErrorResult(err)
 	}
-	defer cleanup()
-
-	// 获取cookies并创建API客户端
-	cookies, err := page.Context().Cookies()
-	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "获取cookies失败"))
-	}
-
-	cookieMap := make(map[string]string)
-	for _, cookie := range cookies {
-		cookieMap[cookie.Name] = cookie.Value
-	}
-
-	apiClient := api.NewClient(cookieMap)
 
 	// 使用API回复评论
 	replyResp, err := apiClient.ReplyComment(videoID, parentCommentID, content)
@@ -255,608 +585,2819 @@ func (s *Server) handleReplyComment(ctx context.Context, args map[string]interfa
 	}
 
 	if replyResp.Code != 0 {
-		return s.createErrorResult(errors.Errorf("API返回错误: %s (code: %d)", replyResp.Message, replyResp.Code))
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(replyResp.Code, replyResp.Message), "API返回错误"))
 	}
 
 	return s.createToolResult(fmt.Sprintf("回复评论成功 - 视频: %s, 回复ID: %s", videoID, replyResp.Data.RPID), false)
 }
 
-// 视频相关处理器
-
-// handleGetVideoInfo 获取视频信息 - 使用API优先
-func (s *Server) handleGetVideoInfo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+// handleDeleteComment 删除自己发表的评论
+func (s *Server) handleDeleteComment(ctx context.Context, args map[string]interface{}) *MCPToolResult {
 	videoID, ok := args["video_id"].(string)
 	if !ok || videoID == "" {
 		return s.createToolResult("缺少video_id参数", true)
 	}
-
-	if err := s.validateVideoID(videoID); err != nil {
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
 		return s.createErrorResult(err)
 	}
 
-	// 创建API客户端（不需要登录cookies获取基本视频信息）
-	apiClient := api.NewClient(map[string]string{})
-
-	// 使用API获取视频信息
-	videoInfo, err := apiClient.GetVideoInfo(videoID)
+	rpid, err := s.getInt64Arg(args, "comment_id")
 	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
+		return s.createErrorResult(err)
 	}
-
-	if videoInfo.Code != 0 {
-		return s.createErrorResult(errors.Errorf("API返回错误: %s (code: %d)", videoInfo.Message, videoInfo.Code))
+	if rpid <= 0 {
+		return s.createToolResult("缺少comment_id参数", true)
 	}
 
-	// 格式化输出
-	jsonData, err := json.MarshalIndent(videoInfo.Data, "", "  ")
+	apiClient, err := s.getAuthedAPIClient(ctx, s.getAccountName(args))
 	if err != nil {
 		return s.createErrorResult(err)
 	}
 
-	return s.createToolResult(string(jsonData), false)
+	resp, err := apiClient.DeleteComment(videoID, rpid)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "删除评论失败"))
+	}
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
+
+	logger.Infof("🗑️ 已删除评论 %d (视频 %s)", rpid, videoID)
+	return s.createToolResult(fmt.Sprintf("删除评论成功 - 视频: %s, 评论ID: %d", videoID, rpid), false)
 }
 
-// handleDownloadMedia 下载媒体文件（音频、视频或合并文件）
-func (s *Server) handleDownloadMedia(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+// handleLikeComment 点赞或取消点赞评论
+func (s *Server) handleLikeComment(ctx context.Context, args map[string]interface{}) *MCPToolResult {
 	videoID, ok := args["video_id"].(string)
 	if !ok || videoID == "" {
-		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
 	}
 
-	// 获取媒体类型，默认为合并文件
-	mediaTypeStr := "merged"
-	if mt, ok := args["media_type"].(string); ok && mt != "" {
-		mediaTypeStr = mt
+	rpid, err := s.getInt64Arg(args, "comment_id")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+	if rpid <= 0 {
+		return s.createToolResult("缺少comment_id参数", true)
 	}
 
-	var mediaType download.MediaType
-	switch mediaTypeStr {
-	case "audio":
-		mediaType = download.MediaTypeAudio
-	case "video":
-		mediaType = download.MediaTypeVideo
-	case "merged":
-		mediaType = download.MediaTypeMerged
-	default:
-		return s.createErrorResult(errors.Errorf("不支持的媒体类型: %s，支持的类型: audio, video, merged", mediaTypeStr))
+	like := true
+	if value, ok := args["like"].(bool); ok {
+		like = value
+	}
+	action, actionText := 1, "点赞"
+	if !like {
+		action, actionText = 0, "取消点赞"
 	}
 
-	// 获取清晰度，默认为0（自动选择）
-	quality := 0
-	if q, ok := args["quality"]; ok {
-		if qInt, ok := q.(float64); ok {
-			quality = int(qInt)
-		}
+	accountName := s.getAccountName(args)
+
+	// 检查频率限制
+	if err := s.rateLimiter.Check(accountName, "like_comment", strconv.FormatInt(rpid, 10)); err != nil {
+		return s.createErrorResult(err)
 	}
 
-	// 获取CID
-	var cid int64
-	if cidValue, ok := args["cid"]; ok {
-		switch v := cidValue.(type) {
-		case float64:
-			cid = int64(v)
-		case int:
-			cid = int64(v)
-		case int64:
-			cid = v
-		case string:
-			parsed, err := strconv.ParseInt(v, 10, 64)
-			if err != nil {
-				return s.createToolResult("cid参数格式错误", true)
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	resp, err := apiClient.LikeComment(videoID, rpid, action)
+	if err != nil {
+		return s.createErrorResult(errors.Wrapf(err, "%s评论失败", actionText))
+	}
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
+
+	return s.createToolResult(fmt.Sprintf("%s评论成功 - 视频: %s, 评论ID: %d", actionText, videoID, rpid), false)
+}
+
+// handlePinComment 置顶或取消置顶评论
+func (s *Server) handlePinComment(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	rpid, err := s.getInt64Arg(args, "comment_id")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+	if rpid <= 0 {
+		return s.createToolResult("缺少comment_id参数", true)
+	}
+
+	pin := true
+	if value, ok := args["pin"].(bool); ok {
+		pin = value
+	}
+	actionText := "置顶"
+	if !pin {
+		actionText = "取消置顶"
+	}
+
+	apiClient, err := s.getAuthedAPIClient(ctx, s.getAccountName(args))
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	resp, err := apiClient.PinComment(videoID, rpid, pin)
+	if err != nil {
+		return s.createErrorResult(errors.Wrapf(err, "%s评论失败", actionText))
+	}
+	switch resp.Code {
+	case 0:
+	case api.CodeForbidden:
+		// 非UP主置顶评论时返回权限不足
+		return s.createErrorResult(errors.Errorf("%s评论失败: 只有视频UP主才能置顶评论，请使用UP主账号操作", actionText))
+	default:
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
+
+	logger.Infof("📌 已%s评论 %d (视频 %s)", actionText, rpid, videoID)
+	return s.createToolResult(fmt.Sprintf("%s评论成功 - 视频: %s, 评论ID: %d", actionText, videoID, rpid), false)
+}
+
+// handleListEmotes 列出评论可用的表情代码
+func (s *Server) handleListEmotes(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	keyword, _ := args["keyword"].(string)
+	keyword = strings.TrimSpace(keyword)
+
+	emotes, err := api.NewClient(map[string]string{}).GetEmotes()
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取表情列表失败"))
+	}
+
+	matched := make([]api.Emote, 0, len(emotes))
+	for _, emote := range emotes {
+		if keyword == "" || strings.Contains(emote.Text, keyword) || strings.Contains(emote.PackageName, keyword) {
+			matched = append(matched, emote)
+		}
+	}
+	if len(matched) == 0 {
+		return s.createToolResult(fmt.Sprintf("没有找到包含 \"%s\" 的表情", keyword), false)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].PackageID != matched[j].PackageID {
+			return matched[i].PackageID < matched[j].PackageID
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("😀 共 %d 个表情，在评论内容中写入表情代码（如 [doge]）即可显示为表情\n", len(matched)))
+	var lastPackage int64 = -1
+	for _, emote := range matched {
+		if emote.PackageID != lastPackage {
+			lastPackage = emote.PackageID
+			message.WriteString(fmt.Sprintf("\n【%s】\n", emote.PackageName))
+		}
+		message.WriteString(emote.Text)
+		message.WriteString(" ")
+	}
+	message.WriteString("\n")
+
+	return s.createToolResult(message.String(), false)
+}
+
+// 视频相关处理器
+
+// handleGetVideoInfo 获取视频信息 - 使用API优先
+func (s *Server) handleGetVideoInfo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 创建API客户端（不需要登录cookies获取基本视频信息）
+	apiClient := api.NewClient(map[string]string{})
+	if noCache, _ := args["no_cache"].(bool); noCache {
+		apiClient.SetNoCache(true)
+	}
+
+	// 使用API获取视频信息
+	videoInfo, err := apiClient.GetVideoInfo(videoID)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
+	}
+
+	if videoInfo.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "API返回错误"))
+	}
+
+	// 格式化输出，封面地址统一为https，副本避免修改缓存中的数据
+	data := videoInfo.Data
+	data.Pic = download.NormalizeCoverURL(data.Pic)
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	return s.createToolResult(string(jsonData), false)
+}
+
+// handleGetVideoSummary 获取B站AI视频总结
+func (s *Server) handleGetVideoSummary(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	cid, err := s.getInt64Arg(args, "cid")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient := api.NewClient(map[string]string{})
+
+	// 总结接口需要cid和UP主mid
+	videoInfo, err := apiClient.GetVideoInfo(videoID)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
+	}
+	if videoInfo.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "API返回错误"))
+	}
+	if cid == 0 {
+		cid = videoInfo.Data.Cid
+	}
+
+	conclusion, err := apiClient.GetVideoConclusion(videoID, strconv.FormatInt(cid, 10), videoInfo.Data.Owner.Mid)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取视频总结失败"))
+	}
+	if conclusion.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(conclusion.Code, conclusion.Message), "API返回错误"))
+	}
+
+	if !conclusion.HasSummary() {
+		return s.createToolResult(fmt.Sprintf("视频 %s 暂无AI总结，可以使用 download_media + whisper_audio_2_text 转录后自行总结", videoID), false)
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("📝 %s - AI总结\n\n", videoInfo.Data.Title))
+	message.WriteString(conclusion.Data.ModelResult.Summary)
+	message.WriteString("\n")
+
+	if len(conclusion.Data.ModelResult.Outline) > 0 {
+		message.WriteString("\n📋 提纲\n")
+		for i, section := range conclusion.Data.ModelResult.Outline {
+			message.WriteString(fmt.Sprintf("\n%d. [%s] %s\n", i+1, formatTimestamp(section.Timestamp), section.Title))
+			for _, point := range section.PartOutline {
+				message.WriteString(fmt.Sprintf("   • [%s] %s\n", formatTimestamp(point.Timestamp), point.Content))
 			}
-			cid = parsed
 		}
 	}
 
-	// 获取输出目录
-	outputDir := "./downloads"
-	if dir, ok := args["output_dir"].(string); ok && dir != "" {
-		outputDir = dir
+	return s.createToolResult(message.String(), false)
+}
+
+// defaultSummaryTranscriptChars summarize_video默认返回的转录文本最大字符数
+const defaultSummaryTranscriptChars = 8000
+
+// handleSummarizeVideo 汇总视频信息，优先使用官方AI总结，没有时下载音频并转录
+func (s *Server) handleSummarizeVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	cid, err := s.getInt64Arg(args, "cid")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	useOfficial := true
+	if v, ok := args["use_official_summary"].(bool); ok {
+		useOfficial = v
+	}
+	transcribe := true
+	if v, ok := args["transcribe"].(bool); ok {
+		transcribe = v
+	}
+	maxChars := defaultSummaryTranscriptChars
+	if v, ok := args["max_transcript_chars"].(float64); ok && v > 0 {
+		maxChars = int(v)
+	}
+
+	// 登录后可获取更多音频流，未登录时使用匿名客户端
+	apiClient, err := s.getAuthedAPIClient(ctx, s.getAccountName(args))
+	if err != nil {
+		logger.Warnf("获取登录账号失败，使用未登录状态: %v", err)
+		apiClient = api.NewClient(map[string]string{})
+	}
+
+	videoInfo, err := apiClient.GetVideoInfo(videoID)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
+	}
+	if videoInfo.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "API返回错误"))
+	}
+	info := videoInfo.Data
+	if cid == 0 {
+		cid = info.Cid
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("🎬 %s\n\n", info.Title))
+	message.WriteString(fmt.Sprintf("   • UP主: %s (UID: %d)\n", info.Owner.Name, info.Owner.Mid))
+	message.WriteString(fmt.Sprintf("   • 时长: %s\n", formatTimestamp(int64(info.Duration))))
+	message.WriteString(fmt.Sprintf("   • 发布时间: %s\n", time.Unix(info.Pubdate, 0).Format("2006-01-02 15:04")))
+	message.WriteString(fmt.Sprintf("   • 数据: 播放 %d / 点赞 %d / 投币 %d / 收藏 %d / 评论 %d / 弹幕 %d\n",
+		info.Stat.View, info.Stat.Like, info.Stat.Coin, info.Stat.Favorite, info.Stat.Reply, info.Stat.Danmaku))
+	if len(info.Tags) > 0 {
+		tags := make([]string, 0, len(info.Tags))
+		for _, tag := range info.Tags {
+			tags = append(tags, tag.TagName)
+		}
+		message.WriteString(fmt.Sprintf("   • 标签: %s\n", strings.Join(tags, ", ")))
+	}
+	if desc := strings.TrimSpace(info.Desc); desc != "" && desc != "-" {
+		message.WriteString(fmt.Sprintf("   • 简介: %s\n", desc))
+	}
+
+	// 1. 优先使用官方AI总结
+	if useOfficial {
+		conclusion, err := apiClient.GetVideoConclusion(videoID, strconv.FormatInt(cid, 10), info.Owner.Mid)
+		if err != nil {
+			logger.Warnf("获取官方AI总结失败: %v", err)
+		} else if conclusion.HasSummary() {
+			message.WriteString("\n📝 官方AI总结\n")
+			message.WriteString(conclusion.Data.ModelResult.Summary)
+			message.WriteString("\n")
+			for i, section := range conclusion.Data.ModelResult.Outline {
+				message.WriteString(fmt.Sprintf("%d. [%s] %s\n", i+1, formatTimestamp(section.Timestamp), section.Title))
+			}
+			return s.createToolResult(message.String(), false)
+		}
+	}
+
+	// 2. 没有官方总结时下载音频并转录
+	if !transcribe {
+		message.WriteString("\n⚠️ 该视频没有官方AI总结，未启用转录\n")
+		return s.createToolResult(message.String(), false)
+	}
+	if !s.currentConfig().Features.Whisper.Enabled {
+		message.WriteString("\n⚠️ 该视频没有官方AI总结，且Whisper功能未启用，请先运行 ./bilibili-whisper-init 进行初始化\n")
+		return s.createToolResult(message.String(), false)
+	}
+
+	transcription, errResult := s.downloadAndTranscribe(ctx, apiClient, videoID, cid, args, whisper.TranscribeOptions{})
+	if errResult != nil {
+		return errResult
+	}
+	audio, transcript := transcription.audio, transcription.transcript
+
+	text := strings.TrimSpace(transcript.Text)
+	truncated := false
+	if runes := []rune(text); len(runes) > maxChars {
+		text = string(runes[:maxChars])
+		truncated = true
+	}
+
+	message.WriteString(fmt.Sprintf("\n🎤 转录文本 (模型: %s)\n", transcript.Model))
+	message.WriteString(text)
+	message.WriteString("\n")
+	if truncated {
+		message.WriteString(fmt.Sprintf("\n…… 转录文本已截断，完整内容见: %s\n", transcript.OutputPath))
+	}
+
+	message.WriteString("\n📁 生成的文件\n")
+	if transcription.keepAudio {
+		message.WriteString(fmt.Sprintf("   • 原始音频: %s%s\n", audio.AudioPath, audioQualityLabel(audio.AudioQuality)))
+	} else {
+		message.WriteString("   • 原始音频: 已按 keep_audio=false 删除\n")
+	}
+	message.WriteString(fmt.Sprintf("   • 转录文件(%s): %s\n", strings.ToUpper(transcript.OutputFormat), transcript.OutputPath))
+	if transcript.WAVPath != "" {
+		message.WriteString(fmt.Sprintf("   • WAV中间文件: %s\n", transcript.WAVPath))
+	}
+
+	return s.createToolResult(message.String(), false)
+}
+
+// handleGetVideoChapters 获取视频分段章节
+func (s *Server) handleGetVideoChapters(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	cid, err := s.getInt64Arg(args, "cid")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient := api.NewClient(map[string]string{})
+
+	title := videoID
+	if cid == 0 {
+		videoInfo, err := apiClient.GetVideoInfo(videoID)
+		if err != nil {
+			return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
+		}
+		if videoInfo.Code != 0 {
+			return s.createErrorResult(errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "API返回错误"))
+		}
+		cid = videoInfo.Data.Cid
+		title = videoInfo.Data.Title
+	}
+
+	chapters, err := apiClient.GetVideoChapters(videoID, cid)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取视频章节失败"))
+	}
+
+	if len(chapters) == 0 {
+		return s.createToolResult(fmt.Sprintf("视频 %s 没有设置分段章节", videoID), false)
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("📑 %s - 分段章节 (CID: %d)\n\n", title, cid))
+	for i, chapter := range chapters {
+		message.WriteString(fmt.Sprintf("%d. [%s - %s] %s\n", i+1, formatTimestamp(chapter.From), formatTimestamp(chapter.To), chapter.Title))
+	}
+
+	return s.createToolResult(message.String(), false)
+}
+
+// videoPage 分P列表项
+type videoPage struct {
+	Page     int    `json:"page"`     // 分P序号
+	CID      int64  `json:"cid"`      // 分P的CID
+	Part     string `json:"part"`     // 分P标题
+	Duration int    `json:"duration"` // 分P时长(秒)
+}
+
+// handleGetVideoPages 获取视频分P列表
+func (s *Server) handleGetVideoPages(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	videoInfo, err := api.NewClient(map[string]string{}).GetVideoInfo(videoID)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
+	}
+	if videoInfo.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "API返回错误"))
+	}
+
+	pages := make([]videoPage, 0, len(videoInfo.Data.Pages))
+	for _, p := range videoInfo.Data.Pages {
+		pages = append(pages, videoPage{Page: p.Page, CID: p.Cid, Part: p.Part, Duration: p.Duration})
+	}
+
+	jsonData, err := json.Marshal(pages)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+	return s.createToolResult(string(jsonData), false)
+}
+
+// partCandidate 分P匹配结果
+type partCandidate struct {
+	Page     int    `json:"page"`     // 分P序号
+	CID      int64  `json:"cid"`      // 分P的CID
+	Title    string `json:"title"`    // 分P标题
+	Duration int    `json:"duration"` // 分P时长(秒)
+}
+
+// handleResolvePart 根据分P标题查找CID
+func (s *Server) handleResolvePart(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	partTitle, ok := args["part_title"].(string)
+	if !ok || strings.TrimSpace(partTitle) == "" {
+		return s.createToolResult("缺少part_title参数", true)
+	}
+
+	apiClient := api.NewClient(map[string]string{})
+	videoInfo, err := apiClient.GetVideoInfo(videoID)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
+	}
+	if videoInfo.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "API返回错误"))
+	}
+
+	all := make([]partCandidate, 0, len(videoInfo.Data.Pages))
+	for _, p := range videoInfo.Data.Pages {
+		all = append(all, partCandidate{Page: p.Page, CID: p.Cid, Title: p.Part, Duration: p.Duration})
+	}
+
+	// 匹配优先级：完全相同 > 包含关系
+	query := normalizePartTitle(partTitle)
+	var exact, fuzzy []partCandidate
+	for _, p := range all {
+		title := normalizePartTitle(p.Title)
+		switch {
+		case title == query:
+			exact = append(exact, p)
+		case strings.Contains(title, query) || (title != "" && strings.Contains(query, title)):
+			fuzzy = append(fuzzy, p)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = fuzzy
+	}
+
+	result := map[string]interface{}{
+		"video_id":   videoID,
+		"part_title": partTitle,
+	}
+
+	switch len(matches) {
+	case 1:
+		result["matched"] = matches[0]
+	case 0:
+		result["message"] = "未找到匹配的分P，以下为全部分P"
+		result["candidates"] = all
+	default:
+		result["message"] = "匹配到多个分P，请从候选中选择"
+		result["candidates"] = matches
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	return s.createToolResult(string(jsonData), false)
+}
+
+// normalizePartTitle 标准化分P标题，忽略大小写、空白和常见标点
+func normalizePartTitle(title string) string {
+	title = strings.ToLower(title)
+	var b strings.Builder
+	for _, r := range title {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// handleDownloadMedia 下载媒体文件（音频、视频或合并文件）
+func (s *Server) handleDownloadMedia(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 获取媒体类型，默认为合并文件
+	mediaTypeStr := "merged"
+	if mt, ok := args["media_type"].(string); ok && mt != "" {
+		mediaTypeStr = mt
+	}
+
+	var mediaType download.MediaType
+	switch mediaTypeStr {
+	case "audio":
+		mediaType = download.MediaTypeAudio
+	case "video":
+		mediaType = download.MediaTypeVideo
+	case "merged":
+		mediaType = download.MediaTypeMerged
+	default:
+		return s.createErrorResult(errors.Errorf("不支持的媒体类型: %s，支持的类型: audio, video, merged", mediaTypeStr))
+	}
+
+	// 获取清晰度，默认为0（自动选择）
+	quality := 0
+	var fnval int
+	var platform string
+
+	// 预设会展开为 fnval/platform/quality 组合，显式传入的quality优先
+	if presetName, ok := args["preset"].(string); ok && presetName != "" {
+		preset, exists := api.GetStreamPreset(presetName)
+		if !exists {
+			return s.createErrorResult(errors.Errorf("不支持的预设: %s，支持: %s", presetName, strings.Join(api.StreamPresetNames(), ", ")))
+		}
+		quality, fnval, platform = preset.Quality, preset.FnVal, preset.Platform
+	}
+
+	if q, ok := args["quality"]; ok {
+		if qInt, ok := q.(float64); ok {
+			quality = int(qInt)
+		}
+	}
+
+	// 平台默认html5（无防盗链），仅在明确指定时使用pc
+	if p, ok := args["platform"].(string); ok && p != "" {
+		if p != "html5" && p != "pc" {
+			return s.createErrorResult(errors.Errorf("不支持的平台: %s，支持: html5, pc", p))
+		}
+		platform = p
+	}
+
+	// 获取CID
+	var cid int64
+	if cidValue, ok := args["cid"]; ok {
+		switch v := cidValue.(type) {
+		case float64:
+			cid = int64(v)
+		case int:
+			cid = int64(v)
+		case int64:
+			cid = v
+		case string:
+			parsed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return s.createToolResult("cid参数格式错误", true)
+			}
+			cid = parsed
+		}
+	}
+	allPages, _ := args["all_pages"].(bool)
+	if allPages && cid != 0 {
+		return s.createToolResult("all_pages 与 cid 不能同时指定", true)
+	}
+
+	// 获取输出目录
+	outputDir := "./downloads"
+	if dir, ok := args["output_dir"].(string); ok && dir != "" {
+		outputDir = dir
+	}
+
+	accountName := s.getAccountName(args)
+
+	// 直接读取账号cookies创建API客户端，无需启动浏览器
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+	if noCache, _ := args["no_cache"].(bool); noCache {
+		apiClient.SetNoCache(true)
+	}
+
+	// 创建媒体下载服务
+	mediaDownloadService := download.NewMediaDownloadService(apiClient, outputDir)
+
+	// 设置下载选项
+	opts := download.DownloadOptions{
+		MediaType: mediaType,
+		Quality:   quality,
+		CID:       cid,
+		FnVal:     fnval,
+		Platform:  platform,
+	}
+	if tagAudio, ok := args["tag_audio"].(bool); ok {
+		opts.TagAudio = tagAudio
+	}
+	if outputFormat, ok := args["output_format"].(string); ok {
+		opts.OutputFormat = outputFormat
+	}
+	if preferNoMerge, ok := args["prefer_no_merge"].(bool); ok {
+		opts.PreferNoMerge = &preferNoMerge
+	}
+	if autoMerge, ok := args["auto_merge"].(bool); ok {
+		opts.AutoMerge = autoMerge
+	}
+	if verifyChecksum, ok := args["verify_checksum"].(bool); ok {
+		opts.VerifyChecksum = verifyChecksum
+	}
+	if filenameTemplate, ok := args["filename_template"].(string); ok {
+		opts.FilenameTemplate = filenameTemplate
+	}
+	if audioQuality, ok := args["audio_quality"].(string); ok {
+		opts.AudioQuality = audioQuality
+	}
+	switch v := args["max_quality"].(type) {
+	case float64:
+		opts.MaxQuality = int(v)
+	case string:
+		maxQuality, err := download.ParseMaxQuality(v)
+		if err != nil {
+			return s.createErrorResult(err)
+		}
+		opts.MaxQuality = maxQuality
+	}
+
+	// 客户端提供progressToken时推送下载进度通知
+	onProgress, stopProgress := s.startProgress(ctx)
+	opts.OnProgress = onProgress
+
+	if allPages {
+		pages, err := mediaDownloadService.DownloadAllPages(ctx, videoID, opts)
+		stopProgress()
+		if pages == nil {
+			return s.createErrorResult(errors.Wrap(err, "下载媒体失败"))
+		}
+		return s.createToolResult(formatAllPagesResult(pages, err), false)
+	}
+
+	// 下载媒体
+	result, err := mediaDownloadService.DownloadMedia(ctx, videoID, opts)
+	stopProgress()
+	if err != nil {
+		if timeoutResult := s.createDownloadTimeoutResult(ctx, err); timeoutResult != nil {
+			return timeoutResult
+		}
+		return s.createErrorResult(errors.Wrap(err, "下载媒体失败"))
+	}
+
+	// 构建格式化的结果信息
+	var message strings.Builder
+	message.WriteString("🎉 媒体下载完成！\n\n")
+
+	// 基本信息
+	message.WriteString("1. 视频信息\n")
+	message.WriteString(fmt.Sprintf("   • 标题: %s\n", result.Title))
+	if result.Page > 0 {
+		message.WriteString(fmt.Sprintf("   • 分P: P%d %s\n", result.Page, result.PartTitle))
+	}
+	message.WriteString(fmt.Sprintf("   • 类型: %s\n", result.MediaType))
+	if result.CoverURL != "" {
+		message.WriteString(fmt.Sprintf("   • 封面: %s\n", result.CoverURL))
+	}
+	message.WriteString(fmt.Sprintf("   • 时长: %d秒\n\n", result.Duration))
+
+	// 当前下载清晰度信息
+	message.WriteString("2. 当前下载清晰度\n")
+	message.WriteString(fmt.Sprintf("   • 清晰度: %s", result.CurrentQuality.Description))
+	if result.CurrentQuality.Width > 0 && result.CurrentQuality.Height > 0 {
+		message.WriteString(fmt.Sprintf(" (%dx%d)", result.CurrentQuality.Width, result.CurrentQuality.Height))
+	}
+	if result.CurrentQuality.HasAudio {
+		message.WriteString(" [包含音频]")
+	} else {
+		message.WriteString(" [纯视频，需合并音频]")
+	}
+	message.WriteString("\n\n")
+
+	// 可用清晰度列表
+	if len(result.AvailableQualities) > 0 {
+		message.WriteString("3. 所有可用清晰度\n")
+		for i, quality := range result.AvailableQualities {
+			marker := "   "
+			if quality.Quality == result.CurrentQuality.Quality {
+				marker = " ✓ " // 标记当前选择的清晰度
+			}
+
+			message.WriteString(fmt.Sprintf("%s%s", marker, quality.Description))
+			if quality.Width > 0 && quality.Height > 0 {
+				message.WriteString(fmt.Sprintf(" (%dx%d)", quality.Width, quality.Height))
+			}
+			if quality.HasAudio {
+				message.WriteString(" [完整视频]")
+			} else {
+				message.WriteString(" [需合并]")
+			}
+			message.WriteString("\n")
+
+			// 限制显示数量，避免信息过多
+			if i >= 9 {
+				remaining := len(result.AvailableQualities) - i - 1
+				if remaining > 0 {
+					message.WriteString(fmt.Sprintf("   ... 还有 %d 个清晰度可选\n", remaining))
+				}
+				break
+			}
+		}
+		message.WriteString("\n")
+	}
+
+	// 文件信息
+	sectionNum := 4
+	if len(result.AvailableQualities) == 0 {
+		sectionNum = 3
+	}
+	message.WriteString(fmt.Sprintf("%d. 下载文件\n", sectionNum))
+	fileCount := 1
+	merged := result.MergedPath != "" && !result.MergeRequired
+	if merged {
+		message.WriteString(fmt.Sprintf("   %d) 完整视频: %s (%.2f MB)\n",
+			fileCount, filepath.Base(result.MergedPath), float64(result.MergedSize)/(1024*1024)))
+		fileCount++
+	}
+	if result.AudioPath != "" && !merged {
+		message.WriteString(fmt.Sprintf("   %d) 音频文件: %s (%.2f MB)%s\n",
+			fileCount, filepath.Base(result.AudioPath), float64(result.AudioSize)/(1024*1024), audioQualityLabel(result.AudioQuality)))
+		fileCount++
+	}
+	if result.VideoPath != "" && !merged {
+		message.WriteString(fmt.Sprintf("   %d) 视频文件: %s (%.2f MB)\n",
+			fileCount, filepath.Base(result.VideoPath), float64(result.VideoSize)/(1024*1024)))
+		fileCount++
+	}
+	if merged && result.MergedSHA256 != "" {
+		message.WriteString(fmt.Sprintf("   • SHA256: %s\n", result.MergedSHA256))
+	}
+	if !merged && result.AudioSHA256 != "" {
+		message.WriteString(fmt.Sprintf("   • 音频SHA256: %s\n", result.AudioSHA256))
+	}
+	if !merged && result.VideoSHA256 != "" {
+		message.WriteString(fmt.Sprintf("   • 视频SHA256: %s\n", result.VideoSHA256))
+	}
+
+	// 合并提示和高清视频建议
+	sectionNum++
+	needsSection := result.MergeRequired || (!result.CurrentQuality.HasAudio && result.MediaType == download.MediaTypeMerged)
+
+	if needsSection {
+		message.WriteString(fmt.Sprintf("\n%d. 重要提示\n", sectionNum))
+
+		if result.MergeRequired && result.MergeCommand != "" {
+			message.WriteString("   ⚠️  当前下载的视频为：纯视频 + 音频，需要手动合并\n")
+			message.WriteString(fmt.Sprintf("   请执行：%s\n", result.MergeCommand))
+			message.WriteString("   💡 传入 auto_merge=true 可在安装ffmpeg时自动合并\n")
+			if result.Notes != "" {
+				message.WriteString(fmt.Sprintf("   📝 %s\n", result.Notes))
+			}
+		}
+
+		// 如果下载的是纯视频，提示用户可以下载高清
+		if !result.CurrentQuality.HasAudio && result.MediaType == download.MediaTypeMerged {
+			message.WriteString("\n   💡 提示：如果需要更高清晰度的视频，可以指定 quality 参数\n")
+			message.WriteString("   例如：quality=80 (1080P), quality=112 (1080P+), quality=120 (4K)\n")
+			message.WriteString("   高清视频会自动下载音频并提供合并命令\n")
+		}
+	}
+
+	// 其他提示
+	if result.Notes != "" && !result.MergeRequired && !needsSection {
+		message.WriteString(fmt.Sprintf("\n%d. 提示信息\n", sectionNum))
+		message.WriteString(fmt.Sprintf("   📝 %s\n", result.Notes))
+	}
+
+	return s.createToolResult(message.String(), false)
+}
+
+// createDownloadTimeoutResult 下载超时时返回已下载进度的说明，非超时错误返回nil
+func (s *Server) createDownloadTimeoutResult(ctx context.Context, err error) *MCPToolResult {
+	var partial *download.PartialDownloadError
+	isPartial := errors.As(err, &partial)
+	if !isPartial && ctx.Err() == nil {
+		return nil
+	}
+
+	var message strings.Builder
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded {
+		message.WriteString("⏱️ 下载超时：操作超过了工具调用的时间限制\n\n")
+	} else {
+		message.WriteString("⏹️ 下载已取消\n\n")
+	}
+
+	if isPartial {
+		if partial.Total > 0 {
+			message.WriteString(fmt.Sprintf("   • 已下载: %s / %s (%.1f%%)\n",
+				formatFileSize(partial.Downloaded), formatFileSize(partial.Total),
+				float64(partial.Downloaded)*100/float64(partial.Total)))
+		} else {
+			message.WriteString(fmt.Sprintf("   • 已下载: %s\n", formatFileSize(partial.Downloaded)))
+		}
+		if partial.Kept {
+			message.WriteString(fmt.Sprintf("   • 未完成文件已保留: %s（再次下载时会断点续传）\n", partial.Path))
+		} else {
+			message.WriteString("   • 未完成文件已清理（可在配置中设置 download.keep_partial: true 保留）\n")
+		}
+	}
+
+	message.WriteString("\n💡 提示：可以选择较低的清晰度（quality 参数）或仅下载音频后重试")
+	return s.createToolResult(message.String(), true)
+}
+
+// 弹幕列表返回条数限制
+const (
+	defaultDanmakuLimit = 200
+	maxDanmakuLimit     = 5000
+)
+
+// danmakuItem 弹幕列表输出项
+type danmakuItem struct {
+	ProgressMs int64  `json:"progress_ms"` // 出现时间(毫秒)
+	Time       string `json:"time"`        // 出现时间(mm:ss)
+	Content    string `json:"content"`     // 弹幕内容
+	Mode       int    `json:"mode"`        // 弹幕类型
+	Color      string `json:"color"`       // 颜色(十六进制RGB)
+	SendTime   string `json:"send_time"`   // 发送时间
+}
+
+// handleGetDanmaku 获取视频弹幕列表
+func (s *Server) handleGetDanmaku(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	cid, err := s.getInt64Arg(args, "cid")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	limit := defaultDanmakuLimit
+	if l, ok := args["limit"].(float64); ok && l >= 1 {
+		limit = int(l)
+	}
+	if limit > maxDanmakuLimit {
+		limit = maxDanmakuLimit
+	}
+
+	// 弹幕接口不需要登录
+	apiClient := api.NewClient(map[string]string{})
+
+	if cid == 0 {
+		videoInfo, err := apiClient.GetVideoInfo(videoID)
+		if err != nil {
+			return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
+		}
+		if videoInfo.Code != 0 {
+			return s.createErrorResult(errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "获取视频信息失败"))
+		}
+		if len(videoInfo.Data.Pages) == 0 {
+			return s.createErrorResult(errors.New("该视频没有可用的分P"))
+		}
+		cid = videoInfo.Data.Pages[0].Cid
+	}
+
+	items, _, err := apiClient.GetDanmaku(cid)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取弹幕失败"))
+	}
+
+	total := len(items)
+	if total > limit {
+		items = items[:limit]
+	}
+
+	danmaku := make([]danmakuItem, 0, len(items))
+	for _, item := range items {
+		danmaku = append(danmaku, danmakuItem{
+			ProgressMs: int64(item.Time * 1000),
+			Time:       formatTimestamp(int64(item.Time)),
+			Content:    item.Content,
+			Mode:       item.Mode,
+			Color:      fmt.Sprintf("%06X", item.Color),
+			SendTime:   time.Unix(item.Timestamp, 0).Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	result := map[string]interface{}{
+		"video_id":  videoID,
+		"cid":       cid,
+		"total":     total,
+		"returned":  len(danmaku),
+		"truncated": total > len(danmaku),
+		"danmaku":   danmaku,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	return s.createToolResult(string(jsonData), false)
+}
+
+// handleDownloadDanmaku 下载视频弹幕（json/xml/ass）
+func (s *Server) handleDownloadDanmaku(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	cid, err := s.getInt64Arg(args, "cid")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	format := download.DanmakuFormatASS
+	if f, ok := args["format"].(string); ok && f != "" {
+		format = download.DanmakuFormat(strings.ToLower(f))
+	}
+	switch format {
+	case download.DanmakuFormatJSON, download.DanmakuFormatXML, download.DanmakuFormatASS:
+	default:
+		return s.createErrorResult(errors.Errorf("不支持的弹幕格式: %s，支持的格式: json, xml, ass", format))
+	}
+
+	outputDir := "./downloads"
+	if dir, ok := args["output_dir"].(string); ok && dir != "" {
+		outputDir = dir
+	}
+
+	// 弹幕接口不需要登录
+	apiClient := api.NewClient(map[string]string{})
+	danmakuService := download.NewDanmakuDownloadService(apiClient, outputDir)
+
+	result, err := danmakuService.DownloadDanmaku(ctx, videoID, cid, format)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "下载弹幕失败"))
+	}
+
+	var message strings.Builder
+	message.WriteString("💬 弹幕下载完成！\n\n")
+	message.WriteString(fmt.Sprintf("   • 标题: %s\n", result.Title))
+	message.WriteString(fmt.Sprintf("   • CID: %d\n", result.CID))
+	message.WriteString(fmt.Sprintf("   • 格式: %s\n", result.Format))
+	message.WriteString(fmt.Sprintf("   • 弹幕数量: %d\n", result.Count))
+	message.WriteString(fmt.Sprintf("   • 文件: %s (%s)\n", result.FilePath, formatFileSize(result.FileSize)))
+
+	if result.Format == download.DanmakuFormatASS {
+		message.WriteString("\n💡 提示：ASS文件可作为外挂字幕使用，或通过ffmpeg烧录到视频中：\n")
+		message.WriteString(fmt.Sprintf("   ffmpeg -i video.mp4 -vf \"ass='%s'\" output.mp4\n", result.FilePath))
+	}
+
+	return s.createToolResult(message.String(), false)
+}
+
+// handleDownloadCover 下载视频封面
+func (s *Server) handleDownloadCover(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	outputDir := "./downloads"
+	if dir, ok := args["output_dir"].(string); ok && dir != "" {
+		outputDir = dir
+	}
+
+	// 封面不需要登录
+	apiClient := api.NewClient(map[string]string{})
+	result, err := download.NewCoverService(apiClient).Download(ctx, videoID, outputDir)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "下载封面失败"))
+	}
+
+	var message strings.Builder
+	message.WriteString("🖼️ 封面下载完成！\n\n")
+	message.WriteString(fmt.Sprintf("   • 标题: %s\n", result.Title))
+	message.WriteString(fmt.Sprintf("   • 封面地址: %s\n", result.CoverURL))
+	message.WriteString(fmt.Sprintf("   • 文件: %s (%s)\n", result.FilePath, formatFileSize(result.FileSize)))
+
+	return s.createToolResult(message.String(), false)
+}
+
+// handleDownloadSubtitle 下载视频官方字幕并转换为SRT
+func (s *Server) handleDownloadSubtitle(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	language, _ := args["language"].(string)
+
+	outputDir := "./downloads"
+	if dir, ok := args["output_dir"].(string); ok && dir != "" {
+		outputDir = dir
+	}
+
+	// 字幕列表和字幕文件需要登录cookies才能完整获取
+	apiClient, err := s.getAuthedAPIClient(ctx, s.getAccountName(args))
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+	// 未登录时缓存的视频信息不包含字幕列表，需要重新获取
+	apiClient.SetNoCache(true)
+
+	subtitleService := download.NewSubtitleDownloadService(apiClient, outputDir)
+	result, err := subtitleService.DownloadSubtitle(ctx, videoID, language)
+	if err != nil {
+		if errors.Is(err, download.ErrNoSubtitle) {
+			return s.createToolResult(fmt.Sprintf("视频 %s 没有可用的官方字幕。可以使用 download_media 下载音频后，通过 whisper_audio_2_text 转录", videoID), false)
+		}
+		return s.createErrorResult(errors.Wrap(err, "下载字幕失败"))
+	}
+
+	var message strings.Builder
+	message.WriteString("📜 字幕下载完成！\n\n")
+	message.WriteString(fmt.Sprintf("   • 标题: %s\n", result.Title))
+	message.WriteString(fmt.Sprintf("   • 语言: %s (%s)\n", result.LanguageName, result.Language))
+	message.WriteString(fmt.Sprintf("   • 字幕条数: %d\n", result.Count))
+	message.WriteString(fmt.Sprintf("   • 文件: %s (%s)\n", result.FilePath, formatFileSize(result.FileSize)))
+	if len(result.Available) > 1 {
+		languages := make([]string, 0, len(result.Available))
+		for _, info := range result.Available {
+			languages = append(languages, fmt.Sprintf("%s(%s)", info.Language, info.LanguageName))
+		}
+		message.WriteString(fmt.Sprintf("   • 其他可用语言: %s\n", strings.Join(languages, ", ")))
+	}
+
+	message.WriteString("\n📝 字幕文本\n")
+	message.WriteString(result.Text)
+	message.WriteString("\n")
+
+	return s.createToolResult(message.String(), false)
+}
+
+// handleGetVideoSubtitle 通过播放器接口获取字幕列表，指定语言时返回转换后的SRT文本
+func (s *Server) handleGetVideoSubtitle(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	cid, err := s.getInt64Arg(args, "cid")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+	language, _ := args["language"].(string)
+
+	// 播放器接口只有登录后才返回字幕（尤其是AI字幕）
+	apiClient, err := s.getAuthedAPIClient(ctx, s.getAccountName(args))
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	title := videoID
+	if cid == 0 {
+		videoInfo, err := apiClient.GetVideoInfo(videoID)
+		if err != nil {
+			return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
+		}
+		if videoInfo.Code != 0 {
+			return s.createErrorResult(errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "API返回错误"))
+		}
+		cid = videoInfo.Data.Cid
+		title = videoInfo.Data.Title
+	}
+
+	list, err := apiClient.GetSubtitleList(videoID, cid)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取字幕列表失败"))
+	}
+	if len(list) == 0 {
+		return s.createToolResult(fmt.Sprintf("视频 %s 没有可用的字幕。可以使用 download_media 下载音频后，通过 whisper_audio_2_text 转录", videoID), false)
+	}
+
+	if language == "" {
+		var message strings.Builder
+		message.WriteString(fmt.Sprintf("📜 %s - 可用字幕 (CID: %d)\n\n", title, cid))
+		for i, meta := range list {
+			kind := "人工字幕"
+			if meta.IsAI() {
+				kind = "AI字幕"
+			}
+			message.WriteString(fmt.Sprintf("%d. %s (%s) - %s\n", i+1, meta.LanDoc, meta.Lan, kind))
+		}
+		message.WriteString("\n💡 指定 language 参数即可获取对应语言的SRT字幕")
+		return s.createToolResult(message.String(), false)
+	}
+
+	var chosen *api.SubtitleMeta
+	for i := range list {
+		if strings.EqualFold(list[i].Lan, language) {
+			chosen = &list[i]
+			break
+		}
+	}
+	if chosen == nil {
+		languages := make([]string, 0, len(list))
+		for _, meta := range list {
+			languages = append(languages, fmt.Sprintf("%s(%s)", meta.Lan, meta.LanDoc))
+		}
+		return s.createToolResult(fmt.Sprintf("没有 %s 语言的字幕，可用语言: %s", language, strings.Join(languages, ", ")), true)
+	}
+
+	content, err := apiClient.GetSubtitleContent(chosen.SubtitleURL)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取字幕内容失败"))
+	}
+
+	segments := make([]subtitles.Segment, 0, len(content.Body))
+	for _, line := range content.Body {
+		segments = append(segments, subtitles.Segment{From: line.From, To: line.To, Content: line.Content})
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("📜 %s - %s (%s)，共 %d 条\n\n", title, chosen.LanDoc, chosen.Lan, len(segments)))
+	message.WriteString(subtitles.SegmentsToSRT(segments))
+
+	return s.createToolResult(message.String(), false)
+}
+
+// handleGetUserInfo 获取用户空间资料
+func (s *Server) handleGetUserInfo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	userInput, ok := args["user_id"].(string)
+	if !ok || userInput == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: user_id"))
+	}
+
+	accountName := s.getAccountName(args)
+	userID, err := s.resolveUserID(ctx, userInput, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 检查频率限制 - 按查询的用户限流
+	if err := s.rateLimiter.Check(accountName, "get_user_info", userID); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 获取用户资料不需要登录，指定账号时携带cookies可降低风控概率
+	apiClient := api.NewClient(map[string]string{})
+	if accountName != "" {
+		authedClient, err := s.getAuthedAPIClient(ctx, accountName)
+		if err != nil {
+			return s.createErrorResult(err)
+		}
+		apiClient = authedClient
+	}
+
+	info, err := apiClient.GetUserInfo(userID)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取用户信息失败"))
+	}
+	if info.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(info.Code, info.Message), "API返回错误"))
+	}
+
+	result := map[string]interface{}{
+		"mid":         info.Data.Mid,
+		"name":        info.Data.Name,
+		"sign":        info.Data.Sign,
+		"level":       info.Data.Level,
+		"sex":         info.Data.Sex,
+		"face":        info.Data.Face,
+		"is_followed": info.Data.IsFollowed,
+	}
+	if info.Data.Official.Type >= 0 && info.Data.Official.Title != "" {
+		result["official"] = info.Data.Official.Title
+	}
+	if info.Data.Vip.Status == 1 && info.Data.Vip.Label.Text != "" {
+		result["vip"] = info.Data.Vip.Label.Text
+	}
+
+	// 关注数和粉丝数获取失败不影响基本资料
+	if stat, err := apiClient.GetRelationStat(userID); err != nil {
+		logger.Warnf("获取用户关系统计失败: %v", err)
+	} else if stat.Code != 0 {
+		logger.Warnf("获取用户关系统计失败: %s (code: %d)", stat.Message, stat.Code)
+	} else {
+		result["following"] = stat.Data.Following
+		result["follower"] = stat.Data.Follower
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	return s.createToolResult(string(jsonData), false)
+}
+
+// handleGetUserVideos 获取用户视频列表
+func (s *Server) handleGetUserVideos(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	userInput, ok := args["user_id"].(string)
+	if !ok || userInput == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: user_id"))
+	}
+
+	userID, err := s.resolveUserID(ctx, userInput, s.getAccountName(args))
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 检查频率限制 - 按查询的用户限流
+	if err := s.rateLimiter.Check(s.getAccountName(args), "get_user_videos", userID); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 获取页码参数
+	page := 1
+	if p, ok := args["page"].(float64); ok {
+		page = int(p)
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	// 获取每页数量参数
+	pageSize := 20
+	if ps, ok := args["page_size"].(float64); ok {
+		pageSize = int(ps)
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 50 {
+		pageSize = 50
+	}
+
+	logger.Infof("获取用户视频列表 - 用户ID: %s, 页码: %d, 每页数量: %d", userID, page, pageSize)
+
+	// 创建API客户端（获取用户视频列表不需要登录，指定账号时携带cookies可降低风控概率）
+	apiClient := api.NewClient(map[string]string{})
+	apiClient.SetContext(ctx)
+	if accountName := s.getAccountName(args); accountName != "" {
+		authedClient, err := s.getAuthedAPIClient(ctx, accountName)
+		if err != nil {
+			return s.createErrorResult(err)
+		}
+		apiClient = authedClient
+	}
+
+	// 获取用户视频列表
+	userVideos, err := apiClient.GetUserVideos(userID, page, pageSize)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取用户视频列表失败"))
+	}
+
+	if userVideos.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(userVideos.Code, userVideos.Message), "API返回错误"))
+	}
+
+	// 格式化输出
+	result := map[string]interface{}{
+		"user_id":     userID,
+		"page":        userVideos.Data.Page.Pn,
+		"page_size":   userVideos.Data.Page.Ps,
+		"total_count": userVideos.Data.Page.Count,
+		"videos":      userVideos.Data.List.Vlist,
+		"categories":  userVideos.Data.List.Tlist,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	return s.createToolResult(string(jsonData), false)
+}
+
+// handleLikeVideo 点赞视频 - 使用API优先
+func (s *Server) handleLikeVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 获取点赞状态，默认为true（点赞）
+	like := true
+	if likeArg, ok := args["like"].(bool); ok {
+		like = likeArg
+	}
+
+	accountName := s.getAccountName(args)
+	logger.Infof("点赞视频 - 使用账号: '%s' (空表示默认账号)", accountName)
+
+	// 检查频率限制
+	if err := s.rateLimiter.Check(accountName, "like_video", videoID); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 从多个域名获取完整cookie，确保包含bili_jct
+	allCookies, err := s.getAccountCookies(accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient := api.NewClient(allCookies)
+
+	// 使用API点赞视频
+	action := 1
+	if !like {
+		action = 2 // 取消点赞
+	}
+
+	likeResp, err := apiClient.LikeVideo(videoID, action)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "点赞视频失败"))
+	}
+
+	if likeResp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(likeResp.Code, likeResp.Message), "API返回错误"))
+	}
+
+	actionText := "点赞"
+	if !like {
+		actionText = "取消点赞"
+	}
+
+	return s.createToolResult(fmt.Sprintf("%s成功 - 视频: %s", actionText, videoID), false)
+}
+
+// handleGetVideoRelation 查询当前账号与视频的互动状态
+func (s *Server) handleGetVideoRelation(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient, err := s.getAuthedAPIClient(ctx, s.getAccountName(args))
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	relationResp, err := apiClient.GetVideoRelation(videoID)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取视频互动状态失败"))
+	}
+
+	if relationResp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(relationResp.Code, relationResp.Message), "API返回错误"))
+	}
+
+	result := map[string]interface{}{
+		"video_id":  videoID,
+		"like":      relationResp.Data.Like,
+		"coin":      relationResp.Data.Coin,
+		"favorite":  relationResp.Data.Favorite,
+		"dislike":   relationResp.Data.Dislike,
+		"attention": relationResp.Data.Attention,
+	}
+
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	return s.createToolResult(string(jsonData), false)
+}
+
+// maxDanmakuLength 单条弹幕的最大字符数
+const maxDanmakuLength = 100
+
+// handleSendDanmaku 发送弹幕
+func (s *Server) handleSendDanmaku(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	message, _ := args["message"].(string)
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: message"))
+	}
+	if length := len([]rune(message)); length > maxDanmakuLength {
+		return s.createErrorResult(errors.Errorf("弹幕内容过长: %d 个字符，最多 %d 个字符", length, maxDanmakuLength))
+	}
+
+	cid, err := s.getInt64Arg(args, "cid")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	progress := 0.0
+	if p, ok := args["progress"].(float64); ok {
+		if p < 0 {
+			return s.createErrorResult(errors.New("progress不能为负数"))
+		}
+		progress = p
+	}
+
+	mode := api.DanmakuModeScroll
+	if m, ok := args["mode"].(float64); ok {
+		mode = int(m)
+	}
+	switch mode {
+	case api.DanmakuModeScroll, api.DanmakuModeBottom, api.DanmakuModeTop:
+	default:
+		return s.createErrorResult(errors.Errorf("不支持的弹幕类型: %d，支持: 1=滚动, 4=底部, 5=顶部", mode))
+	}
+
+	color := api.DanmakuColorWhite
+	if c, ok := args["color"].(string); ok && c != "" {
+		parsed, err := strconv.ParseInt(strings.TrimPrefix(c, "#"), 16, 64)
+		if err != nil || parsed < 0 || parsed > 0xFFFFFF {
+			return s.createErrorResult(errors.Errorf("无效的弹幕颜色: %s，请使用十六进制RGB，如 FFFFFF", c))
+		}
+		color = int(parsed)
+	}
+
+	accountName := s.getAccountName(args)
+
+	// 检查频率限制
+	if err := s.rateLimiter.Check(accountName, "send_danmaku", ""); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 未指定CID时使用第一个分P
+	if cid == 0 {
+		videoInfo, err := apiClient.GetVideoInfo(videoID)
+		if err != nil {
+			return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
+		}
+		if videoInfo.Code != 0 {
+			return s.createErrorResult(errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "获取视频信息失败"))
+		}
+		if len(videoInfo.Data.Pages) == 0 {
+			return s.createErrorResult(errors.New("该视频没有可用的分P"))
+		}
+		cid = videoInfo.Data.Pages[0].Cid
+	}
+
+	resp, err := apiClient.SendDanmaku(videoID, cid, int(progress*1000), message, mode, color)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "发送弹幕失败"))
+	}
+
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
+
+	logger.Infof("💬 发送弹幕成功 - 视频: %s, CID: %d, 时间: %.1fs", videoID, cid, progress)
+	return s.createToolResult(fmt.Sprintf("发送弹幕成功 - 视频: %s, 时间点: %s, 弹幕ID: %s", videoID, formatTimestamp(int64(progress)), resp.Data.DmIDStr), false)
+}
+
+// handleTripleVideo 一键三连
+func (s *Server) handleTripleVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	accountName := s.getAccountName(args)
+
+	// 检查频率限制
+	if err := s.rateLimiter.Check(accountName, "triple_video", videoID); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	allCookies, err := s.getAccountCookies(accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient := api.NewClient(allCookies)
+
+	tripleResp, err := apiClient.TripleVideo(videoID)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "一键三连失败"))
+	}
+
+	if tripleResp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(tripleResp.Code, tripleResp.Message), "API返回错误"))
+	}
+
+	status := func(ok bool) string {
+		if ok {
+			return "✅"
+		}
+		return "❌"
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("一键三连完成 - 视频: %s\n", videoID))
+	message.WriteString(fmt.Sprintf("   • 点赞: %s\n", status(tripleResp.Data.Like)))
+	message.WriteString(fmt.Sprintf("   • 投币: %s", status(tripleResp.Data.Coin)))
+	if tripleResp.Data.Coin {
+		message.WriteString(fmt.Sprintf(" (%d枚)", tripleResp.Data.Multiply))
+	}
+	message.WriteString("\n")
+	message.WriteString(fmt.Sprintf("   • 收藏: %s\n", status(tripleResp.Data.Fav)))
+	if !tripleResp.Data.Coin {
+		message.WriteString("\n💡 投币未成功，可能已投过币、硬币不足或该视频不可投币\n")
+	}
+
+	logger.Infof("🎉 一键三连 - 视频: %s, 点赞: %v, 投币: %v, 收藏: %v", videoID, tripleResp.Data.Like, tripleResp.Data.Coin, tripleResp.Data.Fav)
+	return s.createToolResult(message.String(), false)
+}
+
+// handleDislikeVideo 标记视频为不喜欢
+func (s *Server) handleDislikeVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 默认为标记不喜欢
+	dislike := true
+	if dislikeArg, ok := args["dislike"].(bool); ok {
+		dislike = dislikeArg
+	}
+
+	accountName := s.getAccountName(args)
+
+	// 检查频率限制
+	if err := s.rateLimiter.Check(accountName, "dislike_video", videoID); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	action := 1
+	actionText := "标记不喜欢"
+	if !dislike {
+		action = 2
+		actionText = "取消不喜欢"
+	}
+
+	resp, err := apiClient.DislikeVideo(videoID, action)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, actionText+"失败"))
+	}
+
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
+
+	logger.Infof("👎 %s成功 - 视频: %s", actionText, videoID)
+	return s.createToolResult(fmt.Sprintf("%s成功 - 视频: %s", actionText, videoID), false)
+}
+
+// handleShareVideo 分享视频
+func (s *Server) handleShareVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	accountName := s.getAccountName(args)
+
+	// 检查频率限制
+	if err := s.rateLimiter.Check(accountName, "share_video", videoID); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	resp, err := apiClient.ShareVideo(videoID)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "分享视频失败"))
+	}
+
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
+
+	logger.Infof("🔗 分享视频成功 - 视频: %s, 当前分享数: %d", videoID, resp.Data)
+	return s.createToolResult(fmt.Sprintf("分享成功 - 视频: %s, 当前分享数: %d", videoID, resp.Data), false)
+}
+
+// handleCoinVideo 投币视频
+func (s *Server) handleCoinVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	coinCount := 1
+	if count, ok := args["coin_count"].(float64); ok {
+		coinCount = int(count)
+		if coinCount < 1 || coinCount > 2 {
+			coinCount = 1
+		}
+	}
+
+	// 是否同时点赞
+	alsoLike := false
+	if like, ok := args["also_like"].(bool); ok {
+		alsoLike = like
+	}
+
+	accountName := s.getAccountName(args)
+
+	// 检查频率限制
+	if err := s.rateLimiter.Check(accountName, "coin_video", videoID); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 直接读取账号cookies创建API客户端，无需启动浏览器
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 使用API投币视频
+	coinResp, err := apiClient.CoinVideo(videoID, coinCount, alsoLike)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "投币视频失败"))
+	}
+
+	if coinResp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(coinResp.Code, coinResp.Message), "API返回错误"))
+	}
+
+	resultMsg := fmt.Sprintf("投币成功 - 视频: %s, 数量: %d", videoID, coinCount)
+	if alsoLike && coinResp.Data.Like {
+		resultMsg += " (同时点赞)"
+	}
+
+	return s.createToolResult(resultMsg, false)
+}
+
+// handleFavoriteVideo 收藏视频
+func (s *Server) handleFavoriteVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	folderID := ""
+	if id, ok := args["folder_id"].(string); ok {
+		folderID = id
+	}
+
+	accountName := s.getAccountName(args)
+
+	// 检查频率限制
+	if err := s.rateLimiter.Check(accountName, "favorite_video", videoID); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 直接读取账号cookies创建API客户端，无需启动浏览器
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	// 使用API收藏视频
+	folderIDs := []string{}
+	if folderID != "" {
+		folderIDs = []string{folderID}
+	}
+
+	// 指定了要移出的收藏夹时，在一次请求中完成移动
+	removeIDs := parseFolderIDs(args["remove_from_folders"])
+
+	var favResp *api.FavoriteVideoResponse
+	if len(removeIDs) > 0 {
+		if len(folderIDs) == 0 {
+			return s.createToolResult("移动视频时需要同时指定目标收藏夹folder_id", true)
+		}
+		favResp, err = apiClient.DealFavorite(videoID, folderIDs, removeIDs)
+	} else {
+		favResp, err = apiClient.FavoriteVideo(videoID, folderIDs, true)
+	}
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "收藏视频失败"))
+	}
+
+	if favResp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(favResp.Code, favResp.Message), "API返回错误"))
+	}
+
+	if len(removeIDs) > 0 {
+		return s.createToolResult(fmt.Sprintf("移动成功 - 视频: %s, 已移出收藏夹: %s, 已加入收藏夹: %s", videoID, strings.Join(removeIDs, ","), folderID), false)
+	}
+	return s.createToolResult(fmt.Sprintf("收藏成功 - 视频: %s", videoID), false)
+}
+
+// handleUnfavoriteVideo 将视频移出收藏夹
+func (s *Server) handleUnfavoriteVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	folderIDs := parseFolderIDs(args["folder_id"])
+
+	accountName := s.getAccountName(args)
+
+	// 与收藏共用频率限制，避免反复收藏/取消
+	if err := s.rateLimiter.Check(accountName, "favorite_video", videoID); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	favResp, err := apiClient.UnfavoriteVideo(videoID, folderIDs)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "取消收藏失败"))
+	}
+
+	if favResp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(favResp.Code, favResp.Message), "API返回错误"))
+	}
+
+	if len(folderIDs) == 0 {
+		return s.createToolResult(fmt.Sprintf("取消收藏成功 - 视频: %s（默认收藏夹）", videoID), false)
+	}
+	return s.createToolResult(fmt.Sprintf("取消收藏成功 - 视频: %s, 收藏夹: %s", videoID, strings.Join(folderIDs, ",")), false)
+}
+
+// parseFolderIDs 解析逗号分隔的收藏夹ID列表，忽略空项
+func parseFolderIDs(arg interface{}) []string {
+	raw, _ := arg.(string)
+	var ids []string
+	for _, id := range strings.Split(raw, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// 用户相关处理器
+
+// handleResolveUser 根据用户名或空间链接查找用户UID
+func (s *Server) handleResolveUser(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	query, _ := args["query"].(string)
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return s.createToolResult("缺少query参数", true)
+	}
+
+	if mid, ok := api.ParseUserID(query); ok {
+		return s.createToolResult(fmt.Sprintf("✅ 解析到用户UID: %s", mid), false)
+	}
+
+	limit := 10
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	if limit > 20 {
+		limit = 20
+	}
+
+	if err := s.rateLimiter.Check(s.getAccountName(args), "resolve_user", query); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient := api.NewClient(map[string]string{})
+	apiClient.SetContext(ctx)
+	if accountName := s.getAccountName(args); accountName != "" {
+		authedClient, err := s.getAuthedAPIClient(ctx, accountName)
+		if err != nil {
+			return s.createErrorResult(err)
+		}
+		apiClient = authedClient
+	}
+
+	resp, err := apiClient.SearchUsers(query, 1)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "搜索用户失败"))
+	}
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
+
+	if len(resp.Data.Result) == 0 {
+		return s.createToolResult(fmt.Sprintf("未找到与 %s 相关的用户", query), false)
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("🔍 用户搜索结果: %s（共 %d 个）\n\n", query, resp.Data.NumResults))
+	for i, user := range resp.Data.Result {
+		if i >= limit {
+			break
+		}
+		marker := ""
+		if strings.EqualFold(user.Uname, query) {
+			marker = " ✅ 完全匹配"
+		}
+		message.WriteString(fmt.Sprintf("%d. %s (UID: %d)%s\n", i+1, user.Uname, user.Mid, marker))
+		message.WriteString(fmt.Sprintf("   • 粉丝: %d | 投稿: %d | 等级: Lv%d\n", user.Fans, user.Videos, user.Level))
+		if user.Usign != "" {
+			message.WriteString(fmt.Sprintf("   • 签名: %s\n", user.Usign))
+		}
+	}
+
+	return s.createToolResult(message.String(), false)
+}
+
+// handleSearchVideos 按关键词搜索视频
+func (s *Server) handleSearchVideos(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	keyword, _ := args["keyword"].(string)
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return s.createToolResult("缺少keyword参数", true)
+	}
+
+	page := 1
+	if p, ok := args["page"].(float64); ok && p > 0 {
+		page = int(p)
+	}
+	order, _ := args["order"].(string)
+
+	if err := s.rateLimiter.Check(s.getAccountName(args), "search_videos", fmt.Sprintf("%s#%d", keyword, page)); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient := api.NewClient(map[string]string{})
+	apiClient.SetContext(ctx)
+	if accountName := s.getAccountName(args); accountName != "" {
+		authedClient, err := s.getAuthedAPIClient(ctx, accountName)
+		if err != nil {
+			return s.createErrorResult(err)
+		}
+		apiClient = authedClient
+	}
+
+	resp, err := apiClient.SearchVideos(keyword, page, order)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "搜索视频失败"))
+	}
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
+
+	if len(resp.Data.Result) == 0 {
+		return s.createToolResult(fmt.Sprintf("未找到与 %s 相关的视频（第 %d 页）", keyword, page), false)
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("🔍 视频搜索结果: %s（共 %d 个，第 %d/%d 页）\n\n", keyword, resp.Data.NumResults, page, resp.Data.NumPages))
+	for i, video := range resp.Data.Result {
+		message.WriteString(fmt.Sprintf("%d. %s\n", i+1, video.Title))
+		message.WriteString(fmt.Sprintf("   • BV号: %s | UP主: %s (UID: %d)\n", video.Bvid, video.Author, video.Mid))
+		message.WriteString(fmt.Sprintf("   • 播放: %d | 时长: %s | 发布: %s\n",
+			video.Play, video.Duration, time.Unix(video.Pubdate, 0).Format("2006-01-02")))
+	}
+	if page < resp.Data.NumPages {
+		message.WriteString(fmt.Sprintf("\n💡 传入 page=%d 查看下一页\n", page+1))
+	}
+
+	return s.createToolResult(message.String(), false)
+}
+
+// handleGetRelatedVideos 获取视频的相关推荐
+func (s *Server) handleGetRelatedVideos(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient := api.NewClient(map[string]string{})
+	apiClient.SetContext(ctx)
+
+	resp, err := apiClient.GetRelatedVideos(videoID)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取相关推荐失败"))
+	}
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
+
+	if len(resp.Data) == 0 {
+		return s.createToolResult(fmt.Sprintf("视频 %s 没有相关推荐", videoID), false)
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("🎬 视频 %s 的相关推荐（共 %d 个）\n\n", videoID, len(resp.Data)))
+	writeArchiveList(&message, resp.Data)
+
+	return s.createToolResult(message.String(), false)
+}
+
+// writeArchiveList 格式化视频列表
+func writeArchiveList(message *strings.Builder, videos []api.ArchiveItem) {
+	for i, video := range videos {
+		message.WriteString(fmt.Sprintf("%d. %s\n", i+1, video.Title))
+		message.WriteString(fmt.Sprintf("   • BV号: %s | UP主: %s (UID: %d)\n", video.Bvid, video.Owner.Name, video.Owner.Mid))
+		message.WriteString(fmt.Sprintf("   • 播放: %d | 时长: %s\n", video.Stat.View, formatTimestamp(video.Duration)))
+		if desc := shortDescription(video.Desc); desc != "" {
+			message.WriteString(fmt.Sprintf("   • 简介: %s\n", desc))
+		}
+	}
+}
+
+// shortDescription 将视频简介压缩为一行并截断，空简介和占位符 "-" 返回空字符串
+func shortDescription(desc string) string {
+	desc = strings.Join(strings.Fields(desc), " ")
+	if desc == "-" {
+		return ""
+	}
+	if runes := []rune(desc); len(runes) > maxDescriptionChars {
+		desc = string(runes[:maxDescriptionChars]) + "…"
+	}
+	return desc
+}
+
+// maxDescriptionChars 视频列表中简介的最大字符数
+const maxDescriptionChars = 60
+
+// handleGetPopularVideos 获取综合热门视频
+func (s *Server) handleGetPopularVideos(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	page := 1
+	if p, ok := args["page"].(float64); ok && p >= 1 {
+		page = int(p)
+	}
+	pageSize := 20
+	if ps, ok := args["page_size"].(float64); ok && ps >= 1 && ps <= 50 {
+		pageSize = int(ps)
+	}
+
+	apiClient := api.NewClient(map[string]string{})
+	apiClient.SetContext(ctx)
+
+	resp, err := apiClient.GetPopularVideos(page, pageSize)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取热门视频失败"))
+	}
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
+
+	if len(resp.Data.List) == 0 {
+		return s.createToolResult(fmt.Sprintf("第 %d 页没有热门视频", page), false)
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("🔥 综合热门（第 %d 页，%d 个）\n\n", page, len(resp.Data.List)))
+	writeArchiveList(&message, resp.Data.List)
+	if !resp.Data.NoMore {
+		message.WriteString(fmt.Sprintf("\n💡 传入 page=%d 查看下一页\n", page+1))
+	}
+
+	return s.createToolResult(message.String(), false)
+}
+
+// handleGetRanking 获取分区排行榜
+func (s *Server) handleGetRanking(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	tid := 0
+	if t, ok := args["tid"].(float64); ok && t >= 0 {
+		tid = int(t)
+	}
+	limit := 20
+	if l, ok := args["limit"].(float64); ok && l >= 1 && l <= 100 {
+		limit = int(l)
+	}
+
+	apiClient := api.NewClient(map[string]string{})
+	apiClient.SetContext(ctx)
+
+	resp, err := apiClient.GetRanking(tid)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取排行榜失败"))
+	}
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
+
+	if len(resp.Data.List) == 0 {
+		return s.createToolResult(fmt.Sprintf("分区 %d 的排行榜为空，请检查分区ID", tid), false)
+	}
+
+	videos := resp.Data.List
+	if len(videos) > limit {
+		videos = videos[:limit]
+	}
+
+	var message strings.Builder
+	if tid == 0 {
+		message.WriteString(fmt.Sprintf("🏆 全站排行榜（前 %d 名）\n", len(videos)))
+	} else {
+		message.WriteString(fmt.Sprintf("🏆 分区 %d 排行榜（前 %d 名）\n", tid, len(videos)))
 	}
+	if resp.Data.Note != "" {
+		message.WriteString(resp.Data.Note + "\n")
+	}
+	message.WriteString("\n")
+	writeArchiveList(&message, videos)
 
-	accountName := s.getAccountName(args)
+	return s.createToolResult(message.String(), false)
+}
 
-	// 获取带认证的浏览器页面（仅用于获取cookies）
-	page, cleanup, err := s.browserPool.GetWithAuth(accountName)
+// handleListFavoriteFolders 列出收藏夹
+func (s *Server) handleListFavoriteFolders(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	apiClient, err := s.getAuthedAPIClient(ctx, s.getAccountName(args))
 	if err != nil {
 		return s.createErrorResult(err)
 	}
-	defer cleanup()
 
-	// 获取cookies并创建API客户端
-	cookies, err := page.Context().Cookies()
+	nav, err := apiClient.GetNavInfo()
 	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "获取cookies失败"))
+		return s.createErrorResult(errors.Wrap(err, "获取用户信息失败"))
+	}
+	if nav.Code != 0 || !nav.Data.IsLogin {
+		return s.createToolResult("账号未登录或登录已失效，请重新登录", true)
 	}
 
-	cookieMap := make(map[string]string)
-	for _, cookie := range cookies {
-		cookieMap[cookie.Name] = cookie.Value
+	resp, err := apiClient.ListFavoriteFolders(nav.Data.Mid)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取收藏夹列表失败"))
+	}
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
 	}
 
-	apiClient := api.NewClient(cookieMap)
+	folders := make([]map[string]interface{}, 0, len(resp.Data.List))
+	for i, folder := range resp.Data.List {
+		folders = append(folders, map[string]interface{}{
+			"id":          folder.ID,
+			"title":       folder.Title,
+			"media_count": folder.MediaCount,
+			"private":     folder.IsPrivate(),
+			"default":     i == 0,
+		})
+	}
 
-	// 创建媒体下载服务
-	mediaDownloadService := download.NewMediaDownloadService(apiClient, outputDir)
+	jsonData, err := json.MarshalIndent(map[string]interface{}{
+		"count":   resp.Data.Count,
+		"folders": folders,
+	}, "", "  ")
+	if err != nil {
+		return s.createErrorResult(err)
+	}
 
-	// 设置下载选项
-	opts := download.DownloadOptions{
-		MediaType: mediaType,
-		Quality:   quality,
-		CID:       cid,
+	return s.createToolResult(string(jsonData), false)
+}
+
+// handleCreateFavoriteFolder 创建收藏夹
+func (s *Server) handleCreateFavoriteFolder(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	title, _ := args["title"].(string)
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: title"))
 	}
 
-	// 下载媒体
-	result, err := mediaDownloadService.DownloadMedia(ctx, videoID, opts)
+	private, _ := args["private"].(bool)
+	accountName := s.getAccountName(args)
+
+	// 检查频率限制
+	if err := s.rateLimiter.Check(accountName, "create_favorite_folder", ""); err != nil {
+		return s.createErrorResult(err)
+	}
+
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
 	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "下载媒体失败"))
+		return s.createErrorResult(err)
 	}
 
-	// 构建格式化的结果信息
-	var message strings.Builder
-	message.WriteString("🎉 媒体下载完成！\n\n")
+	resp, err := apiClient.CreateFavoriteFolder(title, private)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "创建收藏夹失败"))
+	}
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
 
-	// 基本信息
-	message.WriteString("1. 视频信息\n")
-	message.WriteString(fmt.Sprintf("   • 标题: %s\n", result.Title))
-	message.WriteString(fmt.Sprintf("   • 类型: %s\n", result.MediaType))
-	message.WriteString(fmt.Sprintf("   • 时长: %d秒\n\n", result.Duration))
+	logger.Infof("📁 创建收藏夹成功 - %s (ID: %d)", title, resp.Data.ID)
+	return s.createToolResult(fmt.Sprintf("创建收藏夹成功 - 名称: %s, ID: %d", title, resp.Data.ID), false)
+}
 
-	// 当前下载清晰度信息
-	message.WriteString("2. 当前下载清晰度\n")
-	message.WriteString(fmt.Sprintf("   • 清晰度: %s", result.CurrentQuality.Description))
-	if result.CurrentQuality.Width > 0 && result.CurrentQuality.Height > 0 {
-		message.WriteString(fmt.Sprintf(" (%dx%d)", result.CurrentQuality.Width, result.CurrentQuality.Height))
+// handleAddWatchLater 加入稍后再看
+func (s *Server) handleAddWatchLater(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	return s.modifyWatchLater(ctx, args, true)
+}
+
+// handleRemoveWatchLater 移出稍后再看
+func (s *Server) handleRemoveWatchLater(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	return s.modifyWatchLater(ctx, args, false)
+}
+
+// modifyWatchLater 加入或移出稍后再看
+func (s *Server) modifyWatchLater(ctx context.Context, args map[string]interface{}, add bool) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
 	}
-	if result.CurrentQuality.HasAudio {
-		message.WriteString(" [包含音频]")
-	} else {
-		message.WriteString(" [纯视频，需合并音频]")
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
 	}
-	message.WriteString("\n\n")
 
-	// 可用清晰度列表
-	if len(result.AvailableQualities) > 0 {
-		message.WriteString("3. 所有可用清晰度\n")
-		for i, quality := range result.AvailableQualities {
-			marker := "   "
-			if quality.Quality == result.CurrentQuality.Quality {
-				marker = " ✓ " // 标记当前选择的清晰度
-			}
+	accountName := s.getAccountName(args)
 
-			message.WriteString(fmt.Sprintf("%s%s", marker, quality.Description))
-			if quality.Width > 0 && quality.Height > 0 {
-				message.WriteString(fmt.Sprintf(" (%dx%d)", quality.Width, quality.Height))
-			}
-			if quality.HasAudio {
-				message.WriteString(" [完整视频]")
-			} else {
-				message.WriteString(" [需合并]")
-			}
-			message.WriteString("\n")
+	// 检查频率限制
+	if err := s.rateLimiter.Check(accountName, "watch_later", videoID); err != nil {
+		return s.createErrorResult(err)
+	}
 
-			// 限制显示数量，避免信息过多
-			if i >= 9 {
-				remaining := len(result.AvailableQualities) - i - 1
-				if remaining > 0 {
-					message.WriteString(fmt.Sprintf("   ... 还有 %d 个清晰度可选\n", remaining))
-				}
-				break
-			}
-		}
-		message.WriteString("\n")
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
 	}
 
-	// 文件信息
-	sectionNum := 4
-	if len(result.AvailableQualities) == 0 {
-		sectionNum = 3
+	actionText := "加入稍后再看"
+	var resp *api.ToViewResponse
+	if add {
+		resp, err = apiClient.AddToWatchLater(videoID)
+	} else {
+		actionText = "移出稍后再看"
+		resp, err = apiClient.DeleteFromWatchLater(videoID)
 	}
-	message.WriteString(fmt.Sprintf("%d. 下载文件\n", sectionNum))
-	fileCount := 1
-	if result.MergedPath != "" {
-		message.WriteString(fmt.Sprintf("   %d) 完整视频: %s (%.2f MB)\n",
-			fileCount, filepath.Base(result.MergedPath), float64(result.MergedSize)/(1024*1024)))
-		fileCount++
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, actionText+"失败"))
 	}
-	if result.AudioPath != "" && result.MergedPath == "" {
-		message.WriteString(fmt.Sprintf("   %d) 音频文件: %s (%.2f MB)\n",
-			fileCount, filepath.Base(result.AudioPath), float64(result.AudioSize)/(1024*1024)))
-		fileCount++
+
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
 	}
-	if result.VideoPath != "" && result.MergedPath == "" {
-		message.WriteString(fmt.Sprintf("   %d) 视频文件: %s (%.2f MB)\n",
-			fileCount, filepath.Base(result.VideoPath), float64(result.VideoSize)/(1024*1024)))
-		fileCount++
+
+	logger.Infof("🕒 %s成功 - 视频: %s", actionText, videoID)
+	return s.createToolResult(fmt.Sprintf("%s成功 - 视频: %s", actionText, videoID), false)
+}
+
+// handleListWatchLater 获取稍后再看列表
+func (s *Server) handleListWatchLater(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	apiClient, err := s.getAuthedAPIClient(ctx, s.getAccountName(args))
+	if err != nil {
+		return s.createErrorResult(err)
 	}
 
-	// 合并提示和高清视频建议
-	sectionNum++
-	needsSection := result.MergeRequired || (!result.CurrentQuality.HasAudio && result.MediaType == download.MediaTypeMerged)
+	resp, err := apiClient.GetWatchLater()
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取稍后再看列表失败"))
+	}
 
-	if needsSection {
-		message.WriteString(fmt.Sprintf("\n%d. 重要提示\n", sectionNum))
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
+	}
 
-		if result.MergeRequired && result.MergeCommand != "" {
-			message.WriteString("   ⚠️  当前下载的视频为：纯视频 + 音频，需要手动合并\n")
-			message.WriteString(fmt.Sprintf("   请执行：%s\n", result.MergeCommand))
-		}
+	videos := make([]map[string]interface{}, 0, len(resp.Data.List))
+	for _, item := range resp.Data.List {
+		videos = append(videos, map[string]interface{}{
+			"bvid":     item.Bvid,
+			"title":    item.Title,
+			"author":   item.Owner.Name,
+			"duration": formatTimestamp(item.Duration),
+			"progress": item.Progress,
+			"added_at": time.Unix(item.AddAt, 0).Format("2006-01-02 15:04:05"),
+		})
+	}
 
-		// 如果下载的是纯视频，提示用户可以下载高清
-		if !result.CurrentQuality.HasAudio && result.MediaType == download.MediaTypeMerged {
-			message.WriteString("\n   💡 提示：如果需要更高清晰度的视频，可以指定 quality 参数\n")
-			message.WriteString("   例如：quality=80 (1080P), quality=112 (1080P+), quality=120 (4K)\n")
-			message.WriteString("   高清视频会自动下载音频并提供合并命令\n")
-		}
+	result := map[string]interface{}{
+		"count":  resp.Data.Count,
+		"videos": videos,
 	}
 
-	// 其他提示
-	if result.Notes != "" && !result.MergeRequired && !needsSection {
-		message.WriteString(fmt.Sprintf("\n%d. 提示信息\n", sectionNum))
-		message.WriteString(fmt.Sprintf("   📝 %s\n", result.Notes))
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return s.createErrorResult(err)
 	}
 
-	return s.createToolResult(message.String(), false)
+	return s.createToolResult(string(jsonData), false)
 }
 
-// handleGetUserVideos 获取用户视频列表
-func (s *Server) handleGetUserVideos(ctx context.Context, args map[string]interface{}) *MCPToolResult {
-	userID, ok := args["user_id"].(string)
-	if !ok || userID == "" {
-		return s.createErrorResult(errors.New("缺少必需的参数: user_id"))
+// handleFollowUser 关注用户
+func (s *Server) handleFollowUser(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	userInput, ok := args["user_id"].(string)
+	if !ok || userInput == "" {
+		return s.createToolResult("缺少user_id参数", true)
 	}
 
-	// 检查频率限制 - 每个用户每20秒最多请求一次
-	rateLimitKey := fmt.Sprintf("get_user_videos_%s", userID)
-	if err := checkRateLimit(rateLimitKey, 20*time.Second); err != nil {
-		return s.createErrorResult(err)
+	// 默认为关注
+	follow := true
+	if followArg, ok := args["follow"].(bool); ok {
+		follow = followArg
 	}
 
-	// 获取页码参数
-	page := 1
-	if p, ok := args["page"].(float64); ok {
-		page = int(p)
+	groupName, _ := args["group_name"].(string)
+	groupName = strings.TrimSpace(groupName)
+	if !follow && groupName != "" {
+		return s.createToolResult("取消关注时不能指定group_name", true)
 	}
-	if page < 1 {
-		page = 1
+
+	accountName := s.getAccountName(args)
+
+	userID, err := s.resolveUserID(ctx, userInput, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
 	}
 
-	// 获取每页数量参数
-	pageSize := 20
-	if ps, ok := args["page_size"].(float64); ok {
-		pageSize = int(ps)
+	// 检查频率限制
+	if err := s.rateLimiter.Check(accountName, "follow_user", userID); err != nil {
+		return s.createErrorResult(err)
 	}
-	if pageSize < 1 {
-		pageSize = 20
+
+	// 直接读取账号cookies创建API客户端，无需启动浏览器
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
 	}
-	if pageSize > 50 {
-		pageSize = 50
+
+	// 使用API关注用户 (1:关注 2:取消关注)
+	action := 1
+	actionText := "关注"
+	if !follow {
+		action = 2
+		actionText = "取消关注"
 	}
 
-	logger.Infof("获取用户视频列表 - 用户ID: %s, 页码: %d, 每页数量: %d", userID, page, pageSize)
+	followResp, err := apiClient.FollowUser(userID, action)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, actionText+"用户失败"))
+	}
 
-	// 创建API客户端（获取用户视频列表不需要登录）
-	apiClient := api.NewClient(map[string]string{})
+	if followResp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(followResp.Code, followResp.Message), "API返回错误"))
+	}
 
-	// 获取用户视频列表
-	userVideos, err := apiClient.GetUserVideos(userID, page, pageSize)
-	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "获取用户视频列表失败"))
+	// x/relation/modify 不返回关系状态，需要单独查询；查询失败不影响操作结果
+	relation := ""
+	if relationResp, err := apiClient.GetRelation(userID); err != nil {
+		logger.Warnf("查询关系状态失败: %v", err)
+	} else if relationResp.Code != 0 {
+		logger.Warnf("查询关系状态失败: %s (code: %d)", relationResp.Message, relationResp.Code)
+	} else {
+		relation = ", 关系状态: " + formatRelationStatus(relationResp.Data.Attribute)
 	}
 
-	if userVideos.Code != 0 {
-		return s.createErrorResult(errors.Errorf("API返回错误: %s (code: %d)", userVideos.Message, userVideos.Code))
+	// 可选：将用户加入指定的关注分组
+	if groupName == "" {
+		return s.createToolResult(fmt.Sprintf("%s成功 - 用户: %s%s", actionText, userID, relation), false)
 	}
 
-	// 格式化输出
-	result := map[string]interface{}{
-		"user_id":     userID,
-		"page":        userVideos.Data.Page.Pn,
-		"page_size":   userVideos.Data.Page.Ps,
-		"total_count": userVideos.Data.Page.Count,
-		"videos":      userVideos.Data.List.Vlist,
-		"categories":  userVideos.Data.List.Tlist,
+	tagID, created, err := apiClient.ResolveFollowTag(groupName)
+	if err != nil {
+		return s.createErrorResult(errors.Wrapf(err, "关注成功，但设置分组 '%s' 失败", groupName))
 	}
 
-	jsonData, err := json.MarshalIndent(result, "", "  ")
+	tagResp, err := apiClient.AddUsersToFollowTags([]string{userID}, []int64{tagID})
 	if err != nil {
-		return s.createErrorResult(err)
+		return s.createErrorResult(errors.Wrapf(err, "关注成功，但设置分组 '%s' 失败", groupName))
+	}
+	if tagResp.Code != 0 {
+		return s.createErrorResult(errors.Wrapf(api.NewAPIError(tagResp.Code, tagResp.Message), "关注成功，但设置分组 '%s' 失败", groupName))
 	}
 
-	return s.createToolResult(string(jsonData), false)
+	groupNote := ""
+	if created {
+		groupNote = "（新建分组）"
+	}
+	return s.createToolResult(fmt.Sprintf("关注成功 - 用户: %s, 分组: %s%s%s", userID, groupName, groupNote, relation), false)
 }
 
-// handleLikeVideo 点赞视频 - 使用API优先
-func (s *Server) handleLikeVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
-	videoID, ok := args["video_id"].(string)
-	if !ok || videoID == "" {
-		return s.createErrorResult(errors.New("缺少必需的参数: video_id"))
+// handleChargeUser 为UP主充电，会实际花费B币，必须显式确认
+func (s *Server) handleChargeUser(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	userInput, ok := args["user_id"].(string)
+	if !ok || userInput == "" {
+		return s.createToolResult("缺少user_id参数", true)
 	}
 
-	if err := s.validateVideoID(videoID); err != nil {
+	count, err := s.getInt64Arg(args, "count")
+	if err != nil {
 		return s.createErrorResult(err)
 	}
-
-	// 获取点赞状态，默认为true（点赞）
-	like := true
-	if likeArg, ok := args["like"].(bool); ok {
-		like = likeArg
+	if count < api.MinChargeBP || count > api.MaxChargeBP {
+		return s.createToolResult(fmt.Sprintf("count参数必须在 %d-%d 之间（B币数量）", api.MinChargeBP, api.MaxChargeBP), true)
 	}
 
 	accountName := s.getAccountName(args)
-	logger.Infof("点赞视频 - 使用账号: '%s' (空表示默认账号)", accountName)
 
-	// 检查频率限制
-	rateLimitKey := fmt.Sprintf("like_video_%s_%s", accountName, videoID)
-	if err := checkRateLimit(rateLimitKey, 5*time.Second); err != nil {
+	userID, err := s.resolveUserID(ctx, userInput, accountName)
+	if err != nil {
 		return s.createErrorResult(err)
 	}
-
-	// 获取带认证的浏览器页面（仅用于获取cookies）
-	page, cleanup, err := s.browserPool.GetWithAuth(accountName)
+	upMid, err := strconv.ParseInt(userID, 10, 64)
 	if err != nil {
-		logger.Errorf("获取浏览器页面失败: %v", err)
-		return s.createErrorResult(err)
+		return s.createErrorResult(errors.Errorf("无效的用户UID: %s", userID))
 	}
-	defer cleanup()
 
-	// 获取cookies并创建API客户端 - 从多个域名获取完整cookie
-	allCookies := make(map[string]string)
+	// 充电会花费真实货币，未确认时只返回将要花费的金额
+	cost := fmt.Sprintf("%d B币（%d 元，UP主获得 %d 电池）", count, count, count*10)
+	if confirm, _ := args["confirm"].(bool); !confirm {
+		return s.createToolResult(fmt.Sprintf("⚠️ 未执行充电：将为UP主 %s 充电 %s，会从账号B币余额中扣除。确认花费后请传入 confirm: true 再次调用", userID, cost), true)
+	}
 
-	// 获取所有相关域名的cookies
-	domains := []string{
-		"https://www.bilibili.com",
-		"https://api.bilibili.com",
-		"https://passport.bilibili.com",
-		"https://space.bilibili.com",
+	// 检查频率限制（按账号计算）
+	if err := s.rateLimiter.Check(accountName, "charge_user", ""); err != nil {
+		return s.createErrorResult(err)
 	}
 
-	for _, domain := range domains {
-		cookies, err := page.Context().Cookies(domain)
-		if err != nil {
-			logger.Warnf("获取%s域名cookies失败: %v", domain, err)
-			continue
-		}
+	// 直接读取账号cookies创建API客户端，无需启动浏览器
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
 
-		for _, cookie := range cookies {
-			allCookies[cookie.Name] = cookie.Value
+	logger.Warnf("💰 为UP主 %s 充电 %s，账号: %s", userID, cost, accountName)
+	resp, err := apiClient.ChargeUser(upMid, int(count))
+	if err != nil {
+		if errors.Is(err, api.ErrChargeResultUnknown) {
+			logger.Warnf("⚠️ 为UP主 %s 充电的结果未知: %v", userID, err)
+			return s.createToolResult(fmt.Sprintf("⚠️ 充电结果未知（%v）。B币可能已经扣除，请不要重复调用，先在B站钱包的消费记录中确认是否充电成功", err), true)
 		}
+		return s.createErrorResult(errors.Wrap(err, "充电失败"))
+	}
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "充电失败"))
 	}
 
-	// 如果还是没有bili_jct，尝试获取所有cookies
-	if _, exists := allCookies["bili_jct"]; !exists {
-		logger.Warn("从指定域名未获取到bili_jct，尝试获取所有cookies")
-		allPageCookies, err := page.Context().Cookies()
-		if err == nil {
-			for _, cookie := range allPageCookies {
-				allCookies[cookie.Name] = cookie.Value
-			}
-		}
+	switch resp.Data.Status {
+	case api.ChargeStatusSuccess:
+		return s.createToolResult(fmt.Sprintf("⚡ 充电成功 - UP主: %s, 花费: %s, 订单号: %s, 获得经验: %d", userID, cost, resp.Data.OrderNo, resp.Data.Exp), false)
+	case api.ChargeStatusNoBalance:
+		return s.createToolResult("充电失败：B币余额不足", true)
+	case api.ChargeStatusBelowMinimum:
+		return s.createToolResult("充电失败：低于最低充电数量", true)
+	default:
+		return s.createToolResult(fmt.Sprintf("充电未完成：%s (status: %d)", resp.Data.Msg, resp.Data.Status), true)
+	}
+}
+
+// formatRelationStatus 格式化关系状态
+func formatRelationStatus(status int) string {
+	switch status {
+	case 0:
+		return "未关注"
+	case 1:
+		return "悄悄关注"
+	case 2:
+		return "已关注"
+	case 6:
+		return "互相关注"
+	case 128:
+		return "已拉黑"
+	default:
+		return fmt.Sprintf("未知(%d)", status)
+	}
+}
+
+// handleReportVideo 举报视频
+func (s *Server) handleReportVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
 	}
 
-	// 调试：检查bili_jct是否存在
-	logger.Infof("调试cookie信息: 总数=%d", len(allCookies))
-	if biliJct, exists := allCookies["bili_jct"]; exists {
-		logger.Infof("bili_jct存在: %s", biliJct[:8]+"...")
-	} else {
-		logger.Warnf("bili_jct不存在，可用的cookies: %v", func() []string {
-			var names []string
-			for name := range allCookies {
-				names = append(names, name)
-			}
-			return names
-		}())
-
-		// 如果没有bili_jct，返回错误并提示重新登录
-		return s.createErrorResult(errors.New("缺少CSRF token (bili_jct)，请重新登录账号"))
+	reasonValue, ok := args["reason"].(float64)
+	if !ok {
+		return s.createToolResult("缺少reason参数", true)
+	}
+	reason := int(reasonValue)
+	reasonText, exists := api.VideoReportReasons[reason]
+	if !exists {
+		return s.createErrorResult(errors.Errorf("不支持的举报理由: %d", reason))
 	}
 
-	apiClient := api.NewClient(allCookies)
+	detail, _ := args["detail"].(string)
+	accountName := s.getAccountName(args)
 
-	// 使用API点赞视频
-	action := 1
-	if !like {
-		action = 2 // 取消点赞
+	// 举报按账号限流，而不是按视频
+	if err := s.rateLimiter.Check(accountName, "report", ""); err != nil {
+		return s.createErrorResult(err)
 	}
 
-	likeResp, err := apiClient.LikeVideo(videoID, action)
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
 	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "点赞视频失败"))
+		return s.createErrorResult(err)
 	}
 
-	if likeResp.Code != 0 {
-		return s.createErrorResult(errors.Errorf("API返回错误: %s (code: %d)", likeResp.Message, likeResp.Code))
+	infoResp, err := apiClient.GetVideoInfo(videoID)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
+	}
+	if infoResp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(infoResp.Code, infoResp.Message), "获取视频信息失败"))
 	}
 
-	actionText := "点赞"
-	if !like {
-		actionText = "取消点赞"
+	reportResp, err := apiClient.ReportVideo(infoResp.Data.Aid, reason, detail)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "举报视频失败"))
+	}
+	if reportResp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(reportResp.Code, reportResp.Message), "API返回错误"))
 	}
 
-	return s.createToolResult(fmt.Sprintf("%s成功 - 视频: %s", actionText, videoID), false)
+	logger.Infof("🚩 已举报视频 %s - 理由: %s", videoID, reasonText)
+	return s.createToolResult(fmt.Sprintf("举报已提交 - 视频: %s, 理由: %s", videoID, reasonText), false)
 }
 
-// handleCoinVideo 投币视频
-func (s *Server) handleCoinVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+// handleReportComment 举报评论
+func (s *Server) handleReportComment(ctx context.Context, args map[string]interface{}) *MCPToolResult {
 	videoID, ok := args["video_id"].(string)
 	if !ok || videoID == "" {
 		return s.createToolResult("缺少video_id参数", true)
 	}
-
-	if err := s.validateVideoID(videoID); err != nil {
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
 		return s.createErrorResult(err)
 	}
 
-	coinCount := 1
-	if count, ok := args["coin_count"].(float64); ok {
-		coinCount = int(count)
-		if coinCount < 1 || coinCount > 2 {
-			coinCount = 1
-		}
+	commentID, ok := args["comment_id"].(string)
+	if !ok || commentID == "" {
+		return s.createToolResult("缺少comment_id参数", true)
 	}
 
-	// 是否同时点赞
-	alsoLike := false
-	if like, ok := args["also_like"].(bool); ok {
-		alsoLike = like
+	reasonValue, ok := args["reason"].(float64)
+	if !ok {
+		return s.createToolResult("缺少reason参数", true)
+	}
+	reason := int(reasonValue)
+	reasonText, exists := api.CommentReportReasons[reason]
+	if !exists {
+		return s.createErrorResult(errors.Errorf("不支持的举报理由: %d", reason))
 	}
 
 	accountName := s.getAccountName(args)
 
-	// 检查频率限制
-	rateLimitKey := fmt.Sprintf("coin_video_%s_%s", accountName, videoID)
-	if err := checkRateLimit(rateLimitKey, 10*time.Second); err != nil {
+	if err := s.rateLimiter.Check(accountName, "report", ""); err != nil {
 		return s.createErrorResult(err)
 	}
 
-	// 获取带认证的浏览器页面（仅用于获取cookies）
-	page, cleanup, err := s.browserPool.GetWithAuth(accountName)
+	apiClient, err := s.getAuthedAPIClient(ctx, accountName)
 	if err != nil {
 		return s.createErrorResult(err)
 	}
-	defer cleanup()
 
-	// 获取cookies并创建API客户端
-	cookies, err := page.Context().Cookies()
+	infoResp, err := apiClient.GetVideoInfo(videoID)
 	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "获取cookies失败"))
+		return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
 	}
-
-	cookieMap := make(map[string]string)
-	for _, cookie := range cookies {
-		cookieMap[cookie.Name] = cookie.Value
+	if infoResp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(infoResp.Code, infoResp.Message), "获取视频信息失败"))
 	}
 
-	apiClient := api.NewClient(cookieMap)
-
-	// 使用API投币视频
-	coinResp, err := apiClient.CoinVideo(videoID, coinCount, alsoLike)
+	reportResp, err := apiClient.ReportComment(strconv.FormatInt(infoResp.Data.Aid, 10), commentID, reason)
 	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "投币视频失败"))
-	}
-
-	if coinResp.Code != 0 {
-		return s.createErrorResult(errors.Errorf("API返回错误: %s (code: %d)", coinResp.Message, coinResp.Code))
+		return s.createErrorResult(errors.Wrap(err, "举报评论失败"))
 	}
-
-	resultMsg := fmt.Sprintf("投币成功 - 视频: %s, 数量: %d", videoID, coinCount)
-	if alsoLike && coinResp.Data.Like {
-		resultMsg += " (同时点赞)"
+	if reportResp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(reportResp.Code, reportResp.Message), "API返回错误"))
 	}
 
-	return s.createToolResult(resultMsg, false)
+	logger.Infof("🚩 已举报评论 %s (视频 %s) - 理由: %s", commentID, videoID, reasonText)
+	return s.createToolResult(fmt.Sprintf("举报已提交 - 评论: %s, 理由: %s", commentID, reasonText), false)
 }
 
-// handleFavoriteVideo 收藏视频
-func (s *Server) handleFavoriteVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+// handleGetCommentStatus 检查评论是否仍然存在及其可见状态
+func (s *Server) handleGetCommentStatus(ctx context.Context, args map[string]interface{}) *MCPToolResult {
 	videoID, ok := args["video_id"].(string)
 	if !ok || videoID == "" {
 		return s.createToolResult("缺少video_id参数", true)
 	}
-
-	if err := s.validateVideoID(videoID); err != nil {
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
 		return s.createErrorResult(err)
 	}
 
-	folderID := ""
-	if id, ok := args["folder_id"].(string); ok {
-		folderID = id
+	commentID, ok := args["comment_id"].(string)
+	if !ok || commentID == "" {
+		return s.createToolResult("缺少comment_id参数", true)
 	}
 
-	accountName := s.getAccountName(args)
-
-	// 检查频率限制
-	rateLimitKey := fmt.Sprintf("favorite_video_%s_%s", accountName, videoID)
-	if err := checkRateLimit(rateLimitKey, 10*time.Second); err != nil {
+	// 使用评论者账号查询：审核中的评论只有作者本人可见
+	apiClient, err := s.getAuthedAPIClient(ctx, s.getAccountName(args))
+	if err != nil {
 		return s.createErrorResult(err)
 	}
 
-	// 获取带认证的浏览器页面（仅用于获取cookies）
-	page, cleanup, err := s.browserPool.GetWithAuth(accountName)
+	infoResp, err := apiClient.GetVideoInfo(videoID)
 	if err != nil {
-		return s.createErrorResult(err)
+		return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
+	}
+	if infoResp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(infoResp.Code, infoResp.Message), "获取视频信息失败"))
 	}
-	defer cleanup()
+	aid := infoResp.Data.Aid
 
-	// 获取cookies并创建API客户端
-	cookies, err := page.Context().Cookies()
+	authedResp, err := apiClient.GetCommentDetail(aid, commentID)
 	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "获取cookies失败"))
+		return s.createErrorResult(errors.Wrap(err, "获取评论详情失败"))
 	}
 
-	cookieMap := make(map[string]string)
-	for _, cookie := range cookies {
-		cookieMap[cookie.Name] = cookie.Value
+	switch authedResp.Code {
+	case 0:
+	case api.CommentCodeDeleted, api.CommentCodeNotExist:
+		return s.createToolResult(fmt.Sprintf("🗑️ 评论 %s 已被删除或不存在 (code: %d)", commentID, authedResp.Code), false)
+	case api.CommentCodeAreaDisabled:
+		return s.createToolResult(fmt.Sprintf("🔒 视频 %s 的评论区已关闭", videoID), false)
+	case api.CodeRequestBlocked:
+		return s.createErrorResult(errors.Wrapf(api.NewAPIError(authedResp.Code, authedResp.Message), "查询评论 %s 的请求被B站风控拦截，请稍后再试", commentID))
+	default:
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(authedResp.Code, authedResp.Message), "API返回错误"))
 	}
 
-	apiClient := api.NewClient(cookieMap)
-
-	// 使用API收藏视频
-	folderIDs := []string{}
-	if folderID != "" {
-		folderIDs = []string{folderID}
+	reply := authedResp.Data.Root
+	if reply == nil {
+		return s.createToolResult(fmt.Sprintf("🗑️ 评论 %s 已被删除或不存在", commentID), false)
 	}
 
-	favResp, err := apiClient.FavoriteVideo(videoID, folderIDs, true)
-	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "收藏视频失败"))
+	// 再以未登录身份查询，他人看不到时说明评论处于审核中或被折叠
+	visible, blocked := !reply.Invisible, false
+	if visible {
+		publicClient := api.NewClient(map[string]string{})
+		publicClient.SetContext(ctx)
+		publicResp, err := publicClient.GetCommentDetail(aid, commentID)
+		if err != nil {
+			logger.Warnf("匿名查询评论失败，跳过可见性检查: %v", err)
+		} else if publicResp.Code == api.CodeRequestBlocked {
+			// 风控拦截不代表评论不可见，不能据此判断为审核中
+			logger.Warnf("匿名查询评论被风控拦截，跳过可见性检查 - 评论: %s", commentID)
+			blocked = true
+		} else if publicResp.Code != 0 || publicResp.Data.Root == nil || publicResp.Data.Root.Invisible {
+			visible = false
+		}
 	}
 
-	if favResp.Code != 0 {
-		return s.createErrorResult(errors.Errorf("API返回错误: %s (code: %d)", favResp.Message, favResp.Code))
+	var message strings.Builder
+	if blocked {
+		message.WriteString(fmt.Sprintf("⚠️ 评论 %s 存在，但检查他人可见性的请求被B站风控拦截 (code: %d)，请稍后再试\n\n", commentID, api.CodeRequestBlocked))
+	} else if visible {
+		message.WriteString(fmt.Sprintf("✅ 评论 %s 正常可见\n\n", commentID))
+	} else {
+		message.WriteString(fmt.Sprintf("⏳ 评论 %s 审核中或被折叠（仅自己可见）\n\n", commentID))
 	}
+	message.WriteString(fmt.Sprintf("   • 作者: %s (UID: %s)\n", reply.Member.Uname, reply.Member.Mid))
+	message.WriteString(fmt.Sprintf("   • 内容: %s\n", reply.Content.Message))
+	message.WriteString(fmt.Sprintf("   • 点赞: %d | 回复: %d\n", reply.Like, reply.Rcount))
+	message.WriteString(fmt.Sprintf("   • 发布时间: %s\n", time.Unix(reply.Ctime, 0).Format("2006-01-02 15:04:05")))
 
-	return s.createToolResult(fmt.Sprintf("收藏成功 - 视频: %s", videoID), false)
+	return s.createToolResult(message.String(), false)
 }
 
-// 用户相关处理器
+// commentItem 评论列表输出项
+type commentItem struct {
+	Rpid      int64  `json:"rpid"`       // 评论ID
+	Mid       string `json:"mid"`        // 作者UID
+	Uname     string `json:"uname"`      // 作者用户名
+	Message   string `json:"message"`    // 评论内容
+	Like      int64  `json:"like"`       // 点赞数
+	Replies   int64  `json:"replies"`    // 回复数
+	CreatedAt string `json:"created_at"` // 发布时间
+	Top       bool   `json:"top,omitempty"`
+}
 
-// handleFollowUser 关注用户
-func (s *Server) handleFollowUser(ctx context.Context, args map[string]interface{}) *MCPToolResult {
-	userID, ok := args["user_id"].(string)
-	if !ok || userID == "" {
-		return s.createToolResult("缺少user_id参数", true)
+// handleGetComments 获取视频评论列表
+func (s *Server) handleGetComments(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
 	}
-
-	accountName := s.getAccountName(args)
-
-	// 检查频率限制
-	rateLimitKey := fmt.Sprintf("follow_user_%s_%s", accountName, userID)
-	if err := checkRateLimit(rateLimitKey, 10*time.Second); err != nil {
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
 		return s.createErrorResult(err)
 	}
 
-	// 获取带认证的浏览器页面（仅用于获取cookies）
-	page, cleanup, err := s.browserPool.GetWithAuth(accountName)
-	if err != nil {
-		return s.createErrorResult(err)
+	page := 1
+	if p, ok := args["page"].(float64); ok && p >= 1 {
+		page = int(p)
+	}
+	pageSize := 20
+	if ps, ok := args["page_size"].(float64); ok && ps >= 1 {
+		pageSize = int(ps)
+	}
+	if pageSize > 30 {
+		pageSize = 30
+	}
+	sort := api.CommentSortLike
+	if v, ok := args["sort"].(float64); ok {
+		sort = int(v)
+	}
+	if sort != api.CommentSortTime && sort != api.CommentSortLike {
+		return s.createToolResult("sort参数只支持0（按时间）或1（按热度）", true)
+	}
+
+	// 读取评论不需要登录，指定账号时携带cookies
+	apiClient := api.NewClient(map[string]string{})
+	apiClient.SetContext(ctx)
+	if accountName := s.getAccountName(args); accountName != "" {
+		authedClient, err := s.getAuthedAPIClient(ctx, accountName)
+		if err != nil {
+			return s.createErrorResult(err)
+		}
+		apiClient = authedClient
 	}
-	defer cleanup()
 
-	// 获取cookies并创建API客户端
-	cookies, err := page.Context().Cookies()
+	resp, err := apiClient.GetComments(videoID, page, pageSize, sort)
 	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "获取cookies失败"))
+		return s.createErrorResult(errors.Wrap(err, "获取评论列表失败"))
+	}
+	if resp.Code != 0 {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "API返回错误"))
 	}
 
-	cookieMap := make(map[string]string)
-	for _, cookie := range cookies {
-		cookieMap[cookie.Name] = cookie.Value
+	comments := make([]commentItem, 0, len(resp.Data.TopReplies)+len(resp.Data.Replies))
+	if page == 1 {
+		for _, reply := range resp.Data.TopReplies {
+			item := newCommentItem(reply)
+			item.Top = true
+			comments = append(comments, item)
+		}
+	}
+	for _, reply := range resp.Data.Replies {
+		comments = append(comments, newCommentItem(reply))
 	}
 
-	apiClient := api.NewClient(cookieMap)
+	result := map[string]interface{}{
+		"video_id":    videoID,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_count": resp.Data.Cursor.AllCount,
+		"is_end":      resp.Data.Cursor.IsEnd,
+		"comments":    comments,
+	}
 
-	// 使用API关注用户 (1:关注 2:取消关注)
-	followResp, err := apiClient.FollowUser(userID, 1)
+	jsonData, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "关注用户失败"))
+		return s.createErrorResult(err)
 	}
 
-	if followResp.Code != 0 {
-		return s.createErrorResult(errors.Errorf("API返回错误: %s (code: %d)", followResp.Message, followResp.Code))
-	}
+	return s.createToolResult(string(jsonData), false)
+}
 
-	return s.createToolResult(fmt.Sprintf("关注成功 - 用户: %s", userID), false)
+// newCommentItem 转换评论为输出格式
+func newCommentItem(reply api.CommentReply) commentItem {
+	return commentItem{
+		Rpid:      reply.Rpid,
+		Mid:       reply.Member.Mid,
+		Uname:     reply.Member.Uname,
+		Message:   reply.Content.Message,
+		Like:      reply.Like,
+		Replies:   reply.Rcount,
+		CreatedAt: time.Unix(reply.Ctime, 0).Format("2006-01-02 15:04:05"),
+	}
 }
 
 // 可选功能处理器
@@ -869,18 +3410,18 @@ func (s *Server) handleWhisperAudio2Text(ctx context.Context, args map[string]in
 	}
 
 	// 检查Whisper是否启用
-	if !s.config.Features.Whisper.Enabled {
+	if !s.currentConfig().Features.Whisper.Enabled {
 		return s.createToolResult("Whisper功能未启用，请先运行 ./bilibili-whisper-init 进行初始化", true)
 	}
 
 	// 获取语言参数
-	language := s.config.Features.Whisper.Language
+	language := s.currentConfig().Features.Whisper.Language
 	if lang, ok := args["language"].(string); ok && lang != "" {
 		language = lang
 	}
 
 	// 获取模型参数（可选）
-	requestedModel := s.config.Features.Whisper.DefaultModel
+	requestedModel := s.currentConfig().Features.Whisper.DefaultModel
 	if m, ok := args["model"].(string); ok && m != "" {
 		requestedModel = m
 	}
@@ -897,31 +3438,64 @@ func (s *Server) handleWhisperAudio2Text(ctx context.Context, args map[string]in
 	// 但由于whisper服务现在使用完整配置，我们需要在服务层面处理这些参数
 	// 这里暂时保持原有逻辑，在后续优化中可以改进
 
+	opts := whisper.TranscribeOptions{}
+	if outputFormat, ok := args["output_format"].(string); ok {
+		opts.OutputFormat = outputFormat
+	}
+	if task, ok := args["task"].(string); ok {
+		opts.Task = task
+	}
+	if prompt, ok := args["initial_prompt"].(string); ok {
+		opts.InitialPrompt = prompt
+	}
+	if temperature, ok := args["temperature"].(float64); ok {
+		if temperature < 0 || temperature > 1 {
+			return s.createToolResult("temperature参数必须在0-1之间", true)
+		}
+		opts.Temperature = &temperature
+	}
+
 	// 执行转录
-	result, err := whisperService.TranscribeAudio(ctx, audioPath)
+	result, err := whisperService.TranscribeAudio(ctx, audioPath, opts)
 	if err != nil {
+		if whisper.IsInterrupted(err) {
+			return s.createErrorResult(err)
+		}
 		return s.createErrorResult(errors.Wrap(err, "音频转录失败"))
 	}
 
+	// plain模式仅返回转录文本，便于程序处理
+	if plain, ok := args["plain"].(bool); ok && plain {
+		return s.createToolResult(result.Text, false)
+	}
+
 	// 构建结果消息
 	var message strings.Builder
 	message.WriteString("🎤 音频转录完成！\n\n")
 
 	message.WriteString("📁 文件信息\n")
 	message.WriteString(fmt.Sprintf("   • 音频文件: %s\n", filepath.Base(result.AudioPath)))
-	message.WriteString(fmt.Sprintf("   • SRT文件: %s\n", filepath.Base(result.OutputPath)))
+	message.WriteString(fmt.Sprintf("   • %s文件: %s\n", strings.ToUpper(result.OutputFormat), filepath.Base(result.OutputPath)))
+	if result.WAVPath != "" {
+		message.WriteString(fmt.Sprintf("   • WAV中间文件: %s\n", filepath.Base(result.WAVPath)))
+	}
 	message.WriteString(fmt.Sprintf("   • 处理时间: %.2f秒\n\n", result.ProcessTime))
 
 	message.WriteString("⚙️ 转录配置\n")
 	message.WriteString(fmt.Sprintf("   • 模型: %s\n", result.Model))
 	message.WriteString(fmt.Sprintf("   • 语言: %s\n", result.Language))
+	message.WriteString(fmt.Sprintf("   • 任务: %s\n", formatWhisperTask(result.Task)))
+	if result.InitialPrompt != "" {
+		message.WriteString(fmt.Sprintf("   • 提示词: %s\n", result.InitialPrompt))
+	}
+	message.WriteString(fmt.Sprintf("   • 温度: %g\n", result.Temperature))
 	message.WriteString(fmt.Sprintf("   • 加速类型: %s\n", result.AccelerationType))
 	message.WriteString(fmt.Sprintf("   • 创建时间: %s\n\n", result.CreatedAt.Format("2006-01-02 15:04:05")))
 
 	message.WriteString("📝 转录文本\n")
 	message.WriteString("=" + strings.Repeat("=", 50) + "\n")
-	message.WriteString(result.Text)
-	message.WriteString("\n" + strings.Repeat("=", 51) + "\n")
+	writeTranscript(&message, result)
+	message.WriteString(strings.Repeat("=", 51) + "\n")
 
 	// 转换为绝对路径
 	absOutputPath, err := filepath.Abs(result.OutputPath)
@@ -962,6 +3536,171 @@ func (s *Server) handleWhisperAudio2Text(ctx context.Context, args map[string]in
 	return s.createToolResult(message.String(), false)
 }
 
+// handleWhisperDetectLanguage 使用Whisper.cpp检测音频语言
+func (s *Server) handleWhisperDetectLanguage(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	audioPath, ok := args["audio_path"].(string)
+	if !ok || audioPath == "" {
+		return s.createToolResult("缺少audio_path参数", true)
+	}
+
+	if !s.currentConfig().Features.Whisper.Enabled {
+		return s.createToolResult("Whisper功能未启用，请先运行 ./bilibili-whisper-init 进行初始化", true)
+	}
+
+	whisperService, err := s.getOrCreateWhisperService()
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	lang, confidence, err := whisperService.DetectLanguage(ctx, audioPath)
+	if err != nil {
+		return s.createErrorResult(errors.Wrap(err, "语言检测失败"))
+	}
+
+	return s.createToolResult(fmt.Sprintf("🌐 检测到语言: %s\n   • 置信度: %.1f%%\n   • 音频文件: %s",
+		lang, confidence*100, filepath.Base(audioPath)), false)
+}
+
+// handleTranscribeVideo 下载视频音频并转录
+func (s *Server) handleTranscribeVideo(ctx context.Context, args map[string]interface{}) *MCPToolResult {
+	videoID, ok := args["video_id"].(string)
+	if !ok || videoID == "" {
+		return s.createToolResult("缺少video_id参数", true)
+	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
+
+	if !s.currentConfig().Features.Whisper.Enabled {
+		return s.createToolResult("Whisper功能未启用，请先运行 ./bilibili-whisper-init 进行初始化", true)
+	}
+
+	opts := whisper.TranscribeOptions{}
+	if outputFormat, ok := args["output_format"].(string); ok {
+		opts.OutputFormat = outputFormat
+	}
+	if task, ok := args["task"].(string); ok {
+		opts.Task = task
+	}
+
+	// 登录后可获取更多音频流，未登录时使用匿名客户端
+	apiClient, err := s.getAuthedAPIClient(ctx, s.getAccountName(args))
+	if err != nil {
+		logger.Warnf("获取登录账号失败，使用未登录状态: %v", err)
+		apiClient = api.NewClient(map[string]string{})
+	}
+
+	transcription, errResult := s.downloadAndTranscribe(ctx, apiClient, videoID, 0, args, opts)
+	if errResult != nil {
+		return errResult
+	}
+	audio, result := transcription.audio, transcription.transcript
+
+	var message strings.Builder
+	message.WriteString("🎤 视频转录完成！\n\n")
+
+	message.WriteString("🎬 视频信息\n")
+	message.WriteString(fmt.Sprintf("   • 标题: %s\n", audio.Title))
+	message.WriteString(fmt.Sprintf("   • 视频ID: %s\n", audio.VideoID))
+	message.WriteString(fmt.Sprintf("   • 时长: %s\n\n", formatTimestamp(int64(audio.Duration))))
+
+	message.WriteString("📁 文件信息\n")
+	if transcription.keepAudio {
+		message.WriteString(fmt.Sprintf("   • 音频文件: %s%s\n", audio.AudioPath, audioQualityLabel(audio.AudioQuality)))
+	} else {
+		message.WriteString("   • 音频文件: 转录后已删除\n")
+	}
+	message.WriteString(fmt.Sprintf("   • %s文件: %s\n", strings.ToUpper(result.OutputFormat), result.OutputPath))
+	if result.WAVPath != "" {
+		message.WriteString(fmt.Sprintf("   • WAV中间文件: %s\n", result.WAVPath))
+	}
+	message.WriteString(fmt.Sprintf("   • 模型: %s (%s)\n", result.Model, result.AccelerationType))
+	message.WriteString(fmt.Sprintf("   • 任务: %s\n", formatWhisperTask(result.Task)))
+	message.WriteString(fmt.Sprintf("   • 处理时间: %.2f秒\n\n", result.ProcessTime))
+
+	message.WriteString("📝 转录文本\n")
+	writeTranscript(&message, result)
+
+	return s.createToolResult(message.String(), false)
+}
+
+// videoTranscription 下载音频并转录的结果
+type videoTranscription struct {
+	audio      *download.MediaDownloadResult
+	transcript *whisper.TranscribeResult
+	keepAudio  bool // 转录后是否保留了音频文件
+}
+
+// downloadAndTranscribe 下载视频音频并转录，供 summarize_video 和 transcribe_video 共用。
+// 从参数中读取 output_dir、audio_quality 和 keep_audio（默认使用配置 features.whisper.keep_audio）；
+// 转录成功后才按需删除音频，失败时保留已下载的音频以便重试。出错时返回错误结果
+func (s *Server) downloadAndTranscribe(ctx context.Context, apiClient *api.Client, videoID string, cid int64, args map[string]interface{}, opts whisper.TranscribeOptions) (*videoTranscription, *MCPToolResult) {
+	outputDir := "./downloads"
+	if dir, ok := args["output_dir"].(string); ok && dir != "" {
+		outputDir = dir
+	}
+	keepAudio := s.currentConfig().Features.Whisper.KeepAudio
+	if v, ok := args["keep_audio"].(bool); ok {
+		keepAudio = v
+	}
+	audioQuality, _ := args["audio_quality"].(string)
+
+	whisperService, err := s.getOrCreateWhisperService()
+	if err != nil {
+		return nil, s.createErrorResult(err)
+	}
+
+	audio, err := download.NewMediaDownloadService(apiClient, outputDir).DownloadMedia(ctx, videoID, download.DownloadOptions{
+		MediaType:    download.MediaTypeAudio,
+		CID:          cid,
+		AudioQuality: audioQuality,
+	})
+	if err != nil {
+		if timeoutResult := s.createDownloadTimeoutResult(ctx, err); timeoutResult != nil {
+			return nil, timeoutResult
+		}
+		return nil, s.createErrorResult(errors.Wrap(err, "下载音频失败"))
+	}
+
+	transcript, err := whisperService.TranscribeAudio(ctx, audio.AudioPath, opts)
+	if err != nil {
+		if whisper.IsInterrupted(err) {
+			return nil, s.createErrorResult(errors.Wrapf(err, "已下载的音频保留在: %s", audio.AudioPath))
+		}
+		return nil, s.createErrorResult(errors.Wrapf(err, "音频转录失败，已下载的音频保留在: %s", audio.AudioPath))
+	}
+
+	if !keepAudio {
+		if err := os.Remove(audio.AudioPath); err != nil && !os.IsNotExist(err) {
+			logger.Warnf("删除音频文件失败: %v", err)
+		}
+	}
+
+	return &videoTranscription{audio: audio, transcript: transcript, keepAudio: keepAudio}, nil
+}
+
+// formatWhisperTask 格式化转录任务
+func formatWhisperTask(task string) string {
+	if task == whisper.TaskTranslate {
+		return "翻译为英文"
+	}
+	return "原语言转录"
+}
+
+// writeTranscript 写出转录文本，有时间轴片段时逐段带上起止时间，便于按时间引用
+func writeTranscript(message *strings.Builder, result *whisper.TranscribeResult) {
+	if len(result.Segments) == 0 {
+		message.WriteString(result.Text)
+		message.WriteString("\n")
+		return
+	}
+	for _, segment := range result.Segments {
+		message.WriteString(fmt.Sprintf("[%s - %s] %s\n",
+			formatTimestamp(int64(segment.Start)), formatTimestamp(int64(segment.End)), segment.Text))
+	}
+}
+
 // formatFileSize 格式化文件大小
 func formatFileSize(size int64) string {
 	const (
@@ -982,12 +3721,24 @@ func formatFileSize(size int64) string {
 	}
 }
 
+// formatTimestamp 格式化秒数为 mm:ss 或 hh:mm:ss
+func formatTimestamp(seconds int64) string {
+	if seconds >= 3600 {
+		return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, (seconds%3600)/60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
 // handleGetVideoStream 获取视频流地址
 func (s *Server) handleGetVideoStream(ctx context.Context, args map[string]interface{}) *MCPToolResult {
 	videoID, ok := args["video_id"].(string)
 	if !ok || videoID == "" {
 		return s.createToolResult("缺少video_id参数", true)
 	}
+	videoID, err := normalizeVideoID(ctx, videoID)
+	if err != nil {
+		return s.createErrorResult(err)
+	}
 
 	// CID现在是可选参数，如果没有提供就自动获取
 	var cid int64
@@ -1017,57 +3768,57 @@ func (s *Server) handleGetVideoStream(ctx context.Context, args map[string]inter
 
 	// 可选参数
 	quality := 64 // 默认720P
+	fnval := 16   // 默认DASH格式
+	platform := ""
+
+	// 预设会展开为 fnval/platform/quality 组合，显式传入的原始参数优先
+	if presetName, ok := args["preset"].(string); ok && presetName != "" {
+		preset, exists := api.GetStreamPreset(presetName)
+		if !exists {
+			return s.createToolResult(fmt.Sprintf("不支持的预设: %s，支持: %s", presetName, strings.Join(api.StreamPresetNames(), ", ")), true)
+		}
+		quality, fnval, platform = preset.Quality, preset.FnVal, preset.Platform
+	}
+
 	if q, ok := args["quality"]; ok {
 		if qInt, ok := q.(float64); ok {
 			quality = int(qInt)
 		}
 	}
 
-	fnval := 16 // 默认DASH格式
 	if f, ok := args["fnval"]; ok {
 		if fInt, ok := f.(float64); ok {
 			fnval = int(fInt)
 		}
 	}
 
-	platform := ""
-	if p, ok := args["platform"].(string); ok {
+	if p, ok := args["platform"].(string); ok && p != "" {
 		platform = p
 	}
 
+	emitCommand, _ := args["emit_command"].(bool)
+	refererURL := fmt.Sprintf("https://www.bilibili.com/video/%s", videoID)
+
 	accountName := s.getAccountName(args)
 
-	// 获取带认证的浏览器页面（用于获取cookies）
-	page, cleanup, err := s.browserPool.GetWithAuth(accountName)
+	// 直接读取账号cookies创建API客户端，无需启动浏览器
+	client, err := s.getAuthedAPIClient(ctx, accountName)
 	if err != nil {
 		return s.createErrorResult(err)
 	}
-	defer cleanup()
-
-	// 从playwright页面获取cookies
-	cookies, err := page.Context().Cookies()
-	if err != nil {
-		return s.createErrorResult(errors.Wrap(err, "获取cookies失败"))
-	}
-
-	// 转换为map格式
-	cookieMap := make(map[string]string)
-	for _, cookie := range cookies {
-		cookieMap[cookie.Name] = cookie.Value
+	if noCache, _ := args["no_cache"].(bool); noCache {
+		client.SetNoCache(true)
 	}
 
-	// 创建API客户端
-	client := api.NewClient(cookieMap)
-
 	// 如果没有提供CID，自动获取视频信息来获取CID
 	if cid == 0 {
 		videoInfo, err := client.GetVideoInfo(videoID)
 		if err != nil {
-			return s.createToolResult(fmt.Sprintf("获取视频信息失败: %v", err), true)
+			return s.createErrorResult(errors.Wrap(err, "获取视频信息失败"))
 		}
 
 		if videoInfo.Code != 0 {
-			return s.createToolResult(fmt.Sprintf("获取视频信息失败: %s (code: %d)", videoInfo.Message, videoInfo.Code), true)
+			return s.createErrorResult(errors.Wrap(api.NewAPIError(videoInfo.Code, videoInfo.Message), "获取视频信息失败"))
 		}
 
 		if len(videoInfo.Data.Pages) == 0 {
@@ -1085,16 +3836,26 @@ func (s *Server) handleGetVideoStream(ctx context.Context, args map[string]inter
 	// 调用API获取视频流
 	streamResp, err := client.GetVideoStream(videoID, cid, quality, fnval, platform)
 	if err != nil {
-		return s.createToolResult(fmt.Sprintf("获取视频流失败: %v", err), true)
+		return s.createErrorResult(errors.Wrap(err, "获取视频流失败"))
+	}
+
+	if streamResp.Code != 0 || streamResp.Data == nil {
+		return s.createErrorResult(errors.Wrap(api.NewAPIError(streamResp.Code, streamResp.Message), "获取视频流失败"))
+	}
+
+	acceptQualities := make([]string, 0, len(streamResp.Data.AcceptQuality))
+	for _, q := range streamResp.Data.AcceptQuality {
+		acceptQualities = append(acceptQualities, getQualityDescription(q))
 	}
 
 	// 构建简化的播放地址结果
 	result := map[string]interface{}{
-		"video_id":   videoID,
-		"cid":        cid,
-		"quality":    streamResp.Data.Quality,
-		"duration":   streamResp.Data.TimeLength / 1000, // 转换为秒
-		"usage_note": "注意：播放地址需要正确的Referer和User-Agent才能访问",
+		"video_id":       videoID,
+		"cid":            cid,
+		"quality":        streamResp.Data.Quality,
+		"accept_quality": acceptQualities,
+		"duration":       streamResp.Data.TimeLength / 1000, // 转换为秒
+		"usage_note":     "注意：播放地址需要正确的Referer和User-Agent才能访问",
 	}
 
 	// 提取播放地址
@@ -1102,35 +3863,53 @@ func (s *Server) handleGetVideoStream(ctx context.Context, args map[string]inter
 
 	// DASH格式的音视频流
 	if streamResp.Data.DASH != nil {
-		// 视频流地址
+		// 视频流地址，同时按编码分组（fnval=4048时会同时返回AVC/HEVC/AV1）
 		if len(streamResp.Data.DASH.Video) > 0 {
 			videoStreams := make([]map[string]interface{}, 0)
+			videoByCodec := make(map[string][]map[string]interface{})
 			for _, video := range streamResp.Data.DASH.Video {
-				videoStreams = append(videoStreams, map[string]interface{}{
+				codec := codecName(video.CodecID, video.Codecs)
+				stream := map[string]interface{}{
+					"quality_id": video.ID,
 					"quality":    getQualityDescription(video.ID),
 					"resolution": fmt.Sprintf("%dx%d", video.Width, video.Height),
+					"frame_rate": video.FrameRate,
 					"url":        video.BaseURL,
+					"backup_url": video.BackupURL,
+					"codec":      codec,
 					"codecs":     video.Codecs,
 					"bandwidth":  video.Bandwidth,
-				})
+				}
+				videoStreams = append(videoStreams, stream)
+				videoByCodec[codec] = append(videoByCodec[codec], stream)
 			}
 			playUrls["video_streams"] = videoStreams
+			playUrls["video_by_codec"] = videoByCodec
 		}
 
 		// 音频流地址
 		if len(streamResp.Data.DASH.Audio) > 0 {
 			audioStreams := make([]map[string]interface{}, 0)
 			for _, audio := range streamResp.Data.DASH.Audio {
-				audioStreams = append(audioStreams, map[string]interface{}{
-					"quality":   fmt.Sprintf("ID_%d", audio.ID),
-					"url":       audio.BaseURL,
-					"codecs":    audio.Codecs,
-					"bandwidth": audio.Bandwidth,
-				})
+				audioStreams = append(audioStreams, dashAudioStream(audio))
 			}
 			playUrls["audio_streams"] = audioStreams
 		}
 
+		// 杜比全景声音轨（fnval包含256时返回）
+		if dolby := streamResp.Data.DASH.Dolby; dolby != nil && len(dolby.Audio) > 0 {
+			dolbyStreams := make([]map[string]interface{}, 0, len(dolby.Audio))
+			for _, audio := range dolby.Audio {
+				dolbyStreams = append(dolbyStreams, dashAudioStream(audio))
+			}
+			playUrls["dolby_audio"] = dolbyStreams
+		}
+
+		// Hi-Res无损音轨
+		if flac := streamResp.Data.DASH.FLAC; flac != nil && flac.Audio.BaseURL != "" {
+			playUrls["flac_audio"] = dashAudioStream(flac.Audio)
+		}
+
 		// 推荐的最佳流
 		if len(streamResp.Data.DASH.Video) > 0 && len(streamResp.Data.DASH.Audio) > 0 {
 			// 选择最佳视频流（通常是第一个）
@@ -1150,11 +3929,16 @@ func (s *Server) handleGetVideoStream(ctx context.Context, args map[string]inter
 				}
 			}
 
-			playUrls["recommended"] = map[string]interface{}{
+			recommended := map[string]interface{}{
 				"video_url": bestVideo.BaseURL,
 				"audio_url": bestAudio.BaseURL,
 				"note":      "DASH格式需要分别下载音视频后用ffmpeg合并",
 			}
+			if emitCommand {
+				output := fmt.Sprintf("%s_%s.mp4", videoID, getQualityDescription(bestVideo.ID))
+				recommended["ffmpeg_command"] = s.ffmpegCommand(refererURL, output, bestVideo.BaseURL, bestAudio.BaseURL)
+			}
+			playUrls["recommended"] = recommended
 		}
 	}
 
@@ -1173,17 +3957,21 @@ func (s *Server) handleGetVideoStream(ctx context.Context, args map[string]inter
 
 		// 推荐的合并流（第一个分段）
 		if len(streamResp.Data.DURL) > 0 {
-			playUrls["recommended"] = map[string]interface{}{
+			recommended := map[string]interface{}{
 				"merged_url": streamResp.Data.DURL[0].URL,
 				"note":       "MP4格式已合并音视频，可直接播放",
 			}
+			if emitCommand {
+				output := fmt.Sprintf("%s_%s.mp4", videoID, getQualityDescription(streamResp.Data.Quality))
+				recommended["ffmpeg_command"] = s.ffmpegCommand(refererURL, output, streamResp.Data.DURL[0].URL)
+			}
+			playUrls["recommended"] = recommended
 		}
 	}
 
 	result["play_urls"] = playUrls
 
 	// 添加使用示例
-	refererURL := fmt.Sprintf("https://www.bilibili.com/video/%s", videoID)
 	result["usage_examples"] = map[string]interface{}{
 		"curl_download": fmt.Sprintf(`curl "播放地址" -H "Referer: %s" -H "User-Agent: Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36" -o video.mp4`, refererURL),
 		"ffmpeg_play":   fmt.Sprintf(`ffmpeg -user_agent "Mozilla/5.0..." -referer "%s" -i "播放地址" -c copy output.mp4`, refererURL),
@@ -1198,6 +3986,18 @@ func (s *Server) handleGetVideoStream(ctx context.Context, args map[string]inter
 	return s.createToolResult(string(resultJSON), false)
 }
 
+// ffmpegCommand 生成下载并封装为mp4的ffmpeg命令，每个输入都带上B站要求的Referer和User-Agent。
+// User-Agent 使用 -user_agent 单独传入，避免 -headers 中多个请求头的换行在不同shell中转义不一致
+func (s *Server) ffmpegCommand(refererURL, output string, inputs ...string) string {
+	var cmd strings.Builder
+	cmd.WriteString("ffmpeg")
+	for _, input := range inputs {
+		cmd.WriteString(fmt.Sprintf(` -user_agent "%s" -headers "Referer: %s" -i "%s"`, s.currentConfig().Browser.UserAgent, refererURL, input))
+	}
+	cmd.WriteString(fmt.Sprintf(` -c copy "%s"`, output))
+	return cmd.String()
+}
+
 // getQualityDescription 获取清晰度描述
 func getQualityDescription(quality int) string {
 	qualityMap := map[int]string{
@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// 健康检查路径，供容器编排和负载均衡探测，不需要认证
+const (
+	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+)
+
+// handleProbe 处理健康检查和就绪检查请求，非探测路径返回false
+func (s *Server) handleProbe(w http.ResponseWriter, r *http.Request) bool {
+	switch r.URL.Path {
+	case healthzPath:
+		s.handleHealthz(w)
+	case readyzPath:
+		s.handleReadyz(w)
+	default:
+		return false
+	}
+	return true
+}
+
+// handleHealthz 返回服务状态、浏览器池统计和默认账号是否已登录。
+// 探测接口会被频繁调用，只做只读检查，不解密或迁移cookies、不写入任何文件
+func (s *Server) handleHealthz(w http.ResponseWriter) {
+	status := map[string]interface{}{
+		"status": "ok",
+	}
+	if s.browserPool != nil {
+		status["browser_pool"] = s.browserPool.Stats()
+	}
+	if s.loginService != nil {
+		status["default_account_logged_in"] = s.loginService.HasDefaultSession()
+	}
+
+	writeProbeResponse(w, http.StatusOK, status)
+}
+
+// handleReadyz 配置已加载且浏览器池初始化完成、未关闭时返回200，否则返回503并说明原因
+func (s *Server) handleReadyz(w http.ResponseWriter) {
+	var problems []string
+	if s.currentConfig() == nil {
+		problems = append(problems, "配置未加载")
+	}
+	if s.browserPool == nil {
+		problems = append(problems, "浏览器池未初始化")
+	} else if closed, _ := s.browserPool.Stats()["closed"].(bool); closed {
+		problems = append(problems, "浏览器池已关闭")
+	}
+
+	if len(problems) > 0 {
+		writeProbeResponse(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "not ready", "reasons": problems})
+		return
+	}
+	writeProbeResponse(w, http.StatusOK, map[string]interface{}{"status": "ready"})
+}
+
+// writeProbeResponse 写入探测接口的JSON响应
+func writeProbeResponse(w http.ResponseWriter, code int, body map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
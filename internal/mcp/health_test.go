@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/auth"
+	"github.com/shirenchuang/bilibili-mcp/pkg/config"
+)
+
+// probe 请求探测接口并解析JSON响应
+func probe(t *testing.T, s *Server, path string) (int, map[string]interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid probe response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, body
+}
+
+func TestHealthzIsReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Accounts.CookieDir = dir
+	cfg.Accounts.EncryptCookies = true
+	t.Setenv(auth.CookieKeyEnv, "")
+	previous := config.Get()
+	config.Set(cfg)
+	t.Cleanup(func() { config.Set(previous) })
+
+	// 明文cookies在加密模式下读取时会被迁移重写，健康检查不能触发迁移
+	cookieFile := auth.NewAccountManager().GetCookieFile("alice")
+	cookies := []byte(`[{"name":"SESSDATA","value":"abc"}]`)
+	accounts := []byte(`[{"name":"alice","is_default":true,"is_active":true}]`)
+	if err := os.WriteFile(cookieFile, cookies, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "accounts.json"), accounts, 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(cookieFile, old, old)
+
+	s := &Server{config: cfg, loginService: auth.NewLoginService()}
+	code, body := probe(t, s, healthzPath)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if body["default_account_logged_in"] != true {
+		t.Fatalf("default_account_logged_in = %v, want true", body["default_account_logged_in"])
+	}
+
+	stored, err := os.ReadFile(cookieFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(cookieFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(stored) != string(cookies) || !info.ModTime().Equal(old) {
+		t.Fatal("healthz rewrote the cookies file")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("healthz created files: %v", entries)
+	}
+}
+
+func TestReadyzNotReady(t *testing.T) {
+	tests := []struct {
+		name   string
+		server *Server
+		reason string
+	}{
+		{"no browser pool", &Server{config: &config.Config{}}, "浏览器池未初始化"},
+		{"no config", &Server{}, "配置未加载"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := probe(t, tt.server, readyzPath)
+			if code != http.StatusServiceUnavailable {
+				t.Fatalf("status = %d, want 503", code)
+			}
+			reasons, _ := body["reasons"].([]interface{})
+			found := false
+			for _, reason := range reasons {
+				found = found || reason == tt.reason
+			}
+			if !found {
+				t.Fatalf("reasons = %v, want %q", reasons, tt.reason)
+			}
+		})
+	}
+}
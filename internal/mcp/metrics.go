@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metricsPath 工具调用耗时统计接口路径，与MCP端点一样需要认证
+const metricsPath = "/metrics"
+
+// toolMetricsCapacity 保留的最近工具调用记录条数
+const toolMetricsCapacity = 500
+
+// ToolCallRecord 单次工具调用记录
+type ToolCallRecord struct {
+	Tool       string    `json:"tool"`        // 工具名称
+	StartedAt  time.Time `json:"started_at"`  // 开始时间
+	DurationMs int64     `json:"duration_ms"` // 耗时(毫秒)
+	Error      bool      `json:"error"`       // 是否失败
+}
+
+// toolStats 单个工具的累计统计（服务启动以来）
+type toolStats struct {
+	count  int64
+	errors int64
+	total  time.Duration
+	max    time.Duration
+}
+
+// ToolMetrics 记录工具调用耗时：最近的调用保存在环形缓冲区中，同时按工具累计次数和耗时
+type ToolMetrics struct {
+	mu      sync.Mutex
+	records []ToolCallRecord
+	next    int
+	full    bool
+	stats   map[string]*toolStats
+}
+
+// NewToolMetrics 创建工具调用统计，capacity为保留的最近调用条数
+func NewToolMetrics(capacity int) *ToolMetrics {
+	if capacity <= 0 {
+		capacity = toolMetricsCapacity
+	}
+	return &ToolMetrics{
+		records: make([]ToolCallRecord, capacity),
+		stats:   make(map[string]*toolStats),
+	}
+}
+
+// Record 记录一次工具调用
+func (m *ToolMetrics) Record(tool string, startedAt time.Time, elapsed time.Duration, isError bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records[m.next] = ToolCallRecord{
+		Tool:       tool,
+		StartedAt:  startedAt,
+		DurationMs: elapsed.Milliseconds(),
+		Error:      isError,
+	}
+	m.next = (m.next + 1) % len(m.records)
+	if m.next == 0 {
+		m.full = true
+	}
+
+	stats, ok := m.stats[tool]
+	if !ok {
+		stats = &toolStats{}
+		m.stats[tool] = stats
+	}
+	stats.count++
+	if isError {
+		stats.errors++
+	}
+	stats.total += elapsed
+	if elapsed > stats.max {
+		stats.max = elapsed
+	}
+}
+
+// Recent 返回最近的工具调用记录，按时间从早到晚排列
+func (m *ToolMetrics) Recent() []ToolCallRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.full {
+		return append([]ToolCallRecord(nil), m.records[:m.next]...)
+	}
+	recent := make([]ToolCallRecord, 0, len(m.records))
+	recent = append(recent, m.records[m.next:]...)
+	return append(recent, m.records[:m.next]...)
+}
+
+// ToolSummary 单个工具的耗时汇总
+type ToolSummary struct {
+	Tool    string  `json:"tool"`     // 工具名称
+	Count   int64   `json:"count"`    // 调用次数
+	Errors  int64   `json:"errors"`   // 失败次数
+	TotalMs int64   `json:"total_ms"` // 累计耗时(毫秒)
+	AvgMs   float64 `json:"avg_ms"`   // 平均耗时(毫秒)
+	MaxMs   int64   `json:"max_ms"`   // 最大耗时(毫秒)
+	P95Ms   int64   `json:"p95_ms"`   // 最近调用的95分位耗时(毫秒)
+}
+
+// Summary 按工具名称汇总调用次数和耗时
+func (m *ToolMetrics) Summary() []ToolSummary {
+	recent := m.Recent()
+	durations := make(map[string][]int64)
+	for _, record := range recent {
+		durations[record.Tool] = append(durations[record.Tool], record.DurationMs)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	summary := make([]ToolSummary, 0, len(m.stats))
+	for tool, stats := range m.stats {
+		summary = append(summary, ToolSummary{
+			Tool:    tool,
+			Count:   stats.count,
+			Errors:  stats.errors,
+			TotalMs: stats.total.Milliseconds(),
+			AvgMs:   float64(stats.total.Milliseconds()) / float64(stats.count),
+			MaxMs:   stats.max.Milliseconds(),
+			P95Ms:   percentile(durations[tool], 0.95),
+		})
+	}
+	sort.Slice(summary, func(i, j int) bool { return summary[i].Tool < summary[j].Tool })
+	return summary
+}
+
+// percentile 计算耗时的分位数，values为空时返回0
+func percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(float64(len(sorted)-1) * p)
+	return sorted[index]
+}
+
+// handleMetrics 返回工具调用统计，默认为Prometheus文本格式，?format=json 时返回JSON（包含最近调用记录）
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"tools":  s.metrics.Summary(),
+			"recent": s.metrics.Recent(),
+		})
+		return
+	}
+
+	var b strings.Builder
+	summary := s.metrics.Summary()
+
+	b.WriteString("# HELP bilibili_mcp_tool_calls_total Total number of tool calls.\n")
+	b.WriteString("# TYPE bilibili_mcp_tool_calls_total counter\n")
+	for _, tool := range summary {
+		fmt.Fprintf(&b, "bilibili_mcp_tool_calls_total{tool=%q,status=\"success\"} %d\n", tool.Tool, tool.Count-tool.Errors)
+		fmt.Fprintf(&b, "bilibili_mcp_tool_calls_total{tool=%q,status=\"error\"} %d\n", tool.Tool, tool.Errors)
+	}
+
+	b.WriteString("# HELP bilibili_mcp_tool_duration_seconds Tool call duration in seconds.\n")
+	b.WriteString("# TYPE bilibili_mcp_tool_duration_seconds summary\n")
+	for _, tool := range summary {
+		fmt.Fprintf(&b, "bilibili_mcp_tool_duration_seconds{tool=%q,quantile=\"0.95\"} %.3f\n", tool.Tool, float64(tool.P95Ms)/1000)
+		fmt.Fprintf(&b, "bilibili_mcp_tool_duration_seconds_sum{tool=%q} %.3f\n", tool.Tool, float64(tool.TotalMs)/1000)
+		fmt.Fprintf(&b, "bilibili_mcp_tool_duration_seconds_count{tool=%q} %d\n", tool.Tool, tool.Count)
+	}
+
+	b.WriteString("# HELP bilibili_mcp_tool_duration_max_seconds Maximum tool call duration in seconds.\n")
+	b.WriteString("# TYPE bilibili_mcp_tool_duration_max_seconds gauge\n")
+	for _, tool := range summary {
+		fmt.Fprintf(&b, "bilibili_mcp_tool_duration_max_seconds{tool=%q} %.3f\n", tool.Tool, float64(tool.MaxMs)/1000)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/download"
+)
+
+// progressEventBuffer 进度通道缓冲大小，写满时丢弃事件，不阻塞下载
+const progressEventBuffer = 16
+
+// notifier 向客户端发送JSON-RPC通知
+type notifier func(message interface{})
+
+type contextKey int
+
+const (
+	notifierKey contextKey = iota
+	progressTokenKey
+)
+
+// withNotifier 将当前传输的通知发送函数放入context
+func withNotifier(ctx context.Context, notify notifier) context.Context {
+	return context.WithValue(ctx, notifierKey, notify)
+}
+
+// progressToken 读取tools/call请求 params._meta.progressToken，未提供时返回nil
+func progressToken(request *JSONRPCRequest) interface{} {
+	params, ok := request.Params.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	meta, ok := params["_meta"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	switch token := meta["progressToken"].(type) {
+	case string, float64:
+		return token
+	default:
+		return nil
+	}
+}
+
+// progressEvent 单个文件的下载进度
+type progressEvent struct {
+	filename   string
+	downloaded int64
+	total      int64
+}
+
+// startProgress 为带progressToken的工具调用创建进度通道，转发为 notifications/progress 通知。
+// 客户端未提供token或当前传输不支持通知时返回nil回调；返回的stop需要在下载结束后调用。
+func (s *Server) startProgress(ctx context.Context) (download.ProgressFunc, func()) {
+	token := ctx.Value(progressTokenKey)
+	notify, _ := ctx.Value(notifierKey).(notifier)
+	if token == nil || notify == nil {
+		return nil, func() {}
+	}
+
+	events := make(chan progressEvent, progressEventBuffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		// 音视频分离时多个文件依次下载，按文件累加保证进度单调递增
+		files := make(map[string]progressEvent)
+		var lastProgress int64
+		for event := range events {
+			files[event.filename] = event
+
+			var progress, total int64
+			totalKnown := true
+			for _, file := range files {
+				progress += file.downloaded
+				if file.total > 0 {
+					total += file.total
+				} else {
+					totalKnown = false
+				}
+			}
+			if progress <= lastProgress {
+				continue
+			}
+			lastProgress = progress
+
+			params := map[string]interface{}{
+				"progressToken": token,
+				"progress":      progress,
+				"message":       fmt.Sprintf("%s: 已下载 %.2f MB", event.filename, float64(event.downloaded)/(1024*1024)),
+			}
+			if totalKnown {
+				params["total"] = total
+			}
+			notify(&JSONRPCNotification{
+				JSONRPC: "2.0",
+				Method:  "notifications/progress",
+				Params:  params,
+			})
+		}
+	}()
+
+	var mu sync.Mutex
+	closed := false
+	onProgress := func(filename string, downloaded, total int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case events <- progressEvent{filename: filename, downloaded: downloaded, total: total}:
+		default:
+		}
+	}
+	stop := func() {
+		mu.Lock()
+		if !closed {
+			closed = true
+			close(events)
+		}
+		mu.Unlock()
+		<-done
+	}
+	return onProgress, stop
+}
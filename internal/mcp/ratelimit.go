@@ -0,0 +1,219 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+const (
+	// rateLimitSweepInterval 清理过期限流记录的间隔
+	rateLimitSweepInterval = time.Minute
+	// defaultRateLimitInterval 未在 features.rate_limits 中配置的操作使用的最小间隔，
+	// 可通过 features.rate_limits.default 调整
+	defaultRateLimitInterval = 5 * time.Second
+	// defaultRateLimitKey 配置默认间隔使用的操作名
+	defaultRateLimitKey = "default"
+	// rateLimitStateFile 持久化限流记录的文件名（位于cookie目录下），重启后限流窗口继续生效
+	rateLimitStateFile = "rate_limits.json"
+)
+
+// rateLimitEntry 一次操作的限流记录
+type rateLimitEntry struct {
+	at       time.Time     // 上次执行时间
+	interval time.Duration // 执行时生效的最小间隔
+}
+
+// rateLimitRecord 磁盘上的限流记录
+type rateLimitRecord struct {
+	At       time.Time     `json:"at"`
+	Interval time.Duration `json:"interval"`
+}
+
+// RateLimitError 操作被本地限流拒绝
+type RateLimitError struct {
+	Wait time.Duration // 距离允许再次执行的等待时间
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("请求过于频繁，请等待 %.1f 秒后再试", e.Wait.Seconds())
+}
+
+// RateLimiter 按 账号+操作+目标 限制操作频率，各操作的最小间隔可通过配置调整
+type RateLimiter struct {
+	mu        sync.Mutex
+	entries   map[string]rateLimitEntry
+	intervals map[string]time.Duration
+	stop      chan struct{}
+	stopOnce  sync.Once
+
+	stateFile string     // 持久化文件路径，为空时只保存在内存中
+	saveMu    sync.Mutex // 保证写入磁盘的快照按顺序落盘
+}
+
+// NewRateLimiter 创建限流器并启动后台清理。intervals 中配置为0的操作不限流，
+// 未配置的操作使用默认间隔；stateFile 非空时从该文件恢复并持久化限流记录
+func NewRateLimiter(intervals map[string]time.Duration, stateFile string) *RateLimiter {
+	limiter := &RateLimiter{
+		entries:   make(map[string]rateLimitEntry),
+		intervals: normalizeIntervals(intervals),
+		stop:      make(chan struct{}),
+		stateFile: stateFile,
+	}
+	limiter.load(time.Now())
+	go limiter.sweepLoop()
+	return limiter
+}
+
+// normalizeIntervals 操作名统一转为小写
+func normalizeIntervals(intervals map[string]time.Duration) map[string]time.Duration {
+	normalized := make(map[string]time.Duration, len(intervals))
+	for operation, interval := range intervals {
+		normalized[strings.ToLower(operation)] = interval
+	}
+	return normalized
+}
+
+// SetIntervals 替换各操作的最小间隔，已有的限流记录按新间隔判断
+func (r *RateLimiter) SetIntervals(intervals map[string]time.Duration) {
+	normalized := normalizeIntervals(intervals)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.intervals = normalized
+}
+
+// intervalFor 获取操作的最小间隔，未配置的操作使用默认间隔
+func (r *RateLimiter) intervalFor(operation string) time.Duration {
+	if interval, ok := r.intervals[operation]; ok {
+		return interval
+	}
+	if interval, ok := r.intervals[defaultRateLimitKey]; ok {
+		return interval
+	}
+	return defaultRateLimitInterval
+}
+
+// Check 检查操作是否允许执行，允许时记录本次执行时间
+func (r *RateLimiter) Check(account, operation, target string) error {
+	r.mu.Lock()
+
+	interval := r.intervalFor(operation)
+	if interval <= 0 {
+		r.mu.Unlock()
+		return nil
+	}
+
+	key := account + "|" + operation + "|" + target
+
+	now := time.Now()
+	if entry, exists := r.entries[key]; exists {
+		if elapsed := now.Sub(entry.at); elapsed < interval {
+			r.mu.Unlock()
+			return &RateLimitError{Wait: interval - elapsed}
+		}
+	}
+
+	r.entries[key] = rateLimitEntry{at: now, interval: interval}
+	r.mu.Unlock()
+
+	r.save()
+	return nil
+}
+
+// load 从持久化文件恢复仍在限流窗口内的记录
+func (r *RateLimiter) load(now time.Time) {
+	if r.stateFile == "" {
+		return
+	}
+	data, err := os.ReadFile(r.stateFile)
+	if err != nil {
+		return
+	}
+
+	var records map[string]rateLimitRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		logger.Warnf("忽略无效的限流记录文件 %s: %v", r.stateFile, err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, record := range records {
+		if now.Sub(record.At) < record.Interval {
+			r.entries[key] = rateLimitEntry{at: record.At, interval: record.Interval}
+		}
+	}
+}
+
+// save 将当前限流记录写入持久化文件，失败时仅记录日志
+func (r *RateLimiter) save() {
+	if r.stateFile == "" {
+		return
+	}
+
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+
+	r.mu.Lock()
+	records := make(map[string]rateLimitRecord, len(r.entries))
+	for key, entry := range r.entries {
+		records[key] = rateLimitRecord{At: entry.at, Interval: entry.interval}
+	}
+	r.mu.Unlock()
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(r.stateFile), 0755); err != nil {
+		logger.Warnf("保存限流记录失败: %v", err)
+		return
+	}
+	tempFile := r.stateFile + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0600); err != nil {
+		logger.Warnf("保存限流记录失败: %v", err)
+		return
+	}
+	if err := os.Rename(tempFile, r.stateFile); err != nil {
+		logger.Warnf("保存限流记录失败: %v", err)
+	}
+}
+
+// Stop 停止后台清理
+func (r *RateLimiter) Stop() {
+	r.stopOnce.Do(func() { close(r.stop) })
+}
+
+// sweepLoop 定期清理已过限流窗口的记录
+func (r *RateLimiter) sweepLoop() {
+	ticker := time.NewTicker(rateLimitSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case now := <-ticker.C:
+			r.sweep(now)
+		}
+	}
+}
+
+// sweep 删除在 now 时刻已不再限流的记录
+func (r *RateLimiter) sweep(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, entry := range r.entries {
+		if now.Sub(entry.at) >= entry.interval {
+			delete(r.entries, key)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shirenchuang/bilibili-mcp/pkg/config"
+)
+
+func TestRateLimiterWindow(t *testing.T) {
+	limiter := NewRateLimiter(map[string]time.Duration{"like_video": 50 * time.Millisecond}, "")
+	defer limiter.Stop()
+
+	if err := limiter.Check("alice", "like_video", "BV1"); err != nil {
+		t.Fatalf("first call rejected: %v", err)
+	}
+
+	err := limiter.Check("alice", "like_video", "BV1")
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("second call within window: got %v, want RateLimitError", err)
+	}
+	if rateErr.Wait <= 0 || rateErr.Wait > 50*time.Millisecond {
+		t.Fatalf("unexpected wait %v", rateErr.Wait)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if err := limiter.Check("alice", "like_video", "BV1"); err != nil {
+		t.Fatalf("call after window rejected: %v", err)
+	}
+}
+
+func TestRateLimiterKeys(t *testing.T) {
+	limiter := NewRateLimiter(map[string]time.Duration{"like_video": time.Minute}, "")
+	defer limiter.Stop()
+
+	if err := limiter.Check("alice", "like_video", "BV1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := limiter.Check("bob", "like_video", "BV1"); err != nil {
+		t.Fatalf("other account limited: %v", err)
+	}
+	if err := limiter.Check("alice", "like_video", "BV2"); err != nil {
+		t.Fatalf("other target limited: %v", err)
+	}
+}
+
+func TestRateLimiterDefaultInterval(t *testing.T) {
+	limiter := NewRateLimiter(map[string]time.Duration{"like_video": 0}, "")
+	defer limiter.Stop()
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.Check("alice", "like_video", "BV1"); err != nil {
+			t.Fatalf("interval 0 should disable limiting: %v", err)
+		}
+	}
+
+	if err := limiter.Check("alice", "unlisted_op", "BV1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := limiter.Check("alice", "unlisted_op", "BV1"); err == nil {
+		t.Fatal("unlisted operation should fall back to the default interval")
+	}
+
+	limiter.SetIntervals(map[string]time.Duration{"default": 0})
+	if err := limiter.Check("alice", "unlisted_op", "BV1"); err != nil {
+		t.Fatalf("configured default 0 should disable limiting: %v", err)
+	}
+}
+
+func TestRateLimiterPersistence(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), rateLimitStateFile)
+	intervals := map[string]time.Duration{"coin_video": time.Minute, "like_video": 10 * time.Millisecond}
+
+	first := NewRateLimiter(intervals, stateFile)
+	if err := first.Check("alice", "coin_video", "BV1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Check("alice", "like_video", "BV1"); err != nil {
+		t.Fatal(err)
+	}
+	first.Stop()
+
+	time.Sleep(20 * time.Millisecond)
+
+	restarted := NewRateLimiter(intervals, stateFile)
+	defer restarted.Stop()
+	if err := restarted.Check("alice", "coin_video", "BV1"); err == nil {
+		t.Fatal("rate limit window was lost after restart")
+	}
+	if err := restarted.Check("alice", "like_video", "BV1"); err != nil {
+		t.Fatalf("expired record restored: %v", err)
+	}
+}
+
+func TestUpdateConfigChangesRateLimit(t *testing.T) {
+	current := &config.Config{}
+	current.Features.RateLimits = map[string]time.Duration{"like_video": time.Minute}
+	s := &Server{config: current, rateLimiter: NewRateLimiter(current.Features.RateLimits, "")}
+	defer s.rateLimiter.Stop()
+
+	if err := s.rateLimiter.Check("alice", "like_video", "BV1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.rateLimiter.Check("alice", "like_video", "BV1"); err == nil {
+		t.Fatal("expected rate limit before reload")
+	}
+
+	next := &config.Config{}
+	next.Features.RateLimits = map[string]time.Duration{"like_video": 0}
+	s.UpdateConfig(next)
+
+	if err := s.rateLimiter.Check("alice", "like_video", "BV1"); err != nil {
+		t.Fatalf("reloaded interval not applied: %v", err)
+	}
+	if got := s.currentConfig().Features.RateLimits["like_video"]; got != 0 {
+		t.Fatalf("server config not updated: %v", got)
+	}
+}
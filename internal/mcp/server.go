@@ -2,15 +2,20 @@ package mcp
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
 	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/auth"
 	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/whisper"
 	"github.com/shirenchuang/bilibili-mcp/internal/browser"
@@ -21,19 +26,73 @@ import (
 // Server MCP服务器
 type Server struct {
 	config         *config.Config
+	configMu       sync.RWMutex
 	browserPool    *browser.BrowserPool
 	loginService   *auth.LoginService
 	whisperService *whisper.Service
 	whisperMutex   sync.RWMutex
+	rateLimiter    *RateLimiter
+	metrics        *ToolMetrics
+
+	// 账号能力探测结果缓存（按账号名）
+	capabilityCache map[string]*accountCapabilities
+	capabilityMutex sync.Mutex
 }
 
 // NewServer 创建MCP服务器
 func NewServer(cfg *config.Config, browserPool *browser.BrowserPool) *Server {
+	var rateLimits map[string]time.Duration
+	var rateLimitFile string
+	if cfg != nil {
+		rateLimits = cfg.Features.RateLimits
+		if dir := cfg.GetResolvedCookieDir(); dir != "" {
+			rateLimitFile = filepath.Join(dir, rateLimitStateFile)
+		}
+	}
+
 	return &Server{
-		config:       cfg,
-		browserPool:  browserPool,
-		loginService: auth.NewLoginService(),
+		config:          cfg,
+		browserPool:     browserPool,
+		loginService:    auth.NewLoginService(),
+		rateLimiter:     NewRateLimiter(rateLimits, rateLimitFile),
+		metrics:         NewToolMetrics(toolMetricsCapacity),
+		capabilityCache: make(map[string]*accountCapabilities),
+	}
+}
+
+// currentConfig 获取当前生效的配置，重新加载配置后会被替换
+func (s *Server) currentConfig() *config.Config {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.config
+}
+
+// UpdateConfig 应用重新加载的配置：只替换运行时可以安全变更的限流、转录和日志级别设置，
+// 其他配置的变化会提示需要重启。返回实际生效的配置
+func (s *Server) UpdateConfig(next *config.Config) *config.Config {
+	s.configMu.Lock()
+	current := s.config
+	applied, restartRequired := next, []string(nil)
+	if current != nil {
+		applied, restartRequired = current.ApplyReloadable(next)
+	}
+	s.config = applied
+	s.configMu.Unlock()
+
+	s.rateLimiter.SetIntervals(applied.Features.RateLimits)
+
+	// 转录服务按配置创建，配置变化后在下次使用时重新创建
+	if current == nil || !reflect.DeepEqual(current.Features.Whisper, applied.Features.Whisper) {
+		s.whisperMutex.Lock()
+		s.whisperService = nil
+		s.whisperMutex.Unlock()
+		logger.Info("🎙️ 转录配置已更新")
 	}
+
+	for _, section := range restartRequired {
+		logger.Warnf("⚠️ %s 配置已修改，需要重启服务才能生效", section)
+	}
+	return applied
 }
 
 // ServeHTTP 处理HTTP请求
@@ -41,7 +100,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// 设置CORS头
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, Mcp-Session-Id")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, Authorization, Mcp-Session-Id")
 
 	// 处理OPTIONS请求
 	if r.Method == "OPTIONS" {
@@ -49,8 +108,23 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 健康检查和就绪检查
+	if r.Method == "GET" && s.handleProbe(w, r) {
+		return
+	}
+
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	switch r.Method {
 	case "GET":
+		if r.URL.Path == metricsPath {
+			s.handleMetrics(w, r)
+			return
+		}
 		s.handleSSEConnection(w, r)
 	case "POST":
 		s.handleJSONRPCRequest(w, r)
@@ -59,6 +133,20 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// authorized 校验 Authorization: Bearer <token>，未配置 server.auth_token 时不校验
+func (s *Server) authorized(r *http.Request) bool {
+	cfg := s.currentConfig()
+	if cfg == nil || cfg.Server.AuthToken == "" {
+		return true
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(cfg.Server.AuthToken)) == 1
+}
+
 // handleSSEConnection 处理SSE连接
 func (s *Server) handleSSEConnection(w http.ResponseWriter, r *http.Request) {
 	// 检查是否支持SSE
@@ -103,6 +191,15 @@ func (s *Server) handleJSONRPCRequest(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("收到MCP请求: %s", request.Method)
 
+	// 带progressToken的工具调用以SSE流返回，先推送进度通知再返回结果
+	if request.Method == "tools/call" && progressToken(&request) != nil &&
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		if _, ok := w.(http.Flusher); ok {
+			s.handleStreamingRequest(w, r, &request)
+			return
+		}
+	}
+
 	// 处理请求
 	response := s.processRequest(&request, r.Context())
 
@@ -110,6 +207,30 @@ func (s *Server) handleJSONRPCRequest(w http.ResponseWriter, r *http.Request) {
 	s.sendJSONResponse(w, response)
 }
 
+// handleStreamingRequest 以SSE流处理请求，处理期间的通知和最终响应都作为message事件写出
+func (s *Server) handleStreamingRequest(w http.ResponseWriter, r *http.Request, request *JSONRPCRequest) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	flusher := w.(http.Flusher)
+	var mu sync.Mutex
+	writeEvent := func(message interface{}) {
+		data, err := json.Marshal(message)
+		if err != nil {
+			logger.Errorf("编码SSE消息失败: %v", err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	ctx := withNotifier(r.Context(), writeEvent)
+	writeEvent(s.processRequest(request, ctx))
+}
+
 // processRequest 处理请求
 func (s *Server) processRequest(request *JSONRPCRequest, ctx context.Context) *JSONRPCResponse {
 	switch request.Method {
@@ -183,6 +304,9 @@ func (s *Server) handleToolCall(ctx context.Context, request *JSONRPCRequest) *J
 	// 设置工具调用超时时间为5分钟（支持音频下载等耗时操作）
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
+	if token := progressToken(request); token != nil {
+		ctx = context.WithValue(ctx, progressTokenKey, token)
+	}
 	// 解析参数
 	params, ok := request.Params.(map[string]interface{})
 	if !ok {
@@ -202,6 +326,7 @@ func (s *Server) handleToolCall(ctx context.Context, request *JSONRPCRequest) *J
 	logger.Infof("执行工具调用: %s", toolName)
 
 	var result *MCPToolResult
+	start := time.Now()
 
 	switch toolName {
 	case "check_login_status":
@@ -210,30 +335,116 @@ func (s *Server) handleToolCall(ctx context.Context, request *JSONRPCRequest) *J
 		result = s.handleListAccounts(ctx, toolArgs)
 	case "switch_account":
 		result = s.handleSwitchAccount(ctx, toolArgs)
+	case "delete_account":
+		result = s.handleDeleteAccount(ctx, toolArgs)
+	case "logout_account":
+		result = s.handleLogoutAccount(ctx, toolArgs)
+	case "refresh_cookie":
+		result = s.handleRefreshCookie(ctx, toolArgs)
+	case "check_all_accounts":
+		result = s.handleCheckAllAccounts(ctx, toolArgs)
 	case "post_comment":
 		result = s.handlePostComment(ctx, toolArgs)
 	// case "post_image_comment":
 	// 	result = s.handlePostImageComment(ctx, toolArgs)
 	case "reply_comment":
 		result = s.handleReplyComment(ctx, toolArgs)
+	case "delete_comment":
+		result = s.handleDeleteComment(ctx, toolArgs)
+	case "like_comment":
+		result = s.handleLikeComment(ctx, toolArgs)
+	case "pin_comment":
+		result = s.handlePinComment(ctx, toolArgs)
+	case "list_emotes":
+		result = s.handleListEmotes(ctx, toolArgs)
+	case "report_video":
+		result = s.handleReportVideo(ctx, toolArgs)
+	case "report_comment":
+		result = s.handleReportComment(ctx, toolArgs)
+	case "get_comment_status":
+		result = s.handleGetCommentStatus(ctx, toolArgs)
+	case "get_comments":
+		result = s.handleGetComments(ctx, toolArgs)
 	case "get_video_info":
 		result = s.handleGetVideoInfo(ctx, toolArgs)
+	case "get_video_summary":
+		result = s.handleGetVideoSummary(ctx, toolArgs)
+	case "summarize_video":
+		result = s.handleSummarizeVideo(ctx, toolArgs)
+	case "get_video_chapters":
+		result = s.handleGetVideoChapters(ctx, toolArgs)
+	case "get_video_pages":
+		result = s.handleGetVideoPages(ctx, toolArgs)
+	case "resolve_part":
+		result = s.handleResolvePart(ctx, toolArgs)
+	case "account_capabilities":
+		result = s.handleAccountCapabilities(ctx, toolArgs)
 	case "like_video":
 		result = s.handleLikeVideo(ctx, toolArgs)
+	case "dislike_video":
+		result = s.handleDislikeVideo(ctx, toolArgs)
+	case "triple_video":
+		result = s.handleTripleVideo(ctx, toolArgs)
+	case "get_video_relation":
+		result = s.handleGetVideoRelation(ctx, toolArgs)
+	case "send_danmaku":
+		result = s.handleSendDanmaku(ctx, toolArgs)
 	case "download_media":
 		result = s.handleDownloadMedia(ctx, toolArgs)
+	case "share_video":
+		result = s.handleShareVideo(ctx, toolArgs)
 	case "coin_video":
 		result = s.handleCoinVideo(ctx, toolArgs)
 	case "favorite_video":
 		result = s.handleFavoriteVideo(ctx, toolArgs)
+	case "unfavorite_video":
+		result = s.handleUnfavoriteVideo(ctx, toolArgs)
+	case "list_favorite_folders":
+		result = s.handleListFavoriteFolders(ctx, toolArgs)
+	case "create_favorite_folder":
+		result = s.handleCreateFavoriteFolder(ctx, toolArgs)
+	case "add_watch_later":
+		result = s.handleAddWatchLater(ctx, toolArgs)
+	case "list_watch_later":
+		result = s.handleListWatchLater(ctx, toolArgs)
+	case "remove_watch_later":
+		result = s.handleRemoveWatchLater(ctx, toolArgs)
 	case "follow_user":
 		result = s.handleFollowUser(ctx, toolArgs)
+	case "charge_user":
+		result = s.handleChargeUser(ctx, toolArgs)
+	case "get_user_info":
+		result = s.handleGetUserInfo(ctx, toolArgs)
 	case "get_user_videos":
 		result = s.handleGetUserVideos(ctx, toolArgs)
+	case "resolve_user":
+		result = s.handleResolveUser(ctx, toolArgs)
+	case "search_videos":
+		result = s.handleSearchVideos(ctx, toolArgs)
+	case "get_related_videos":
+		result = s.handleGetRelatedVideos(ctx, toolArgs)
+	case "get_popular_videos":
+		result = s.handleGetPopularVideos(ctx, toolArgs)
+	case "get_ranking":
+		result = s.handleGetRanking(ctx, toolArgs)
 	case "whisper_audio_2_text":
 		result = s.handleWhisperAudio2Text(ctx, toolArgs)
+	case "whisper_detect_language":
+		result = s.handleWhisperDetectLanguage(ctx, toolArgs)
+	case "transcribe_video":
+		result = s.handleTranscribeVideo(ctx, toolArgs)
 	case "get_video_stream":
 		result = s.handleGetVideoStream(ctx, toolArgs)
+	case "get_danmaku":
+		result = s.handleGetDanmaku(ctx, toolArgs)
+	case "download_danmaku":
+		result = s.handleDownloadDanmaku(ctx, toolArgs)
+	case "download_cover":
+		result = s.handleDownloadCover(ctx, toolArgs)
+	case "download_subtitle":
+		result = s.handleDownloadSubtitle(ctx, toolArgs)
+	case "get_video_subtitle":
+		result = s.handleGetVideoSubtitle(ctx, toolArgs)
 	default:
 		return &JSONRPCResponse{
 			JSONRPC: "2.0",
@@ -245,6 +456,14 @@ func (s *Server) handleToolCall(ctx context.Context, request *JSONRPCRequest) *J
 		}
 	}
 
+	elapsed := time.Since(start)
+	s.metrics.Record(toolName, start, elapsed, result.IsError)
+	status := "success"
+	if result.IsError {
+		status = "error"
+	}
+	logger.Infof("⏱️ 工具调用完成 tool=%s status=%s elapsed_ms=%d", toolName, status, elapsed.Milliseconds())
+
 	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		Result:  result,
@@ -291,9 +510,14 @@ func (s *Server) createToolResult(content string, isError bool) *MCPToolResult {
 	}
 }
 
-// createErrorResult 创建错误结果
+// createErrorResult 创建错误结果：第一个内容块是供展示的错误信息，
+// 第二个内容块是 {code, message, retriable} 形式的JSON，供客户端按错误分类处理
 func (s *Server) createErrorResult(err error) *MCPToolResult {
-	return s.createToolResult(fmt.Sprintf("操作失败: %v", err), true)
+	result := s.createToolResult(fmt.Sprintf("操作失败: %v", err), true)
+	if content, ok := errorContent(err); ok {
+		result.Content = append(result.Content, content)
+	}
+	return result
 }
 
 // getAccountName 获取账号名称
@@ -304,18 +528,118 @@ func (s *Server) getAccountName(args map[string]interface{}) string {
 	return "" // 空字符串表示使用默认账号
 }
 
-// validateVideoID 验证视频ID格式
-func (s *Server) validateVideoID(videoID string) error {
-	if videoID == "" {
-		return errors.New("视频ID不能为空")
+// getAuthedAPIClient 使用指定账号的cookies创建API客户端，请求重试受ctx截止时间约束
+func (s *Server) getAuthedAPIClient(ctx context.Context, accountName string) (*api.Client, error) {
+	cookieMap, err := s.loginService.LoadCookieMap(accountName)
+	if err != nil {
+		return nil, err
+	}
+
+	apiClient := api.NewClient(cookieMap)
+	apiClient.SetContext(ctx)
+	return apiClient, nil
+}
+
+// getAccountCookies 读取指定账号保存的cookies，缺少bili_jct时返回错误
+func (s *Server) getAccountCookies(accountName string) (map[string]string, error) {
+	cookieMap, err := s.loginService.LoadCookieMap(accountName)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, exists := cookieMap["bili_jct"]; !exists {
+		names := make([]string, 0, len(cookieMap))
+		for name := range cookieMap {
+			names = append(names, name)
+		}
+		logger.Warnf("bili_jct不存在，可用的cookies: %v", names)
+		return nil, errors.New("缺少CSRF token (bili_jct)，请重新登录账号")
+	}
+
+	return cookieMap, nil
+}
+
+// getInt64Arg 解析整数参数，兼容数字和字符串形式，未提供时返回0
+func (s *Server) getInt64Arg(args map[string]interface{}, key string) (int64, error) {
+	value, ok := args[key]
+	if !ok || value == nil {
+		return 0, nil
 	}
 
-	// 检查是否是BV号或AV号格式
-	if !strings.HasPrefix(videoID, "BV") && !strings.HasPrefix(videoID, "av") {
-		return errors.New("视频ID格式错误，应为BV号（如BV1234567890）或AV号（如av123456）")
+	switch v := value.(type) {
+	case float64:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case string:
+		if v == "" {
+			return 0, nil
+		}
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, &InvalidArgumentError{Message: fmt.Sprintf("%s参数格式错误", key)}
+		}
+		return parsed, nil
+	default:
+		return 0, &InvalidArgumentError{Message: fmt.Sprintf("%s参数类型错误", key)}
 	}
+}
 
-	return nil
+// resolveUserID 将UID、用户空间链接或用户名解析为mid。
+// 用户名需要与搜索结果完全匹配且唯一，否则返回候选列表，提示使用 resolve_user 确认。
+func (s *Server) resolveUserID(ctx context.Context, input, accountName string) (string, error) {
+	if mid, ok := api.ParseUserID(input); ok {
+		return mid, nil
+	}
+
+	name := strings.TrimSpace(input)
+	if name == "" {
+		return "", errors.New("用户ID不能为空")
+	}
+
+	apiClient := api.NewClient(map[string]string{})
+	if accountName != "" {
+		authedClient, err := s.getAuthedAPIClient(ctx, accountName)
+		if err != nil {
+			return "", err
+		}
+		apiClient = authedClient
+	}
+	apiClient.SetContext(ctx)
+
+	resp, err := apiClient.SearchUsers(name, 1)
+	if err != nil {
+		return "", errors.Wrap(err, "按用户名搜索用户失败")
+	}
+	if resp.Code != 0 {
+		return "", errors.Wrap(api.NewAPIError(resp.Code, resp.Message), "按用户名搜索用户失败")
+	}
+
+	var matched []api.UserSearchResult
+	for _, user := range resp.Data.Result {
+		if strings.EqualFold(user.Uname, name) {
+			matched = append(matched, user)
+		}
+	}
+	if len(matched) == 1 {
+		logger.Infof("🔍 用户名 %s 解析为UID: %d", name, matched[0].Mid)
+		return strconv.FormatInt(matched[0].Mid, 10), nil
+	}
+
+	if len(resp.Data.Result) == 0 {
+		return "", errors.Errorf("未找到用户: %s，请提供UID或用户空间链接", name)
+	}
+
+	candidates := make([]string, 0, 5)
+	for i, user := range resp.Data.Result {
+		if i >= 5 {
+			break
+		}
+		candidates = append(candidates, fmt.Sprintf("%s(UID:%d)", user.Uname, user.Mid))
+	}
+	return "", errors.Errorf("无法唯一确定用户 %s，候选: %s。请使用 resolve_user 确认后传入UID", name, strings.Join(candidates, ", "))
 }
 
 // getOrCreateWhisperService 获取或创建Whisper服务
@@ -336,7 +660,7 @@ func (s *Server) getOrCreateWhisperService() (*whisper.Service, error) {
 	}
 
 	// 创建新的Whisper服务，传递完整配置
-	service, err := whisper.NewService(s.config)
+	service, err := whisper.NewService(s.currentConfig())
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shirenchuang/bilibili-mcp/pkg/config"
+)
+
+func TestAuthorized(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.AuthToken = "s3cret"
+	s := &Server{config: cfg}
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		wantStatus int
+	}{
+		{"no token", "/mcp", "", http.StatusUnauthorized},
+		{"wrong token", "/mcp", "Bearer wrong", http.StatusUnauthorized},
+		{"missing bearer prefix", "/mcp", "s3cret", http.StatusUnauthorized},
+		{"correct token", "/mcp", "Bearer s3cret", http.StatusBadRequest}, // 通过认证，未请求SSE返回400
+		{"probe bypass", readyzPath, "", http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuthorizedWithoutToken(t *testing.T) {
+	s := &Server{config: &config.Config{}}
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	if !s.authorized(req) {
+		t.Fatal("requests must be allowed when server.auth_token is not set")
+	}
+}
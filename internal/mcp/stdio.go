@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/pkg/logger"
+)
+
+// stdioMaxMessageSize 单条stdio消息的最大长度
+const stdioMaxMessageSize = 16 * 1024 * 1024
+
+// stdioWriter 串行写出换行分隔的JSON-RPC消息
+type stdioWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// write 编码消息并写出一行
+func (w *stdioWriter) write(message interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		logger.Errorf("编码响应失败: %v", err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.out.Write(append(data, '\n')); err != nil {
+		logger.Errorf("写出响应失败: %v", err)
+	}
+}
+
+// ServeStdio 通过stdio提供MCP服务：从in读取换行分隔的JSON-RPC消息，响应写入out。
+// 请求并发处理，耗时的工具调用不会阻塞ping等其他请求；in关闭或ctx取消时返回。
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	writer := &stdioWriter{out: out}
+	ctx = withNotifier(ctx, writer.write)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), stdioMaxMessageSize)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lines := make(chan string)
+	scanErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		scanErr <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line, ok := <-lines:
+			if !ok {
+				select {
+				case err := <-scanErr:
+					if err != nil {
+						return errors.Wrap(err, "读取stdin失败")
+					}
+				default:
+				}
+				logger.Info("stdin已关闭，停止stdio服务")
+				return nil
+			}
+
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+
+			var request JSONRPCRequest
+			if err := json.Unmarshal([]byte(line), &request); err != nil {
+				logger.Errorf("MCP错误: Parse error - %v", err)
+				writer.write(&JSONRPCResponse{
+					JSONRPC: "2.0",
+					Error:   &JSONRPCError{Code: -32700, Message: "Parse error", Data: err.Error()},
+				})
+				continue
+			}
+
+			logger.Infof("收到MCP请求: %s", request.Method)
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				response := s.processRequest(&request, ctx)
+				// 没有id的通知消息不需要响应
+				if request.ID == nil {
+					return
+				}
+				writer.write(response)
+			}()
+		}
+	}
+}
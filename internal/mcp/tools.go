@@ -14,12 +14,17 @@ func GetMCPTools() []MCPTool {
 						"type":        "string",
 						"description": "账号名称（可选，默认使用当前账号）",
 					},
+					"validate": map[string]interface{}{
+						"type":        "boolean",
+						"description": "是否请求B站接口校验会话是否有效，失效的账号会被自动停用（默认false，仅检查cookies文件）",
+						"default":     false,
+					},
 				},
 			},
 		},
 		{
 			Name:        "list_accounts",
-			Description: "列出所有已登录的账号",
+			Description: "列出所有已登录的账号，包含账号等级和大会员状态（大会员才能获取1080P+/无损等高画质音质）",
 			InputSchema: map[string]interface{}{
 				"type":       "object",
 				"properties": map[string]interface{}{},
@@ -39,11 +44,77 @@ func GetMCPTools() []MCPTool {
 				"required": []string{"account_name"},
 			},
 		},
+		{
+			Name:        "logout_account",
+			Description: "退出指定账号：注销B站服务端会话并删除本地cookies（用于停用账号）",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "要退出的账号名称",
+					},
+				},
+				"required": []string{"account_name"},
+			},
+		},
+		{
+			Name:        "delete_account",
+			Description: "从本地删除指定账号及其cookies（不注销服务端会话），删除默认账号时自动将最近使用的账号设为默认，返回剩余账号列表",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "要删除的账号名称（必须显式指定）",
+					},
+				},
+				"required": []string{"account_name"},
+			},
+		},
+		{
+			Name:        "refresh_cookie",
+			Description: "按B站官方流程刷新账号cookies并保存，服务端认为无需刷新时直接返回当前状态",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "账号名称（可选，默认使用当前账号）",
+					},
+				},
+			},
+		},
+		{
+			Name:        "check_all_accounts",
+			Description: "并发检查所有已登录账号的登录状态，汇总有效和已失效的账号，并更新账号等级和大会员状态",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "account_capabilities",
+			Description: "探测当前账号实际可获取的清晰度、视频编码和音质档位（区分大会员与普通账号），结果按账号缓存1小时",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "账号名称（可选，默认使用当前账号）",
+					},
+					"refresh": map[string]interface{}{
+						"type":        "boolean",
+						"description": "忽略缓存重新探测（可选，默认false）",
+					},
+				},
+			},
+		},
 
 		// 评论相关
 		{
 			Name:        "post_comment",
-			Description: "发表文字评论到视频",
+			Description: "发表文字评论到视频。内容中的方括号表情代码（如 [doge]、[tv_doge]）会显示为B站表情，可通过 list_emotes 查询可用代码",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -72,7 +143,7 @@ func GetMCPTools() []MCPTool {
 		// 		"properties": map[string]interface{}{
 		// 			"video_id": map[string]interface{}{
 		// 				"type":        "string",
-		// 				"description": "视频BV号或AV号",
+		// 				"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
 		// 			},
 		// 			"content": map[string]interface{}{
 		// 				"type":        "string",
@@ -98,7 +169,7 @@ func GetMCPTools() []MCPTool {
 				"properties": map[string]interface{}{
 					"video_id": map[string]interface{}{
 						"type":        "string",
-						"description": "视频BV号或AV号",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
 					},
 					"parent_comment_id": map[string]interface{}{
 						"type":        "string",
@@ -116,151 +187,180 @@ func GetMCPTools() []MCPTool {
 				"required": []string{"video_id", "parent_comment_id", "content"},
 			},
 		},
-
-		// 视频操作
 		{
-			Name:        "get_video_info",
-			Description: "获取视频详细信息",
+			Name:        "delete_comment",
+			Description: "删除自己发表的评论或回复（需要登录，只能删除当前账号发表的评论）",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
 					"video_id": map[string]interface{}{
 						"type":        "string",
-						"description": "视频BV号或AV号",
+						"description": "评论所在视频的BV号或AV号",
+					},
+					"comment_id": map[string]interface{}{
+						"type":        "string",
+						"description": "要删除的评论ID（rpid）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "发表评论的账号名称（可选）",
 					},
 				},
-				"required": []string{"video_id"},
+				"required": []string{"video_id", "comment_id"},
 			},
 		},
 		{
-			Name:        "like_video",
-			Description: "点赞视频",
+			Name:        "like_comment",
+			Description: "点赞或取消点赞视频评论（需要登录）",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
 					"video_id": map[string]interface{}{
 						"type":        "string",
-						"description": "视频BV号或AV号",
+						"description": "评论所在视频的BV号或AV号",
+					},
+					"comment_id": map[string]interface{}{
+						"type":        "string",
+						"description": "评论ID（rpid）",
+					},
+					"like": map[string]interface{}{
+						"type":        "boolean",
+						"description": "true=点赞，false=取消点赞（默认true）",
 					},
 					"account_name": map[string]interface{}{
 						"type":        "string",
 						"description": "指定使用的账号名称（可选）",
 					},
 				},
-				"required": []string{"video_id"},
+				"required": []string{"video_id", "comment_id"},
 			},
 		},
 		{
-			Name:        "coin_video",
-			Description: "投币视频",
+			Name:        "pin_comment",
+			Description: "置顶或取消置顶视频评论（需要登录，只有视频UP主可以操作，每个视频只能置顶一条评论）",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
 					"video_id": map[string]interface{}{
 						"type":        "string",
-						"description": "视频BV号或AV号",
+						"description": "评论所在视频的BV号或AV号（需为当前账号投稿的视频）",
 					},
-					"coin_count": map[string]interface{}{
-						"type":        "integer",
-						"description": "投币数量（1或2）",
-						"minimum":     1,
-						"maximum":     2,
-						"default":     1,
+					"comment_id": map[string]interface{}{
+						"type":        "string",
+						"description": "评论ID（rpid）",
+					},
+					"pin": map[string]interface{}{
+						"type":        "boolean",
+						"description": "true=置顶，false=取消置顶（默认true）",
 					},
 					"account_name": map[string]interface{}{
 						"type":        "string",
-						"description": "指定使用的账号名称（可选）",
+						"description": "UP主账号名称（可选）",
 					},
 				},
-				"required": []string{"video_id"},
+				"required": []string{"video_id", "comment_id"},
 			},
 		},
 		{
-			Name:        "favorite_video",
-			Description: "收藏视频",
+			Name:        "list_emotes",
+			Description: "列出评论可用的表情代码（如 [doge]），写入 post_comment/reply_comment 的内容即可显示为表情",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"keyword": map[string]interface{}{
+						"type":        "string",
+						"description": "按表情代码或表情包名称过滤（可选），如 doge、小电视",
+					},
+				},
+			},
+		},
+
+		// 举报相关
+		{
+			Name:        "report_video",
+			Description: "举报视频（需要登录，同一账号每分钟最多举报一次）",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
 					"video_id": map[string]interface{}{
 						"type":        "string",
-						"description": "视频BV号或AV号",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
 					},
-					"folder_id": map[string]interface{}{
+					"reason": map[string]interface{}{
+						"type":        "number",
+						"description": "举报理由：2=违法违禁, 3=色情, 4=低俗, 5=赌博诈骗, 6=血腥暴力, 7=人身攻击, 8=与站内其他视频撞车, 9=引战, 10=青少年不良信息, 52=转载/自制错误",
+						"enum":        []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 52},
+					},
+					"detail": map[string]interface{}{
 						"type":        "string",
-						"description": "收藏夹ID（可选，默认收藏夹）",
+						"description": "举报详细说明（可选）",
 					},
 					"account_name": map[string]interface{}{
 						"type":        "string",
 						"description": "指定使用的账号名称（可选）",
 					},
 				},
-				"required": []string{"video_id"},
+				"required": []string{"video_id", "reason"},
 			},
 		},
 		{
-			Name:        "download_media",
-			Description: "智能下载B站视频媒体文件，优先下载包含音频的完整视频，仅在高清视频时使用音视频分离格式。支持实时进度显示和多种清晰度选择",
+			Name:        "report_comment",
+			Description: "举报视频评论（需要登录，同一账号每分钟最多举报一次）",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
 					"video_id": map[string]interface{}{
 						"type":        "string",
-						"description": "视频BV号或AV号",
+						"description": "评论所在视频的BV号或AV号",
 					},
-					"media_type": map[string]interface{}{
+					"comment_id": map[string]interface{}{
 						"type":        "string",
-						"description": "媒体类型：audio=仅音频, video=仅视频, merged=音视频合并（默认）",
-						"enum":        []string{"audio", "video", "merged"},
-					},
-					"quality": map[string]interface{}{
-						"type":        "number",
-						"description": "视频清晰度（可选）：16=360P, 32=480P, 64=720P, 80=1080P, 112=1080P+, 116=1080P60, 120=4K, 125=HDR, 127=8K。0=自动选择最佳",
+						"description": "被举报的评论ID (rpid)",
 					},
-					"cid": map[string]interface{}{
+					"reason": map[string]interface{}{
 						"type":        "number",
-						"description": "视频分P的CID（可选，不指定则使用第一个分P）",
-					},
-					"output_dir": map[string]interface{}{
-						"type":        "string",
-						"description": "输出目录路径（可选，默认为./downloads）",
+						"description": "举报理由：0=其他, 1=垃圾广告, 2=色情, 3=刷屏, 4=引战, 5=剧透, 7=人身攻击, 8=内容不相关, 9=违法违规, 10=低俗, 12=赌博诈骗, 13=传播不实信息, 15=侵犯隐私, 17=青少年不良信息",
+						"enum":        []int{0, 1, 2, 3, 4, 5, 7, 8, 9, 10, 12, 13, 15, 17},
 					},
 					"account_name": map[string]interface{}{
 						"type":        "string",
-						"description": "指定使用的账号名称（可选，登录后可获取更高清晰度）",
+						"description": "指定使用的账号名称（可选）",
 					},
 				},
-				"required": []string{"video_id"},
+				"required": []string{"video_id", "comment_id", "reason"},
 			},
 		},
-
-		// 用户操作
 		{
-			Name:        "follow_user",
-			Description: "关注用户",
+			Name:        "get_comment_status",
+			Description: "检查评论当前状态：正常可见、审核中（仅自己可见）或已删除，并返回点赞数和回复数。可在post_comment/reply_comment后确认评论未被自动审核屏蔽",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"user_id": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"comment_id": map[string]interface{}{
 						"type":        "string",
-						"description": "用户UID",
+						"description": "评论ID（rpid）",
 					},
 					"account_name": map[string]interface{}{
 						"type":        "string",
-						"description": "指定使用的账号名称（可选）",
+						"description": "发表评论的账号名称（可选），用于识别仅自己可见的审核中评论",
 					},
 				},
-				"required": []string{"user_id"},
+				"required": []string{"video_id", "comment_id"},
 			},
 		},
 		{
-			Name:        "get_user_videos",
-			Description: "获取用户发布的视频列表",
+			Name:        "get_comments",
+			Description: "获取视频的评论列表，返回评论ID、作者、内容、点赞数和回复数",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"user_id": map[string]interface{}{
+					"video_id": map[string]interface{}{
 						"type":        "string",
-						"description": "用户UID",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
 					},
 					"page": map[string]interface{}{
 						"type":        "integer",
@@ -273,66 +373,1025 @@ func GetMCPTools() []MCPTool {
 						"description": "每页数量",
 						"default":     20,
 						"minimum":     1,
-						"maximum":     50,
+						"maximum":     30,
+					},
+					"sort": map[string]interface{}{
+						"type":        "integer",
+						"description": "排序方式：0=按时间，1=按热度（默认）",
+						"enum":        []int{0, 1},
+						"default":     1,
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
 					},
 				},
-				"required": []string{"user_id"},
+				"required": []string{"video_id"},
 			},
 		},
 
-		// 可选功能 - Whisper音频转录
+		// 视频操作
 		{
-			Name:        "whisper_audio_2_text",
-			Description: "使用Whisper.cpp将音频文件转录为文字。支持多种音频格式，自动转换为最适合的格式进行识别。需要先运行 ./bilibili-whisper-init 进行初始化",
+			Name:        "get_video_info",
+			Description: "获取视频详细信息",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"audio_path": map[string]interface{}{
+					"video_id": map[string]interface{}{
 						"type":        "string",
-						"description": "音频文件路径（支持mp3, wav, m4a, flac等格式）",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
 					},
-					"language": map[string]interface{}{
-						"type":        "string",
-						"description": "识别语言代码：zh=中文, en=英文, ja=日语, auto=自动检测",
-						"default":     "zh",
+					"no_cache": map[string]interface{}{
+						"type":        "boolean",
+						"description": "跳过视频信息缓存，强制重新获取（可选，默认false）",
 					},
-					"model": map[string]interface{}{
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "get_video_summary",
+			Description: "获取B站官方AI生成的视频总结（摘要和带时间戳的提纲），部分视频可能没有总结",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
 						"type":        "string",
-						"description": "使用的模型（建议不传此参数，系统会自动选择最佳可用模型）。可选值：auto=智能选择最佳, tiny=最快, base=平衡, small=推荐, medium=高质量, large=最佳。如果指定模型不存在，会自动降级到可用的最佳模型",
-						"enum":        []string{"auto", "tiny", "base", "small", "medium", "large"},
-						"default":     "auto",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"cid": map[string]interface{}{
+						"type":        "number",
+						"description": "视频分P的CID（可选，不指定则使用第一个分P）",
 					},
 				},
-				"required": []string{"audio_path"},
+				"required": []string{"video_id"},
 			},
 		},
-
-		// 视频流相关
 		{
-			Name:        "get_video_stream",
-			Description: "获取视频播放地址，直接返回可用的音频和视频流URL。只需提供视频ID即可，会自动获取第一个分P的播放地址",
+			Name:        "summarize_video",
+			Description: "一站式获取视频概要：返回标题、UP主、时长、数据、标签，并优先附带B站官方AI总结；没有官方总结时自动下载音频并用Whisper转录",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
 					"video_id": map[string]interface{}{
 						"type":        "string",
-						"description": "视频ID（BV号或av号）",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
 					},
 					"cid": map[string]interface{}{
 						"type":        "number",
-						"description": "视频分P的CID（可选，不指定则自动获取第一个分P）",
+						"description": "视频分P的CID（可选，不指定则使用第一个分P）",
 					},
-					"quality": map[string]interface{}{
+					"use_official_summary": map[string]interface{}{
+						"type":        "boolean",
+						"description": "是否优先使用B站官方AI总结（可选，默认true）",
+					},
+					"transcribe": map[string]interface{}{
+						"type":        "boolean",
+						"description": "没有官方总结时是否下载音频并转录（可选，默认true，需要启用Whisper）",
+					},
+					"max_transcript_chars": map[string]interface{}{
 						"type":        "number",
-						"description": "视频清晰度（可选）：16=360P, 32=480P, 64=720P, 80=1080P, 112=1080P+, 116=1080P60, 120=4K, 125=HDR, 127=8K",
+						"description": "返回的转录文本最大字符数（可选，默认8000，完整内容保存在SRT文件中）",
 					},
-					"fnval": map[string]interface{}{
+					"output_dir": map[string]interface{}{
+						"type":        "string",
+						"description": "音频和转录文件的输出目录（可选，默认为./downloads）",
+					},
+					"keep_audio": map[string]interface{}{
+						"type":        "boolean",
+						"description": "转录后是否保留下载的原始音频m4a（可选，默认使用配置 features.whisper.keep_audio=true）。SRT文件总是保留，WAV中间文件默认删除",
+					},
+					"audio_quality": map[string]interface{}{
+						"type":        "string",
+						"description": "转录使用的音轨（可选，默认default）：default=最佳标准音轨；high=优先无损，其次杜比；flac=Hi-Res无损；dolby=杜比全景声。无损和杜比通常需要大会员，不可用时回退到标准音轨",
+						"enum":        []string{"default", "high", "flac", "dolby"},
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "get_video_chapters",
+			Description: "获取视频的分段章节（UP主设置的进度条章节），返回每个章节的起止时间和标题",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"cid": map[string]interface{}{
 						"type":        "number",
-						"description": "视频流格式（可选）：1=MP4, 16=DASH, 64=HDR, 128=4K, 256=杜比音频, 512=杜比视界, 1024=8K, 2048=AV1, 4048=所有DASH",
+						"description": "视频分P的CID（可选，不指定则使用第一个分P）",
 					},
-					"platform": map[string]interface{}{
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "get_video_pages",
+			Description: "获取视频的分P列表，只返回每个分P的序号、CID、标题和时长，便于为download_media/get_video_stream选择cid",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
 						"type":        "string",
-						"description": "平台标识（可选）：pc=PC端（有防盗链），html5=移动端（无防盗链）",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "resolve_part",
+			Description: "根据分P标题（支持模糊匹配）查找多P视频中对应分P的CID和序号，结果可用于download_media/get_video_stream",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"part_title": map[string]interface{}{
+						"type":        "string",
+						"description": "分P标题或其中的关键词（如：第3讲）",
+					},
+				},
+				"required": []string{"video_id", "part_title"},
+			},
+		},
+		{
+			Name:        "like_video",
+			Description: "点赞视频",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "get_video_relation",
+			Description: "查询当前账号对视频的互动状态（是否已点赞、投币数、是否收藏、是否不喜欢、是否关注UP主），需要已登录账号，未登录时只会返回默认值",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "send_danmaku",
+			Description: "在视频的指定时间点发送弹幕，需要已登录账号",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"cid": map[string]interface{}{
+						"type":        "number",
+						"description": "视频分P的CID（可选，不指定则使用第一个分P）",
+					},
+					"progress": map[string]interface{}{
+						"type":        "number",
+						"description": "弹幕出现的时间点（秒，可带小数，默认0）",
+						"default":     0,
+					},
+					"message": map[string]interface{}{
+						"type":        "string",
+						"description": "弹幕内容（不超过100个字符）",
+					},
+					"mode": map[string]interface{}{
+						"type":        "number",
+						"description": "弹幕类型：1=滚动（默认），4=底部，5=顶部",
+						"enum":        []int{1, 4, 5},
+						"default":     1,
+					},
+					"color": map[string]interface{}{
+						"type":        "string",
+						"description": "弹幕颜色，十六进制RGB，如 FFFFFF（默认白色）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id", "message"},
+			},
+		},
+		{
+			Name:        "triple_video",
+			Description: "一键三连：同时点赞、投币（2枚）和收藏视频，返回每项操作是否成功",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "share_video",
+			Description: "分享视频（增加视频的分享数），返回分享后的分享数，需要已登录账号",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "dislike_video",
+			Description: "将视频标记为不喜欢（或取消不喜欢），用于调整推荐内容",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"dislike": map[string]interface{}{
+						"type":        "boolean",
+						"description": "true=标记不喜欢，false=取消不喜欢（可选，默认true）",
+						"default":     true,
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "coin_video",
+			Description: "投币视频",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"coin_count": map[string]interface{}{
+						"type":        "integer",
+						"description": "投币数量（1或2）",
+						"minimum":     1,
+						"maximum":     2,
+						"default":     1,
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "favorite_video",
+			Description: "收藏视频；同时指定remove_from_folders可将视频从其他收藏夹移动到folder_id",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"folder_id": map[string]interface{}{
+						"type":        "string",
+						"description": "收藏夹ID（可选，默认收藏夹，可通过list_favorite_folders获取）",
+					},
+					"remove_from_folders": map[string]interface{}{
+						"type":        "string",
+						"description": "同时要移出的收藏夹ID，多个用逗号分隔（可选，指定时必须提供folder_id）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "unfavorite_video",
+			Description: "取消收藏：将视频移出指定收藏夹",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"folder_id": map[string]interface{}{
+						"type":        "string",
+						"description": "要移出的收藏夹ID，多个用逗号分隔（可选，默认收藏夹）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "list_favorite_folders",
+			Description: "列出当前账号创建的所有收藏夹，返回的ID可用于favorite_video的folder_id",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+			},
+		},
+		{
+			Name:        "create_favorite_folder",
+			Description: "创建新的收藏夹",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "收藏夹名称",
+					},
+					"private": map[string]interface{}{
+						"type":        "boolean",
+						"description": "是否设为私密收藏夹（可选，默认false）",
+						"default":     false,
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"title"},
+			},
+		},
+		{
+			Name:        "add_watch_later",
+			Description: "将视频加入稍后再看",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "list_watch_later",
+			Description: "获取稍后再看列表，包含标题、时长、观看进度和添加时间",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+			},
+		},
+		{
+			Name:        "remove_watch_later",
+			Description: "将视频移出稍后再看",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "download_media",
+			Description: "智能下载B站视频媒体文件，优先下载包含音频的完整视频，仅在高清视频时使用音视频分离格式。支持多种清晰度选择。调用时在 params._meta.progressToken 中提供token，会以 notifications/progress 通知推送下载进度（progress/total 为已下载/总字节数，stdio 直接写出，HTTP 需在 Accept 中包含 text/event-stream 以SSE流返回）",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"media_type": map[string]interface{}{
+						"type":        "string",
+						"description": "媒体类型：audio=仅音频, video=仅视频, merged=音视频合并（默认）",
+						"enum":        []string{"audio", "video", "merged"},
+					},
+					"quality": map[string]interface{}{
+						"type":        "number",
+						"description": "视频清晰度（可选）：16=360P, 32=480P, 64=720P, 80=1080P, 112=1080P+, 116=1080P60, 120=4K, 125=HDR, 127=8K。0=自动选择最佳",
+					},
+					"cid": map[string]interface{}{
+						"type":        "number",
+						"description": "视频分P的CID（可选，不指定则使用第一个分P）",
+					},
+					"preset": map[string]interface{}{
+						"type":        "string",
+						"description": "下载预设（可选）：mobile_mp4=音视频合一MP4(≤720P), web_dash=DASH 1080P, web_4k=DASH 4K/HDR（需大会员）, web_all=全部DASH特性（所有编码/杜比/无损/8K，取决于账号权限）。显式传入的quality会覆盖预设",
+						"enum":        []string{"mobile_mp4", "web_dash", "web_4k", "web_all"},
+					},
+					"platform": map[string]interface{}{
+						"type":        "string",
+						"description": "请求平台（可选）：html5=无防盗链，下载稳定但部分高画质可能受限（默认）；pc=网页端，可获取完整画质但需匹配Referer，直链易403",
+						"enum":        []string{"html5", "pc"},
+					},
+					"prefer_no_merge": map[string]interface{}{
+						"type":        "boolean",
+						"description": "合并偏好（可选，仅merged类型生效）：true=选择无需ffmpeg合并的最高清晰度MP4（通常≤1080P）；false=选择最高清晰度的音视频分离格式（需要合并）；不传则标清优先MP4",
+					},
+					"all_pages": map[string]interface{}{
+						"type":        "boolean",
+						"description": "下载多P视频的全部分P（可选，默认false）。文件保存到以视频标题命名的子目录，按 P序号_分P标题 命名，已存在的文件会跳过；不能与cid同时使用",
+						"default":     false,
+					},
+					"audio_quality": map[string]interface{}{
+						"type":        "string",
+						"description": "音质（可选，默认default）：default=最佳标准音轨；high=优先无损，其次杜比，最后标准音轨；flac=Hi-Res无损；dolby=杜比全景声。无损和杜比通常需要大会员，不可用时回退到最佳标准音轨并在结果中提示",
+						"enum":        []string{"default", "high", "flac", "dolby"},
+					},
+					"output_format": map[string]interface{}{
+						"type":        "string",
+						"description": "音视频分离下载时合并输出的容器格式（可选，默认mp4）：mp4/mkv可直接封装；webm仅支持VP9/AV1+Opus，其他编码需要重新编码",
+						"enum":        []string{"mp4", "mkv", "webm"},
+					},
+					"auto_merge": map[string]interface{}{
+						"type":        "boolean",
+						"description": "音视频分离时自动使用ffmpeg合并为单个文件并删除中间文件（可选，默认false）。未安装ffmpeg或合并失败时保留音视频文件并返回合并命令",
+					},
+					"max_quality": map[string]interface{}{
+						"type":        "string",
+						"description": "清晰度上限（可选）：360p/480p/720p/1080p/4k/8k 或清晰度代码（如80）。自动选择时不会超过该清晰度，适合批量下载节省空间；默认使用配置 download.max_quality",
+					},
+					"filename_template": map[string]interface{}{
+						"type":        "string",
+						"description": "文件名模板（可选）：支持 {title}=视频标题、{part_title}=分P标题、{page}=分P序号，视频ID和清晰度会自动追加。不传时多P视频自动使用 {title}_P{page}_{part_title}",
+					},
+					"verify_checksum": map[string]interface{}{
+						"type":        "boolean",
+						"description": "下载完成后计算文件SHA256并在结果中返回，便于校验文件完整性（可选，默认false）",
+					},
+					"tag_audio": map[string]interface{}{
+						"type":        "boolean",
+						"description": "仅音频下载时，使用ffmpeg嵌入视频封面并写入标题/UP主元数据（可选，默认false，未安装ffmpeg时自动跳过）",
+					},
+					"output_dir": map[string]interface{}{
+						"type":        "string",
+						"description": "输出目录路径（可选，默认为./downloads）",
+					},
+					"no_cache": map[string]interface{}{
+						"type":        "boolean",
+						"description": "跳过视频信息缓存，强制重新获取（可选，默认false）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选，登录后可获取更高清晰度）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "get_danmaku",
+			Description: "获取视频弹幕并按出现时间排序返回，适合分析观众情绪和高能片段，不需要登录",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"cid": map[string]interface{}{
+						"type":        "number",
+						"description": "视频分P的CID（可选，不指定则使用第一个分P）",
+					},
+					"limit": map[string]interface{}{
+						"type":        "number",
+						"description": "最多返回的弹幕条数（可选，默认200，最大5000）",
+						"default":     200,
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "download_danmaku",
+			Description: "下载视频弹幕，支持原始XML、解析后的JSON以及可直接外挂或烧录的ASS字幕",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"cid": map[string]interface{}{
+						"type":        "number",
+						"description": "视频分P的CID（可选，不指定则使用第一个分P）",
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"description": "输出格式：json=解析后的弹幕列表, xml=B站原始XML, ass=ASS字幕（默认）",
+						"enum":        []string{"json", "xml", "ass"},
+					},
+					"output_dir": map[string]interface{}{
+						"type":        "string",
+						"description": "输出目录路径（可选，默认为./downloads）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "download_cover",
+			Description: "下载视频封面图片，保存为 <标题>_<BV号>_cover.jpg",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"output_dir": map[string]interface{}{
+						"type":        "string",
+						"description": "输出目录路径（可选，默认为./downloads）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "download_subtitle",
+			Description: "下载视频的官方字幕（CC字幕）并转换为SRT文件，同时返回字幕纯文本。没有官方字幕时可改用whisper_audio_2_text转录。需要登录",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"language": map[string]interface{}{
+						"type":        "string",
+						"description": "字幕语言代码（可选，如 zh-CN、en-US、ai-zh，不指定时优先选择中文字幕）",
+					},
+					"output_dir": map[string]interface{}{
+						"type":        "string",
+						"description": "输出目录路径（可选，默认为./downloads）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "get_video_subtitle",
+			Description: "通过播放器接口获取视频字幕（包括AI生成的字幕）。不指定language时返回可用字幕列表，指定时返回转换后的SRT文本。需要登录",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"cid": map[string]interface{}{
+						"type":        "number",
+						"description": "视频分P的CID（可选，不指定则使用第一个分P）",
+					},
+					"language": map[string]interface{}{
+						"type":        "string",
+						"description": "字幕语言代码（可选，如 zh-CN、en-US、ai-zh，不指定时返回可用字幕列表）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+
+		// 用户操作
+		{
+			Name:        "follow_user",
+			Description: "关注或取消关注用户",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"user_id": map[string]interface{}{
+						"type":        "string",
+						"description": "用户UID，也支持用户空间链接或完整用户名（用户名不唯一时请先使用resolve_user）",
+					},
+					"follow": map[string]interface{}{
+						"type":        "boolean",
+						"description": "true=关注，false=取消关注（可选，默认true）",
+						"default":     true,
+					},
+					"group_name": map[string]interface{}{
+						"type":        "string",
+						"description": "关注后加入的分组名称（可选，不存在时自动创建，取消关注时不可用）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"user_id"},
+			},
+		},
+		{
+			Name:        "charge_user",
+			Description: "使用B币余额为UP主充电（会实际花费B币，1 B币=1元）。必须显式传入 confirm: true 才会执行，否则只返回将要花费的金额",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"user_id": map[string]interface{}{
+						"type":        "string",
+						"description": "UP主UID，也支持用户空间链接或完整用户名（用户名不唯一时请先使用resolve_user）",
+					},
+					"count": map[string]interface{}{
+						"type":        "integer",
+						"description": "充电的B币数量（2-9999）",
+						"minimum":     2,
+						"maximum":     9999,
+					},
+					"confirm": map[string]interface{}{
+						"type":        "boolean",
+						"description": "确认花费B币，必须为true才会执行充电",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"user_id", "count"},
+			},
+		},
+		{
+			Name:        "get_user_info",
+			Description: "获取用户空间资料：昵称、签名、等级、性别、头像、关注数、粉丝数和认证信息",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"user_id": map[string]interface{}{
+						"type":        "string",
+						"description": "用户UID，也支持用户空间链接或完整用户名（用户名不唯一时请先使用resolve_user）",
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选，登录状态下不易触发风控）",
+					},
+				},
+				"required": []string{"user_id"},
+			},
+		},
+		{
+			Name:        "get_user_videos",
+			Description: "获取用户发布的视频列表",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"user_id": map[string]interface{}{
+						"type":        "string",
+						"description": "用户UID，也支持用户空间链接或完整用户名（用户名不唯一时请先使用resolve_user）",
+					},
+					"page": map[string]interface{}{
+						"type":        "integer",
+						"description": "页码",
+						"default":     1,
+						"minimum":     1,
+					},
+					"page_size": map[string]interface{}{
+						"type":        "integer",
+						"description": "每页数量",
+						"default":     20,
+						"minimum":     1,
+						"maximum":     50,
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选，登录状态下不易触发风控）",
+					},
+				},
+				"required": []string{"user_id"},
+			},
+		},
+		{
+			Name:        "resolve_user",
+			Description: "根据用户名或用户空间链接查找用户UID，返回候选用户列表（UID、粉丝数、投稿数），结果可用于follow_user/get_user_videos",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "用户名、UID或用户空间链接（如 https://space.bilibili.com/123）",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "最多返回的候选数量（可选，默认10）",
+						"default":     10,
+						"minimum":     1,
+						"maximum":     20,
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选，登录状态下不易触发风控）",
+					},
+				},
+				"required": []string{"query"},
+			},
+		},
+		{
+			Name:        "search_videos",
+			Description: "按关键词搜索B站视频，返回BV号、标题、UP主、播放数、时长和发布时间，结果可用于get_video_info/download_media",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"keyword": map[string]interface{}{
+						"type":        "string",
+						"description": "搜索关键词",
+					},
+					"order": map[string]interface{}{
+						"type":        "string",
+						"description": "排序方式（可选，默认totalrank）：totalrank=综合排序, click=最多播放, pubdate=最新发布, dm=最多弹幕, stow=最多收藏",
+						"enum":        []string{"totalrank", "click", "pubdate", "dm", "stow"},
+						"default":     "totalrank",
+					},
+					"page": map[string]interface{}{
+						"type":        "integer",
+						"description": "页码（可选，默认1，每页20个）",
+						"default":     1,
+						"minimum":     1,
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选，登录状态下不易触发风控）",
+					},
+				},
+				"required": []string{"keyword"},
+			},
+		},
+		{
+			Name:        "get_related_videos",
+			Description: "获取视频的相关推荐列表（不需要登录），返回BV号、标题、UP主和播放数",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+		{
+			Name:        "get_popular_videos",
+			Description: "获取B站综合热门视频（不需要登录），返回BV号、标题、UP主、播放数和简介",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"page": map[string]interface{}{
+						"type":        "integer",
+						"description": "页码（可选，默认1）",
+						"default":     1,
+						"minimum":     1,
+					},
+					"page_size": map[string]interface{}{
+						"type":        "integer",
+						"description": "每页数量（可选，默认20，最大50）",
+						"default":     20,
+						"minimum":     1,
+						"maximum":     50,
+					},
+				},
+			},
+		},
+		{
+			Name:        "get_ranking",
+			Description: "获取B站排行榜（不需要登录），可按分区筛选，返回BV号、标题、UP主、播放数和简介",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"tid": map[string]interface{}{
+						"type":        "integer",
+						"description": "分区ID（可选，默认0=全站）：1=动画, 3=音乐, 4=游戏, 5=娱乐, 36=知识, 119=鬼畜, 129=舞蹈, 155=时尚, 160=生活, 181=影视, 188=科技, 211=美食, 217=动物圈, 223=汽车, 234=运动",
+						"default":     0,
+						"minimum":     0,
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "返回的条数（可选，默认20，最大100）",
+						"default":     20,
+						"minimum":     1,
+						"maximum":     100,
+					},
+				},
+			},
+		},
+
+		// 可选功能 - Whisper音频转录
+		{
+			Name:        "whisper_audio_2_text",
+			Description: "使用Whisper.cpp将音频文件转录为文字。支持多种音频格式，自动转换为最适合的格式进行识别。需要先运行 ./bilibili-whisper-init 进行初始化",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"audio_path": map[string]interface{}{
+						"type":        "string",
+						"description": "音频文件路径（支持mp3, wav, m4a, flac等格式）",
+					},
+					"language": map[string]interface{}{
+						"type":        "string",
+						"description": "识别语言代码：zh=中文, en=英文, ja=日语, auto=自动检测",
+						"default":     "zh",
+					},
+					"model": map[string]interface{}{
+						"type":        "string",
+						"description": "使用的模型（建议不传此参数，系统会自动选择最佳可用模型）。可选值：auto=智能选择最佳, tiny=最快, base=平衡, small=推荐, medium=高质量, large=最佳。如果指定模型不存在，会自动降级到可用的最佳模型",
+						"enum":        []string{"auto", "tiny", "base", "small", "medium", "large"},
+						"default":     "auto",
+					},
+					"plain": map[string]interface{}{
+						"type":        "boolean",
+						"description": "仅返回转录文本，不包含文件信息、模型列表等格式化内容（可选，默认false）",
+						"default":     false,
+					},
+					"output_format": map[string]interface{}{
+						"type":        "string",
+						"description": "转录结果文件格式，写在音频文件旁边（可选，默认使用配置 features.whisper.output_format=srt）。json 会在结果中返回带起止时间的片段，便于按时间引用",
+						"enum":        []string{"srt", "json", "vtt", "txt"},
+					},
+					"task": map[string]interface{}{
+						"type":        "string",
+						"description": "转录任务（可选，默认使用配置 features.whisper.task=transcribe）：transcribe=按原语言转录，translate=无论源语言都输出英文译文",
+						"enum":        []string{"transcribe", "translate"},
+					},
+					"initial_prompt": map[string]interface{}{
+						"type":        "string",
+						"description": "初始提示词（可选，默认使用配置 features.whisper.initial_prompt）：写入音频中出现的人名、专业术语等，可显著提高识别准确率",
+					},
+					"temperature": map[string]interface{}{
+						"type":        "number",
+						"description": "采样温度，0-1之间（可选，默认使用配置 features.whisper.temperature=0）：0为确定性解码，出现重复文本时可适当调高",
+						"minimum":     0,
+						"maximum":     1,
+					},
+				},
+				"required": []string{"audio_path"},
+			},
+		},
+		{
+			Name:        "whisper_detect_language",
+			Description: "使用Whisper.cpp检测音频的语言（只分析开头30秒，速度较快），返回语言代码和置信度，可用于决定后续转录使用的语言",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"audio_path": map[string]interface{}{
+						"type":        "string",
+						"description": "音频文件路径（支持mp3, wav, m4a, flac等格式）",
+					},
+				},
+				"required": []string{"audio_path"},
+			},
+		},
+		{
+			Name:        "transcribe_video",
+			Description: "一步完成视频转录：下载视频音频后使用Whisper.cpp转录为文字。需要先运行 ./bilibili-whisper-init 进行初始化",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频BV号、AV号或视频链接（支持b23.tv短链接）",
+					},
+					"output_format": map[string]interface{}{
+						"type":        "string",
+						"description": "转录结果文件格式（可选，默认使用配置 features.whisper.output_format=srt）。json 会在结果中返回带起止时间的片段",
+						"enum":        []string{"srt", "json", "vtt", "txt"},
+					},
+					"task": map[string]interface{}{
+						"type":        "string",
+						"description": "转录任务（可选，默认使用配置 features.whisper.task=transcribe）：transcribe=按原语言转录，translate=无论源语言都输出英文译文",
+						"enum":        []string{"transcribe", "translate"},
+					},
+					"output_dir": map[string]interface{}{
+						"type":        "string",
+						"description": "音频和转录文件的输出目录（可选，默认为./downloads）",
+					},
+					"keep_audio": map[string]interface{}{
+						"type":        "boolean",
+						"description": "转录后是否保留下载的音频m4a（可选，默认使用配置 features.whisper.keep_audio=true，转录失败时总是保留）",
+					},
+					"audio_quality": map[string]interface{}{
+						"type":        "string",
+						"description": "转录使用的音轨（可选，默认default）：default=最佳标准音轨；high=优先无损，其次杜比；flac=Hi-Res无损；dolby=杜比全景声。无损和杜比通常需要大会员，不可用时回退到标准音轨",
+						"enum":        []string{"default", "high", "flac", "dolby"},
+					},
+					"account_name": map[string]interface{}{
+						"type":        "string",
+						"description": "指定使用的账号名称（可选）",
+					},
+				},
+				"required": []string{"video_id"},
+			},
+		},
+
+		// 视频流相关
+		{
+			Name:        "get_video_stream",
+			Description: "获取视频播放地址，直接返回可用的音频和视频流URL。只需提供视频ID即可，会自动获取第一个分P的播放地址",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"video_id": map[string]interface{}{
+						"type":        "string",
+						"description": "视频ID（BV号或av号）",
+					},
+					"cid": map[string]interface{}{
+						"type":        "number",
+						"description": "视频分P的CID（可选，不指定则自动获取第一个分P）",
+					},
+					"quality": map[string]interface{}{
+						"type":        "number",
+						"description": "视频清晰度（可选）：16=360P, 32=480P, 64=720P, 80=1080P, 112=1080P+, 116=1080P60, 120=4K, 125=HDR, 127=8K",
+					},
+					"fnval": map[string]interface{}{
+						"type":        "number",
+						"description": "视频流格式（可选）：1=MP4, 16=DASH, 64=HDR, 128=4K, 256=杜比音频, 512=杜比视界, 1024=8K, 2048=AV1, 4048=所有DASH（返回全部编码、杜比/无损音轨和8K，并按编码分组）",
+					},
+					"platform": map[string]interface{}{
+						"type":        "string",
+						"description": "平台标识（可选）：pc=PC端（有防盗链），html5=移动端（无防盗链）",
+					},
+					"preset": map[string]interface{}{
+						"type":        "string",
+						"description": "参数预设（可选）：mobile_mp4=无防盗链MP4(≤720P), web_dash=网页DASH 1080P, web_4k=网页DASH 4K/HDR（需大会员）, web_all=全部DASH特性（所有编码/杜比/无损/8K）。显式传入的quality/fnval/platform会覆盖预设",
+						"enum":        []string{"mobile_mp4", "web_dash", "web_4k", "web_all"},
+					},
+					"no_cache": map[string]interface{}{
+						"type":        "boolean",
+						"description": "跳过视频信息缓存，强制重新获取（可选，默认false）",
+					},
+					"emit_command": map[string]interface{}{
+						"type":        "boolean",
+						"description": "在推荐流中附带可直接执行的ffmpeg命令，合并推荐的音视频流为mp4（可选，默认false）",
 					},
 					"account_name": map[string]interface{}{
 						"type":        "string",
@@ -0,0 +1,29 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+)
+
+// 工具参数中的preset枚举必须与内置预设保持一致
+func TestPresetEnumsMatchStreamPresets(t *testing.T) {
+	want := api.StreamPresetNames()
+	found := 0
+	for _, tool := range GetMCPTools() {
+		schema, _ := tool.InputSchema.(map[string]interface{})
+		properties, _ := schema["properties"].(map[string]interface{})
+		preset, ok := properties["preset"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		found++
+		if enum, _ := preset["enum"].([]string); !reflect.DeepEqual(enum, want) {
+			t.Errorf("%s preset enum = %v, want %v", tool.Name, enum, want)
+		}
+	}
+	if found < 2 {
+		t.Fatalf("found %d tools with preset, want download_media and get_video_stream", found)
+	}
+}
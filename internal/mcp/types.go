@@ -18,6 +18,13 @@ type JSONRPCResponse struct {
 	ID      interface{}   `json:"id"`
 }
 
+// JSONRPCNotification JSON-RPC 通知（没有id，不需要响应）
+type JSONRPCNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
 // JSONRPCError JSON-RPC 错误
 type JSONRPCError struct {
 	Code    int         `json:"code"`
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shirenchuang/bilibili-mcp/internal/bilibili/api"
+)
+
+// shortLinkTimeout 解析短链接的超时时间
+const shortLinkTimeout = 10 * time.Second
+
+var (
+	// bvidPattern 匹配输入中任意位置的BV号
+	bvidPattern = regexp.MustCompile(`(?:^|[^0-9A-Za-z])([Bb][Vv]1[0-9A-Za-z]{9})(?:[^0-9A-Za-z]|$)`)
+	// avidPattern 匹配输入中任意位置的AV号
+	avidPattern = regexp.MustCompile(`(?:^|[^0-9A-Za-z])[Aa][Vv](\d+)(?:[^0-9A-Za-z]|$)`)
+	// shortLinkPattern 匹配b23.tv等B站短链接
+	shortLinkPattern = regexp.MustCompile(`(?i)(?:https?://)?(?:b23\.tv|bili2233\.cn)/[0-9A-Za-z]+`)
+)
+
+// normalizeVideoID 从BV号、AV号、视频链接或b23.tv短链接中提取视频ID，
+// 返回 BV1xxxxxxxxx 或 av123 形式，短链接会发起请求解析跳转地址
+func normalizeVideoID(ctx context.Context, input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", errors.New("视频ID不能为空")
+	}
+
+	if link := shortLinkPattern.FindString(input); link != "" {
+		resolved, err := resolveShortLink(ctx, link)
+		if err != nil {
+			return "", err
+		}
+		input = resolved
+	}
+
+	// 只看路径部分，避免误匹配查询参数中的其他视频
+	path := input
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+
+	if match := bvidPattern.FindStringSubmatch(path); match != nil {
+		return "BV" + match[1][2:], nil
+	}
+	if match := avidPattern.FindStringSubmatch(path); match != nil {
+		return "av" + match[1], nil
+	}
+
+	return "", errors.New("视频ID格式错误，应为BV号（如BV1234567890）、AV号（如av123456）或B站视频链接")
+}
+
+// resolveShortLink 请求短链接并返回跳转后的地址，随工具调用的ctx取消
+func resolveShortLink(ctx context.Context, link string) (string, error) {
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, link, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "解析短链接失败")
+	}
+	client := api.NewHTTPClient(shortLinkTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "解析短链接失败")
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", errors.Errorf("解析短链接失败: HTTP %d", resp.StatusCode)
+	}
+	return resp.Request.URL.String(), nil
+}
@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNormalizeVideoID(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"BV1xx411c7mD", "BV1xx411c7mD"},
+		{"bv1xx411c7mD", "BV1xx411c7mD"},
+		{"  BV1xx411c7mD\n", "BV1xx411c7mD"},
+		{"av170001", "av170001"},
+		{"AV170001", "av170001"},
+		{"https://www.bilibili.com/video/BV1xx411c7mD", "BV1xx411c7mD"},
+		{"https://www.bilibili.com/video/BV1xx411c7mD/?p=2&share_source=copy", "BV1xx411c7mD"},
+		{"https://www.bilibili.com/video/BV1xx411c7mD?p=3#reply", "BV1xx411c7mD"},
+		{"https://www.bilibili.com/video/av170001?p=2", "av170001"},
+		{"https://m.bilibili.com/video/BV1xx411c7mD", "BV1xx411c7mD"},
+		{"m.bilibili.com/video/av170001/", "av170001"},
+		{"【标题】 https://www.bilibili.com/video/BV1xx411c7mD 分享", "BV1xx411c7mD"},
+		// 只看路径，查询参数中的其他视频ID不应被匹配
+		{"https://www.bilibili.com/video/BV1xx411c7mD?from=BV1yy411c7mE", "BV1xx411c7mD"},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeVideoID(context.Background(), tt.input)
+		if err != nil {
+			t.Errorf("normalizeVideoID(%q) error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeVideoID(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeVideoIDInvalid(t *testing.T) {
+	for _, input := range []string{"", "   ", "BV123", "hello", "https://www.bilibili.com/", "avabc"} {
+		if got, err := normalizeVideoID(context.Background(), input); err == nil {
+			t.Errorf("normalizeVideoID(%q) = %q, want error", input, got)
+		}
+	}
+}
+
+func TestNormalizeVideoIDShortLinkHonorsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := normalizeVideoID(ctx, "https://b23.tv/abc123"); err == nil {
+		t.Fatal("short link resolved with a cancelled context")
+	}
+}
+
+func TestResolveShortLink(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/abc123", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("method = %s, want HEAD", r.Method)
+		}
+		http.Redirect(w, r, "/video/BV1xx411c7mD?p=2", http.StatusFound)
+	})
+	mux.HandleFunc("/video/", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resolved, err := resolveShortLink(context.Background(), server.URL+"/abc123")
+	if err != nil {
+		t.Fatalf("resolveShortLink: %v", err)
+	}
+	if resolved != server.URL+"/video/BV1xx411c7mD?p=2" {
+		t.Fatalf("resolved = %q", resolved)
+	}
+
+	got, err := normalizeVideoID(context.Background(), resolved)
+	if err != nil || got != "BV1xx411c7mD" {
+		t.Fatalf("normalizeVideoID(%q) = %q, %v", resolved, got, err)
+	}
+}
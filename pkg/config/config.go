@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/viper"
@@ -16,6 +19,7 @@ type Config struct {
 	Bilibili BilibiliConfig `mapstructure:"bilibili"`
 	Browser  BrowserConfig  `mapstructure:"browser"`
 	Features FeaturesConfig `mapstructure:"features"`
+	Download DownloadConfig `mapstructure:"download"`
 	Logging  LoggingConfig  `mapstructure:"logging"`
 	Accounts AccountsConfig `mapstructure:"accounts"`
 
@@ -25,8 +29,9 @@ type Config struct {
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Port string `mapstructure:"port"`
-	Host string `mapstructure:"host"`
+	Port      string `mapstructure:"port"`
+	Host      string `mapstructure:"host"`
+	AuthToken string `mapstructure:"auth_token"` // 设置后HTTP请求需携带 Authorization: Bearer <token>
 }
 
 // BilibiliConfig B站相关配置
@@ -34,6 +39,26 @@ type BilibiliConfig struct {
 	BaseURL     string `mapstructure:"base_url"`
 	APIURL      string `mapstructure:"api_url"`
 	PassportURL string `mapstructure:"passport_url"`
+
+	// 空间投稿搜索触发风控(-412)时的重试策略
+	SpaceSearchRetries int           `mapstructure:"space_search_retries"`
+	SpaceSearchBackoff time.Duration `mapstructure:"space_search_backoff"`
+
+	// 视频信息缓存有效期，0表示禁用缓存
+	VideoInfoCacheTTL time.Duration `mapstructure:"video_info_cache_ttl"`
+
+	// 合并并发的相同视频信息请求，共享同一次请求结果
+	DedupeRequests bool `mapstructure:"dedupe_requests"`
+
+	// 记录API请求和响应（csrf已脱敏），仅用于调试
+	DebugHTTP bool `mapstructure:"debug_http"`
+
+	// 连接错误和429/5xx响应的重试策略，退避时间按次数指数增长并加入随机抖动
+	RetryAttempts  int           `mapstructure:"retry_attempts"`
+	RetryBaseDelay time.Duration `mapstructure:"retry_base_delay"`
+
+	// 访问B站使用的HTTP/SOCKS5代理，留空时使用 HTTPS_PROXY/HTTP_PROXY 环境变量
+	ProxyURL string `mapstructure:"proxy_url"`
 }
 
 // BrowserConfig 浏览器配置
@@ -43,6 +68,9 @@ type BrowserConfig struct {
 	Timeout   time.Duration         `mapstructure:"timeout"`
 	PoolSize  int                   `mapstructure:"pool_size"`
 	Viewport  BrowserViewportConfig `mapstructure:"viewport"`
+
+	ContextIdleTTL time.Duration `mapstructure:"context_idle_ttl"` // 账号上下文空闲多久后关闭，0表示不复用
+	MaxContexts    int           `mapstructure:"max_contexts"`     // 同时打开的上下文上限，0表示浏览器池大小的两倍
 }
 
 // BrowserViewportConfig 浏览器视口配置
@@ -53,7 +81,8 @@ type BrowserViewportConfig struct {
 
 // FeaturesConfig 功能特性配置
 type FeaturesConfig struct {
-	Whisper WhisperConfig `mapstructure:"whisper"`
+	Whisper    WhisperConfig            `mapstructure:"whisper"`
+	RateLimits map[string]time.Duration `mapstructure:"rate_limits"` // 各操作的最小调用间隔，0表示不限制，default为未配置操作的间隔
 }
 
 // WhisperConfig Whisper配置
@@ -67,6 +96,30 @@ type WhisperConfig struct {
 	TimeoutSeconds int    `mapstructure:"timeout_seconds"`
 	EnableGPU      bool   `mapstructure:"enable_gpu"`
 	EnableCoreMl   bool   `mapstructure:"enable_core_ml"`
+	KeepAudio      bool   `mapstructure:"keep_audio"`    // 转录后保留下载的原始音频（m4a）
+	KeepWAV        bool   `mapstructure:"keep_wav"`      // 转录后保留转换出的16kHz WAV中间文件
+	OutputFormat   string `mapstructure:"output_format"` // 转录输出格式：srt/json/vtt/txt
+	Task           string `mapstructure:"task"`          // 转录任务：transcribe（原语言）/translate（翻译为英文）
+
+	InitialPrompt string  `mapstructure:"initial_prompt"` // 转录的初始提示词，可提供人名、术语提高识别准确率
+	Temperature   float64 `mapstructure:"temperature"`    // 采样温度（0-1），0为确定性解码
+}
+
+// DownloadConfig 下载配置
+type DownloadConfig struct {
+	KeepPartial bool   `mapstructure:"keep_partial"` // 超时或取消时是否保留未完成的 .downloading 文件
+	Platform    string `mapstructure:"platform"`     // 下载请求的平台标识：html5（无防盗链）或 pc
+	VerifyMerge bool   `mapstructure:"verify_merge"` // 合并后用ffprobe校验时长，失败时保留中间文件
+
+	FilenameTemplate string `mapstructure:"filename_template"` // 文件名模板，支持 {title}、{part_title}、{page}，空=自动
+	PartNaming       bool   `mapstructure:"part_naming"`       // 多P视频的文件名是否自动加入分P序号和标题
+	MaxQuality       string `mapstructure:"max_quality"`       // 自动选择清晰度的上限，如 1080p、720p 或清晰度代码，空=不限制
+
+	ParallelChunks int `mapstructure:"parallel_chunks"` // 大文件分块并发下载的连接数，1=单连接
+
+	// 所有下载共享的限制，避免多个下载同时进行时触发CDN限流
+	MaxConcurrent  int   `mapstructure:"max_concurrent"`    // 同时下载的流数量（分块下载算一个流）
+	MaxBytesPerSec int64 `mapstructure:"max_bytes_per_sec"` // 总下载带宽上限（字节/秒），0=不限速
 }
 
 // LoggingConfig 日志配置
@@ -74,12 +127,21 @@ type LoggingConfig struct {
 	Level  string `mapstructure:"level"`
 	Format string `mapstructure:"format"`
 	Output string `mapstructure:"output"`
+
+	// 日志文件按大小轮转
+	MaxSizeMB  int `mapstructure:"max_size_mb"`  // 单个日志文件的最大大小（MB）
+	MaxBackups int `mapstructure:"max_backups"`  // 保留的旧日志文件数量，0表示不按数量清理
+	MaxAgeDays int `mapstructure:"max_age_days"` // 旧日志文件保留天数，0表示不按时间清理
 }
 
 // AccountsConfig 账号配置
 type AccountsConfig struct {
-	CookieDir      string `mapstructure:"cookie_dir"`
-	DefaultAccount string `mapstructure:"default_account"`
+	CookieDir         string `mapstructure:"cookie_dir"`
+	DefaultAccount    string `mapstructure:"default_account"`
+	AutoSelectDefault bool   `mapstructure:"auto_select_default"` // 没有可用默认账号时自动选择最近使用的账号
+	CheckOnStartup    bool   `mapstructure:"check_on_startup"`    // 启动时检查所有账号登录状态
+	CheckConcurrency  int    `mapstructure:"check_concurrency"`   // 账号检查的并发数
+	EncryptCookies    bool   `mapstructure:"encrypt_cookies"`     // 使用本机生成的密钥加密保存cookies
 }
 
 // ResolvedPaths 运行时解析的路径
@@ -90,10 +152,27 @@ type ResolvedPaths struct {
 	CookieDir      string
 }
 
-var globalConfig *Config
+// 覆盖日志级别的环境变量名，LogLevelEnv 优先于通用的 GenericLogLevelEnv
+const (
+	LogLevelEnv        = "BILIBILI_MCP_LOG_LEVEL"
+	GenericLogLevelEnv = "LOG_LEVEL"
+)
+
+// globalConfig 全局配置，重新加载时整体替换，读写无需加锁
+var globalConfig atomic.Pointer[Config]
 
-// Load 加载配置文件，如果文件不存在则使用默认值
+// Load 加载配置文件并设为全局配置，如果文件不存在则使用默认值
 func Load(configPath string) (*Config, error) {
+	cfg, err := Parse(configPath)
+	if err != nil {
+		return nil, err
+	}
+	globalConfig.Store(cfg)
+	return cfg, nil
+}
+
+// Parse 加载并校验配置文件但不替换全局配置，用于重新加载时先检查新配置
+func Parse(configPath string) (*Config, error) {
 	viper.SetConfigFile(configPath)
 	viper.SetConfigType("yaml")
 
@@ -123,28 +202,179 @@ func Load(configPath string) (*Config, error) {
 	}
 	config.resolved = resolved
 
-	globalConfig = &config
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
+// Validate 检查配置取值是否有效，一次性返回所有问题
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Browser.PoolSize < 1 {
+		problems = append(problems, fmt.Sprintf("browser.pool_size 必须大于等于1（当前: %d），为0时获取浏览器实例会一直等待", c.Browser.PoolSize))
+	}
+
+	if port, err := strconv.Atoi(strings.TrimSpace(c.Server.Port)); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("server.port 必须是1-65535之间的数字（当前: %q）", c.Server.Port))
+	}
+
+	switch c.Logging.Format {
+	case "text", "json":
+	default:
+		problems = append(problems, fmt.Sprintf("logging.format 只支持 text 或 json（当前: %q）", c.Logging.Format))
+	}
+
+	if c.Download.MaxConcurrent < 1 {
+		problems = append(problems, fmt.Sprintf("download.max_concurrent 必须大于等于1（当前: %d）", c.Download.MaxConcurrent))
+	}
+	if c.Download.MaxBytesPerSec < 0 {
+		problems = append(problems, fmt.Sprintf("download.max_bytes_per_sec 不能为负数（当前: %d），0表示不限速", c.Download.MaxBytesPerSec))
+	}
+
+	whisper := c.Features.Whisper
+	if whisper.TimeoutSeconds < 0 {
+		problems = append(problems, fmt.Sprintf("features.whisper.timeout_seconds 不能为负数（当前: %d），0表示使用默认的1200秒", whisper.TimeoutSeconds))
+	}
+	if whisper.Temperature < 0 || whisper.Temperature > 1 {
+		problems = append(problems, fmt.Sprintf("features.whisper.temperature 必须在0-1之间（当前: %g）", whisper.Temperature))
+	}
+	if whisper.CPUThreads < 0 {
+		problems = append(problems, fmt.Sprintf("features.whisper.cpu_threads 不能为负数（当前: %d），0表示由whisper自动决定", whisper.CPUThreads))
+	}
+	if whisper.Enabled {
+		// default_model 为 auto 时 model_path 只是候选之一，whisper会在 ./models 和 whisper.cpp 的模型目录中查找可用模型
+		autoModel := whisper.DefaultModel == "" || whisper.DefaultModel == "auto"
+		for _, path := range []struct {
+			key, value, resolved string
+			optional             bool
+		}{
+			{"features.whisper.whisper_cpp_path", whisper.WhisperCppPath, c.GetResolvedWhisperCppPath(), false},
+			{"features.whisper.model_path", whisper.ModelPath, c.GetResolvedModelPath(), autoModel},
+		} {
+			if missing := undefinedEnvVars(path.value); len(missing) > 0 {
+				problems = append(problems, fmt.Sprintf("%s 引用了未设置的环境变量 %s（当前: %q）", path.key, strings.Join(missing, ", "), path.value))
+				continue
+			}
+			if path.resolved == "" || path.optional {
+				continue
+			}
+			if _, err := os.Stat(path.resolved); err != nil {
+				problems = append(problems, fmt.Sprintf("%s 指向的路径不存在: %s（可运行 whisper-init 安装，或将 features.whisper.enabled 设为 false）", path.key, path.resolved))
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("配置无效:\n  - %s", strings.Join(problems, "\n  - "))
+}
+
+// undefinedEnvVars 返回路径中引用但未设置的环境变量，避免展开为空字符串后指向错误的位置
+func undefinedEnvVars(path string) []string {
+	var missing []string
+	os.Expand(path, func(name string) string {
+		if _, ok := os.LookupEnv(name); !ok {
+			missing = append(missing, name)
+		}
+		return ""
+	})
+	return missing
+}
+
 // Get 获取全局配置
 func Get() *Config {
-	return globalConfig
+	return globalConfig.Load()
+}
+
+// Set 替换全局配置，用于重新加载配置后只保留可热更新的部分
+func Set(cfg *Config) {
+	globalConfig.Store(cfg)
+}
+
+// ApplyReloadable 在当前配置的副本上应用新配置中可以运行时生效的部分（限流、转录、日志级别），
+// 返回合并后的配置，以及发生变化但需要重启服务才能生效的配置节
+func (c *Config) ApplyReloadable(next *Config) (*Config, []string) {
+	merged := *c
+	merged.Features = next.Features
+	merged.Logging.Level = next.Logging.Level
+
+	resolved := ResolvedPaths{}
+	if c.resolved != nil {
+		resolved = *c.resolved
+	}
+	if next.resolved != nil {
+		resolved.WhisperCppPath = next.resolved.WhisperCppPath
+		resolved.ModelPath = next.resolved.ModelPath
+	}
+	merged.resolved = &resolved
+
+	// 日志级别可以热更新，比较其余日志配置时忽略
+	currentLogging, nextLogging := c.Logging, next.Logging
+	currentLogging.Level, nextLogging.Level = "", ""
+
+	var restartRequired []string
+	sections := []struct {
+		name          string
+		current, next interface{}
+	}{
+		{"server", c.Server, next.Server},
+		{"bilibili", c.Bilibili, next.Bilibili},
+		{"browser", c.Browser, next.Browser},
+		{"download", c.Download, next.Download},
+		{"accounts", c.Accounts, next.Accounts},
+		{"logging", currentLogging, nextLogging},
+	}
+	for _, section := range sections {
+		if !reflect.DeepEqual(section.current, section.next) {
+			restartRequired = append(restartRequired, section.name)
+		}
+	}
+
+	return &merged, restartRequired
+}
+
+// ApplyLogLevelOverride 使用命令行参数或环境变量覆盖日志级别
+// 优先级: 命令行参数 > BILIBILI_MCP_LOG_LEVEL > LOG_LEVEL > 配置文件
+func (c *Config) ApplyLogLevelOverride(flagLevel string) {
+	if level := strings.TrimSpace(os.Getenv(GenericLogLevelEnv)); level != "" {
+		c.Logging.Level = level
+	}
+	if level := strings.TrimSpace(os.Getenv(LogLevelEnv)); level != "" {
+		c.Logging.Level = level
+	}
+	if level := strings.TrimSpace(flagLevel); level != "" {
+		c.Logging.Level = level
+	}
 }
 
 // setDefaults 设置默认配置值
 func setDefaults() {
 	viper.SetDefault("server.port", "18666")
 	viper.SetDefault("server.host", "localhost")
+	viper.SetDefault("server.auth_token", "")
 
 	viper.SetDefault("bilibili.base_url", "https://www.bilibili.com")
 	viper.SetDefault("bilibili.api_url", "https://api.bilibili.com")
 	viper.SetDefault("bilibili.passport_url", "https://passport.bilibili.com")
+	viper.SetDefault("bilibili.space_search_retries", 1)
+	viper.SetDefault("bilibili.space_search_backoff", "3s")
+	viper.SetDefault("bilibili.video_info_cache_ttl", "5m")
+	viper.SetDefault("bilibili.dedupe_requests", true)
+	viper.SetDefault("bilibili.debug_http", false)
+	viper.SetDefault("bilibili.proxy_url", "")
+	viper.SetDefault("bilibili.retry_attempts", 3)
+	viper.SetDefault("bilibili.retry_base_delay", "500ms")
 
 	viper.SetDefault("browser.headless", true)
 	viper.SetDefault("browser.user_agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 	viper.SetDefault("browser.timeout", "30s")
 	viper.SetDefault("browser.pool_size", 2)
+	viper.SetDefault("browser.context_idle_ttl", "5m")
+	viper.SetDefault("browser.max_contexts", 0)
 	viper.SetDefault("browser.viewport.width", 1920)
 	viper.SetDefault("browser.viewport.height", 1080)
 
@@ -157,13 +387,56 @@ func setDefaults() {
 	viper.SetDefault("features.whisper.timeout_seconds", 1200)
 	viper.SetDefault("features.whisper.enable_gpu", true)
 	viper.SetDefault("features.whisper.enable_core_ml", true)
+	viper.SetDefault("features.whisper.keep_audio", true)
+	viper.SetDefault("features.whisper.keep_wav", false)
+	viper.SetDefault("features.whisper.output_format", "srt")
+	viper.SetDefault("features.whisper.task", "transcribe")
+	viper.SetDefault("features.whisper.initial_prompt", "")
+	viper.SetDefault("features.whisper.temperature", 0.0)
+
+	viper.SetDefault("features.rate_limits.default", "5s")
+	viper.SetDefault("features.rate_limits.like_video", "5s")
+	viper.SetDefault("features.rate_limits.dislike_video", "5s")
+	viper.SetDefault("features.rate_limits.share_video", "10s")
+	viper.SetDefault("features.rate_limits.coin_video", "10s")
+	viper.SetDefault("features.rate_limits.favorite_video", "10s")
+	viper.SetDefault("features.rate_limits.triple_video", "10s")
+	viper.SetDefault("features.rate_limits.reply_comment", "10s")
+	viper.SetDefault("features.rate_limits.like_comment", "3s")
+	viper.SetDefault("features.rate_limits.send_danmaku", "5s")
+	viper.SetDefault("features.rate_limits.follow_user", "10s")
+	viper.SetDefault("features.rate_limits.charge_user", "5m")
+	viper.SetDefault("features.rate_limits.get_user_info", "10s")
+	viper.SetDefault("features.rate_limits.get_user_videos", "20s")
+	viper.SetDefault("features.rate_limits.resolve_user", "5s")
+	viper.SetDefault("features.rate_limits.search_videos", "3s")
+	viper.SetDefault("features.rate_limits.create_favorite_folder", "5s")
+	viper.SetDefault("features.rate_limits.watch_later", "3s")
+	viper.SetDefault("features.rate_limits.report", "60s")
+
+	viper.SetDefault("download.keep_partial", false)
+	viper.SetDefault("download.platform", "html5")
+	viper.SetDefault("download.verify_merge", true)
+	viper.SetDefault("download.filename_template", "")
+	viper.SetDefault("download.part_naming", true)
+	viper.SetDefault("download.max_quality", "")
+	viper.SetDefault("download.parallel_chunks", 4)
+	viper.SetDefault("download.max_concurrent", 2)
+	viper.SetDefault("download.max_bytes_per_sec", 0)
 
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "text")
 	viper.SetDefault("logging.output", "./logs/bilibili-mcp.log")
+	viper.SetDefault("logging.max_size_mb", 100)
+	viper.SetDefault("logging.max_backups", 3)
+	viper.SetDefault("logging.max_age_days", 7)
 
 	viper.SetDefault("accounts.cookie_dir", "./cookies")
 	viper.SetDefault("accounts.default_account", "")
+	viper.SetDefault("accounts.auto_select_default", false)
+	viper.SetDefault("accounts.check_on_startup", false)
+	viper.SetDefault("accounts.check_concurrency", 4)
+	viper.SetDefault("accounts.encrypt_cookies", true)
 }
 
 // createResolvedPaths 创建解析后的路径结构，不修改原始配置
@@ -275,3 +548,16 @@ func (c *Config) GetResolvedCookieDir() string {
 	}
 	return c.Accounts.CookieDir
 }
+
+// EffectiveProxyURL 返回实际生效的代理地址：优先使用 bilibili.proxy_url，其次是代理环境变量
+func (c *Config) EffectiveProxyURL() string {
+	if c != nil && c.Bilibili.ProxyURL != "" {
+		return c.Bilibili.ProxyURL
+	}
+	for _, key := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig 写入测试配置文件
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadChangesRateLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, `
+features:
+  whisper:
+    enabled: false
+  rate_limits:
+    like_video: 5s
+`)
+
+	current, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := current.Features.RateLimits["like_video"]; got != 5*time.Second {
+		t.Fatalf("like_video = %v, want 5s", got)
+	}
+
+	writeConfig(t, path, `
+features:
+  whisper:
+    enabled: false
+  rate_limits:
+    like_video: 1s
+`)
+
+	next, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if Get() != current {
+		t.Fatal("Parse replaced the global config")
+	}
+
+	applied, restartRequired := current.ApplyReloadable(next)
+	if len(restartRequired) != 0 {
+		t.Fatalf("unexpected restart sections: %v", restartRequired)
+	}
+	if got := applied.Features.RateLimits["like_video"]; got != time.Second {
+		t.Fatalf("reloaded like_video = %v, want 1s", got)
+	}
+
+	Set(applied)
+	if got := Get().Features.RateLimits["like_video"]; got != time.Second {
+		t.Fatalf("global like_video = %v, want 1s", got)
+	}
+}
+
+// validConfig 返回可以通过校验的最小配置
+func validConfig() *Config {
+	cfg := &Config{}
+	cfg.Browser.PoolSize = 1
+	cfg.Server.Port = "18666"
+	cfg.Logging.Format = "text"
+	cfg.Download.MaxConcurrent = 2
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "ggml-base.bin")
+	writeConfig(t, model, "model")
+
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantKey string // 为空表示应通过校验
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"pool size zero", func(c *Config) { c.Browser.PoolSize = 0 }, "browser.pool_size"},
+		{"port not numeric", func(c *Config) { c.Server.Port = "http" }, "server.port"},
+		{"port out of range", func(c *Config) { c.Server.Port = "70000" }, "server.port"},
+		{"log format", func(c *Config) { c.Logging.Format = "xml" }, "logging.format"},
+		{"max concurrent zero", func(c *Config) { c.Download.MaxConcurrent = 0 }, "download.max_concurrent"},
+		{"negative bandwidth", func(c *Config) { c.Download.MaxBytesPerSec = -1 }, "download.max_bytes_per_sec"},
+		{"negative whisper timeout", func(c *Config) { c.Features.Whisper.TimeoutSeconds = -1 }, "features.whisper.timeout_seconds"},
+		{"temperature out of range", func(c *Config) { c.Features.Whisper.Temperature = 1.5 }, "features.whisper.temperature"},
+		{"negative cpu threads", func(c *Config) { c.Features.Whisper.CPUThreads = -2 }, "features.whisper.cpu_threads"},
+		{"undefined env var", func(c *Config) {
+			c.Features.Whisper.Enabled = true
+			c.Features.Whisper.WhisperCppPath = "${BILIBILI_MCP_TEST_UNSET_VAR}/whisper.cpp"
+		}, "features.whisper.whisper_cpp_path"},
+		{"missing whisper path", func(c *Config) {
+			c.Features.Whisper.Enabled = true
+			c.Features.Whisper.WhisperCppPath = filepath.Join(dir, "missing")
+		}, "features.whisper.whisper_cpp_path"},
+		{"missing model", func(c *Config) {
+			c.Features.Whisper.Enabled = true
+			c.Features.Whisper.DefaultModel = "base"
+			c.Features.Whisper.ModelPath = filepath.Join(dir, "ggml-missing.bin")
+		}, "features.whisper.model_path"},
+		{"missing model ignored with auto model", func(c *Config) {
+			c.Features.Whisper.Enabled = true
+			c.Features.Whisper.DefaultModel = "auto"
+			c.Features.Whisper.WhisperCppPath = dir
+			c.Features.Whisper.ModelPath = filepath.Join(dir, "ggml-missing.bin")
+		}, ""},
+		{"undefined env var in model path with auto model", func(c *Config) {
+			c.Features.Whisper.Enabled = true
+			c.Features.Whisper.DefaultModel = "auto"
+			c.Features.Whisper.ModelPath = "${BILIBILI_MCP_TEST_UNSET_VAR}/ggml-base.bin"
+		}, "features.whisper.model_path"},
+		{"existing whisper paths", func(c *Config) {
+			c.Features.Whisper.Enabled = true
+			c.Features.Whisper.WhisperCppPath = dir
+			c.Features.Whisper.ModelPath = model
+		}, ""},
+		{"missing paths ignored when disabled", func(c *Config) {
+			c.Features.Whisper.WhisperCppPath = filepath.Join(dir, "missing")
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantKey == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantKey) {
+				t.Fatalf("error %v does not mention %s", err, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestValidateAggregatesProblems(t *testing.T) {
+	cfg := validConfig()
+	cfg.Browser.PoolSize = 0
+	cfg.Server.Port = "0"
+	cfg.Logging.Format = "xml"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, key := range []string{"browser.pool_size", "server.port", "logging.format"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("aggregated error does not mention %s: %v", key, err)
+		}
+	}
+}
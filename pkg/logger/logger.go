@@ -4,23 +4,32 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/shirenchuang/bilibili-mcp/pkg/config"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var log *logrus.Logger
 
+// consoleOutput 控制台日志输出，stdio传输模式下需要切换到stderr以免破坏协议流
+var consoleOutput io.Writer = os.Stdout
+
+// SetConsoleOutput 设置控制台日志输出，需要在Init之前调用
+func SetConsoleOutput(w io.Writer) {
+	consoleOutput = w
+}
+
 // Init 初始化日志系统
 func Init(cfg *config.Config) error {
 	log = logrus.New()
 
-	// 设置日志级别
-	level, err := logrus.ParseLevel(cfg.Logging.Level)
-	if err != nil {
-		level = logrus.InfoLevel
+	// 设置日志级别，无效的级别使用info
+	if err := SetLevel(cfg.Logging.Level); err != nil {
+		log.SetLevel(logrus.InfoLevel)
 	}
-	log.SetLevel(level)
 
 	// 设置日志格式
 	if cfg.Logging.Format == "json" {
@@ -39,19 +48,32 @@ func Init(cfg *config.Config) error {
 			return err
 		}
 
-		// 打开日志文件
-		file, err := os.OpenFile(cfg.Logging.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-		if err != nil {
-			return err
+		// 日志文件按大小轮转，旧文件按数量和天数清理
+		file := &lumberjack.Logger{
+			Filename:   cfg.Logging.Output,
+			MaxSize:    cfg.Logging.MaxSizeMB,
+			MaxBackups: cfg.Logging.MaxBackups,
+			MaxAge:     cfg.Logging.MaxAgeDays,
+			LocalTime:  true,
 		}
 
 		// 同时输出到文件和控制台
-		log.SetOutput(io.MultiWriter(os.Stdout, file))
+		log.SetOutput(io.MultiWriter(consoleOutput, file))
 	}
 
 	return nil
 }
 
+// SetLevel 运行时切换日志级别，只修改级别，不影响日志格式和输出
+func SetLevel(level string) error {
+	parsed, err := logrus.ParseLevel(strings.TrimSpace(level))
+	if err != nil {
+		return errors.Wrapf(err, "无效的日志级别: %s", level)
+	}
+	GetLogger().SetLevel(parsed)
+	return nil
+}
+
 // GetLogger 获取日志实例
 func GetLogger() *logrus.Logger {
 	if log == nil {